@@ -4,27 +4,28 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/castrojo/tap-tools/internal/archive"
 	"github.com/castrojo/tap-tools/internal/checksum"
 	"github.com/castrojo/tap-tools/internal/desktop"
+	"github.com/castrojo/tap-tools/internal/forge"
+	"github.com/castrojo/tap-tools/internal/generator"
 	"github.com/castrojo/tap-tools/internal/github"
 	"github.com/castrojo/tap-tools/internal/homebrew"
+	"github.com/castrojo/tap-tools/internal/metadata"
 	"github.com/castrojo/tap-tools/internal/platform"
+	"github.com/castrojo/tap-tools/internal/spdx"
+	"github.com/castrojo/tap-tools/internal/ui"
 	"github.com/castrojo/tap-tools/internal/validate"
-	"github.com/charmbracelet/lipgloss"
+	"github.com/castrojo/tap-tools/internal/version"
 	"github.com/spf13/cobra"
 )
 
-var (
-	// Styles for pretty output
-	titleStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
-	successStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
-	errorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
-	infoStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
-)
-
 var rootCmd = &cobra.Command{
 	Use:   "tap-cask",
 	Short: "Generate Homebrew casks for Linux",
@@ -32,12 +33,15 @@ var rootCmd = &cobra.Command{
 
 It fetches release information from GitHub, downloads assets,
 verifies checksums, and generates properly formatted cask files.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		ui.Configure(ui.Options{NoColor: flagNoColor, Quiet: flagQuiet})
+	},
 }
 
 var generateCmd = &cobra.Command{
 	Use:   "generate [repo-url]",
-	Short: "Generate a new cask from GitHub repository",
-	Long: `Generate a new cask from a GitHub repository.
+	Short: "Generate a new cask from a GitHub or GitLab repository",
+	Long: `Generate a new cask from a GitHub or GitLab repository.
 
 Examples:
   tap-cask generate https://github.com/sublimehq/sublime_text
@@ -47,21 +51,100 @@ Examples:
 	RunE: runGenerate,
 }
 
+var bumpCmd = &cobra.Command{
+	Use:   "bump [token]",
+	Short: "Update an existing cask to the latest release",
+	Long: `Update an existing cask to the repository's latest release.
+
+The newest Linux asset is chosen the same way as generate, downloaded,
+and checksummed. Only the cask's version, sha256 and url lines are
+rewritten, so binary, artifact and zap stanzas edited by hand are kept.
+A url using #{version} is left as it is when it still matches.
+
+Examples:
+  tap-cask bump sublime-text-linux
+  tap-cask bump Casks/sublime-text-linux.rb`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBump,
+}
+
 var (
-	flagName   string
-	flagOutput string
+	flagQuiet        bool
+	flagNoColor      bool
+	flagName         string
+	flagOutput       string
+	flagTemplateURL  bool
+	flagMaxAssetSize string
+	flagVerbose      bool
+	flagSelectAsset  string
+	flagLogJSON      string
+	flagForce        bool
+	flagAllowDup     bool
+	flagExplainSum   bool
+	flagNoDesktop    bool
+	flagPostflight   bool
+	flagAssetURL     string
+	flagNoMagic      bool
+	flagNoLivecheck  bool
+	flagMetadata     string
+	flagCACert       string
+	flagTimeout      time.Duration
+	flagSHA256       string
+	flagVerifySig    string
+	flagCaveats      []string
+	flagLatest       bool
+	flagChecksumFile []string
+	flagNotes        string
+	flagCache        bool
+	flagVerifyRun    bool
+	flagAllBinaries  bool
+	flagVersion      string
 )
 
+// newForge creates the client for a repository host; tests replace it
+var newForge = forge.New
+
 func init() {
+	rootCmd.PersistentFlags().BoolVar(&flagNoColor, "no-color", false, "Disable colored output (also honors NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVarP(&flagQuiet, "quiet", "q", false, "Only print warnings and errors")
+
 	generateCmd.Flags().StringVar(&flagName, "name", "", "Override package name (will auto-append -linux)")
 	generateCmd.Flags().StringVarP(&flagOutput, "output", "o", "", "Output file path (default: Casks/<name>-linux.rb)")
+	generateCmd.Flags().StringVar(&flagMaxAssetSize, "max-asset-size", platform.DefaultMaxAssetSize, "Skip assets larger than this size (e.g. 500M, 2G; 0 disables)")
+	generateCmd.Flags().BoolVar(&flagNoDesktop, "no-desktop", false, "Skip desktop file, icon and MIME type integration (binary-only cask)")
+	generateCmd.Flags().BoolVar(&flagAllBinaries, "all-binaries", false, "Put every detected executable on PATH, not just the main binary")
+	generateCmd.Flags().BoolVar(&flagNoLivecheck, "no-livecheck", false, "Omit the livecheck block that watches upstream releases")
+	generateCmd.Flags().StringSliceVar(&flagChecksumFile, "checksum-file", nil, "Extra upstream checksum file name to try before the built-in ones (repeatable)")
+	generateCmd.Flags().StringVar(&flagCACert, "ca-cert", "", "PEM CA bundle to trust for API calls and downloads, e.g. a corporate CA (default: $TAP_CA_BUNDLE)")
+	generateCmd.Flags().DurationVar(&flagTimeout, "download-timeout", 10*time.Minute, "Give up on a download, including retries, after this long (0 disables)")
+	generateCmd.Flags().BoolVar(&flagCache, "cache", false, "Cache API responses on disk and revalidate them with ETags (also enabled by $TAP_CACHE_DIR)")
+	generateCmd.Flags().StringVar(&flagMetadata, "metadata", "metadata.yaml", "YAML file of per-package desc/homepage/license overrides (skipped if missing)")
+	generateCmd.Flags().StringArrayVar(&flagCaveats, "caveats", nil, "Custom post-install note for the caveats block (repeatable)")
+	generateCmd.Flags().StringVar(&flagNotes, "notes", "", "File of maintainer notes to add as comments after the generated header")
+	generateCmd.Flags().BoolVar(&flagNoMagic, "no-magic-comments", false, "Omit the # typed: strict and # frozen_string_literal: true comments")
+	generateCmd.Flags().StringVar(&flagVersion, "version", "", "Package the release of this tag (e.g. v1.2.3) instead of the latest one")
+	generateCmd.Flags().StringVar(&flagAssetURL, "asset-url", "", "Download this URL instead of auto-selecting a release asset")
+	generateCmd.Flags().StringVar(&flagSHA256, "sha256", "", "Known SHA256 of the --asset-url download; skips downloading it")
+	generateCmd.Flags().StringVar(&flagVerifySig, "verify-sig", "", "Verify the download against its .minisig, .asc or .sig file using this public key")
+	generateCmd.Flags().BoolVar(&flagVerifyRun, "verify-run", false, "Run the extracted binary with --version/--help in a temp dir and fail if it cannot execute")
+	generateCmd.Flags().StringVar(&flagSelectAsset, "select-asset", "", "Pick a Linux asset by index (see --verbose) or file name instead of auto-selecting")
+	generateCmd.Flags().BoolVar(&flagPostflight, "postflight-cache", true, "Refresh desktop and icon caches after install when desktop integration is present")
+	generateCmd.Flags().BoolVar(&flagTemplateURL, "template-url", false, "Replace the version in the URL with #{version} so it auto-updates")
+	generateCmd.Flags().BoolVar(&flagLatest, "version-latest", false, "Emit version :latest and sha256 :no_check for apps that only offer a rolling download")
+	generateCmd.Flags().BoolVar(&flagForce, "force", false, "Overwrite an existing file even if it was not generated by tap-tools")
+	generateCmd.Flags().BoolVar(&flagAllowDup, "allow-duplicate", false, "Generate even if a similarly named package (e.g. foo vs foo-linux) is already in the tap")
+
+	generateCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "Show why each asset was chosen")
+	generateCmd.Flags().BoolVar(&flagExplainSum, "explain-checksum", false, "Report every upstream checksum source tried and its outcome")
+	generateCmd.Flags().StringVar(&flagLogJSON, "log-json", "", "Write the selection decision as JSON to this file")
 
 	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(bumpCmd)
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, errorStyle.Render("Error: "+err.Error()))
+		ui.Error(err.Error())
 		os.Exit(1)
 	}
 }
@@ -69,133 +152,308 @@ func main() {
 func runGenerate(cmd *cobra.Command, args []string) error {
 	repoURL := args[0]
 
+	maxAssetSize, err := platform.ParseSize(flagMaxAssetSize)
+	if err != nil {
+		return fmt.Errorf("invalid --max-asset-size: %w", err)
+	}
+
+	if err := checksum.ConfigureCABundle(flagCACert); err != nil {
+		return err
+	}
+	checksum.ConfigureDownloadTimeout(flagTimeout)
+	if err := github.ConfigureCache(flagCache); err != nil {
+		return fmt.Errorf("invalid --cache: %w", err)
+	}
+
+	var notes string
+	if flagNotes != "" {
+		content, err := os.ReadFile(flagNotes)
+		if err != nil {
+			return fmt.Errorf("invalid --notes: %w", err)
+		}
+		notes = string(content)
+	}
+
+	if flagSHA256 != "" {
+		if flagAssetURL == "" {
+			return fmt.Errorf("--sha256 requires --asset-url")
+		}
+		if _, err := checksum.ParseSHA256(flagSHA256); err != nil {
+			return fmt.Errorf("invalid --sha256: %w", err)
+		}
+	}
+
+	if flagVerifySig != "" && flagSHA256 != "" {
+		return fmt.Errorf("--verify-sig needs the downloaded asset and cannot be combined with --sha256")
+	}
+
+	if flagVerifyRun && flagSHA256 != "" {
+		return fmt.Errorf("--verify-run needs the downloaded asset and cannot be combined with --sha256")
+	}
+
+	if flagLatest && (flagSHA256 != "" || flagTemplateURL || flagExplainSum) {
+		return fmt.Errorf("--version-latest skips the checksum and version and cannot be combined with --sha256, --template-url, or --explain-checksum")
+	}
+
+	if flagLatest && flagVersion != "" {
+		return fmt.Errorf("--version pins a release and cannot be combined with --version-latest")
+	}
+
+	if flagSelectAsset != "" && flagAssetURL != "" {
+		return fmt.Errorf("--select-asset cannot be combined with --asset-url")
+	}
+
 	// Parse repository URL
-	fmt.Println(titleStyle.Render("🔍 Parsing repository URL..."))
-	owner, repo, err := github.ParseRepoURL(repoURL)
+	ui.Title("🔍 Parsing repository URL...")
+	host, owner, repo, err := github.ParseRepoURL(repoURL)
 	if err != nil {
 		return fmt.Errorf("invalid repository URL: %w", err)
 	}
-	fmt.Println(successStyle.Render(fmt.Sprintf("✓ Repository: %s/%s", owner, repo)))
+	ui.Success(fmt.Sprintf("Repository: %s/%s", owner, repo))
 
-	// Create GitHub client
-	client := github.NewClient()
+	// Create the GitHub or GitLab client
+	client, err := newForge(host)
+	if err != nil {
+		return err
+	}
 
 	// Fetch repository metadata
-	fmt.Println(titleStyle.Render("\n🔍 Fetching repository metadata..."))
+	ui.Title("\n🔍 Fetching repository metadata...")
 	repository, err := client.GetRepository(owner, repo)
 	if err != nil {
 		return fmt.Errorf("failed to fetch repository: %w", err)
 	}
-	fmt.Println(successStyle.Render(fmt.Sprintf("✓ Found: %s", repository.Description)))
-	fmt.Println(infoStyle.Render(fmt.Sprintf("  Homepage: %s", repository.Homepage)))
+	ui.Success(fmt.Sprintf("Found: %s", repository.Description))
+	ui.Info(fmt.Sprintf("Homepage: %s", repository.Homepage))
+
+	// Casks have no license stanza, but report it so the package can be
+	// reviewed; fall back to the license file when the forge could not classify it
+	if repository.License == "" || repository.License == "NOASSERTION" {
+		if guessed, path := spdx.GuessRepo(client, owner, repo, spdx.LicenseFiles...); guessed != "" {
+			ui.Warn(fmt.Sprintf("Guessed license %s from %s (%s could not classify it, please verify)", guessed, path, host))
+			repository.License = guessed
+		}
+	}
+	if repository.License != "" {
+		ui.Info(fmt.Sprintf("License: %s", repository.License))
+	}
 
-	// Get latest release
-	fmt.Println(titleStyle.Render("\n🔍 Finding latest release..."))
-	release, err := client.GetLatestRelease(owner, repo)
+	// Get the pinned or latest release
+	if flagVersion != "" {
+		ui.Title(fmt.Sprintf("\n🔍 Finding release %s...", flagVersion))
+	} else {
+		ui.Title("\n🔍 Finding latest release...")
+	}
+	release, err := github.GetRelease(client, owner, repo, flagVersion)
 	if err != nil {
-		return fmt.Errorf("failed to fetch latest release: %w", err)
+		return fmt.Errorf("failed to fetch release: %w", err)
 	}
-	fmt.Println(successStyle.Render(fmt.Sprintf("✓ Version: %s", release.TagName)))
-
-	// Detect platform for all assets
-	fmt.Println(titleStyle.Render("\n🔍 Analyzing release assets..."))
-	var assets []*platform.Asset
-	for _, ghAsset := range release.Assets {
-		asset := platform.DetectPlatform(ghAsset.Name)
-		asset.URL = ghAsset.URL
-		asset.DownloadURL = ghAsset.BrowserDownloadURL
-		asset.Size = ghAsset.Size
-		assets = append(assets, asset)
+	if release.FromTag {
+		// Only the source tarball, which a cask cannot install
+		return fmt.Errorf("tag %s has no release assets; casks require a prebuilt asset, consider a formula (tap-formula --from-source)", release.TagName)
 	}
 
-	// Filter Linux assets
-	linuxAssets := platform.FilterLinuxAssets(assets)
-	if len(linuxAssets) == 0 {
-		return fmt.Errorf("no Linux assets found in release")
+	// Warn if a newer tag exists without a release marked as latest
+	if flagVersion == "" {
+		if tags, err := client.ListTags(owner, repo); err == nil {
+			if newer := github.NewerTag(release.TagName, tags); newer != "" {
+				ui.Warn(fmt.Sprintf("Tag %s is newer than latest release %s (not marked as a release?)", newer, release.TagName))
+			}
+		}
 	}
-	fmt.Println(successStyle.Render(fmt.Sprintf("✓ Found %d Linux asset(s)", len(linuxAssets))))
+	ui.Success(fmt.Sprintf("Version: %s", release.TagName))
 
-	// Select best asset
-	bestAsset, err := platform.SelectBestAsset(linuxAssets)
+	// Detect platform for all assets
+	ui.Title("\n🔍 Analyzing release assets...")
+	assets := release.PlatformAssets()
+
+	// Record why the asset was chosen
+	decision := platform.NewDecision(owner+"/"+repo, release.TagName)
+	decision.AddCandidates(assets)
+
+	selection, err := platform.SelectAsset(assets, platform.AssetChoice{
+		URL:       flagAssetURL,
+		Pick:      flagSelectAsset,
+		MaxSize:   maxAssetSize,
+		SplitHint: "casks package a single archive",
+	}, decision)
 	if err != nil {
-		return fmt.Errorf("failed to select asset: %w", err)
+		return err
+	}
+	for _, asset := range selection.Skipped {
+		ui.Warn(fmt.Sprintf("Skipping %s (%.2f MB exceeds --max-asset-size)", asset.Name, float64(asset.Size)/1024/1024))
+	}
+	if len(selection.Candidates) > 0 {
+		ui.Success(fmt.Sprintf("Found %d Linux asset(s)", len(selection.Candidates)))
+	}
+	if flagVerbose {
+		for i, asset := range selection.Candidates {
+			ui.Printf("   [%d] %s\n", i, asset.Name)
+		}
+	}
+	bestAsset := selection.Asset
+	ui.Success(fmt.Sprintf("Selected: %s (%s)", bestAsset.Name, selection.Reason))
+	if selection.Warning != "" {
+		ui.Warn(selection.Warning)
+	}
+
+	if flagVerbose {
+		decision.WriteText(ui.Writer())
+	}
+	if flagLogJSON != "" {
+		if err := decision.WriteJSON(flagLogJSON); err != nil {
+			return err
+		}
 	}
-	fmt.Println(successStyle.Render(fmt.Sprintf("✓ Selected: %s (Priority %d)", bestAsset.Name, bestAsset.Priority)))
 
 	// Download and calculate checksum
-	fmt.Println(titleStyle.Render("\n⬇️  Downloading asset..."))
-	data, err := checksum.DownloadFile(bestAsset.DownloadURL)
-	if err != nil {
-		return fmt.Errorf("failed to download asset: %w", err)
+	ui.Title("\n⬇️  Downloading asset...")
+	// GUI apps can be hundreds of MB, so the asset is streamed to a temp file
+	// rather than held in memory
+	var sha256sum, assetPath string
+	if flagSHA256 != "" {
+		if sha256sum, err = checksum.ParseSHA256(flagSHA256); err != nil {
+			return fmt.Errorf("failed to download asset: %w", err)
+		}
+		ui.Info("Skipped download, using --sha256 (archive contents will not be inspected)")
+	} else {
+		bar := ui.NewProgress(bestAsset.Name)
+		sum, size, tmpPath, err := checksum.DownloadAndHashWithProgress(bestAsset.DownloadURL, bar.Update)
+		bar.Done()
+		if err != nil {
+			return fmt.Errorf("failed to download asset: %w", err)
+		}
+		defer os.Remove(tmpPath)
+		sha256sum, assetPath = sum, tmpPath
+		ui.Success(fmt.Sprintf("Downloaded %.2f MB", float64(size)/1024/1024))
+	}
+	if flagLatest {
+		ui.Warn("Using version :latest with sha256 :no_check; the download is not checksummed and brew upgrade won't track new versions")
+	} else {
+		ui.Success(fmt.Sprintf("SHA256: %s", sha256sum))
 	}
-	fmt.Println(successStyle.Render(fmt.Sprintf("✓ Downloaded %.2f MB", float64(len(data))/1024/1024)))
 
-	// Calculate SHA256
-	fmt.Println(titleStyle.Render("\n🔐 Calculating SHA256..."))
-	sha256sum := checksum.CalculateSHA256(data)
-	fmt.Println(successStyle.Render(fmt.Sprintf("✓ SHA256: %s", sha256sum)))
+	if flagVerifySig != "" {
+		sigURL, err := checksum.VerifySignature(bestAsset.DownloadURL, assetPath, flagVerifySig)
+		if err != nil {
+			return fmt.Errorf("signature verification failed: %w", err)
+		}
+		ui.Success(fmt.Sprintf("Signature verified: %s", sigURL))
+	}
 
-	// Try to verify with upstream checksums
-	fmt.Println(titleStyle.Render("\n🔍 Searching for upstream checksums..."))
-	upstreamChecksums, err := checksum.FindUpstreamChecksum(bestAsset.DownloadURL)
-	if err != nil {
-		fmt.Println(infoStyle.Render("✗ No upstream checksums found (not an error)"))
-	} else {
-		if expected, found := upstreamChecksums[bestAsset.Name]; found {
-			if expected == sha256sum {
-				fmt.Println(successStyle.Render("✓ Checksum verified against upstream!"))
-			} else {
-				return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, sha256sum)
-			}
-		} else {
-			fmt.Println(infoStyle.Render("✗ File not in upstream checksums (not an error)"))
+	// Show every checksum source tried, for troubleshooting verification
+	if flagExplainSum {
+		ui.Println()
+		attempts := checksum.ExplainUpstreamChecksum(bestAsset.DownloadURL, bestAsset.Name, flagChecksumFile...)
+		checksum.WriteExplanation(ui.Writer(), attempts, bestAsset.Name, sha256sum)
+	}
+
+	// Try to verify with upstream checksums; a :latest download changes
+	// under the same URL, so there is nothing stable to verify against
+	if !flagLatest {
+		ui.Title("\n🔍 Searching for upstream checksums...")
+		message, found, warn, err := checksum.CheckUpstream(bestAsset.DownloadURL, release.Body, bestAsset.Name, assetPath, sha256sum, flagChecksumFile...)
+		switch {
+		case err != nil:
+			return err
+		case warn:
+			ui.Warn(message)
+		case found:
+			ui.Success(message)
+		default:
+			ui.Info(message)
 		}
 	}
 
 	// Extract archive and inspect contents
-	fmt.Println(titleStyle.Render("\n📦 Inspecting archive contents..."))
-	files, err := archive.ListFiles(data, bestAsset.Name)
-	if err != nil {
-		fmt.Println(infoStyle.Render(fmt.Sprintf("✗ Could not list archive contents: %v", err)))
-		fmt.Println(infoStyle.Render("  Will use default paths"))
-		files = []string{} // Empty list to fall back to defaults
+	ui.Title("\n📦 Inspecting archive contents...")
+	var entries []archive.FileEntry
+	var files []string
+	var appImagePath string
+	if bestAsset.Format == platform.FormatAppImage {
+		// An AppImage is installed as downloaded, there is nothing to unpack
+		appImagePath = bestAsset.Name
+		ui.Info("AppImage download, installing it as is")
 	} else {
-		fmt.Println(successStyle.Render(fmt.Sprintf("✓ Found %d files in archive", len(files))))
+		entries, err = archive.ListEntriesFromFile(assetPath, bestAsset.Name)
+		files = archive.Paths(entries)
+		if err != nil {
+			ui.Info(fmt.Sprintf("Could not list archive contents: %v", err))
+			ui.Info("Will use default paths")
+			files = []string{} // Empty list to fall back to defaults
+		} else {
+			ui.Success(fmt.Sprintf("Found %d files in archive", len(files)))
+		}
+		if appImagePath = archive.FindAppImage(files); appImagePath != "" {
+			ui.Success(fmt.Sprintf("Found AppImage: %s", appImagePath))
+		}
 	}
 
 	// Detect binaries
 	var detectedBinaries []string
-	if len(files) > 0 {
-		detectedBinaries = archive.DetectBinaries(files)
+	if len(files) > 0 && appImagePath == "" {
+		detectedBinaries = archive.DetectBinariesFromEntries(entries)
 		if len(detectedBinaries) > 0 {
-			fmt.Println(successStyle.Render(fmt.Sprintf("✓ Detected %d binary file(s)", len(detectedBinaries))))
+			ui.Success(fmt.Sprintf("Detected %d binary file(s)", len(detectedBinaries)))
 			for _, bin := range detectedBinaries {
-				fmt.Println(infoStyle.Render(fmt.Sprintf("  - %s", bin)))
+				ui.Info(bin)
 			}
 		} else {
-			fmt.Println(infoStyle.Render("✗ No binary files detected"))
+			ui.Info("No binary files detected")
 		}
 	}
 
 	// Detect desktop integration
-	fmt.Println(titleStyle.Render("\n🖼️  Detecting desktop integration..."))
-	var desktopFile *desktop.DesktopFileInfo
+	var desktopFiles []*desktop.DesktopFileInfo
+	var desktopEntry *desktop.DesktopEntry
 	var icon *desktop.IconInfo
+	var icons []*desktop.IconInfo
+	var mimeFiles []*desktop.MimeFileInfo
+
+	if flagNoDesktop {
+		ui.Info("Skipping desktop integration (--no-desktop)")
+	} else {
+		ui.Title("\n🖼️  Detecting desktop integration...")
+	}
 
-	if len(files) > 0 {
-		desktopFile, _ = desktop.DetectDesktopFile(files)
-		icon, _ = desktop.DetectIcon(files)
+	if len(files) > 0 && !flagNoDesktop {
+		desktopFiles, _ = desktop.DetectDesktopFiles(files)
+		icons = desktop.DetectIcons(files)
+		if len(icons) == 0 {
+			icon, _ = desktop.DetectIcon(files)
+		}
+		mimeFiles, _ = desktop.DetectMimeFiles(files)
 
-		if desktopFile != nil {
-			fmt.Println(successStyle.Render(fmt.Sprintf("✓ Found desktop file: %s", desktopFile.Path)))
+		for _, desktopFile := range desktopFiles {
+			ui.Success(fmt.Sprintf("Found desktop file: %s", desktopFile.Path))
+		}
+		if len(desktopFiles) > 0 {
+			// The desktop file names the app better than the repository does
+			desktopFile := desktopFiles[0]
+			content, err := archive.ReadFileFromFile(assetPath, bestAsset.Name, desktopFile.Path)
+			if err == nil {
+				desktopEntry, err = desktop.ParseDesktopFile(content)
+			}
+			if err != nil {
+				ui.Warn(fmt.Sprintf("Could not read %s: %v", desktopFile.Filename, err))
+			} else {
+				ui.Info(fmt.Sprintf("Application name: %s", desktopEntry.Name))
+			}
 		} else {
-			fmt.Println(infoStyle.Render("✗ No desktop file found"))
+			ui.Info("No desktop file found")
 		}
 
+		for _, themeIcon := range icons {
+			ui.Success(fmt.Sprintf("Found icon: %s (size: %s)", themeIcon.Path, themeIcon.Size))
+		}
 		if icon != nil {
-			fmt.Println(successStyle.Render(fmt.Sprintf("✓ Found icon: %s (size: %s)", icon.Path, icon.Size)))
-		} else {
-			fmt.Println(infoStyle.Render("✗ No icon found"))
+			ui.Success(fmt.Sprintf("Found icon: %s (size: %s)", icon.Path, icon.Size))
+		} else if len(icons) == 0 {
+			ui.Info("No icon found")
+		}
+
+		for _, mimeFile := range mimeFiles {
+			ui.Success(fmt.Sprintf("Found MIME types: %s", mimeFile.Path))
 		}
 	}
 
@@ -206,15 +464,65 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	}
 	token := platform.EnsureLinuxSuffix(pkgName)
 
+	// Curated fields in the tap's metadata file win over the API
+	overrides, err := metadata.Load(flagMetadata)
+	if err != nil {
+		return err
+	}
+	applied := overrides.Apply(pkgName, &repository.Description, &repository.Homepage, &repository.License)
+	if len(applied) > 0 {
+		ui.Info(fmt.Sprintf("Metadata overrides from %s: %s", flagMetadata, strings.Join(applied, ", ")))
+	}
+
 	// Create cask data
 	caskData := homebrew.NewCaskData(token, release.TagName, sha256sum, bestAsset.DownloadURL)
 	caskData.AppName = repo
 	caskData.Description = repository.Description
 	caskData.Homepage = repository.Homepage
-	caskData.SourceURL = fmt.Sprintf("https://github.com/%s/%s", owner, repo)
+	caskData.SourceURL = github.RepoURL(host, owner, repo)
+	caskData.Notes = notes
+	caskData.NoMagicComments = flagNoMagic
+	caskData.Livecheck = !flagNoLivecheck
+	caskData.Latest = flagLatest
+
+	// A curated desc still wins over the desktop file's comment. The desktop
+	// file comes from the archive, so values that cannot sit on one line of
+	// the cask (e.g. an escaped newline) are ignored.
+	if desktopEntry != nil {
+		if strings.ContainsFunc(desktopEntry.Name, unicode.IsControl) {
+			ui.Warn(fmt.Sprintf("Ignoring desktop file Name %q: it contains control characters", desktopEntry.Name))
+		} else {
+			caskData.DisplayName = desktopEntry.Name
+		}
+		if strings.ContainsFunc(desktopEntry.Comment, unicode.IsControl) {
+			ui.Warn(fmt.Sprintf("Ignoring desktop file Comment %q: it contains control characters", desktopEntry.Comment))
+		} else if desktopEntry.Comment != "" && !slices.Contains(applied, "desc") {
+			caskData.Description = desktopEntry.Comment
+		}
+	}
+
+	// Template the URL so livecheck and bump can reuse it
+	if flagTemplateURL {
+		caskData.Version = strings.TrimPrefix(release.TagName, "v")
+		caskData.URL = homebrew.TemplateURL(bestAsset.DownloadURL, caskData.Version)
+		if caskData.URL == bestAsset.DownloadURL {
+			ui.Info("Version not found in URL, keeping literal URL")
+		} else {
+			ui.Info(fmt.Sprintf("URL template: %s", caskData.URL))
+		}
+	}
 
 	// Set binary path from detection
-	if len(detectedBinaries) > 0 {
+	if appImagePath != "" {
+		caskData.SetAppImage(appImagePath, pkgName)
+		ui.Info(fmt.Sprintf("AppImage: %s → %s", caskData.BinaryPath, caskData.BinaryName))
+		if flagVerifyRun {
+			ui.Warn("Skipping --verify-run: an AppImage needs FUSE to run")
+		}
+		if flagAllBinaries {
+			ui.Warn("Ignoring --all-binaries: the AppImage is the only binary")
+		}
+	} else if len(detectedBinaries) > 0 {
 		// Select the best binary based on package name
 		bestBinary := archive.SelectBestBinary(detectedBinaries, pkgName)
 		caskData.BinaryPath = bestBinary
@@ -230,8 +538,45 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 			caskData.BinaryName = binaryName
 		}
 
-		fmt.Println(infoStyle.Render(fmt.Sprintf("  Binary: %s → %s", caskData.BinaryPath, caskData.BinaryName)))
+		ui.Info(fmt.Sprintf("Binary: %s → %s", caskData.BinaryPath, caskData.BinaryName))
+		if flagAllBinaries {
+			for _, path := range caskData.AddBinaries(detectedBinaries) {
+				ui.Info(fmt.Sprintf("Binary: %s → %s", path, filepath.Base(path)))
+			}
+		}
+
+		// Catch mislabeled releases and missing shared libraries
+		binary, err := archive.ReadFileFromFile(assetPath, bestAsset.Name, bestBinary)
+		if err == nil {
+			checkBinaryArch(archive.DetectELFArch(binary), bestAsset.Arch)
+			if message, static := validate.CheckLinkage(binary, binaryName, files, "prefer a static build, or a formula with depends_on"); static {
+				ui.Info(message)
+			} else if message != "" {
+				ui.Warn(message)
+			}
+		}
+		if flagVerifyRun {
+			if err != nil {
+				return fmt.Errorf("--verify-run failed to extract %s: %w", bestBinary, err)
+			}
+			message, ok, err := validate.VerifyRun(binary, binaryName, bestAsset.Arch)
+			if err != nil {
+				return err
+			}
+			if ok {
+				ui.Success(message)
+			} else {
+				ui.Warn(message)
+			}
+		}
 	} else {
+		if flagVerifyRun {
+			ui.Warn("Skipping --verify-run: no binary detected in the archive")
+		}
+		if flagAllBinaries {
+			ui.Warn("Ignoring --all-binaries: no binary detected in the archive")
+		}
+
 		// Fallback to guessing
 		rootDir := archive.FindRootDirectory(files)
 		if rootDir != "" {
@@ -240,24 +585,63 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 			caskData.BinaryPath = pkgName
 		}
 		caskData.BinaryName = pkgName
-		fmt.Println(infoStyle.Render(fmt.Sprintf("  Binary (guessed): %s → %s", caskData.BinaryPath, caskData.BinaryName)))
+		ui.Info(fmt.Sprintf("Binary (guessed): %s → %s", caskData.BinaryPath, caskData.BinaryName))
 	}
 
-	// Set desktop file if found
-	if desktopFile != nil {
-		caskData.SetDesktopFile(desktopFile.Path, desktopFile.Filename)
+	// Set desktop files if found
+	for _, desktopFile := range desktopFiles {
+		if !caskData.AddDesktopFile(desktopFile.Path, desktopFile.Filename) {
+			ui.Warn(fmt.Sprintf("Skipping %s: another desktop file is installed as %s", desktopFile.Path, desktopFile.Filename))
+		}
+	}
+	if caskData.IsAppImage && len(desktopFiles) == 0 && !flagNoDesktop {
+		caskData.AddDesktopFile(caskData.AppImageDesktopFile(), caskData.AppImageDesktopFile())
+		ui.Info(fmt.Sprintf("Writing a launcher for the AppImage: %s", caskData.AppImageDesktopFile()))
 	}
 
-	// Set icon if found
+	// Set icons if found, every size into the hicolor theme when known
 	if icon != nil {
 		caskData.SetIcon(icon.Path, icon.Filename)
 	}
+	if len(icons) > 0 {
+		name := themeIconName(desktopEntry, icons)
+		for _, themeIcon := range icons {
+			caskData.AddThemeIcon(themeIcon.Path, themeIcon.Size, name)
+		}
+		ui.Info(fmt.Sprintf("Icon name: %s (%d sizes)", name, len(icons)))
+	}
+
+	// Register the file types the app opens
+	for _, mimeFile := range mimeFiles {
+		if !caskData.AddMimeFile(mimeFile.Path, mimeFile.Filename) {
+			ui.Warn(fmt.Sprintf("Skipping %s: another MIME package is installed as %s", mimeFile.Path, mimeFile.Filename))
+		}
+	}
+
+	// Keep the version out of paths inside a versioned root like tool-1.2.3/,
+	// and out of the name of a top-level AppImage like App-1.2.3.AppImage
+	rootDir := archive.FindRootDirectory(files)
+	if rootDir == "" && caskData.IsAppImage && !strings.Contains(caskData.BinaryPath, "/") {
+		rootDir = caskData.BinaryPath
+	}
+	if caskData.TemplateVersionedRoot(rootDir) {
+		ui.Info(fmt.Sprintf("Versioned archive root, using: %s", caskData.BinaryPath))
+	}
+
+	// Refresh desktop and icon caches so the app shows up in launchers
+	caskData.Postflight = flagPostflight && (caskData.HasDesktopFile || caskData.HasIcons())
 
 	// Infer zap trash paths
 	caskData.InferZapTrash()
 
+	for _, caveat := range flagCaveats {
+		if err := caskData.AddCaveat(caveat); err != nil {
+			return fmt.Errorf("invalid --caveats: %w", err)
+		}
+	}
+
 	// Generate cask
-	fmt.Println(titleStyle.Render("\n📝 Generating cask..."))
+	ui.Title("\n📝 Generating cask...")
 	caskContent, err := homebrew.GenerateCask(caskData)
 	if err != nil {
 		return fmt.Errorf("failed to generate cask: %w", err)
@@ -269,6 +653,20 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		outputPath = filepath.Join("Casks", token+".rb")
 	}
 
+	// Catch near-duplicates elsewhere in the tap, like foo vs foo-linux
+	duplicates, err := homebrew.CheckDuplicates(filepath.Dir(filepath.Dir(outputPath)), token, flagAllowDup, outputPath)
+	if err != nil {
+		return err
+	}
+	for _, existing := range duplicates {
+		ui.Warn(fmt.Sprintf("Similar package already exists: %s", existing))
+	}
+
+	// Refuse to clobber hand-written casks
+	if err := generator.CheckOverwrite(outputPath, flagForce); err != nil {
+		return err
+	}
+
 	// Write cask file
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
@@ -277,30 +675,187 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to write cask file: %w", err)
 	}
 
-	fmt.Println(successStyle.Render(fmt.Sprintf("✓ Created: %s", outputPath)))
+	ui.Success(fmt.Sprintf("Created: %s", outputPath))
 
 	// Validate the generated cask
-	fmt.Println(titleStyle.Render("\n🔍 Validating generated cask..."))
+	ui.Title("\n🔍 Validating generated cask...")
 	result, err := validate.ValidateFile(outputPath, true, true)
 	if err != nil {
-		fmt.Println(errorStyle.Render("✗ Validation failed:"))
 		for _, errMsg := range result.Errors {
-			fmt.Println(errorStyle.Render(fmt.Sprintf("  - %s", errMsg)))
+			ui.Error(errMsg)
 		}
 		return fmt.Errorf("generated cask failed validation")
 	}
 
 	if result.Fixed {
-		fmt.Println(successStyle.Render("✓ Validation passed (style issues auto-fixed)"))
+		ui.Success("Validation passed (style issues auto-fixed)")
 	} else {
-		fmt.Println(successStyle.Render("✓ Validation passed"))
+		ui.Success("Validation passed")
+	}
+	for _, warning := range result.Warnings {
+		ui.Warn(warning)
 	}
 
 	// Print next steps
-	fmt.Println(titleStyle.Render("\n✅ Done! Next steps:"))
-	fmt.Println(infoStyle.Render(fmt.Sprintf("   1. Review %s", outputPath)))
-	fmt.Println(infoStyle.Render(fmt.Sprintf("   2. Test: brew install --cask castrojo/tap/%s", token)))
-	fmt.Println(infoStyle.Render("   3. Commit and push"))
+	ui.Title("\n✅ Done! Next steps:")
+	ui.Printf("   1. Review %s\n", outputPath)
+	ui.Printf("   2. Test: brew install --cask castrojo/tap/%s\n", token)
+	ui.Printf("   3. Commit and push\n")
+
+	return nil
+}
+
+func runBump(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	if !strings.HasSuffix(path, ".rb") {
+		path = filepath.Join("Casks", path+".rb")
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			path = filepath.Join("Casks", platform.EnsureLinuxSuffix(args[0])+".rb")
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read cask: %w", err)
+	}
+
+	src, err := homebrew.ParseCaskSource(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	current := version.Normalize(src.Version)
+	oldURL := homebrew.ExpandVersion(src.URL, src.Version)
+	repoURL := src.RepoURL
+	if repoURL == "" {
+		repoURL = oldURL
+	}
+	host, owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		return fmt.Errorf("cannot find the repository of %s: %w", path, err)
+	}
+
+	client, err := newForge(host)
+	if err != nil {
+		return err
+	}
+
+	ui.Title(fmt.Sprintf("🔍 Checking %s/%s for a new release...", owner, repo))
+	release, err := client.GetLatestRelease(owner, repo)
+	if github.IsNotFound(err) {
+		release, err = client.GetLatestTag(owner, repo)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	latest := version.Normalize(release.TagName)
+	if version.Compare(latest, current) <= 0 {
+		ui.Success(fmt.Sprintf("%s is already latest (%s)", path, src.Version))
+		return nil
+	}
+	// Keep the cask's style of writing the version, with or without "v"
+	newVersion := latest
+	if strings.HasPrefix(src.Version, "v") {
+		newVersion = "v" + latest
+	}
+	ui.Info(fmt.Sprintf("Version: %s → %s", src.Version, newVersion))
+
+	assets := release.PlatformAssets()
+
+	// The same file under the new version is the natural successor; only
+	// fall back to selection when upstream renamed its assets
+	expected := homebrew.BumpURL(oldURL, current, latest)
+	var bestAsset *platform.Asset
+	for _, asset := range assets {
+		if asset.DownloadURL == expected {
+			bestAsset = asset
+			break
+		}
+	}
+	if bestAsset == nil && release.FromTag {
+		// A tag has no assets to select from; try the cask's own URL
+		if bestAsset, err = platform.AssetFromURL(expected); err != nil {
+			return fmt.Errorf("tag %s has no release assets: %w", release.TagName, err)
+		}
+		ui.Warn(fmt.Sprintf("Tag %s has no release, downloading %s", release.TagName, expected))
+	}
+	if bestAsset == nil {
+		linuxAssets := platform.FilterLinuxAssets(assets)
+		if len(linuxAssets) == 0 {
+			if err := platform.CheckFlatpak(assets); err != nil {
+				return err
+			}
+			return fmt.Errorf("no Linux assets found in release %s", release.TagName)
+		}
+		if bestAsset, err = platform.SelectBestAsset(linuxAssets); err != nil {
+			return fmt.Errorf("failed to select asset: %w", err)
+		}
+		ui.Warn(fmt.Sprintf("No asset matches %s; selected %s instead, check the binary and artifact stanzas",
+			filepath.Base(expected), bestAsset.Name))
+	}
+	ui.Success(fmt.Sprintf("Selected: %s", bestAsset.Name))
+
+	newURL := bestAsset.DownloadURL
+	if strings.Contains(src.URL, "#{") {
+		if homebrew.ExpandVersion(src.URL, newVersion) == newURL {
+			newURL = src.URL
+		} else {
+			newURL = homebrew.TemplateURL(newURL, newVersion)
+		}
+	}
+
+	ui.Title("\n⬇️  Downloading asset...")
+	bar := ui.NewProgress(bestAsset.Name)
+	sha256sum, size, tmpPath, err := checksum.DownloadAndHashWithProgress(bestAsset.DownloadURL, bar.Update)
+	bar.Done()
+	if err != nil {
+		return fmt.Errorf("failed to download asset: %w", err)
+	}
+	os.Remove(tmpPath)
+	ui.Success(fmt.Sprintf("Downloaded %.2f MB", float64(size)/1024/1024))
+	ui.Success(fmt.Sprintf("SHA256: %s", sha256sum))
+
+	updated, err := homebrew.BumpCask(string(content), src, newVersion, newURL, sha256sum)
+	if err != nil {
+		return fmt.Errorf("failed to bump %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write cask: %w", err)
+	}
 
+	ui.Success(fmt.Sprintf("Bumped %s to %s", path, newVersion))
 	return nil
 }
+
+// checkBinaryArch warns when the binary's ELF machine type differs from the
+// architecture in the asset filename
+func checkBinaryArch(actual, labeled platform.Architecture) {
+	if actual == platform.ArchUnknown || labeled == platform.ArchUnknown {
+		return
+	}
+	if labeled == platform.ArchAMD64 {
+		labeled = platform.ArchX86_64
+	}
+	if actual != labeled {
+		ui.Warn(fmt.Sprintf("Binary is %s but the asset filename says %s; the release may be mislabeled", actual, labeled))
+	}
+}
+
+// iconNameRe matches an Icon key that names a theme icon, rather than a
+// path or anything that could not be a file name
+var iconNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+
+// themeIconName returns the name theme icons are installed under: the
+// desktop file's Icon key when it names a theme icon, else the file name of
+// the largest icon
+func themeIconName(entry *desktop.DesktopEntry, icons []*desktop.IconInfo) string {
+	name := icons[len(icons)-1].Filename
+	if entry != nil && iconNameRe.MatchString(entry.Icon) {
+		name = entry.Icon
+	}
+	// Names like org.example.App keep their dots
+	switch ext := filepath.Ext(name); ext {
+	case ".png", ".svg", ".xpm":
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
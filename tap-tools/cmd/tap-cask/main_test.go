@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/castrojo/tap-tools/internal/forge/forgetest"
+	"github.com/castrojo/tap-tools/internal/github"
+	"github.com/castrojo/tap-tools/internal/platform"
+)
+
+func TestGenerateVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		release   *github.Release
+		wantCalls []string
+		wantErr   error
+		wantMsg   string
+	}{
+		{
+			name:      "Latest release",
+			args:      []string{"generate", "owner/tool"},
+			release:   forgetest.NewRelease("v2.0.0"),
+			wantCalls: []string{"latest", "tags"},
+			wantErr:   platform.ErrNoAssets,
+		},
+		{
+			name:      "Pinned release skips the tag check",
+			args:      []string{"generate", "owner/tool", "--version", "v1.0.0"},
+			release:   forgetest.NewRelease("v1.0.0"),
+			wantCalls: []string{"tag v1.0.0"},
+			wantErr:   platform.ErrNoAssets,
+		},
+		{
+			name:      "Pinned tag without a release",
+			args:      []string{"generate", "owner/tool", "--version", "v1.0.0"},
+			release:   github.TagRelease("tool", "v1.0.0", "https://github.com/owner/tool/archive/v1.0.0.tar.gz"),
+			wantCalls: []string{"tag v1.0.0"},
+			wantMsg:   "tag v1.0.0 has no release assets",
+		},
+		{
+			name:      "Missing tag",
+			args:      []string{"generate", "owner/tool", "--version", "v0.1.0"},
+			release:   forgetest.NewRelease("v1.0.0"),
+			wantCalls: []string{"tag v0.1.0"},
+			wantErr:   github.ErrTagNotFound,
+		},
+		{
+			name:    "Combined with --version-latest",
+			args:    []string{"generate", "owner/tool", "--version", "v1.0.0", "--version-latest"},
+			release: forgetest.NewRelease("v1.0.0"),
+			wantMsg: "cannot be combined with --version-latest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &forgetest.Forge{Release: tt.release}
+			err := forgetest.Run(t, rootCmd, &newForge, f, tt.args...)
+			if err == nil {
+				t.Fatal("generate succeeded, want an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("generate error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantMsg != "" && !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("generate error = %q, want it to contain %q", err, tt.wantMsg)
+			}
+			if strings.Join(f.Calls, ",") != strings.Join(tt.wantCalls, ",") {
+				t.Errorf("release lookups = %v, want %v", f.Calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestGenerateReleaseAssets(t *testing.T) {
+	tests := []struct {
+		name    string
+		assets  []string
+		wantErr error
+		wantMsg string
+	}{
+		{"Source-only release", nil, platform.ErrNoAssets, "casks require a prebuilt asset"},
+		{"Flatpak bundle only", []string{"tool.flatpak"}, platform.ErrFlatpakBundle, "flatpak install --bundle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &forgetest.Forge{Release: forgetest.NewRelease("v1.0.0", tt.assets...)}
+			err := forgetest.Run(t, rootCmd, &newForge, f, "generate", "owner/tool")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("generate error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("generate error = %q, want it to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
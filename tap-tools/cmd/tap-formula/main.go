@@ -1,29 +1,32 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
 
+	"github.com/castrojo/tap-tools/internal/archive"
 	"github.com/castrojo/tap-tools/internal/buildsystem"
 	"github.com/castrojo/tap-tools/internal/checksum"
+	"github.com/castrojo/tap-tools/internal/forge"
+	"github.com/castrojo/tap-tools/internal/generator"
 	"github.com/castrojo/tap-tools/internal/github"
 	"github.com/castrojo/tap-tools/internal/homebrew"
+	"github.com/castrojo/tap-tools/internal/metadata"
 	"github.com/castrojo/tap-tools/internal/platform"
+	"github.com/castrojo/tap-tools/internal/spdx"
+	"github.com/castrojo/tap-tools/internal/ui"
 	"github.com/castrojo/tap-tools/internal/validate"
-	"github.com/charmbracelet/lipgloss"
+	"github.com/castrojo/tap-tools/internal/version"
 	"github.com/spf13/cobra"
 )
 
-var (
-	// Styles for pretty output
-	titleStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
-	successStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
-	errorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
-	infoStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
-	warnStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
-)
-
 var rootCmd = &cobra.Command{
 	Use:   "tap-formula",
 	Short: "Generate Homebrew formulas for Linux",
@@ -31,12 +34,15 @@ var rootCmd = &cobra.Command{
 
 It fetches release information from GitHub, detects the build system,
 downloads assets, verifies checksums, and generates properly formatted formula files.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		ui.Configure(ui.Options{NoColor: flagNoColor, Quiet: flagQuiet, JSON: flagJSON})
+	},
 }
 
 var generateCmd = &cobra.Command{
 	Use:   "generate [repo-url]",
-	Short: "Generate a new formula from GitHub repository",
-	Long: `Generate a new formula from a GitHub repository.
+	Short: "Generate a new formula from a GitHub or GitLab repository",
+	Long: `Generate a new formula from a GitHub or GitLab repository.
 
 The tool automatically detects the build system (Go, Rust, CMake, etc.)
 and generates appropriate installation instructions.
@@ -44,30 +50,177 @@ and generates appropriate installation instructions.
 Examples:
   tap-formula generate https://github.com/BurntSushi/ripgrep
   tap-formula generate BurntSushi/ripgrep
+  tap-formula generate https://gitlab.com/group/project
   tap-formula generate https://github.com/user/repo --name my-tool`,
 	Args: cobra.ExactArgs(1),
 	RunE: runGenerate,
 }
 
+var normalizeCmd = &cobra.Command{
+	Use:   "normalize [formula-file]",
+	Short: "Strip macOS-only stanzas from a formula",
+	Long: `Strip macOS-only stanzas from an existing formula file.
+
+This tap is Linux-only, so uses_from_macos, on_macos blocks, and
+depends_on :macos are removed. Useful after adapting a formula from
+homebrew-core. The file is rewritten in place.
+
+Examples:
+  tap-formula normalize Formula/ripgrep.rb`,
+	Args: cobra.ExactArgs(1),
+	RunE: runNormalize,
+}
+
+var bumpCmd = &cobra.Command{
+	Use:   "bump [name]",
+	Short: "Update an existing formula to the latest release",
+	Long: `Update an existing formula to the repository's latest release.
+
+The formula's version, url and sha256 lines are rewritten in place, so
+install and test blocks, caveats and comments edited by hand are kept.
+The repository comes from the generation header, or from the url.
+Nothing is changed when the formula is already at the latest version.
+
+Examples:
+  tap-formula bump ripgrep
+  tap-formula bump Formula/ripgrep.rb`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBump,
+}
+
+var listOutdatedCmd = &cobra.Command{
+	Use:   "list-outdated",
+	Short: "List formulas and casks that are behind their latest release",
+	Long: `Check every file in Formula/ and Casks/ of the tap against the latest
+upstream release and list the packages that are behind.
+
+The repository of each package comes from its generation header, or from
+its url. Lookups run concurrently, bounded by --jobs to stay under API rate
+limits. With --json, every package is printed as a JSON array on stdout,
+including its "outdated" state and any error.
+
+Examples:
+  tap-formula list-outdated
+  tap-formula list-outdated --json | jq -r '.[] | select(.outdated) | .name'`,
+	Args: cobra.NoArgs,
+	RunE: runListOutdated,
+}
+
+var addResourceCmd = &cobra.Command{
+	Use:   "add-resource [formula-file] [url]",
+	Short: "Add a resource stanza to an existing formula",
+	Long: `Download a file, calculate its checksum, and add it to an existing
+formula as a resource stanza before the install method.
+
+Examples:
+  tap-formula add-resource Formula/tool.rb https://example.com/plugin-1.0.tar.gz
+  tap-formula add-resource Formula/tool.rb https://example.com/data.zip --resource-name data`,
+	Args: cobra.ExactArgs(2),
+	RunE: runAddResource,
+}
+
+var (
+	flagResourceName string
+	flagQuiet        bool
+	flagNoColor      bool
+	flagName         string
+	flagOutput       string
+	flagBinary       string
+	flagFromSource   bool
+	flagMaxAssetSize string
+	flagVerbose      bool
+	flagLogJSON      string
+	flagClassName    string
+	flagForce        bool
+	flagAllowDup     bool
+	flagInstall      bool
+	flagLibexec      bool
+	flagToolchain    string
+	flagAssetURL     string
+	flagSelectAsset  string
+	flagAllArches    bool
+	flagGoInstall    string
+	flagNoMagic      bool
+	flagMetadata     string
+	flagCACert       string
+	flagTimeout      time.Duration
+	flagSHA256       string
+	flagVerifySig    string
+	flagCaveats      []string
+	flagSbin         bool
+	flagMergeAssets  bool
+	flagNotes        string
+	flagCache        bool
+	flagVerifyRun    bool
+	flagCompletions  string
+	flagJSON         bool
+	flagJobs         int
+	flagNoCoreCheck  bool
+	flagVersion      string
+	flagChecksumFile []string
+)
+
+// newForge creates the client for a repository host, and downloadAndHash
+// fetches assets; tests replace them
 var (
-	flagName       string
-	flagOutput     string
-	flagBinary     string
-	flagFromSource bool
+	newForge        = forge.New
+	downloadAndHash = checksum.DownloadAndHashWithProgress
 )
 
 func init() {
+	rootCmd.PersistentFlags().BoolVar(&flagNoColor, "no-color", false, "Disable colored output (also honors NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVarP(&flagQuiet, "quiet", "q", false, "Only print warnings and errors")
+
 	generateCmd.Flags().StringVar(&flagName, "name", "", "Override package name")
 	generateCmd.Flags().StringVarP(&flagOutput, "output", "o", "", "Output file path (default: Formula/<name>.rb)")
 	generateCmd.Flags().StringVar(&flagBinary, "binary", "", "Binary name (defaults to package name)")
+	generateCmd.Flags().StringVar(&flagClassName, "class-name", "", "Override the Ruby class name (defaults to PascalCase package name)")
 	generateCmd.Flags().BoolVar(&flagFromSource, "from-source", false, "Generate formula for building from source (use source tarball)")
+	generateCmd.Flags().BoolVar(&flagForce, "force", false, "Overwrite an existing file even if it was not generated by tap-tools")
+	generateCmd.Flags().BoolVar(&flagAllowDup, "allow-duplicate", false, "Generate even if a similarly named package (e.g. foo vs foo-linux) is already in the tap")
+	generateCmd.Flags().BoolVar(&flagLibexec, "libexec", false, "Install the extracted tree into libexec and symlink the binary (for bundled runtimes)")
+	generateCmd.Flags().BoolVar(&flagMergeAssets, "merge-assets", false, fmt.Sprintf("Add the release's other same-arch Linux tarballs (up to %d) as resources staged into libexec", platform.MaxMergedAssets))
+	generateCmd.Flags().BoolVar(&flagSbin, "sbin", false, "Install the binary into sbin instead of bin (for daemons and admin tools)")
+	generateCmd.Flags().StringVar(&flagCompletions, "gen-completions", "", "Generate shell completions at install time by running the binary's completion subcommand (e.g. completion)")
+	generateCmd.Flags().StringVar(&flagToolchain, "toolchain", "", "Pin the build toolchain dependency (e.g. go@1.21, replaces go)")
+	generateCmd.Flags().BoolVar(&flagInstall, "install", false, "Install the formula with brew after it passes validation")
+	generateCmd.Flags().StringSliceVar(&flagChecksumFile, "checksum-file", nil, "Extra upstream checksum file name to try before the built-in ones (repeatable)")
+	generateCmd.Flags().StringVar(&flagCACert, "ca-cert", "", "PEM CA bundle to trust for API calls and downloads, e.g. a corporate CA (default: $TAP_CA_BUNDLE)")
+	generateCmd.Flags().DurationVar(&flagTimeout, "download-timeout", 10*time.Minute, "Give up on a download, including retries, after this long (0 disables)")
+	generateCmd.Flags().BoolVar(&flagCache, "cache", false, "Cache API responses on disk and revalidate them with ETags (also enabled by $TAP_CACHE_DIR)")
+	generateCmd.Flags().StringVar(&flagMetadata, "metadata", "metadata.yaml", "YAML file of per-package desc/homepage/license overrides (skipped if missing)")
+	generateCmd.Flags().StringArrayVar(&flagCaveats, "caveats", nil, "Custom post-install note for the caveats block (repeatable)")
+	generateCmd.Flags().StringVar(&flagNotes, "notes", "", "File of maintainer notes to add as comments after the generated header")
+	generateCmd.Flags().BoolVar(&flagNoCoreCheck, "no-core-check", false, "Skip looking up the formula name in homebrew-core for conflicts_with")
+	generateCmd.Flags().BoolVar(&flagNoMagic, "no-magic-comments", false, "Omit the # typed: strict and # frozen_string_literal: true comments")
+	generateCmd.Flags().StringVar(&flagVersion, "version", "", "Package the release of this tag (e.g. v1.2.3) instead of the latest one")
+	generateCmd.Flags().StringVar(&flagAssetURL, "asset-url", "", "Download this URL instead of auto-selecting a release asset")
+	generateCmd.Flags().StringVar(&flagSHA256, "sha256", "", "Known SHA256 of the --asset-url download; skips downloading it")
+	generateCmd.Flags().StringVar(&flagVerifySig, "verify-sig", "", "Verify the download against its .minisig, .asc or .sig file using this public key")
+	generateCmd.Flags().BoolVar(&flagVerifyRun, "verify-run", false, "Run the extracted binary with --version/--help in a temp dir and fail if it cannot execute")
+	generateCmd.Flags().StringVar(&flagGoInstall, "go-install", "", "Build with go install <module-path>@<tag> instead of detecting the build system")
+	generateCmd.Flags().BoolVar(&flagAllArches, "all-arches", false, "Download the x86_64 and arm64 assets and emit on_intel/on_arm blocks")
+	generateCmd.Flags().StringVar(&flagSelectAsset, "select-asset", "", "Pick a Linux asset by index (see --verbose) or file name instead of auto-selecting")
+	generateCmd.Flags().StringVar(&flagMaxAssetSize, "max-asset-size", platform.DefaultMaxAssetSize, "Skip assets larger than this size (e.g. 500M, 2G; 0 disables)")
+
+	generateCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "Show why each asset and build system was chosen")
+	generateCmd.Flags().StringVar(&flagLogJSON, "log-json", "", "Write the selection decision as JSON to this file")
 
 	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(normalizeCmd)
+	rootCmd.AddCommand(bumpCmd)
+
+	listOutdatedCmd.Flags().BoolVar(&flagJSON, "json", false, "Print the result of every package as JSON on stdout")
+	listOutdatedCmd.Flags().IntVarP(&flagJobs, "jobs", "j", 4, "Number of packages to check concurrently")
+	rootCmd.AddCommand(listOutdatedCmd)
+
+	addResourceCmd.Flags().StringVar(&flagResourceName, "resource-name", "", "Resource name (default: filename without archive extension)")
+	rootCmd.AddCommand(addResourceCmd)
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, errorStyle.Render("Error: "+err.Error()))
+		ui.Error(err.Error())
 		os.Exit(1)
 	}
 }
@@ -75,130 +228,433 @@ func main() {
 func runGenerate(cmd *cobra.Command, args []string) error {
 	repoURL := args[0]
 
+	maxAssetSize, err := platform.ParseSize(flagMaxAssetSize)
+	if err != nil {
+		return fmt.Errorf("invalid --max-asset-size: %w", err)
+	}
+
+	if err := checksum.ConfigureCABundle(flagCACert); err != nil {
+		return err
+	}
+	checksum.ConfigureDownloadTimeout(flagTimeout)
+	if err := github.ConfigureCache(flagCache); err != nil {
+		return fmt.Errorf("invalid --cache: %w", err)
+	}
+
+	var notes string
+	if flagNotes != "" {
+		content, err := os.ReadFile(flagNotes)
+		if err != nil {
+			return fmt.Errorf("invalid --notes: %w", err)
+		}
+		notes = string(content)
+	}
+
+	if flagSHA256 != "" {
+		if flagAssetURL == "" {
+			return fmt.Errorf("--sha256 requires --asset-url")
+		}
+		if _, err := checksum.ParseSHA256(flagSHA256); err != nil {
+			return fmt.Errorf("invalid --sha256: %w", err)
+		}
+	}
+
+	if flagVerifySig != "" && flagSHA256 != "" {
+		return fmt.Errorf("--verify-sig needs the downloaded asset and cannot be combined with --sha256")
+	}
+
+	if flagVerifyRun && flagSHA256 != "" {
+		return fmt.Errorf("--verify-run needs the downloaded asset and cannot be combined with --sha256")
+	}
+
+	if flagGoInstall != "" {
+		if flagLibexec || flagAssetURL != "" || flagSelectAsset != "" || flagAllArches {
+			return fmt.Errorf("--go-install builds from source and cannot be combined with --libexec, --asset-url, --select-asset, or --all-arches")
+		}
+		// go install fetches the module itself; the source tarball pins the version
+		flagFromSource = true
+	}
+
+	if flagLibexec && flagFromSource {
+		return fmt.Errorf("--libexec applies to pre-built binaries and cannot be combined with --from-source")
+	}
+
+	if flagAssetURL != "" && flagFromSource {
+		return fmt.Errorf("--asset-url cannot be combined with --from-source")
+	}
+
+	if flagSelectAsset != "" && (flagFromSource || flagAssetURL != "") {
+		return fmt.Errorf("--select-asset cannot be combined with --from-source or --asset-url")
+	}
+
+	if flagAllArches && (flagFromSource || flagAssetURL != "" || flagSelectAsset != "") {
+		return fmt.Errorf("--all-arches cannot be combined with --from-source, --asset-url, or --select-asset")
+	}
+
+	if flagMergeAssets && (flagFromSource || flagAssetURL != "" || flagAllArches || flagSHA256 != "") {
+		return fmt.Errorf("--merge-assets cannot be combined with --from-source, --asset-url, --all-arches, or --sha256")
+	}
+
+	if flagClassName != "" {
+		if err := homebrew.ValidateClassName(flagClassName); err != nil {
+			return fmt.Errorf("invalid --class-name: %w", err)
+		}
+	}
+
 	// Parse repository URL
-	fmt.Println(titleStyle.Render("🔍 Parsing repository URL..."))
-	owner, repo, err := github.ParseRepoURL(repoURL)
+	ui.Title("🔍 Parsing repository URL...")
+	host, owner, repo, err := github.ParseRepoURL(repoURL)
 	if err != nil {
 		return fmt.Errorf("invalid repository URL: %w", err)
 	}
-	fmt.Println(successStyle.Render(fmt.Sprintf("✓ Repository: %s/%s", owner, repo)))
+	ui.Success(fmt.Sprintf("Repository: %s/%s", owner, repo))
 
 	// Determine package name
 	packageName := flagName
 	if packageName == "" {
 		packageName = platform.NormalizePackageName(repo)
 	}
-	fmt.Println(infoStyle.Render(fmt.Sprintf("  Package: %s", packageName)))
+	ui.Info(fmt.Sprintf("Package: %s", packageName))
 
 	// Determine binary name
 	binaryName := flagBinary
+	if binaryName == "" && flagGoInstall != "" {
+		binaryName = homebrew.GoInstallBinaryName(flagGoInstall)
+	}
 	if binaryName == "" {
 		binaryName = packageName
 	}
 
-	// Create GitHub client
-	client := github.NewClient()
+	// Create the GitHub or GitLab client
+	client, err := newForge(host)
+	if err != nil {
+		return err
+	}
 
 	// Fetch repository metadata
-	fmt.Println(titleStyle.Render("\n🔍 Fetching repository metadata..."))
+	ui.Title("\n🔍 Fetching repository metadata...")
 	repository, err := client.GetRepository(owner, repo)
 	if err != nil {
 		return fmt.Errorf("failed to fetch repository: %w", err)
 	}
-	fmt.Println(successStyle.Render(fmt.Sprintf("✓ Found: %s", repository.Description)))
-	fmt.Println(infoStyle.Render(fmt.Sprintf("  Homepage: %s", repository.Homepage)))
-	fmt.Println(infoStyle.Render(fmt.Sprintf("  License: %s", repository.License)))
+	ui.Success(fmt.Sprintf("Found: %s", repository.Description))
+	ui.Info(fmt.Sprintf("Homepage: %s", repository.Homepage))
+	// The repository API reports NOASSERTION for custom license files,
+	// so consult the license endpoint to pick a license stanza brew audit accepts
+	if repository.License == "" || repository.License == "NOASSERTION" {
+		license, err := client.GetLicense(owner, repo)
+		switch {
+		case err != nil:
+			ui.Warn(fmt.Sprintf("Could not fetch license: %v", err))
+		case !license.HasFile:
+			ui.Warn("Repository has no license file (brew audit will flag this)")
+			repository.License = ""
+		case license.SPDXID == "" || license.SPDXID == "NOASSERTION":
+			// The forge's classifier misses reformatted copies of common licenses
+			if guessed, path := spdx.GuessRepo(client, owner, repo, license.Path); guessed != "" {
+				ui.Warn(fmt.Sprintf("Guessed license %s from %s (%s could not classify it, please verify)", guessed, path, host))
+				repository.License = guessed
+			} else {
+				ui.Info(fmt.Sprintf("Custom license in %s", license.Path))
+				repository.License = homebrew.LicenseCannotRepresent
+			}
+		default:
+			repository.License = license.SPDXID
+		}
+	}
+	// Curated fields in the tap's metadata file win over the API
+	overrides, err := metadata.Load(flagMetadata)
+	if err != nil {
+		return err
+	}
+	if applied := overrides.Apply(packageName, &repository.Description, &repository.Homepage, &repository.License); len(applied) > 0 {
+		ui.Info(fmt.Sprintf("Metadata overrides from %s: %s", flagMetadata, strings.Join(applied, ", ")))
+	}
+	ui.Info(fmt.Sprintf("License: %s", repository.License))
 
-	// Get latest release
-	fmt.Println(titleStyle.Render("\n🔍 Finding latest release..."))
-	release, err := client.GetLatestRelease(owner, repo)
+	// Get the pinned or latest release
+	if flagVersion != "" {
+		ui.Title(fmt.Sprintf("\n🔍 Finding release %s...", flagVersion))
+	} else {
+		ui.Title("\n🔍 Finding latest release...")
+	}
+	release, err := github.GetRelease(client, owner, repo, flagVersion)
+	if github.IsNotFound(err) && flagVersion == "" {
+		// Many projects only tag versions; build the newest tag from source
+		ui.Warn("No releases found, falling back to the latest tag")
+		release, err = client.GetLatestTag(owner, repo)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to fetch latest release: %w", err)
+		return fmt.Errorf("failed to fetch release: %w", err)
+	}
+	fromTag := release.FromTag
+	if fromTag {
+		if flagLibexec || flagSelectAsset != "" || flagAllArches || flagMergeAssets {
+			return fmt.Errorf("tag %s has no release; --libexec, --select-asset, --all-arches and --merge-assets need release assets", release.TagName)
+		}
+		if flagVersion != "" {
+			ui.Warn(fmt.Sprintf("Tag %s has no release, building it from source", release.TagName))
+		}
+		if flagAssetURL == "" {
+			flagFromSource = true
+		}
+	}
+
+	// Warn if a newer tag exists without a release marked as latest
+	if !fromTag && flagVersion == "" {
+		if tags, err := client.ListTags(owner, repo); err == nil {
+			if newer := github.NewerTag(release.TagName, tags); newer != "" {
+				ui.Warn(fmt.Sprintf("Tag %s is newer than latest release %s (not marked as a release?)", newer, release.TagName))
+			}
+		}
 	}
 	version := release.TagName
 	if len(version) > 0 && version[0] == 'v' {
 		version = version[1:] // Remove 'v' prefix
 	}
-	fmt.Println(successStyle.Render(fmt.Sprintf("✓ Version: %s", version)))
+	ui.Success(fmt.Sprintf("Version: %s", version))
+
+	// Record why the asset and build system were chosen
+	decision := platform.NewDecision(owner+"/"+repo, version)
 
 	// Select asset
-	fmt.Println(titleStyle.Render("\n🔍 Analyzing release assets..."))
+	ui.Title("\n🔍 Analyzing release assets...")
 
 	var selectedAsset *platform.Asset
+	var archAssets map[platform.Architecture]*platform.Asset
+	var splitAssets []*platform.Asset // Extra archives of a split release, for --merge-assets
 	var downloadURL string
 
-	if flagFromSource {
+	if flagAssetURL != "" {
+		// Use the given asset, skipping Linux filtering and selection
+		selectedAsset, err = platform.AssetFromURL(flagAssetURL)
+		if err != nil {
+			return fmt.Errorf("invalid --asset-url: %w", err)
+		}
+		if err := platform.CheckFlatpak([]*platform.Asset{selectedAsset}); err != nil {
+			return err
+		}
+		downloadURL = selectedAsset.DownloadURL
+		decision.Selected = selectedAsset.Name
+		decision.Reason = "asset URL given with --asset-url"
+		ui.Success(fmt.Sprintf("Using: %s (--asset-url)", selectedAsset.Name))
+	} else if fromTag {
+		// Tag without a release: its source tarball is the only asset
+		downloadURL = release.Assets[0].DownloadURL
+		ui.Info(fmt.Sprintf("Using source tarball of tag %s", release.TagName))
+		decision.Reason = "no releases published, using the latest tag's source tarball"
+		ui.Success(fmt.Sprintf("URL: %s", downloadURL))
+	} else if flagFromSource {
 		// Use source tarball
-		downloadURL = fmt.Sprintf("https://github.com/%s/%s/archive/v%s.tar.gz", owner, repo, version)
-		fmt.Println(infoStyle.Render("  Using source tarball (--from-source)"))
-		fmt.Println(successStyle.Render(fmt.Sprintf("✓ URL: %s", downloadURL)))
+		downloadURL = github.SourceArchiveURL(host, owner, repo, release.TagName)
+		ui.Info("Using source tarball (--from-source)")
+		decision.Reason = "source tarball requested with --from-source"
+		ui.Success(fmt.Sprintf("URL: %s", downloadURL))
 	} else {
 		// Try to find pre-built Linux binary
-		var assets []*platform.Asset
-		for _, ghAsset := range release.Assets {
-			asset := platform.DetectPlatform(ghAsset.Name)
-			if asset != nil {
-				asset.URL = ghAsset.URL
-				asset.DownloadURL = ghAsset.BrowserDownloadURL
-				asset.Size = ghAsset.Size
-				assets = append(assets, asset)
-			}
-		}
+		assets := release.PlatformAssets()
+
+		decision.AddCandidates(assets)
 
 		// Filter Linux assets only
 		linuxAssets := platform.FilterLinuxAssets(assets)
 
-		if len(linuxAssets) == 0 {
-			fmt.Println(warnStyle.Render("⚠ No Linux binaries found in releases"))
-			fmt.Println(infoStyle.Render("  Falling back to source tarball"))
-			downloadURL = fmt.Sprintf("https://github.com/%s/%s/archive/v%s.tar.gz", owner, repo, version)
+		// Skip oversized assets (e.g. VM images) before selection
+		linuxAssets, skipped := platform.FilterBySize(linuxAssets, maxAssetSize)
+		for _, asset := range skipped {
+			decision.Reject(asset.Name, "exceeds --max-asset-size")
+			ui.Warn(fmt.Sprintf("Skipping %s (%.1f MB exceeds --max-asset-size)",
+				asset.Name, float64(asset.Size)/(1024*1024)))
+		}
+
+		if flagSelectAsset != "" && len(linuxAssets) == 0 {
+			return fmt.Errorf("--select-asset given but the release has no Linux assets")
+		}
+		if flagAllArches && len(linuxAssets) == 0 {
+			return fmt.Errorf("--all-arches given but the release has no Linux assets")
+		}
+		if flagAllArches {
+			archAssets = platform.SelectArchAssets(linuxAssets)
+		}
+
+		if err := platform.CheckReleaseAssets(assets, false); err != nil {
+			// Source-only release, nothing prebuilt to choose from
+			ui.Warn(err.Error())
+			downloadURL = github.SourceArchiveURL(host, owner, repo, release.TagName)
 			flagFromSource = true
+			decision.Reason = "release has no uploaded assets, using source tarball"
+		} else if len(linuxAssets) == 0 {
+			ui.Warn("No Linux binaries found in releases")
+			ui.Info("Falling back to source tarball")
+			downloadURL = github.SourceArchiveURL(host, owner, repo, release.TagName)
+			flagFromSource = true
+			decision.Reason = "no Linux binaries found, using source tarball"
 		} else {
-			fmt.Println(infoStyle.Render(fmt.Sprintf("  Found %d Linux asset(s)", len(linuxAssets))))
+			ui.Info(fmt.Sprintf("Found %d Linux asset(s)", len(linuxAssets)))
+			if flagVerbose {
+				for i, asset := range linuxAssets {
+					ui.Printf("   [%d] %s\n", i, asset.Name)
+				}
+			}
 
-			// Select best asset
 			var err error
-			selectedAsset, err = platform.SelectBestAsset(linuxAssets)
-			if err != nil {
-				return fmt.Errorf("failed to select asset: %w", err)
+			if flagSelectAsset != "" {
+				selectedAsset, err = platform.SelectAssetByChoice(linuxAssets, flagSelectAsset)
+				if err != nil {
+					return fmt.Errorf("invalid --select-asset: %w", err)
+				}
+				decision.Selected = selectedAsset.Name
+				decision.Reason = "chosen with --select-asset"
+			} else {
+				// Select best asset
+				selectedAsset, err = platform.SelectBestAsset(linuxAssets)
+				if err != nil {
+					return fmt.Errorf("failed to select asset: %w", err)
+				}
+				decision.Select(selectedAsset)
 			}
 
 			downloadURL = selectedAsset.DownloadURL
-			fmt.Println(successStyle.Render(fmt.Sprintf("✓ Selected: %s (%s - Priority %d)",
-				selectedAsset.Name, selectedAsset.Format, selectedAsset.Priority)))
+			ui.Success(fmt.Sprintf("Selected: %s (%s - Priority %d)",
+				selectedAsset.Name, selectedAsset.Format, selectedAsset.Priority))
+
+			// Some releases split a tool across several archives
+			splitAssets = platform.SplitAssets(linuxAssets, selectedAsset)
+			if len(splitAssets) > 0 && !flagMergeAssets {
+				ui.Warn(platform.SplitAssetsWarning(splitAssets, selectedAsset, "add them with --merge-assets"))
+				splitAssets = nil
+			} else if flagMergeAssets && len(splitAssets) == 0 {
+				ui.Info("No other archives to merge (--merge-assets)")
+			}
+			if len(splitAssets) > platform.MaxMergedAssets {
+				return fmt.Errorf("--merge-assets found %d extra archives (%s), more than the limit of %d; package them separately",
+					len(splitAssets), platform.AssetNames(splitAssets), platform.MaxMergedAssets)
+			}
 		}
 	}
 
 	// Download and calculate checksum
-	fmt.Println(titleStyle.Render("\n⬇️  Downloading asset..."))
-	data, err := checksum.DownloadFile(downloadURL)
-	if err != nil {
-		return fmt.Errorf("failed to download asset: %w", err)
+	ui.Title("\n⬇️  Downloading asset...")
+	var sha256, assetPath string
+	if flagSHA256 != "" {
+		if sha256, err = checksum.ParseSHA256(flagSHA256); err != nil {
+			return fmt.Errorf("failed to download asset: %w", err)
+		}
+		ui.Info("Skipped download, using --sha256 (archive contents will not be inspected)")
+	} else {
+		limit := maxAssetSize
+		if flagFromSource {
+			limit = 0 // --max-asset-size is for release assets
+		}
+		sum, size, tmpPath, err := downloadAsset(downloadURL, path.Base(downloadURL), limit)
+		if err != nil {
+			return fmt.Errorf("failed to download asset: %w", err)
+		}
+		defer os.Remove(tmpPath)
+		sha256, assetPath = sum, tmpPath
+		ui.Success(fmt.Sprintf("Downloaded %.1f MB", float64(size)/(1024*1024)))
+	}
+	ui.Success(fmt.Sprintf("SHA256: %s", sha256))
+
+	if flagVerifySig != "" {
+		sigURL, err := checksum.VerifySignature(downloadURL, assetPath, flagVerifySig)
+		if err != nil {
+			return fmt.Errorf("signature verification failed: %w", err)
+		}
+		ui.Success(fmt.Sprintf("Signature verified: %s", sigURL))
+	}
+
+	// Check the pre-built asset against checksums the project publishes,
+	// either as a checksum file or pasted into the release notes
+	if !flagFromSource {
+		message, found, warn, err := checksum.CheckUpstream(downloadURL, release.Body, selectedAsset.Name, assetPath, sha256, flagChecksumFile...)
+		switch {
+		case err != nil:
+			return err
+		case warn:
+			ui.Warn(message)
+		case found:
+			ui.Success(message)
+		default:
+			ui.Info(message)
+		}
+	}
+
+	// Locate the binary inside pre-built archives, relative to the directory
+	// Homebrew extracts into, and check for a bundled runtime layout
+	libexecBinary := binaryName
+	minGlibc := ""
+	if !flagFromSource && assetPath != "" {
+		binary, glibc, err := inspectAsset(assetPath, selectedAsset, binaryName)
+		if err != nil {
+			return err
+		}
+		if binary != "" {
+			libexecBinary = binary
+		}
+		minGlibc = glibc
 	}
-	fmt.Println(successStyle.Render(fmt.Sprintf("✓ Downloaded %.1f MB", float64(len(data))/(1024*1024))))
 
-	// Calculate SHA256
-	fmt.Println(titleStyle.Render("\n🔐 Calculating SHA256..."))
-	sha256 := checksum.CalculateSHA256(data)
-	fmt.Println(successStyle.Render(fmt.Sprintf("✓ SHA256: %s", sha256)))
+	if flagVerifyRun && flagFromSource {
+		ui.Warn("Skipping --verify-run: the formula builds from source")
+	}
 
 	// Generate formula based on whether we're building from source
-	fmt.Println(titleStyle.Render("\n📝 Generating formula..."))
+	ui.Title("\n📝 Generating formula...")
 
-	var formula string
+	var formulaData *homebrew.FormulaData
+	completionsRouted := false
 
-	if flagFromSource {
+	if flagGoInstall != "" {
+		ui.Info(fmt.Sprintf("Building with go install %s@%s", flagGoInstall, homebrew.GoInstallVersion(release.TagName)))
+		decision.BuildSystem = "Go"
+
+		formulaData, err = homebrew.NewFormulaDataGoInstall(
+			packageName,
+			version,
+			sha256,
+			downloadURL,
+			repository.Description,
+			repository.Homepage,
+			repository.License,
+			flagGoInstall,
+			release.TagName,
+			binaryName,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create formula data: %w", err)
+		}
+	} else if flagFromSource {
 		// Fetch repository files to detect build system
-		fmt.Println(infoStyle.Render("  Detecting build system from repository..."))
+		ui.Info("Detecting build system from repository...")
 
-		// Get repository tree to detect build system
-		repoFiles, err := client.GetRepoFiles(owner, repo)
+		// Get the full repository tree so nested main packages can be found
+		repoFiles, err := client.GetRepoTree(owner, repo)
 		if err != nil {
-			fmt.Println(warnStyle.Render(fmt.Sprintf("  ⚠ Could not fetch repository files: %v", err)))
-			fmt.Println(infoStyle.Render("  Generating simple formula template"))
+			ui.Warn(fmt.Sprintf("Could not fetch repository files: %v", err))
+			ui.Info("Generating simple formula template")
+		} else if len(repoFiles) == 0 {
+			ui.Warn("Repository is empty")
+			ui.Info("Generating simple formula template")
+		} else if buildSys := buildsystem.Detect(repoFiles); buildSys == nil {
+			ui.Warn("Could not detect build system")
+			ui.Info("Generating simple formula template")
+		} else {
+			ui.Success(fmt.Sprintf("Detected build system: %s", buildSys.Name()))
+			decision.BuildSystem = buildSys.Name()
 
-			// Fallback to simple formula
-			formulaData := homebrew.NewFormulaDataSimple(
+			// Some build systems need file contents, e.g. the Makefile's targets
+			sources := map[string]string{}
+			for _, path := range homebrew.SourcesToFetch(buildSys, repoFiles) {
+				text, err := client.GetFileContent(owner, repo, path)
+				if err != nil {
+					ui.Warn(fmt.Sprintf("Could not fetch %s: %v", path, err))
+					continue
+				}
+				sources[path] = text
+			}
+
+			formulaData, err = homebrew.NewFormulaData(
 				packageName,
 				version,
 				sha256,
@@ -206,65 +662,27 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 				repository.Description,
 				repository.Homepage,
 				repository.License,
+				repoFiles,
+				sources,
 				binaryName,
+				flagCompletions,
 			)
-			formulaData.SourceURL = fmt.Sprintf("https://github.com/%s/%s", owner, repo)
-
-			formula, err = homebrew.GenerateFormula(formulaData)
 			if err != nil {
-				return fmt.Errorf("failed to generate formula: %w", err)
+				return fmt.Errorf("failed to create formula data: %w", err)
 			}
-		} else {
-			// Detect build system
-			buildSys := buildsystem.Detect(repoFiles)
-			if buildSys == nil {
-				fmt.Println(warnStyle.Render("  ⚠ Could not detect build system"))
-				fmt.Println(infoStyle.Render("  Generating simple formula template"))
-
-				formulaData := homebrew.NewFormulaDataSimple(
-					packageName,
-					version,
-					sha256,
-					downloadURL,
-					repository.Description,
-					repository.Homepage,
-					repository.License,
-					binaryName,
-				)
-				formulaData.SourceURL = fmt.Sprintf("https://github.com/%s/%s", owner, repo)
-
-				formula, err = homebrew.GenerateFormula(formulaData)
-				if err != nil {
-					return fmt.Errorf("failed to generate formula: %w", err)
-				}
-			} else {
-				fmt.Println(successStyle.Render(fmt.Sprintf("✓ Detected build system: %s", buildSys.Name())))
-
-				formulaData, err := homebrew.NewFormulaData(
-					packageName,
-					version,
-					sha256,
-					downloadURL,
-					repository.Description,
-					repository.Homepage,
-					repository.License,
-					repoFiles,
-					binaryName,
-				)
-				if err != nil {
-					return fmt.Errorf("failed to create formula data: %w", err)
-				}
-				formulaData.SourceURL = fmt.Sprintf("https://github.com/%s/%s", owner, repo)
+			completionsRouted = true
 
-				formula, err = homebrew.GenerateFormula(formulaData)
-				if err != nil {
-					return fmt.Errorf("failed to generate formula: %w", err)
-				}
+			// A detected build system also builds a checkout of the default branch
+			if repository.DefaultBranch != "" {
+				formulaData.HeadURL = github.RepoURL(host, owner, repo) + ".git"
+				formulaData.HeadBranch = repository.DefaultBranch
 			}
 		}
-	} else {
-		// Pre-built binary - simple install
-		formulaData := homebrew.NewFormulaDataSimple(
+	}
+
+	if formulaData == nil {
+		// Pre-built binary or undetected build system - simple install
+		formulaData, err = homebrew.NewFormulaDataSimple(
 			packageName,
 			version,
 			sha256,
@@ -274,11 +692,128 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 			repository.License,
 			binaryName,
 		)
-		formulaData.SourceURL = fmt.Sprintf("https://github.com/%s/%s", owner, repo)
+		if err != nil {
+			return fmt.Errorf("failed to create formula data: %w", err)
+		}
+	}
+	formulaData.SourceURL = github.RepoURL(host, owner, repo)
+	formulaData.MinGlibc = minGlibc
+	for _, caveat := range flagCaveats {
+		if err := formulaData.AddCaveat(caveat); err != nil {
+			return fmt.Errorf("invalid --caveats: %w", err)
+		}
+	}
+	formulaData.Notes = notes
+	formulaData.NoMagicComments = flagNoMagic
 
-		formula, err = homebrew.GenerateFormula(formulaData)
+	for _, asset := range splitAssets {
+		resource, err := downloadSplitAsset(asset)
 		if err != nil {
-			return fmt.Errorf("failed to generate formula: %w", err)
+			return err
+		}
+		formulaData.AddResource(resource.Name, resource.URL, resource.SHA256)
+	}
+
+	if flagAllArches {
+		var assets [2]*homebrew.ArchAsset
+		for i, arch := range []platform.Architecture{platform.ArchX86_64, platform.ArchARM64} {
+			asset := archAssets[arch]
+			if asset == nil || asset == selectedAsset {
+				if asset != nil {
+					assets[i] = &homebrew.ArchAsset{URL: asset.DownloadURL, SHA256: sha256}
+					ui.Success(fmt.Sprintf("%s: %s (%s)", asset.Arch, asset.Name, sha256))
+				}
+				continue
+			}
+
+			archAsset, binary, glibc, err := downloadArchAsset(asset, binaryName, maxAssetSize)
+			if err != nil {
+				return err
+			}
+			// Every architecture shares the install block and glibc caveat
+			if binary != "" && assetPath != "" && binary != libexecBinary {
+				return fmt.Errorf("--all-arches: the %s archive has the binary at %s, not %s", arch, binary, libexecBinary)
+			}
+			formulaData.MinGlibc = newerGlibc(formulaData.MinGlibc, glibc)
+			assets[i] = archAsset
+		}
+		if assets[0] == nil || assets[1] == nil {
+			ui.Warn("Only one architecture has a Linux asset; restricting the formula to it with depends_on arch")
+		}
+		formulaData.SetArchAssets(assets[0], assets[1])
+	}
+
+	if flagLibexec {
+		if flagFromSource {
+			ui.Warn("Ignoring --libexec for source build")
+		} else {
+			formulaData.InstallBlock = homebrew.LibexecInstallBlock(libexecBinary, binaryName)
+			ui.Info(fmt.Sprintf("Installing into libexec, linking %s", libexecBinary))
+		}
+	}
+
+	if flagSbin {
+		if err := formulaData.InstallToSbin(); err != nil {
+			return fmt.Errorf("cannot use --sbin: %w", err)
+		}
+		ui.Info("Installing into sbin (--sbin)")
+	}
+
+	// NewFormulaData already generates completions for source builds
+	if flagCompletions != "" && !completionsRouted {
+		if err := formulaData.AddCompletions(binaryName, flagCompletions); err != nil {
+			return fmt.Errorf("cannot use --gen-completions: %w", err)
+		}
+		ui.Info(fmt.Sprintf("Generating completions with %s %s", binaryName, flagCompletions))
+	}
+
+	if flagToolchain != "" {
+		if formulaData.BuildSystem == "Binary" {
+			return fmt.Errorf("--toolchain requires a source build (use --from-source)")
+		}
+		formulaData.Dependencies, err = buildsystem.ReplaceToolchain(formulaData.Dependencies, flagToolchain)
+		if err != nil {
+			return err
+		}
+		ui.Info(fmt.Sprintf("Toolchain: %s (--toolchain)", flagToolchain))
+	}
+
+	if flagClassName != "" {
+		formulaData.ClassName = flagClassName
+		ui.Info(fmt.Sprintf("Class name: %s (--class-name)", flagClassName))
+	}
+
+	// The test block must exercise a binary the install block provides
+	if err := homebrew.CheckTestBinary(formulaData); err != nil {
+		ui.Warn(fmt.Sprintf("%v; the formula test will fail (adjust --binary or --name)", err))
+	}
+
+	// A same-named homebrew-core formula installs the same files
+	if !flagNoCoreCheck {
+		core, err := homebrew.LookupCoreFormula(packageName)
+		if err != nil {
+			ui.Warn(fmt.Sprintf("Could not check homebrew-core: %v", err))
+		} else if core != nil {
+			name, because := homebrew.CoreConflict(core)
+			formulaData.ConflictsWith = []string{name}
+			formulaData.ConflictsBecause = because
+			ui.Warn(fmt.Sprintf("homebrew-core also has a %s formula; adding conflicts_with (skip with --no-core-check)", core.Name))
+		}
+	}
+
+	formula, err := homebrew.GenerateFormula(formulaData)
+	if err != nil {
+		return fmt.Errorf("failed to generate formula: %w", err)
+	}
+
+	// Emit the selection decision
+	if flagVerbose {
+		ui.Println()
+		decision.WriteText(ui.Writer())
+	}
+	if flagLogJSON != "" {
+		if err := decision.WriteJSON(flagLogJSON); err != nil {
+			return err
 		}
 	}
 
@@ -289,6 +824,20 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		outputPath = filepath.Join("Formula", packageName+".rb")
 	}
 
+	// Catch near-duplicates elsewhere in the tap, like foo vs foo-linux
+	duplicates, err := homebrew.CheckDuplicates(filepath.Dir(filepath.Dir(outputPath)), packageName, flagAllowDup, outputPath)
+	if err != nil {
+		return err
+	}
+	for _, existing := range duplicates {
+		ui.Warn(fmt.Sprintf("Similar package already exists: %s", existing))
+	}
+
+	// Refuse to clobber hand-written formulas
+	if err := generator.CheckOverwrite(outputPath, flagForce); err != nil {
+		return err
+	}
+
 	// Ensure Formula directory exists
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
@@ -299,37 +848,375 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to write formula: %w", err)
 	}
 
-	fmt.Println(successStyle.Render(fmt.Sprintf("✓ Created: %s", outputPath)))
+	ui.Success(fmt.Sprintf("Created: %s", outputPath))
 
 	// Validate the generated formula
-	fmt.Println(titleStyle.Render("\n🔍 Validating generated formula..."))
+	ui.Title("\n🔍 Validating generated formula...")
 	result, err := validate.ValidateFile(outputPath, false, true)
 	if err != nil {
-		fmt.Println(errorStyle.Render("✗ Validation failed:"))
 		if result != nil {
 			for _, errMsg := range result.Errors {
-				fmt.Println(errorStyle.Render(fmt.Sprintf("  - %s", errMsg)))
+				ui.Error(errMsg)
 			}
 		}
 		return fmt.Errorf("generated formula failed validation")
 	}
 
 	if result.Fixed {
-		fmt.Println(successStyle.Render("✓ Validation passed (style issues auto-fixed)"))
+		ui.Success("Validation passed (style issues auto-fixed)")
 	} else {
-		fmt.Println(successStyle.Render("✓ Validation passed"))
+		ui.Success("Validation passed")
+	}
+
+	if flagInstall {
+		ui.Title("\n📦 Installing formula...")
+		if err := validate.Install(outputPath, flagFromSource); err != nil {
+			return fmt.Errorf("failed to install formula: %w", err)
+		}
+		ui.Success(fmt.Sprintf("Installed %s", packageName))
+
+		ui.Title("\n✅ Done! Next steps:")
+		ui.Printf("   1. Review %s\n", outputPath)
+		ui.Printf("   2. Commit and push\n")
+		return nil
 	}
 
 	// Print next steps
-	fmt.Println(titleStyle.Render("\n✅ Done! Next steps:"))
-	fmt.Println(infoStyle.Render(fmt.Sprintf("   1. Review %s", outputPath)))
+	ui.Title("\n✅ Done! Next steps:")
+	ui.Printf("   1. Review %s\n", outputPath)
 	if flagFromSource {
-		fmt.Println(infoStyle.Render("   2. Test: HOMEBREW_NO_INSTALL_FROM_API=1 brew install --build-from-source " + packageName))
+		ui.Printf("   2. Test: HOMEBREW_NO_INSTALL_FROM_API=1 brew install --build-from-source %s\n", packageName)
 	} else {
-		fmt.Println(infoStyle.Render("   2. Verify binary paths and adjust if needed"))
-		fmt.Println(infoStyle.Render("   3. Test: brew install " + packageName))
+		ui.Printf("   2. Verify binary paths and adjust if needed\n")
+		ui.Printf("   3. Test: brew install %s\n", packageName)
+	}
+	ui.Printf("   4. Commit and push\n")
+
+	return nil
+}
+
+func runNormalize(cmd *cobra.Command, args []string) error {
+	path := args[0]
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read formula: %w", err)
+	}
+
+	normalized := homebrew.StripMacOSStanzas(string(content))
+	if normalized == string(content) {
+		ui.Success(fmt.Sprintf("%s has no macOS-only stanzas", path))
+		return nil
+	}
+
+	if err := os.WriteFile(path, []byte(normalized), 0644); err != nil {
+		return fmt.Errorf("failed to write formula: %w", err)
+	}
+
+	ui.Success(fmt.Sprintf("Stripped macOS-only stanzas from %s", path))
+	return nil
+}
+
+func runBump(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	if !strings.HasSuffix(path, ".rb") {
+		path = filepath.Join("Formula", path+".rb")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read formula: %w", err)
+	}
+
+	src, err := homebrew.ParseFormulaSource(string(content))
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	repoURL := src.RepoURL
+	if repoURL == "" && len(src.URLs) > 0 {
+		repoURL = src.URLs[0]
+	}
+	host, owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		return fmt.Errorf("cannot find the repository of %s: %w", path, err)
+	}
+
+	client, err := newForge(host)
+	if err != nil {
+		return err
+	}
+
+	ui.Title(fmt.Sprintf("🔍 Checking %s/%s for a new release...", owner, repo))
+	release, err := client.GetLatestRelease(owner, repo)
+	if github.IsNotFound(err) {
+		release, err = client.GetLatestTag(owner, repo)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	latest := version.Normalize(release.TagName)
+	if version.Compare(latest, src.Version) <= 0 {
+		ui.Success(fmt.Sprintf("%s is already latest (%s)", path, src.Version))
+		return nil
+	}
+	ui.Info(fmt.Sprintf("Version: %s → %s", src.Version, latest))
+
+	ui.Title("\n⬇️  Downloading new release...")
+	sha256s := make(map[string]string, len(src.URLs))
+	for _, url := range src.URLs {
+		newURL := homebrew.BumpURL(url, src.Version, latest)
+		bar := ui.NewProgress(filepath.Base(newURL))
+		sum, _, tmpPath, err := checksum.DownloadAndHashWithProgress(newURL, bar.Update)
+		bar.Done()
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %w", newURL, err)
+		}
+		os.Remove(tmpPath)
+		sha256s[url] = sum
+		ui.Success(fmt.Sprintf("%s: %s", filepath.Base(newURL), sha256s[url]))
+	}
+
+	updated, err := homebrew.BumpFormula(string(content), src, latest, sha256s)
+	if err != nil {
+		return fmt.Errorf("failed to bump %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write formula: %w", err)
+	}
+
+	ui.Success(fmt.Sprintf("Bumped %s to %s", path, latest))
+	return nil
+}
+
+func runListOutdated(cmd *cobra.Command, args []string) error {
+	repoRoot, err := validate.FindRepoRoot()
+	if err != nil {
+		return fmt.Errorf("failed to find repository root: %w", err)
+	}
+	files, err := validate.TapFiles(repoRoot)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no formulas or casks found in %s", repoRoot)
+	}
+
+	// One client per forge host, shared by the workers so the rate limit
+	// is tracked across all lookups
+	var mu sync.Mutex
+	clients := map[string]github.Forge{}
+	latest := func(host, owner, repo string) (string, error) {
+		mu.Lock()
+		client, ok := clients[host]
+		if !ok {
+			var err error
+			if client, err = newForge(host); err != nil {
+				mu.Unlock()
+				return "", err
+			}
+			clients[host] = client
+		}
+		mu.Unlock()
+
+		release, err := client.GetLatestRelease(owner, repo)
+		if github.IsNotFound(err) {
+			release, err = client.GetLatestTag(owner, repo)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch latest release: %w", err)
+		}
+		return release.TagName, nil
+	}
+
+	ui.Title(fmt.Sprintf("🔍 Checking %d package(s) for new releases...", len(files)))
+	results := homebrew.CheckOutdated(files, flagJobs, latest)
+
+	if flagJSON {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode results: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	var outdated []*homebrew.PackageStatus
+	for _, result := range results {
+		if result.Error != "" {
+			ui.Warn(fmt.Sprintf("%s: %s", result.Name, result.Error))
+		} else if result.Outdated {
+			outdated = append(outdated, result)
+		}
+	}
+	if len(outdated) == 0 {
+		ui.Success("No outdated packages found")
+		return nil
+	}
+
+	ui.Println()
+	w := tabwriter.NewWriter(ui.Writer(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tKIND\tCURRENT\tLATEST\tREPOSITORY")
+	for _, result := range outdated {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", result.Name, result.Kind, result.Current, result.Latest, result.Repo)
+	}
+	w.Flush()
+	ui.Println()
+	ui.Info(fmt.Sprintf("%d of %d package(s) are outdated", len(outdated), len(results)))
+	return nil
+}
+
+func runAddResource(cmd *cobra.Command, args []string) error {
+	path, url := args[0], args[1]
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read formula: %w", err)
+	}
+
+	name := flagResourceName
+	if name == "" {
+		name = homebrew.ResourceNameFromURL(url)
+	}
+
+	ui.Title("⬇️  Downloading resource...")
+	sha256, _, tmpPath, err := checksum.DownloadAndHash(url)
+	if err != nil {
+		return fmt.Errorf("failed to download resource: %w", err)
+	}
+	os.Remove(tmpPath)
+	ui.Success(fmt.Sprintf("SHA256: %s", sha256))
+
+	updated, err := homebrew.InsertResource(string(content), name, url, sha256)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write formula: %w", err)
 	}
-	fmt.Println(infoStyle.Render("   4. Commit and push"))
 
+	ui.Success(fmt.Sprintf("Added resource %q to %s", name, path))
 	return nil
 }
+
+// downloadAsset downloads an asset with a progress bar and hashes it,
+// refusing assets over maxSize (0 disables the limit), including those
+// whose size the release did not report. The caller removes tmpPath.
+func downloadAsset(url, name string, maxSize int64) (sha256sum string, size int64, tmpPath string, err error) {
+	bar := ui.NewProgress(name)
+	sha256sum, size, tmpPath, err = downloadAndHash(url, bar.Update)
+	bar.Done()
+	if err != nil {
+		return "", 0, "", err
+	}
+	if maxSize > 0 && size > maxSize {
+		os.Remove(tmpPath)
+		return "", 0, "", fmt.Errorf("%s is %.1f MB, over --max-asset-size", name, float64(size)/(1024*1024))
+	}
+	return sha256sum, size, tmpPath, nil
+}
+
+// downloadArchAsset downloads and inspects another architecture's asset
+// for --all-arches, returning its URL and SHA256 along with the binary and
+// minimum glibc inspectAsset found in it
+func downloadArchAsset(asset *platform.Asset, binaryName string, maxSize int64) (archAsset *homebrew.ArchAsset, binary, minGlibc string, err error) {
+	if maxSize > 0 && asset.Size > maxSize {
+		return nil, "", "", fmt.Errorf("%s asset %s exceeds --max-asset-size", asset.Arch, asset.Name)
+	}
+
+	ui.Info(fmt.Sprintf("Downloading %s...", asset.Name))
+	sha256, _, tmpPath, err := downloadAsset(asset.DownloadURL, asset.Name, maxSize)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to download %s asset: %w", asset.Arch, err)
+	}
+	defer os.Remove(tmpPath)
+
+	if flagVerifySig != "" {
+		if _, err := checksum.VerifySignature(asset.DownloadURL, tmpPath, flagVerifySig); err != nil {
+			return nil, "", "", fmt.Errorf("signature verification failed for %s asset: %w", asset.Arch, err)
+		}
+	}
+	if binary, minGlibc, err = inspectAsset(tmpPath, asset, binaryName); err != nil {
+		return nil, "", "", err
+	}
+
+	ui.Success(fmt.Sprintf("%s: %s (%s)", asset.Arch, asset.Name, sha256))
+	return &homebrew.ArchAsset{URL: asset.DownloadURL, SHA256: sha256}, binary, minGlibc, nil
+}
+
+// newerGlibc returns the newer of two glibc versions, either of which may
+// be "" for none
+func newerGlibc(a, b string) string {
+	if version.Compare(b, a) > 0 {
+		return b
+	}
+	return a
+}
+
+// inspectAsset looks inside a downloaded pre-built archive for the binary,
+// returning its path relative to the directory Homebrew extracts into and
+// the newest glibc it needs, and reports its linkage and bundled runtime.
+// binary is "" when the archive cannot be read or has no binary.
+func inspectAsset(assetPath string, asset *platform.Asset, binaryName string) (binary, minGlibc string, err error) {
+	entries, err := archive.ListEntriesFromFile(assetPath, asset.Name)
+	if err != nil {
+		return "", "", nil
+	}
+	files := archive.Paths(entries)
+	best := archive.SelectBestBinary(archive.DetectBinariesFromEntries(entries), binaryName)
+	if best == "" {
+		return "", "", nil
+	}
+	binary = strings.TrimPrefix(best, archive.FindRootDirectory(files))
+	if !flagLibexec && archive.IsBundledRuntime(files, best) {
+		ui.Info(fmt.Sprintf("%s ships with a bundled runtime; consider --libexec", filepath.Base(best)))
+	}
+
+	// glibc-linked binaries fail on distros older than their newest symbol version
+	data, err := archive.ReadFileFromFile(assetPath, asset.Name, best)
+	if err != nil {
+		return binary, "", nil
+	}
+	if minGlibc, err = archive.DetectMinGlibc(data); err == nil && minGlibc != "" {
+		ui.Info(fmt.Sprintf("%s binary requires glibc %s or newer", asset.Arch, minGlibc))
+	}
+	if message, static := validate.CheckLinkage(data, filepath.Base(best), files, "add the providing formulas with depends_on or use --from-source"); static {
+		ui.Info(message)
+	} else if message != "" {
+		ui.Warn(message)
+	}
+	if flagVerifyRun {
+		message, ok, err := validate.VerifyRun(data, filepath.Base(best), asset.Arch)
+		if err != nil {
+			return "", "", err
+		}
+		if ok {
+			ui.Success(message)
+		} else {
+			ui.Warn(message)
+		}
+	}
+	return binary, minGlibc, nil
+}
+
+// downloadSplitAsset downloads an extra archive for --merge-assets and
+// returns it as a resource named after the file
+func downloadSplitAsset(asset *platform.Asset) (*homebrew.Resource, error) {
+	ui.Info(fmt.Sprintf("Downloading %s...", asset.Name))
+	bar := ui.NewProgress(asset.Name)
+	sha256, _, tmpPath, err := checksum.DownloadAndHashWithProgress(asset.DownloadURL, bar.Update)
+	bar.Done()
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", asset.Name, err)
+	}
+	defer os.Remove(tmpPath)
+
+	if flagVerifySig != "" {
+		if _, err := checksum.VerifySignature(asset.DownloadURL, tmpPath, flagVerifySig); err != nil {
+			return nil, fmt.Errorf("signature verification failed for %s: %w", asset.Name, err)
+		}
+	}
+
+	ui.Success(fmt.Sprintf("Resource: %s (%s)", asset.Name, sha256))
+	return &homebrew.Resource{Name: homebrew.ResourceNameFromURL(asset.Name), URL: asset.DownloadURL, SHA256: sha256}, nil
+}
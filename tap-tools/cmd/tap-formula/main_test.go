@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/castrojo/tap-tools/internal/checksum"
+	"github.com/castrojo/tap-tools/internal/forge/forgetest"
+)
+
+// errDownloadStopped ends a test run at the download, once the URL is known
+var errDownloadStopped = errors.New("download stopped")
+
+func TestGenerateSourceFallback(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		release []string
+		flags   []string
+		wantURL string
+	}{
+		{"Source-only release", "v1.0.0", nil, nil, "https://github.com/owner/tool/archive/v1.0.0.tar.gz"},
+		{"No Linux assets", "v1.0.0", []string{"tool-windows-amd64.zip", "tool-darwin-arm64.dmg"}, nil, "https://github.com/owner/tool/archive/v1.0.0.tar.gz"},
+		{"Tag without a v prefix", "1.2.3", nil, nil, "https://github.com/owner/tool/archive/1.2.3.tar.gz"},
+		{"From source", "1.2.3", []string{"tool-linux-amd64.tar.gz"}, []string{"--from-source"}, "https://github.com/owner/tool/archive/1.2.3.tar.gz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var downloaded []string
+			saved := downloadAndHash
+			downloadAndHash = func(url string, progress checksum.ProgressFunc) (string, int64, string, error) {
+				downloaded = append(downloaded, url)
+				return "", 0, "", errDownloadStopped
+			}
+			t.Cleanup(func() { downloadAndHash = saved })
+
+			f := &forgetest.Forge{Release: forgetest.NewRelease(tt.tag, tt.release...)}
+			err := forgetest.Run(t, rootCmd, &newForge, f, append([]string{"generate", "owner/tool"}, tt.flags...)...)
+			if !errors.Is(err, errDownloadStopped) {
+				t.Fatalf("generate error = %v, want the download to be reached", err)
+			}
+			if len(downloaded) != 1 || downloaded[0] != tt.wantURL {
+				t.Errorf("downloaded %v, want [%s]", downloaded, tt.wantURL)
+			}
+		})
+	}
+}
@@ -10,50 +10,21 @@ import (
 
 	"github.com/castrojo/tap-tools/internal/github"
 	"github.com/castrojo/tap-tools/internal/issues"
-	"github.com/charmbracelet/lipgloss"
+	"github.com/castrojo/tap-tools/internal/ui"
 	"github.com/spf13/cobra"
 )
 
-// Styles
-var (
-	successStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Bold(true)
-	errorStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true)
-	infoStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
-	warnStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true)
-	sectionStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("13")).Bold(true)
-	highlightStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
-)
-
-func printSuccess(msg string) {
-	fmt.Println(successStyle.Render("✓ " + msg))
-}
-
-func printError(msg string) {
-	fmt.Fprintln(os.Stderr, errorStyle.Render("Error: "+msg))
-}
-
-func printInfo(msg string) {
-	fmt.Println(infoStyle.Render("→ " + msg))
-}
-
-func printWarn(msg string) {
-	fmt.Println(warnStyle.Render("⚠ " + msg))
-}
-
-func printSection(msg string) {
-	fmt.Println()
-	fmt.Println(sectionStyle.Render("━━━ " + msg + " ━━━"))
-}
-
-func printHighlight(msg string) {
-	fmt.Println(highlightStyle.Render(msg))
-}
-
 var (
+	noColor  bool
+	quiet    bool
+	cache    bool
 	createPR bool
 	dryRun   bool
 	owner    string
 	repo     string
+
+	label        string
+	summaryIssue int
 )
 
 func main() {
@@ -66,8 +37,19 @@ func main() {
 3. Generating the appropriate package
 4. Creating git branch and commit
 5. Optionally creating PR and commenting on issue`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			ui.Configure(ui.Options{NoColor: noColor, Quiet: quiet})
+			if err := github.ConfigureCache(cache); err != nil {
+				return fmt.Errorf("invalid --cache: %w", err)
+			}
+			return nil
+		},
 	}
 
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also honors NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only print warnings and errors")
+	rootCmd.PersistentFlags().BoolVar(&cache, "cache", false, "Cache API responses on disk and revalidate them with ETags (also enabled by $TAP_CACHE_DIR)")
+
 	processCmd := &cobra.Command{
 		Use:   "process <issue-number>",
 		Short: "Process a GitHub issue and create package",
@@ -80,210 +62,372 @@ func main() {
 	processCmd.Flags().StringVar(&owner, "owner", "", "GitHub repository owner (auto-detected from git remote if not specified)")
 	processCmd.Flags().StringVar(&repo, "repo", "", "GitHub repository name (auto-detected from git remote if not specified)")
 
+	lintCmd := &cobra.Command{
+		Use:   "lint <issue-number>",
+		Short: "Check that an issue can be parsed before processing it",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runLint,
+	}
+
+	lintCmd.Flags().StringVar(&owner, "owner", "", "GitHub repository owner (auto-detected from git remote if not specified)")
+	lintCmd.Flags().StringVar(&repo, "repo", "", "GitHub repository name (auto-detected from git remote if not specified)")
+
+	processAllCmd := &cobra.Command{
+		Use:   "process-all",
+		Short: "Process every open issue with a label",
+		Args:  cobra.NoArgs,
+		RunE:  runProcessAll,
+	}
+
+	processAllCmd.Flags().StringVar(&label, "label", "package-request", "Process open issues with this label")
+	processAllCmd.Flags().IntVar(&summaryIssue, "summary-issue", 0, "Post the batch summary as a comment on this issue")
+	processAllCmd.Flags().BoolVar(&createPR, "create-pr", false, "Create a pull request for each generated package")
+	processAllCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Parse issues and show plans without creating anything")
+	processAllCmd.Flags().StringVar(&owner, "owner", "", "GitHub repository owner (auto-detected from git remote if not specified)")
+	processAllCmd.Flags().StringVar(&repo, "repo", "", "GitHub repository name (auto-detected from git remote if not specified)")
+
 	rootCmd.AddCommand(processCmd)
+	rootCmd.AddCommand(processAllCmd)
+	rootCmd.AddCommand(lintCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
 }
 
+func runLint(cmd *cobra.Command, args []string) error {
+	issueNumber, err := strconv.Atoi(args[0])
+	if err != nil {
+		ui.Error("Issue number must be a positive integer")
+		return err
+	}
+
+	if owner == "" || repo == "" {
+		detectedOwner, detectedRepo, err := getGitHubRepo()
+		if err != nil {
+			ui.Error("Could not determine GitHub repository from git remote")
+			return err
+		}
+		owner = detectedOwner
+		repo = detectedRepo
+	}
+
+	ui.Section(fmt.Sprintf("Linting Issue #%d", issueNumber))
+
+	report, err := issues.NewClient().LintIssue(owner, repo, issueNumber)
+	if err != nil {
+		ui.Error(err.Error())
+		return err
+	}
+	ui.Info(fmt.Sprintf("Title: %s", report.Title))
+	ui.Println()
+
+	for _, field := range report.Fields {
+		label := field.Field
+		if field.Value != "" {
+			label = fmt.Sprintf("%s: %s", field.Field, field.Value)
+		}
+
+		switch {
+		case field.Status == issues.FieldFound:
+			ui.Success(label)
+		case field.Required && field.Status != issues.FieldAmbiguous:
+			ui.Error(fmt.Sprintf("%s (%s)", label, field.Status))
+		default:
+			ui.Warn(fmt.Sprintf("%s (%s)", label, field.Status))
+		}
+		if field.Suggestion != "" {
+			ui.Printf("    %s\n", field.Suggestion)
+		}
+	}
+
+	ui.Println()
+	if !report.OK() {
+		ui.Error(fmt.Sprintf("Issue #%d cannot be processed until required fields are fixed", issueNumber))
+		return fmt.Errorf("issue #%d is missing required fields", issueNumber)
+	}
+	ui.Success(fmt.Sprintf("Issue #%d is ready: tap-issue process %d", issueNumber, issueNumber))
+	return nil
+}
+
 func runProcess(cmd *cobra.Command, args []string) error {
 	issueNumber, err := strconv.Atoi(args[0])
 	if err != nil {
-		printError("Issue number must be a positive integer")
+		ui.Error("Issue number must be a positive integer")
 		return err
 	}
 
+	if err := preflight(); err != nil {
+		return err
+	}
+
+	return processIssue(issues.NewClient(), issueNumber)
+}
+
+func runProcessAll(cmd *cobra.Command, args []string) error {
+	if err := preflight(); err != nil {
+		return err
+	}
+
+	client := issues.NewClient()
+
+	ui.Section(fmt.Sprintf("Listing Issues Labeled %q", label))
+	numbers, err := client.ListIssuesByLabel(owner, repo, label)
+	if err != nil {
+		ui.Error(err.Error())
+		return err
+	}
+	if len(numbers) == 0 {
+		ui.Info("No open issues with this label")
+		return nil
+	}
+	ui.Success(fmt.Sprintf("Found %d open issue(s)", len(numbers)))
+
+	// Each issue branches from where we started
+	startBranch, err := currentBranch()
+	if err != nil {
+		ui.Error("Could not determine current branch")
+		return err
+	}
+
+	var results []issues.BatchResult
+	for _, number := range numbers {
+		err := processIssue(client, number)
+		if err != nil {
+			ui.Error(fmt.Sprintf("Issue #%d failed: %v", number, err))
+		}
+		results = append(results, issues.BatchResult{Number: number, Err: err})
+
+		if !dryRun {
+			if err := runCommand("git", "checkout", startBranch); err != nil {
+				ui.Error(fmt.Sprintf("Failed to return to %s; stopping batch", startBranch))
+				return err
+			}
+		}
+	}
+
+	summary := issues.SummarizeBatch(label, results)
+	ui.Section("Batch Summary")
+	ui.Println(summary)
+
+	if summaryIssue > 0 && !dryRun {
+		if err := client.CommentOnIssue(owner, repo, summaryIssue, summary); err != nil {
+			ui.Warn(fmt.Sprintf("Failed to post summary on issue #%d", summaryIssue))
+		} else {
+			ui.Success(fmt.Sprintf("Posted summary on issue #%d", summaryIssue))
+		}
+	}
+
+	if failed := issues.FailedCount(results); failed > 0 {
+		return fmt.Errorf("%d of %d issue(s) failed", failed, len(results))
+	}
+	return nil
+}
+
+// preflight checks the token, git repository, and owner/repo before processing
+func preflight() error {
 	// Preflight checks
-	printSection("Preflight Checks")
+	ui.Section("Preflight Checks")
 
 	// Check for GitHub token - use the enhanced error message from github package
 	// This provides context-specific error messages based on the environment
 	if _, err := github.NewClientWithTokenCheck(); err != nil {
-		printError(err.Error())
+		ui.Error(err.Error())
 		return err
 	}
-	printSuccess("GitHub token found")
+	ui.Success("GitHub token found")
 
 	// Check if we're in a git repository
 	if !isGitRepo() {
-		printError("Not in a git repository")
+		ui.Error("Not in a git repository")
 		return fmt.Errorf("must be run from git repository")
 	}
-	printSuccess("Git repository detected")
+	ui.Success("Git repository detected")
 
 	// Auto-detect owner/repo from git remote if not specified
 	if owner == "" || repo == "" {
 		detectedOwner, detectedRepo, err := getGitHubRepo()
 		if err != nil {
-			printError("Could not determine GitHub repository from git remote")
+			ui.Error("Could not determine GitHub repository from git remote")
 			return err
 		}
 		owner = detectedOwner
 		repo = detectedRepo
 	}
-	printSuccess(fmt.Sprintf("Repository: %s/%s", owner, repo))
+	ui.Success(fmt.Sprintf("Repository: %s/%s", owner, repo))
 
-	// Fetch and parse issue
-	printSection(fmt.Sprintf("Fetching Issue #%d", issueNumber))
+	// Catch missing token permissions now rather than after generating the package
+	if createPR {
+		if err := github.NewClient().CheckPullRequestAccess(owner, repo); err != nil {
+			ui.Error(err.Error())
+			return err
+		}
+		ui.Success("Token can create pull requests")
+	}
 
-	client := issues.NewClient()
+	return nil
+}
 
-	printInfo("Fetching issue data...")
+// processIssue generates, commits, and pushes the package for one issue
+func processIssue(client *issues.Client, issueNumber int) error {
+	// Fetch and parse issue
+	ui.Section(fmt.Sprintf("Fetching Issue #%d", issueNumber))
+
+	ui.Info("Fetching issue data...")
 	req, err := client.GetIssue(owner, repo, issueNumber)
 	if err != nil {
-		printError(fmt.Sprintf("Failed to fetch issue: %v", err))
+		ui.Error(fmt.Sprintf("Failed to fetch issue: %v", err))
 		return err
 	}
 
-	printSuccess(fmt.Sprintf("Issue: %s", req.Title))
-	printInfo(fmt.Sprintf("State: %s", req.State))
-	printInfo(fmt.Sprintf("URL: %s", req.URL))
+	ui.Success(fmt.Sprintf("Issue: %s", req.Title))
+	ui.Info(fmt.Sprintf("State: %s", req.State))
+	ui.Info(fmt.Sprintf("URL: %s", req.URL))
 
 	if req.State == "closed" {
-		printWarn("Issue is already closed. Continuing anyway...")
+		ui.Warn("Issue is already closed. Continuing anyway...")
 	}
 
-	printSection("Package Detection")
+	ui.Section("Package Detection")
 
-	printSuccess(fmt.Sprintf("Repository URL: %s", req.RepoURL))
-	printSuccess(fmt.Sprintf("Package Name: %s", req.PackageName))
-	printSuccess(fmt.Sprintf("Package Type: %s", req.PackageType))
+	ui.Success(fmt.Sprintf("Repository URL: %s", req.RepoURL))
+	ui.Success(fmt.Sprintf("Package Name: %s", req.PackageName))
+	ui.Success(fmt.Sprintf("Package Type: %s", req.PackageType))
 
 	if req.Description != "" {
-		printInfo(fmt.Sprintf("Description: %s", req.Description))
+		ui.Info(fmt.Sprintf("Description: %s", req.Description))
 	}
 
 	// Dry run - show plan and exit
 	if dryRun {
-		printSection("Dry Run - Plan")
-		fmt.Println()
-		printHighlight("Would execute:")
-		fmt.Printf("  1. Create branch: package-request-%d-%s\n", issueNumber, req.PackageName)
+		ui.Section("Dry Run - Plan")
+		ui.Println()
+		ui.Highlight("Would execute:")
+		ui.Printf("  1. Create branch: package-request-%d-%s\n", issueNumber, req.PackageName)
 
 		var targetFile string
 		if req.PackageType == issues.PackageTypeCask {
 			targetFile = fmt.Sprintf("Casks/%s.rb", req.PackageName)
-			fmt.Printf("  2. Generate cask: %s\n", targetFile)
+			ui.Printf("  2. Generate cask: %s\n", targetFile)
 		} else {
 			targetFile = fmt.Sprintf("Formula/%s.rb", req.PackageName)
-			fmt.Printf("  2. Generate formula: %s\n", targetFile)
+			ui.Printf("  2. Generate formula: %s\n", targetFile)
 		}
 
-		fmt.Printf("  3. Commit: feat: add %s %s (closes #%d)\n", req.PackageName, req.PackageType, issueNumber)
-		fmt.Printf("  4. Push to origin\n")
+		ui.Printf("  3. Commit: feat: add %s %s (closes #%d)\n", req.PackageName, req.PackageType, issueNumber)
+		ui.Printf("  4. Push to origin\n")
 
 		if createPR {
-			fmt.Printf("  5. Create pull request\n")
-			fmt.Printf("  6. Comment on issue #%d\n", issueNumber)
+			ui.Printf("  5. Create pull request\n")
+			ui.Printf("  6. Comment on issue #%d\n", issueNumber)
 		}
 
 		return nil
 	}
 
 	// Create git branch
-	printSection("Creating Git Branch")
+	ui.Section("Creating Git Branch")
 
 	branchName := fmt.Sprintf("package-request-%d-%s", issueNumber, req.PackageName)
 	branchName = normalizeBranchName(branchName)
 
 	if branchExists(branchName) {
-		printWarn(fmt.Sprintf("Branch %s already exists", branchName))
-		printInfo("Checking out existing branch...")
+		ui.Warn(fmt.Sprintf("Branch %s already exists", branchName))
+		ui.Info("Checking out existing branch...")
 		if err := runCommand("git", "checkout", branchName); err != nil {
-			printError("Failed to checkout existing branch")
+			ui.Error("Failed to checkout existing branch")
 			return err
 		}
 	} else {
-		printInfo(fmt.Sprintf("Creating branch: %s", branchName))
+		ui.Info(fmt.Sprintf("Creating branch: %s", branchName))
 		if err := runCommand("git", "checkout", "-b", branchName); err != nil {
-			printError("Failed to create branch")
+			ui.Error("Failed to create branch")
 			return err
 		}
 	}
-	printSuccess(fmt.Sprintf("On branch: %s", branchName))
+	ui.Success(fmt.Sprintf("On branch: %s", branchName))
 
 	// Generate package
-	printSection("Generating Package")
+	ui.Section("Generating Package")
 
 	var targetFile string
 	if req.PackageType == issues.PackageTypeCask {
-		printInfo("Generating cask...")
+		ui.Info("Generating cask...")
 		targetFile = fmt.Sprintf("Casks/%s.rb", req.PackageName)
 
 		// Run tap-cask generate
-		caskCmd := exec.Command("./tap-cask", "generate", req.RepoURL)
+		caskCmd := exec.Command("./tap-cask", generateArgs(req.RepoURL)...)
 		caskCmd.Dir = filepath.Join(mustGetWorkingDir(), "tap-tools")
 		caskCmd.Stdout = os.Stdout
 		caskCmd.Stderr = os.Stderr
 
 		if err := caskCmd.Run(); err != nil {
-			printError(fmt.Sprintf("Failed to generate cask: %v", err))
+			ui.Error(fmt.Sprintf("Failed to generate cask: %v", err))
 			return err
 		}
 	} else {
-		printInfo("Generating formula...")
+		ui.Info("Generating formula...")
 		targetFile = fmt.Sprintf("Formula/%s.rb", req.PackageName)
 
 		// Run tap-formula generate
-		formulaCmd := exec.Command("./tap-formula", "generate", req.RepoURL)
+		formulaCmd := exec.Command("./tap-formula", generateArgs(req.RepoURL)...)
 		formulaCmd.Dir = filepath.Join(mustGetWorkingDir(), "tap-tools")
 		formulaCmd.Stdout = os.Stdout
 		formulaCmd.Stderr = os.Stderr
 
 		if err := formulaCmd.Run(); err != nil {
-			printError(fmt.Sprintf("Failed to generate formula: %v", err))
+			ui.Error(fmt.Sprintf("Failed to generate formula: %v", err))
 			return err
 		}
 	}
-	printSuccess("Package generated successfully")
+	ui.Success("Package generated successfully")
 
 	// Commit changes
-	printSection("Committing Changes")
+	ui.Section("Committing Changes")
 
-	printInfo(fmt.Sprintf("Staging %s...", targetFile))
+	ui.Info(fmt.Sprintf("Staging %s...", targetFile))
 	if err := runCommand("git", "add", targetFile); err != nil {
-		printError(fmt.Sprintf("Failed to stage %s", targetFile))
+		ui.Error(fmt.Sprintf("Failed to stage %s", targetFile))
 		return err
 	}
 
 	commitMsg := fmt.Sprintf("feat: add %s %s (closes #%d)\n\nAssisted-by: Claude 3.5 Sonnet via OpenCode",
 		req.PackageName, req.PackageType, issueNumber)
-	printInfo(fmt.Sprintf("Creating commit: feat: add %s %s (closes #%d)", req.PackageName, req.PackageType, issueNumber))
+	ui.Info(fmt.Sprintf("Creating commit: feat: add %s %s (closes #%d)", req.PackageName, req.PackageType, issueNumber))
 
 	if err := runCommand("git", "commit", "-m", commitMsg); err != nil {
-		printError("Failed to commit")
+		ui.Error("Failed to commit")
 		return err
 	}
-	printSuccess("Changes committed")
+	ui.Success("Changes committed")
 
 	// Push to remote
-	printSection("Pushing to Remote")
+	ui.Section("Pushing to Remote")
 
-	printInfo("Pushing branch to remote...")
+	ui.Info("Pushing branch to remote...")
 	if err := runCommand("git", "push", "-u", "origin", branchName); err != nil {
-		printError("Failed to push branch")
+		ui.Error("Failed to push branch")
 		return err
 	}
-	printSuccess(fmt.Sprintf("Branch pushed to origin/%s", branchName))
+	ui.Success(fmt.Sprintf("Branch pushed to origin/%s", branchName))
 
 	// Summary
-	printSection("Summary")
-	fmt.Println()
-	printHighlight("Package Details:")
-	fmt.Printf("  Name:        %s\n", req.PackageName)
-	fmt.Printf("  Type:        %s\n", req.PackageType)
-	fmt.Printf("  Repository:  %s\n", req.RepoURL)
-	fmt.Printf("  File:        %s\n", targetFile)
-	fmt.Println()
-	printHighlight("Git Details:")
-	fmt.Printf("  Branch:      %s\n", branchName)
-	fmt.Printf("  Commit:      feat: add %s %s (closes #%d)\n", req.PackageName, req.PackageType, issueNumber)
-	fmt.Println()
+	ui.Section("Summary")
+	ui.Println()
+	ui.Highlight("Package Details:")
+	ui.Printf("  Name:        %s\n", req.PackageName)
+	ui.Printf("  Type:        %s\n", req.PackageType)
+	ui.Printf("  Repository:  %s\n", req.RepoURL)
+	ui.Printf("  File:        %s\n", targetFile)
+	ui.Println()
+	ui.Highlight("Git Details:")
+	ui.Printf("  Branch:      %s\n", branchName)
+	ui.Printf("  Commit:      feat: add %s %s (closes #%d)\n", req.PackageName, req.PackageType, issueNumber)
+	ui.Println()
 
 	// Create PR if requested
 	if createPR {
-		printSection("Creating Pull Request")
+		ui.Section("Creating Pull Request")
 
 		prTitle := fmt.Sprintf("feat(%s): add %s", req.PackageType, req.PackageName)
 		prBody := fmt.Sprintf(`## Summary
@@ -300,54 +444,60 @@ This PR adds the `+"`%s`"+` %s to the tap.
 
 Closes #%d`, req.PackageName, req.PackageType, req.PackageName, req.PackageType, req.RepoURL, issueNumber, issueNumber)
 
-		printInfo("Creating pull request...")
+		ui.Info("Creating pull request...")
 		// Get default branch (typically "main")
 		prURL, err := client.CreatePullRequest(owner, repo, branchName, "main", prTitle, prBody)
 		if err != nil {
-			printError(fmt.Sprintf("Failed to create PR: %v", err))
+			ui.Error(fmt.Sprintf("Failed to create PR: %v", err))
 			return err
 		}
-		printSuccess(fmt.Sprintf("Pull request created: %s", prURL))
+		ui.Success(fmt.Sprintf("Pull request created: %s", prURL))
 
 		// Comment on issue
-		printInfo(fmt.Sprintf("Commenting on issue #%d...", issueNumber))
+		ui.Info(fmt.Sprintf("Commenting on issue #%d...", issueNumber))
 		commentBody := fmt.Sprintf("✅ Package %s has been generated and a pull request has been created: %s\n\nThe %s will be available once the PR is reviewed and merged.",
 			req.PackageType, prURL, req.PackageType)
 
 		if err := client.CommentOnIssue(owner, repo, issueNumber, commentBody); err != nil {
-			printWarn("Failed to comment on issue")
+			ui.Warn("Failed to comment on issue")
 		}
 
-		fmt.Println()
-		printHighlight("Next Steps:")
-		fmt.Printf("  1. Review the PR: %s\n", prURL)
-		fmt.Printf("  2. Test the %s locally\n", req.PackageType)
-		fmt.Printf("  3. Merge the PR to publish the package\n")
+		ui.Println()
+		ui.Highlight("Next Steps:")
+		ui.Printf("  1. Review the PR: %s\n", prURL)
+		ui.Printf("  2. Test the %s locally\n", req.PackageType)
+		ui.Printf("  3. Merge the PR to publish the package\n")
 	} else {
-		fmt.Println()
-		printHighlight("Next Steps:")
-		fmt.Printf("  1. Review the generated %s: %s\n", req.PackageType, targetFile)
+		ui.Println()
+		ui.Highlight("Next Steps:")
+		ui.Printf("  1. Review the generated %s: %s\n", req.PackageType, targetFile)
 
 		caskFlag := ""
 		if req.PackageType == issues.PackageTypeCask {
 			caskFlag = "--cask "
 		}
-		fmt.Printf("  2. Test locally: brew install %s%s\n", caskFlag, req.PackageName)
-		fmt.Printf("  3. Create a PR manually: gh pr create --fill\n")
-		fmt.Printf("  4. Or run with --create-pr flag: tap-issue process %d --create-pr\n", issueNumber)
+		ui.Printf("  2. Test locally: brew install %s%s\n", caskFlag, req.PackageName)
+		ui.Printf("  3. Create a PR manually: gh pr create --fill\n")
+		ui.Printf("  4. Or run with --create-pr flag: tap-issue process %d --create-pr\n", issueNumber)
 	}
 
-	fmt.Println()
-	fmt.Println(successStyle.Render("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
-	fmt.Println(successStyle.Render("✓ Automation complete!"))
-	fmt.Println(successStyle.Render("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
-	fmt.Println()
+	ui.Section("Done")
+	ui.Success("Automation complete!")
+	ui.Println()
 
 	return nil
 }
 
 // Helper functions
 
+func currentBranch() (string, error) {
+	output, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
 func isGitRepo() bool {
 	cmd := exec.Command("git", "rev-parse", "--git-dir")
 	return cmd.Run() == nil
@@ -418,3 +568,13 @@ func mustGetWorkingDir() string {
 	}
 	return wd
 }
+
+// generateArgs returns the arguments of a generate command for repoURL,
+// passing --cache on to it
+func generateArgs(repoURL string) []string {
+	args := []string{"generate", repoURL}
+	if cache {
+		args = append(args, "--cache")
+	}
+	return args
+}
@@ -8,9 +8,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/castrojo/tap-tools/internal/homebrew"
+	"github.com/castrojo/tap-tools/internal/validate"
 	"github.com/spf13/cobra"
 )
 
+var (
+	formulaFile    string
+	installFormula bool
+)
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "tap-test",
@@ -21,10 +28,27 @@ func main() {
 	testFormulaCmd := &cobra.Command{
 		Use:   "formula [name]",
 		Short: "Test that a formula works after installation",
-		Args:  cobra.ExactArgs(1),
-		RunE:  testFormula,
+		Long: `Test that a formula works after installation.
+
+Pass a formula name, or use --file to read the binary name from a formula
+file. With --install, the formula file is installed before testing.
+
+Examples:
+  tap-test formula ripgrep
+  tap-test formula --file Formula/ripgrep.rb
+  tap-test formula --file Formula/ripgrep.rb --install`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if formulaFile != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: testFormula,
 	}
 
+	testFormulaCmd.Flags().StringVar(&formulaFile, "file", "", "Formula file to test (reads binary name from the file)")
+	testFormulaCmd.Flags().BoolVar(&installFormula, "install", false, "Install the formula file before testing (requires --file)")
+
 	testCaskCmd := &cobra.Command{
 		Use:   "cask [name]",
 		Short: "Test that a cask works after installation",
@@ -41,16 +65,47 @@ func main() {
 }
 
 func testFormula(cmd *cobra.Command, args []string) error {
-	formulaName := args[0]
+	if installFormula && formulaFile == "" {
+		return fmt.Errorf("--install requires --file")
+	}
+
+	var formulaName, binaryName string
+	if formulaFile != "" {
+		content, err := os.ReadFile(formulaFile)
+		if err != nil {
+			return fmt.Errorf("failed to read formula file: %w", err)
+		}
+		formulaName = strings.TrimSuffix(filepath.Base(formulaFile), ".rb")
+		binaryName, err = homebrew.ExtractFormulaBinary(string(content))
+		if err != nil {
+			fmt.Printf("⚠ %v, using formula name\n", err)
+			binaryName = formulaName
+		}
+	} else {
+		formulaName = args[0]
+		binaryName = formulaName
+	}
 
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 	fmt.Printf("Testing formula: %s\n", formulaName)
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 
+	if binaryName != formulaName {
+		fmt.Printf("Binary: %s\n", binaryName)
+	}
+
+	if installFormula {
+		fmt.Printf("Installing %s...\n", formulaFile)
+		if err := validate.Install(formulaFile, false); err != nil {
+			return fmt.Errorf("failed to install formula: %w", err)
+		}
+		fmt.Println("✓ Formula installed")
+	}
+
 	// Check if binary exists in PATH
-	_, err := exec.LookPath(formulaName)
+	binaryPath, err := exec.LookPath(binaryName)
 	if err != nil {
-		fmt.Printf("❌ Binary '%s' not found in PATH\n", formulaName)
+		fmt.Printf("❌ Binary '%s' not found in PATH\n", binaryName)
 		fmt.Println("Searching for binary in Homebrew prefix...")
 
 		// Try to find in Homebrew prefix
@@ -60,20 +115,20 @@ func testFormula(cmd *cobra.Command, args []string) error {
 		}
 
 		possiblePaths := []string{
-			filepath.Join(homebrewPrefix, "bin", formulaName),
-			filepath.Join(homebrewPrefix, "opt", formulaName, "bin", formulaName),
+			filepath.Join(homebrewPrefix, "bin", binaryName),
+			filepath.Join(homebrewPrefix, "opt", formulaName, "bin", binaryName),
 		}
 
-		found := false
+		binaryPath = ""
 		for _, path := range possiblePaths {
 			if fileExists(path) && isExecutable(path) {
 				fmt.Printf("✓ Found binary at: %s\n", path)
-				found = true
+				binaryPath = path
 				break
 			}
 		}
 
-		if !found {
+		if binaryPath == "" {
 			return fmt.Errorf("binary not found in expected locations")
 		}
 	}
@@ -82,22 +137,19 @@ func testFormula(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 	fmt.Println("Testing binary execution...")
 
-	versionFlags := []string{"--version", "-v", "-V", "version", "--help", "-h"}
-	success := false
-
-	for _, flag := range versionFlags {
-		testCmd := exec.Command(formulaName, flag)
-		if err := testCmd.Run(); err == nil {
-			fmt.Printf("✓ Binary executes successfully (tested: %s %s)\n", formulaName, flag)
-			success = true
-			break
-		}
+	flag, err := validate.RunBinary(binaryPath, validate.RunTimeout)
+	if err != nil {
+		return fmt.Errorf("binary does not run: %w", err)
+	}
+	success := flag != ""
+	if success {
+		fmt.Printf("✓ Binary executes successfully (tested: %s %s)\n", binaryName, flag)
 	}
 
 	if !success {
 		// Try running without flags with timeout
 		fmt.Println("Trying execution without flags (5s timeout)...")
-		testCmd := exec.Command(formulaName)
+		testCmd := exec.Command(binaryPath)
 
 		done := make(chan error, 1)
 		go func() {
@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -12,7 +11,9 @@ import (
 )
 
 var (
-	fixStyle bool
+	fixStyle    bool
+	formatCheck bool
+	fixCheck    bool
 )
 
 func main() {
@@ -35,11 +36,22 @@ func main() {
 		RunE:  validateFileCmd,
 	}
 
+	fixAllCmd := &cobra.Command{
+		Use:   "fix-all",
+		Short: "Reformat all formulas and casks with brew style --fix",
+		RunE:  fixAll,
+	}
+
 	validateAllCmd.Flags().BoolVar(&fixStyle, "fix", false, "Automatically fix style issues")
 	validateFileCmd.Flags().BoolVar(&fixStyle, "fix", false, "Automatically fix style issues")
+	validateAllCmd.Flags().BoolVar(&formatCheck, "format-check", false, "Fail on casks with a literal version in url, binary or artifact paths")
+	validateFileCmd.Flags().BoolVar(&formatCheck, "format-check", false, "Fail on casks with a literal version in url, binary or artifact paths")
+
+	fixAllCmd.Flags().BoolVar(&fixCheck, "check", false, "Run brew style without --fix and fail on offenses (for CI)")
 
 	rootCmd.AddCommand(validateAllCmd)
 	rootCmd.AddCommand(validateFileCmd)
+	rootCmd.AddCommand(fixAllCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
@@ -47,7 +59,7 @@ func main() {
 }
 
 func validateAll(cmd *cobra.Command, args []string) error {
-	repoRoot, err := findRepoRoot()
+	repoRoot, err := validate.FindRepoRoot()
 	if err != nil {
 		return fmt.Errorf("failed to find repository root: %w", err)
 	}
@@ -115,12 +127,17 @@ func validateAll(cmd *cobra.Command, args []string) error {
 						}
 					}
 					failed++
+				} else if formatCheck && len(result.Warnings) > 0 {
+					fmt.Printf("  ✗ %s failed format check\n", name)
+					printWarnings("    ", result.Warnings)
+					failed++
 				} else {
 					if result.Fixed {
 						fmt.Printf("  ✓ %s passed (style issues auto-fixed)\n", name)
 					} else {
 						fmt.Printf("  ✓ %s passed\n", name)
 					}
+					printWarnings("    ", result.Warnings)
 				}
 			}
 		} else {
@@ -158,20 +175,78 @@ func validateFileCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if formatCheck && len(result.Warnings) > 0 {
+		fmt.Println("✗ Format check failed")
+		printWarnings("  ", result.Warnings)
+		return fmt.Errorf("%d literal version(s) found", len(result.Warnings))
+	}
+
 	if result.Fixed {
 		fmt.Println("✓ Validation passed (style issues auto-fixed)")
 	} else {
 		fmt.Println("✓ Validation passed")
 	}
+	printWarnings("  ", result.Warnings)
 
 	return nil
 }
 
-func findRepoRoot() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	output, err := cmd.Output()
+func fixAll(cmd *cobra.Command, args []string) error {
+	repoRoot, err := validate.FindRepoRoot()
 	if err != nil {
-		return "", err
+		return fmt.Errorf("failed to find repository root: %w", err)
+	}
+
+	files, err := validate.TapFiles(repoRoot)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		fmt.Println("→ No formulas or casks to format")
+		return nil
+	}
+
+	if fixCheck {
+		fmt.Printf("→ Running brew style on %d file(s)...\n", len(files))
+	} else {
+		fmt.Printf("→ Running brew style --fix on %d file(s)...\n", len(files))
+	}
+	result, styleErr := validate.FormatFiles(files, fixCheck)
+	if result == nil {
+		return styleErr
+	}
+
+	verb := "Reformatted"
+	if fixCheck {
+		verb = "Needs formatting:"
+	}
+	for _, file := range result.Changed {
+		rel, err := filepath.Rel(repoRoot, file)
+		if err != nil {
+			rel = file
+		}
+		fmt.Printf("  %s %s\n", verb, rel)
+	}
+
+	fmt.Println()
+
+	if styleErr != nil {
+		return styleErr
+	}
+	if len(result.Changed) == 0 {
+		fmt.Println("✓ All files are formatted")
+		return nil
+	}
+	if fixCheck {
+		return fmt.Errorf("✗ %d file(s) need formatting; run tap-validate fix-all", len(result.Changed))
+	}
+	fmt.Printf("✓ Reformatted %d file(s)\n", len(result.Changed))
+	return nil
+}
+
+// printWarnings prints non-fatal validation warnings with the given indent
+func printWarnings(indent string, warnings []string) {
+	for _, warning := range warnings {
+		fmt.Printf("%s⚠ %s\n", indent, warning)
 	}
-	return strings.TrimSpace(string(output)), nil
 }
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/castrojo/tap-tools/internal/archive"
+	"github.com/castrojo/tap-tools/internal/checksum"
+	"github.com/castrojo/tap-tools/internal/forge"
+	"github.com/castrojo/tap-tools/internal/generator"
+	"github.com/castrojo/tap-tools/internal/github"
+	"github.com/castrojo/tap-tools/internal/homebrew"
+	"github.com/castrojo/tap-tools/internal/metadata"
+	"github.com/castrojo/tap-tools/internal/platform"
+	"github.com/castrojo/tap-tools/internal/spdx"
+	"github.com/castrojo/tap-tools/internal/ui"
+	"github.com/castrojo/tap-tools/internal/validate"
+	"github.com/spf13/cobra"
+)
+
+var rootCmd = &cobra.Command{
+	Use:   "tap",
+	Short: "Generate Homebrew packages for Linux",
+	Long: `tap orchestrates the tap-tools generation pipelines.
+
+Use tap-formula or tap-cask when the package type is known. Use tap when
+a tool could reasonably be packaged either way and you want to compare.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// --stdout prints packages to stdout, so progress goes to stderr
+		ui.Configure(ui.Options{NoColor: flagNoColor, Quiet: flagQuiet, JSON: flagJSON, Stderr: flagStdout})
+	},
+}
+
+var generateCmd = &cobra.Command{
+	Use:   "generate [repo-url]",
+	Short: "Generate both a formula and a cask from a GitHub or GitLab repository",
+	Long: `Generate both a formula and a cask skeleton from the same release.
+
+The release is fetched and the asset downloaded once; both files share the
+same version, URL, and checksum. Keep the one that fits and delete the other.
+
+Examples:
+  tap generate https://github.com/jesseduffield/lazygit --both
+  tap generate jesseduffield/lazygit --both --stdout`,
+	Args: cobra.ExactArgs(1),
+	RunE: runGenerate,
+}
+
+var (
+	flagQuiet        bool
+	flagNoColor      bool
+	flagName         string
+	flagBoth         bool
+	flagStdout       bool
+	flagJSON         bool
+	flagMaxAssetSize string
+	flagForce        bool
+	flagAllowDup     bool
+	flagAssetURL     string
+	flagNoMagic      bool
+	flagMetadata     string
+	flagCACert       string
+	flagTimeout      time.Duration
+	flagSHA256       string
+	flagCaveats      []string
+	flagNotes        string
+	flagCache        bool
+	flagVerbose      bool
+)
+
+// newForge creates the client for a repository host; tests replace it
+var newForge = forge.New
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&flagNoColor, "no-color", false, "Disable colored output (also honors NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVarP(&flagQuiet, "quiet", "q", false, "Only print warnings and errors")
+
+	generateCmd.Flags().StringVar(&flagName, "name", "", "Override package name")
+	generateCmd.Flags().BoolVar(&flagBoth, "both", false, "Generate both a formula and a cask")
+	generateCmd.Flags().StringVar(&flagMaxAssetSize, "max-asset-size", platform.DefaultMaxAssetSize, "Skip assets larger than this size (e.g. 500M, 2G; 0 disables)")
+	generateCmd.Flags().BoolVar(&flagStdout, "stdout", false, "Print both packages to stdout instead of writing files")
+	generateCmd.Flags().BoolVar(&flagJSON, "json", false, "Print both packages as a JSON object on stdout instead of writing files")
+	generateCmd.Flags().StringVar(&flagCACert, "ca-cert", "", "PEM CA bundle to trust for API calls and downloads, e.g. a corporate CA (default: $TAP_CA_BUNDLE)")
+	generateCmd.Flags().DurationVar(&flagTimeout, "download-timeout", 10*time.Minute, "Give up on a download, including retries, after this long (0 disables)")
+	generateCmd.Flags().BoolVar(&flagCache, "cache", false, "Cache API responses on disk and revalidate them with ETags (also enabled by $TAP_CACHE_DIR)")
+	generateCmd.Flags().StringVar(&flagMetadata, "metadata", "metadata.yaml", "YAML file of per-package desc/homepage/license overrides (skipped if missing)")
+	generateCmd.Flags().StringArrayVar(&flagCaveats, "caveats", nil, "Custom post-install note for the caveats of both packages (repeatable)")
+	generateCmd.Flags().StringVar(&flagNotes, "notes", "", "File of maintainer notes to add as comments after the generated header")
+	generateCmd.Flags().BoolVar(&flagNoMagic, "no-magic-comments", false, "Omit the # typed: strict and # frozen_string_literal: true comments")
+	generateCmd.Flags().StringVar(&flagAssetURL, "asset-url", "", "Download this URL instead of auto-selecting a release asset")
+	generateCmd.Flags().StringVar(&flagSHA256, "sha256", "", "Known SHA256 of the --asset-url download; skips downloading it")
+	generateCmd.Flags().BoolVar(&flagForce, "force", false, "Overwrite existing files even if they were not generated by tap-tools")
+	generateCmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "Show why each asset was chosen")
+	generateCmd.Flags().BoolVar(&flagAllowDup, "allow-duplicate", false, "Generate even if a similarly named package (e.g. foo vs foo-linux) is already in the tap")
+
+	rootCmd.AddCommand(generateCmd)
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		ui.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+// generatedFile is a package printed by --json
+type generatedFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+func runGenerate(cmd *cobra.Command, args []string) error {
+	if !flagBoth {
+		return fmt.Errorf("use tap-formula or tap-cask for a single package type, or pass --both")
+	}
+
+	if flagJSON && flagStdout {
+		return fmt.Errorf("--json and --stdout cannot be combined")
+	}
+
+	repoURL := args[0]
+
+	maxAssetSize, err := platform.ParseSize(flagMaxAssetSize)
+	if err != nil {
+		return fmt.Errorf("invalid --max-asset-size: %w", err)
+	}
+
+	if err := checksum.ConfigureCABundle(flagCACert); err != nil {
+		return err
+	}
+	checksum.ConfigureDownloadTimeout(flagTimeout)
+	if err := github.ConfigureCache(flagCache); err != nil {
+		return fmt.Errorf("invalid --cache: %w", err)
+	}
+
+	var notes string
+	if flagNotes != "" {
+		content, err := os.ReadFile(flagNotes)
+		if err != nil {
+			return fmt.Errorf("invalid --notes: %w", err)
+		}
+		notes = string(content)
+	}
+
+	if flagSHA256 != "" {
+		if flagAssetURL == "" {
+			return fmt.Errorf("--sha256 requires --asset-url")
+		}
+		if _, err := checksum.ParseSHA256(flagSHA256); err != nil {
+			return fmt.Errorf("invalid --sha256: %w", err)
+		}
+	}
+
+	// Parse repository URL
+	ui.Title("🔍 Parsing repository URL...")
+	host, owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		return fmt.Errorf("invalid repository URL: %w", err)
+	}
+	ui.Success(fmt.Sprintf("Repository: %s/%s", owner, repo))
+
+	packageName := flagName
+	if packageName == "" {
+		packageName = platform.NormalizePackageName(repo)
+	}
+
+	client, err := newForge(host)
+	if err != nil {
+		return err
+	}
+
+	// Fetch repository metadata
+	ui.Title("\n🔍 Fetching repository metadata...")
+	repository, err := client.GetRepository(owner, repo)
+	if err != nil {
+		return fmt.Errorf("failed to fetch repository: %w", err)
+	}
+	ui.Success(fmt.Sprintf("Found: %s", repository.Description))
+
+	// Curated fields in the tap's metadata file win over the API
+	overrides, err := metadata.Load(flagMetadata)
+	if err != nil {
+		return err
+	}
+	if applied := overrides.Apply(packageName, &repository.Description, &repository.Homepage, &repository.License); len(applied) > 0 {
+		ui.Info(fmt.Sprintf("Metadata overrides from %s: %s", flagMetadata, strings.Join(applied, ", ")))
+	}
+
+	// Fall back to the license file when the forge could not classify it
+	if repository.License == "" || repository.License == "NOASSERTION" {
+		if guessed, path := spdx.GuessRepo(client, owner, repo, spdx.LicenseFiles...); guessed != "" {
+			ui.Warn(fmt.Sprintf("Guessed license %s from %s (%s could not classify it, please verify)", guessed, path, host))
+			repository.License = guessed
+		}
+	}
+
+	// Get latest release
+	ui.Title("\n🔍 Finding latest release...")
+	release, err := client.GetLatestRelease(owner, repo)
+	if err != nil {
+		return fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	version := release.TagName
+	if len(version) > 0 && version[0] == 'v' {
+		version = version[1:] // Remove 'v' prefix
+	}
+	ui.Success(fmt.Sprintf("Version: %s", version))
+
+	// Select asset, recording why it was chosen
+	ui.Title("\n🔍 Analyzing release assets...")
+	assets := release.PlatformAssets()
+	decision := platform.NewDecision(owner+"/"+repo, release.TagName)
+	decision.AddCandidates(assets)
+	selection, err := platform.SelectAsset(assets, platform.AssetChoice{
+		URL:       flagAssetURL,
+		MaxSize:   maxAssetSize,
+		SplitHint: "add them with tap-formula --merge-assets",
+	}, decision)
+	if errors.Is(err, platform.ErrNoLinuxAssets) {
+		return fmt.Errorf("%w (use tap-formula --from-source)", err)
+	} else if err != nil {
+		return err
+	}
+	for _, asset := range selection.Skipped {
+		ui.Warn(fmt.Sprintf("Skipping %s (%.2f MB exceeds --max-asset-size)", asset.Name, float64(asset.Size)/1024/1024))
+	}
+	if len(selection.Candidates) > 0 {
+		ui.Success(fmt.Sprintf("Found %d Linux asset(s)", len(selection.Candidates)))
+	}
+	if flagVerbose {
+		for i, asset := range selection.Candidates {
+			ui.Printf("   [%d] %s\n", i, asset.Name)
+		}
+	}
+	bestAsset := selection.Asset
+	ui.Success(fmt.Sprintf("Selected: %s (%s)", bestAsset.Name, selection.Reason))
+	if selection.Warning != "" {
+		ui.Warn(selection.Warning)
+	}
+	if flagVerbose {
+		decision.WriteText(ui.Writer())
+	}
+
+	// Download and calculate checksum once for both packages
+	ui.Title("\n⬇️  Downloading asset...")
+	var sha256sum, assetPath string
+	if flagSHA256 != "" {
+		if sha256sum, err = checksum.ParseSHA256(flagSHA256); err != nil {
+			return fmt.Errorf("failed to download asset: %w", err)
+		}
+		ui.Info("Skipped download, using --sha256 (archive contents will not be inspected)")
+	} else {
+		bar := ui.NewProgress(bestAsset.Name)
+		sum, size, tmpPath, err := checksum.DownloadAndHashWithProgress(bestAsset.DownloadURL, bar.Update)
+		bar.Done()
+		if err != nil {
+			return fmt.Errorf("failed to download asset: %w", err)
+		}
+		defer os.Remove(tmpPath)
+		sha256sum, assetPath = sum, tmpPath
+		ui.Success(fmt.Sprintf("Downloaded %.2f MB", float64(size)/1024/1024))
+	}
+	ui.Success(fmt.Sprintf("SHA256: %s", sha256sum))
+
+	// Verify against the checksums the project publishes, as tap-cask and
+	// tap-formula do
+	ui.Title("\n🔍 Searching for upstream checksums...")
+	message, found, warn, err := checksum.CheckUpstream(bestAsset.DownloadURL, release.Body, bestAsset.Name, assetPath, sha256sum)
+	switch {
+	case err != nil:
+		return err
+	case warn:
+		ui.Warn(message)
+	case found:
+		ui.Success(message)
+	default:
+		ui.Info(message)
+	}
+
+	// Detect binary in archive
+	info := &homebrew.PackageInfo{
+		Name:        packageName,
+		AppName:     repo,
+		Version:     version,
+		SHA256:      sha256sum,
+		URL:         bestAsset.DownloadURL,
+		Description: repository.Description,
+		Homepage:    repository.Homepage,
+		License:     repository.License,
+		BinaryName:  packageName,
+		SourceURL:   github.RepoURL(host, owner, repo),
+		Caveats:     flagCaveats,
+		Notes:       notes,
+
+		NoMagicComments: flagNoMagic,
+	}
+
+	if assetPath != "" {
+		if entries, err := archive.ListEntriesFromFile(assetPath, bestAsset.Name); err == nil {
+			if binaries := archive.DetectBinariesFromEntries(entries); len(binaries) > 0 {
+				info.BinaryPath = archive.SelectBestBinary(binaries, packageName)
+				info.BinaryName = filepath.Base(info.BinaryPath)
+				info.ArchiveRoot = archive.FindRootDirectory(archive.Paths(entries))
+				ui.Info(fmt.Sprintf("Binary: %s", info.BinaryPath))
+			}
+		}
+	}
+
+	// Generate both packages
+	ui.Title("\n📝 Generating formula and cask...")
+	formula, cask, err := homebrew.GenerateBoth(info)
+	if err != nil {
+		return err
+	}
+
+	formulaPath := filepath.Join("Formula", packageName+".rb")
+	caskPath := filepath.Join("Casks", platform.EnsureLinuxSuffix(packageName)+".rb")
+
+	if flagJSON {
+		data, err := json.MarshalIndent(map[string]generatedFile{
+			"formula": {Path: formulaPath, Content: formula},
+			"cask":    {Path: caskPath, Content: cask},
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode packages: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	if flagStdout {
+		fmt.Println("# ━━━ Formula/" + packageName + ".rb ━━━")
+		fmt.Println(formula)
+		fmt.Println("# ━━━ Casks/" + platform.EnsureLinuxSuffix(packageName) + ".rb ━━━")
+		fmt.Println(cask)
+		return nil
+	}
+
+	outputs := []struct {
+		path    string
+		content string
+		isCask  bool
+	}{
+		{formulaPath, formula, false},
+		{caskPath, cask, true},
+	}
+
+	// Catch near-duplicates elsewhere in the tap, like foo vs foo-linux
+	duplicates, err := homebrew.CheckDuplicates(".", packageName, flagAllowDup, outputs[0].path, outputs[1].path)
+	if err != nil {
+		return err
+	}
+	for _, existing := range duplicates {
+		ui.Warn(fmt.Sprintf("Similar package already exists: %s", existing))
+	}
+
+	// Check both targets before writing either, so a refusal leaves no partial output
+	for _, o := range outputs {
+		if err := generator.CheckOverwrite(o.path, flagForce); err != nil {
+			return err
+		}
+	}
+
+	for _, o := range outputs {
+		if err := os.MkdirAll(filepath.Dir(o.path), 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		if err := os.WriteFile(o.path, []byte(o.content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", o.path, err)
+		}
+		ui.Success(fmt.Sprintf("Created: %s", o.path))
+
+		result, err := validate.ValidateFile(o.path, o.isCask, true)
+		if err != nil {
+			return fmt.Errorf("generated %s failed validation", o.path)
+		}
+		for _, warning := range result.Warnings {
+			ui.Warn(warning)
+		}
+	}
+
+	// Print next steps
+	ui.Title("\n✅ Done! Next steps:")
+	ui.Printf("   1. Review both files and keep the one that fits\n")
+	ui.Printf("   2. Delete the other\n")
+	ui.Printf("   3. Commit and push\n")
+
+	return nil
+}
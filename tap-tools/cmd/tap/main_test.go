@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/castrojo/tap-tools/internal/forge/forgetest"
+	"github.com/castrojo/tap-tools/internal/platform"
+)
+
+func TestGenerateReleaseAssets(t *testing.T) {
+	tests := []struct {
+		name    string
+		assets  []string
+		wantErr error
+		wantMsg string
+	}{
+		{"Source-only release", nil, platform.ErrNoAssets, "consider a formula"},
+		{"Other platforms only", []string{"tool-windows-amd64.zip", "tool-darwin-arm64.dmg"}, platform.ErrNoLinuxAssets, "use tap-formula --from-source"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &forgetest.Forge{Release: forgetest.NewRelease("v1.0.0", tt.assets...)}
+			err := forgetest.Run(t, rootCmd, &newForge, f, "generate", "owner/tool", "--both")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("generate error = %v, want %v", err, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("generate error = %q, want it to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -2,14 +2,17 @@ package archive
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"bytes"
 	"compress/bzip2"
 	"compress/gzip"
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/ulikunitz/xz"
 )
 
@@ -20,33 +23,58 @@ type FileEntry struct {
 	Mode int64
 }
 
-// ListFiles lists all files in a tar archive (supports .tar.gz, .tar.xz, .tar.bz2)
+// ListFiles lists all files in a tar archive (supports .tar.gz, .tar.xz, .tar.bz2, .tar.zst)
+// or a .zip
 // Returns list of file paths found in the archive
 func ListFiles(data []byte, filename string) ([]string, error) {
-	// Decompress based on extension
-	var reader io.Reader = bytes.NewReader(data)
-	var err error
+	entries, err := ListEntries(data, filename)
+	if err != nil {
+		return nil, err
+	}
+	return Paths(entries), nil
+}
 
-	if strings.HasSuffix(filename, ".tar.gz") || strings.HasSuffix(filename, ".tgz") {
-		reader, err = gzip.NewReader(reader)
+// ListEntries lists all regular files in a tar archive with their size and
+// permission bits
+func ListEntries(data []byte, filename string) ([]FileEntry, error) {
+	return listEntries(bytes.NewReader(data), filename)
+}
+
+// ListEntriesFromFile is ListEntries for an archive on disk, such as one
+// saved by checksum.DownloadAndHash, streaming it instead of loading it whole.
+// filename is the archive's original name, used to pick the decompressor.
+func ListEntriesFromFile(archivePath, filename string) ([]FileEntry, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	return listEntries(file, filename)
+}
+
+// listEntries lists the regular files in the tar stream or zip r
+func listEntries(r io.Reader, filename string) ([]FileEntry, error) {
+	if isZip(filename) {
+		zipReader, err := openZip(r)
 		if err != nil {
-			return nil, fmt.Errorf("failed to decompress gzip: %w", err)
+			return nil, err
 		}
-		defer reader.(io.Closer).Close()
-	} else if strings.HasSuffix(filename, ".tar.xz") {
-		reader, err = xz.NewReader(reader)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decompress xz: %w", err)
+		var entries []FileEntry
+		for _, f := range zipReader.File {
+			if f.Mode().IsRegular() {
+				entries = append(entries, FileEntry{Path: f.Name, Size: int64(f.UncompressedSize64), Mode: int64(f.Mode().Perm())})
+			}
 		}
-	} else if strings.HasSuffix(filename, ".tar.bz2") {
-		reader = bzip2.NewReader(reader)
-	} else if !strings.HasSuffix(filename, ".tar") {
-		return nil, fmt.Errorf("unsupported archive format: %s", filename)
+		return entries, nil
 	}
 
-	// Read tar entries
-	tarReader := tar.NewReader(reader)
-	var files []string
+	tarReader, err := openTar(r, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []FileEntry
 
 	for {
 		header, err := tarReader.Next()
@@ -59,121 +87,303 @@ func ListFiles(data []byte, filename string) ([]string, error) {
 
 		// Only include regular files (not directories)
 		if header.Typeflag == tar.TypeReg {
-			files = append(files, header.Name)
+			entries = append(entries, FileEntry{Path: header.Name, Size: header.Size, Mode: header.Mode})
 		}
 	}
 
-	return files, nil
+	return entries, nil
 }
 
-// DetectBinaries finds executable files in the archive
-// Returns paths to potential binary executables
-// The list is sorted with most likely binaries first
-func DetectBinaries(files []string) []string {
-	var binaries []string
+// Paths returns the paths of entries in archive order
+func Paths(entries []FileEntry) []string {
+	paths := make([]string, len(entries))
+	for i, e := range entries {
+		paths[i] = e.Path
+	}
+	return paths
+}
+
+// ReadFileHeader returns up to the first n bytes of a file inside a tar archive
+// Used to inspect binaries (e.g. ELF headers) without extracting them fully
+func ReadFileHeader(data []byte, filename, path string, n int) ([]byte, error) {
+	entry, err := findEntry(bytes.NewReader(data), filename, path)
+	if err != nil {
+		return nil, err
+	}
+	defer entry.Close()
 
-	// Common binary locations
-	binPaths := []string{"bin/", "usr/bin/", "usr/local/bin/"}
+	buf := make([]byte, n)
+	read, err := io.ReadFull(entry, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return buf[:read], nil
+}
+
+// ReadFile returns the full contents of a file inside a tar archive
+func ReadFile(data []byte, filename, path string) ([]byte, error) {
+	return readFile(bytes.NewReader(data), filename, path)
+}
 
-	// Common non-binary file patterns to exclude
-	excludePatterns := []string{
-		"LICENSE", "README", "CHANGELOG", "COPYING", "AUTHORS",
-		"NOTICE", "PATENTS", "VERSION", "MANIFEST", "TODO",
+// ReadFileFromFile is ReadFile for an archive on disk
+func ReadFileFromFile(archivePath, filename, path string) ([]byte, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
 	}
+	defer file.Close()
 
-	// Patterns for support files (not main binaries)
-	supportPatterns := []string{
-		"autocomplete/", "completions/", "bash_completion/",
-		"zsh/", "fish/", "man/", "doc/", "docs/",
+	return readFile(file, filename, path)
+}
+
+// readFile returns the contents of path in the tar stream or zip r
+func readFile(r io.Reader, filename, path string) ([]byte, error) {
+	entry, err := findEntry(r, filename, path)
+	if err != nil {
+		return nil, err
 	}
+	defer entry.Close()
 
-	for _, file := range files {
-		base := filepath.Base(file)
-		baseUpper := strings.ToUpper(base)
-
-		// Exclude documentation files
-		isDoc := false
-		for _, pattern := range excludePatterns {
-			if strings.HasPrefix(baseUpper, pattern) {
-				isDoc = true
-				break
+	content, err := io.ReadAll(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return content, nil
+}
+
+// findEntry returns a reader for the regular file named path
+func findEntry(r io.Reader, filename, path string) (io.ReadCloser, error) {
+	if isZip(filename) {
+		zipReader, err := openZip(r)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zipReader.File {
+			if f.Mode().IsRegular() && f.Name == path {
+				entry, err := f.Open()
+				if err != nil {
+					return nil, fmt.Errorf("failed to open %s: %w", path, err)
+				}
+				return entry, nil
 			}
 		}
-		if isDoc {
-			continue
+		return nil, fmt.Errorf("file not found in archive: %s", path)
+	}
+
+	tarReader, err := openTar(r, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("file not found in archive: %s", path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar entry: %w", err)
 		}
 
-		// Exclude support files (completions, man pages, etc.)
-		isSupport := false
-		for _, pattern := range supportPatterns {
-			if strings.Contains(strings.ToLower(file), pattern) {
-				isSupport = true
-				break
-			}
+		if header.Typeflag == tar.TypeReg && header.Name == path {
+			return io.NopCloser(tarReader), nil
+		}
+	}
+}
+
+// isZip reports whether filename is a zip archive
+func isZip(filename string) bool {
+	return strings.HasSuffix(strings.ToLower(filename), ".zip")
+}
+
+// openZip returns a zip reader for r. The central directory sits at the end
+// of a zip, so it needs random access: files and byte slices are read in
+// place, anything else is buffered in memory.
+func openZip(r io.Reader) (*zip.Reader, error) {
+	var readerAt io.ReaderAt
+	var size int64
+	switch src := r.(type) {
+	case *bytes.Reader:
+		readerAt, size = src, src.Size()
+	case *os.File:
+		info, err := src.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open zip: %w", err)
+		}
+		readerAt, size = src, info.Size()
+	default:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read zip: %w", err)
+		}
+		readerAt, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	zipReader, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip: %w", err)
+	}
+	return zipReader, nil
+}
+
+// openTar returns a tar reader for the archive, decompressing based on extension
+func openTar(reader io.Reader, filename string) (*tar.Reader, error) {
+	var err error
+
+	if strings.HasSuffix(filename, ".tar.gz") || strings.HasSuffix(filename, ".tgz") {
+		reader, err = gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress gzip: %w", err)
 		}
-		if isSupport {
+	} else if strings.HasSuffix(filename, ".tar.xz") {
+		reader, err = xz.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress xz: %w", err)
+		}
+	} else if strings.HasSuffix(filename, ".tar.bz2") {
+		reader = bzip2.NewReader(reader)
+	} else if strings.HasSuffix(filename, ".tar.zst") || strings.HasSuffix(filename, ".tzst") {
+		// A single-block decoder streams synchronously, so it starts no
+		// goroutines that would outlive the tar reader handed back
+		dec, err := zstd.NewReader(reader, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress zstd: %w", err)
+		}
+		reader = dec
+	} else if !strings.HasSuffix(filename, ".tar") {
+		return nil, fmt.Errorf("unsupported archive format: %s", filename)
+	}
+
+	return tar.NewReader(reader), nil
+}
+
+// Common non-binary file patterns to exclude
+var excludePatterns = []string{
+	"LICENSE", "README", "CHANGELOG", "COPYING", "AUTHORS",
+	"NOTICE", "PATENTS", "VERSION", "MANIFEST", "TODO",
+}
+
+// Patterns for support files (not main binaries)
+var supportPatterns = []string{
+	"autocomplete/", "completions/", "bash_completion/",
+	"zsh/", "fish/", "man/", "doc/", "docs/",
+}
+
+// isDocOrSupport reports whether a file is documentation or a support file
+// such as a completion script or man page
+func isDocOrSupport(file string) bool {
+	baseUpper := strings.ToUpper(filepath.Base(file))
+	for _, pattern := range excludePatterns {
+		if strings.HasPrefix(baseUpper, pattern) {
+			return true
+		}
+	}
+
+	lower := strings.ToLower(file)
+	for _, pattern := range supportPatterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTextExt reports whether an extension belongs to a text document
+func isTextExt(ext string) bool {
+	switch ext {
+	case ".txt", ".md", ".rst", ".pdf", ".html", ".xml", ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// isConfigExt reports whether an extension belongs to a config file
+func isConfigExt(ext string) bool {
+	return ext == ".conf" || ext == ".cfg" || ext == ".ini"
+}
+
+// isShellScript reports whether a file is a shell script by extension
+func isShellScript(file string) bool {
+	return strings.HasSuffix(file, ".sh") || strings.HasSuffix(file, ".bash")
+}
+
+// inBinDir reports whether a file is in a conventional bin directory
+func inBinDir(file string) bool {
+	for _, binPath := range []string{"bin/", "usr/bin/", "usr/local/bin/"} {
+		if strings.Contains(file, binPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectBinariesFromEntries finds executables using the archive's permission
+// bits, which catches binaries outside bin/ and skips data files inside it.
+// Falls back to DetectBinaries when the archive carries no mode information
+// or no executable looks like a binary.
+func DetectBinariesFromEntries(entries []FileEntry) []string {
+	var inBin, elsewhere []string
+	hasModes := false
+
+	for _, e := range entries {
+		if e.Mode != 0 {
+			hasModes = true
+		}
+		if e.Mode&0111 == 0 || isDocOrSupport(e.Path) || isShellScript(e.Path) {
+			continue
+		}
+		ext := strings.ToLower(filepath.Ext(e.Path))
+		if isTextExt(ext) || isConfigExt(ext) {
 			continue
 		}
 
-		// Exclude text files
-		ext := strings.ToLower(filepath.Ext(file))
-		if ext == ".txt" || ext == ".md" || ext == ".rst" || ext == ".pdf" ||
-			ext == ".html" || ext == ".xml" || ext == ".json" || ext == ".yaml" || ext == ".yml" {
+		// Keep binaries in conventional locations first
+		if inBinDir(e.Path) {
+			inBin = append(inBin, e.Path)
+		} else {
+			elsewhere = append(elsewhere, e.Path)
+		}
+	}
+
+	if !hasModes || len(inBin)+len(elsewhere) == 0 {
+		return DetectBinaries(Paths(entries))
+	}
+	return append(inBin, elsewhere...)
+}
+
+// DetectBinaries finds executable files in the archive
+// Returns paths to potential binary executables
+// The list is sorted with most likely binaries first
+func DetectBinaries(files []string) []string {
+	var binaries []string
+
+	for _, file := range files {
+		// Exclude documentation and support files (completions, man pages, etc.)
+		if isDocOrSupport(file) {
 			continue
 		}
 
-		// Check if in a bin directory
-		inBinDir := false
-		for _, binPath := range binPaths {
-			if strings.Contains(file, binPath) {
-				inBinDir = true
-				break
-			}
+		// Exclude text files
+		if isTextExt(strings.ToLower(filepath.Ext(file))) {
+			continue
 		}
 
-		if inBinDir {
-			// Exclude shell scripts (unless they're the only thing there)
-			if !strings.HasSuffix(base, ".sh") && !strings.HasSuffix(base, ".bash") {
-				binaries = append(binaries, file)
-			}
+		// Exclude shell scripts (unless they're the only thing there)
+		if inBinDir(file) && !isShellScript(filepath.Base(file)) {
+			binaries = append(binaries, file)
 		}
 	}
 
 	// If no binaries found in standard locations, look for executables anywhere
 	if len(binaries) == 0 {
 		for _, file := range files {
-			base := filepath.Base(file)
-			baseUpper := strings.ToUpper(base)
-
-			// Exclude documentation files
-			isDoc := false
-			for _, pattern := range excludePatterns {
-				if strings.HasPrefix(baseUpper, pattern) {
-					isDoc = true
-					break
-				}
-			}
-			if isDoc {
-				continue
-			}
-
-			// Exclude support files
-			isSupport := false
-			for _, pattern := range supportPatterns {
-				if strings.Contains(strings.ToLower(file), pattern) {
-					isSupport = true
-					break
-				}
-			}
-			if isSupport {
+			// Exclude documentation and support files
+			if isDocOrSupport(file) {
 				continue
 			}
 
 			// Exclude text and data files
 			ext := strings.ToLower(filepath.Ext(file))
-			if ext == ".txt" || ext == ".md" || ext == ".rst" || ext == ".pdf" ||
-				ext == ".html" || ext == ".xml" || ext == ".json" || ext == ".yaml" || ext == ".yml" ||
-				ext == ".conf" || ext == ".cfg" || ext == ".ini" {
+			if isTextExt(ext) || isConfigExt(ext) {
 				continue
 			}
 
@@ -221,6 +431,34 @@ func SelectBestBinary(binaries []string, packageName string) string {
 	return binaries[0]
 }
 
+// bundledRuntimeSiblings is how many files next to a binary suggest it
+// ships with a bundled runtime (Electron, JRE, Python, ...)
+const bundledRuntimeSiblings = 5
+
+// IsBundledRuntime reports whether a binary sits among the files it needs
+// at runtime, so the whole tree should be installed rather than just the binary
+// Binaries in a bin/ directory are a conventional layout and never count
+func IsBundledRuntime(files []string, binaryPath string) bool {
+	dir := filepath.Dir(binaryPath)
+	if filepath.Base(dir) == "bin" {
+		return false
+	}
+
+	siblings := 0
+	for _, file := range files {
+		if file == binaryPath || filepath.Dir(file) != dir {
+			continue
+		}
+		// Shared libraries next to the binary are a sure sign
+		if strings.HasSuffix(file, ".so") || strings.Contains(filepath.Base(file), ".so.") {
+			return true
+		}
+		siblings++
+	}
+
+	return siblings >= bundledRuntimeSiblings
+}
+
 // FindRootDirectory finds the common root directory in archive
 // Many tarballs wrap everything in app-version/ directory
 func FindRootDirectory(files []string) string {
@@ -246,3 +484,18 @@ func FindRootDirectory(files []string) string {
 
 	return candidate
 }
+
+// FindAppImage returns the shallowest .AppImage in an archive file list, for
+// releases that wrap an AppImage in a zip, or "" if there is none
+func FindAppImage(files []string) string {
+	best := ""
+	for _, file := range files {
+		if !strings.HasSuffix(strings.ToLower(file), ".appimage") {
+			continue
+		}
+		if best == "" || strings.Count(file, "/") < strings.Count(best, "/") {
+			best = file
+		}
+	}
+	return best
+}
@@ -0,0 +1,351 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestIsBundledRuntime(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      []string
+		binaryPath string
+		want       bool
+	}{
+		{
+			name: "Electron bundle",
+			files: []string{
+				"app-1.0.0/app", "app-1.0.0/chrome-sandbox", "app-1.0.0/libffmpeg.so",
+				"app-1.0.0/resources.pak", "app-1.0.0/resources/app.asar",
+			},
+			binaryPath: "app-1.0.0/app",
+			want:       true,
+		},
+		{
+			name: "Many runtime files",
+			files: []string{
+				"tool/tool", "tool/icudtl.dat", "tool/snapshot_blob.bin", "tool/v8_context_snapshot.bin",
+				"tool/chrome_100_percent.pak", "tool/chrome_200_percent.pak",
+			},
+			binaryPath: "tool/tool",
+			want:       true,
+		},
+		{
+			name:       "Single binary with docs",
+			files:      []string{"tool/tool", "tool/README.md", "tool/LICENSE"},
+			binaryPath: "tool/tool",
+			want:       false,
+		},
+		{
+			name:       "Conventional bin directory",
+			files:      []string{"tool/bin/tool", "tool/bin/a", "tool/bin/b", "tool/bin/c", "tool/bin/d", "tool/bin/e"},
+			binaryPath: "tool/bin/tool",
+			want:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBundledRuntime(tt.files, tt.binaryPath); got != tt.want {
+				t.Errorf("IsBundledRuntime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// tarEntry describes one file for writeTestTar
+type tarEntry struct {
+	name string
+	mode int64
+}
+
+// writeTestTar builds a gzipped tarball with the given files and modes
+func writeTestTar(t *testing.T, entries []tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	for _, e := range entries {
+		data := []byte("content of " + e.name)
+		if err := tw.WriteHeader(&tar.Header{Name: e.name, Mode: e.mode, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatalf("Failed to write tar entry: %v", err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestListEntries(t *testing.T) {
+	data := writeTestTar(t, []tarEntry{
+		{"tool-1.0.0/tool", 0755},
+		{"tool-1.0.0/README.md", 0644},
+	})
+
+	entries, err := ListEntries(data, "tool-1.0.0-linux-amd64.tar.gz")
+	if err != nil {
+		t.Fatalf("ListEntries() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("ListEntries() returned %d entries, want 2", len(entries))
+	}
+	if entries[0].Path != "tool-1.0.0/tool" || entries[0].Mode != 0755 {
+		t.Errorf("entries[0] = %+v, want tool-1.0.0/tool with mode 0755", entries[0])
+	}
+	if entries[1].Mode != 0644 || entries[1].Size != int64(len("content of tool-1.0.0/README.md")) {
+		t.Errorf("entries[1] = %+v, want mode 0644 and the file size", entries[1])
+	}
+
+	files, err := ListFiles(data, "tool-1.0.0-linux-amd64.tar.gz")
+	if err != nil {
+		t.Fatalf("ListFiles() error = %v", err)
+	}
+	if !reflect.DeepEqual(files, Paths(entries)) {
+		t.Errorf("ListFiles() = %v, want %v", files, Paths(entries))
+	}
+}
+
+func TestListEntriesFromFile(t *testing.T) {
+	data := writeTestTar(t, []tarEntry{
+		{"tool-1.0.0/tool", 0755},
+		{"tool-1.0.0/README.md", 0644},
+	})
+	// The temp file's own name says nothing about the format
+	archivePath := filepath.Join(t.TempDir(), "download")
+	if err := os.WriteFile(archivePath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ListEntriesFromFile(archivePath, "tool-1.0.0-linux-amd64.tar.gz")
+	if err != nil {
+		t.Fatalf("ListEntriesFromFile() error = %v", err)
+	}
+	want, _ := ListEntries(data, "tool-1.0.0-linux-amd64.tar.gz")
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("ListEntriesFromFile() = %+v, want %+v", entries, want)
+	}
+
+	content, err := ReadFileFromFile(archivePath, "tool-1.0.0-linux-amd64.tar.gz", "tool-1.0.0/tool")
+	if err != nil {
+		t.Fatalf("ReadFileFromFile() error = %v", err)
+	}
+	if string(content) != "content of tool-1.0.0/tool" {
+		t.Errorf("ReadFileFromFile() = %q", content)
+	}
+
+	if _, err := ListEntriesFromFile(filepath.Join(t.TempDir(), "missing"), "tool.tar.gz"); err == nil {
+		t.Error("ListEntriesFromFile() expected error for a missing file")
+	}
+}
+
+// writeTestZip builds a zip with the given files and modes
+func writeTestZip(t *testing.T, entries []tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		header.SetMode(os.FileMode(e.mode))
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatalf("Failed to write zip header: %v", err)
+		}
+		if _, err := w.Write([]byte("content of " + e.name)); err != nil {
+			t.Fatalf("Failed to write zip entry: %v", err)
+		}
+	}
+	if _, err := zw.Create("App/"); err != nil {
+		t.Fatalf("Failed to write zip directory: %v", err)
+	}
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestListEntriesZip(t *testing.T) {
+	data := writeTestZip(t, []tarEntry{
+		{"App/App-1.0.0-x86_64.AppImage", 0755},
+		{"App/README.md", 0644},
+	})
+	want := []FileEntry{
+		{Path: "App/App-1.0.0-x86_64.AppImage", Size: int64(len("content of App/App-1.0.0-x86_64.AppImage")), Mode: 0755},
+		{Path: "App/README.md", Size: int64(len("content of App/README.md")), Mode: 0644},
+	}
+
+	entries, err := ListEntries(data, "App-1.0.0-linux.ZIP")
+	if err != nil {
+		t.Fatalf("ListEntries() error = %v", err)
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("ListEntries() = %+v, want %+v", entries, want)
+	}
+
+	content, err := ReadFile(data, "App-1.0.0-linux.zip", "App/README.md")
+	if err != nil || string(content) != "content of App/README.md" {
+		t.Errorf("ReadFile() = %q, %v", content, err)
+	}
+	header, err := ReadFileHeader(data, "App-1.0.0-linux.zip", "App/README.md", 7)
+	if err != nil || string(header) != "content" {
+		t.Errorf("ReadFileHeader() = %q, %v", header, err)
+	}
+	if _, err := ReadFile(data, "App-1.0.0-linux.zip", "App/missing"); err == nil {
+		t.Error("ReadFile() expected error for a missing file")
+	}
+
+	archivePath := filepath.Join(t.TempDir(), "download")
+	if err := os.WriteFile(archivePath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = ListEntriesFromFile(archivePath, "App-1.0.0-linux.zip")
+	if err != nil {
+		t.Fatalf("ListEntriesFromFile() error = %v", err)
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("ListEntriesFromFile() = %+v, want %+v", entries, want)
+	}
+	content, err = ReadFileFromFile(archivePath, "App-1.0.0-linux.zip", "App/App-1.0.0-x86_64.AppImage")
+	if err != nil || string(content) != "content of App/App-1.0.0-x86_64.AppImage" {
+		t.Errorf("ReadFileFromFile() = %q, %v", content, err)
+	}
+
+	if _, err := ListEntries([]byte("not a zip"), "App.zip"); err == nil {
+		t.Error("ListEntries() expected error for a corrupt zip")
+	}
+}
+
+func TestFindAppImage(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{"Top-level AppImage", []string{"README.md", "App-1.0.0-x86_64.AppImage"}, "App-1.0.0-x86_64.AppImage"},
+		{"Shallowest wins", []string{"App/extras/Helper.AppImage", "App/App.appimage"}, "App/App.appimage"},
+		{"No AppImage", []string{"app/bin/app"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindAppImage(tt.files); got != tt.want {
+				t.Errorf("FindAppImage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectBinariesFromEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		want    []string
+	}{
+		{
+			name: "Executable bit beats bin/ heuristic",
+			entries: []tarEntry{
+				{"tool/bin/tool.dat", 0644},
+				{"tool/libexec/tool", 0755},
+				{"tool/README.md", 0644},
+			},
+			want: []string{"tool/libexec/tool"},
+		},
+		{
+			name: "Executables in bin/ come first",
+			entries: []tarEntry{
+				{"tool/helper", 0755},
+				{"tool/bin/tool", 0755},
+				{"tool/install.sh", 0755},
+				{"tool/completions/tool.bash", 0755},
+			},
+			want: []string{"tool/bin/tool", "tool/helper"},
+		},
+		{
+			name: "No executable bits falls back to heuristics",
+			entries: []tarEntry{
+				{"tool/bin/tool", 0644},
+				{"tool/LICENSE", 0644},
+			},
+			want: []string{"tool/bin/tool"},
+		},
+		{
+			name: "No mode information falls back to heuristics",
+			entries: []tarEntry{
+				{"tool/tool", 0},
+				{"tool/README.md", 0},
+			},
+			want: []string{"tool/tool"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := ListEntries(writeTestTar(t, tt.entries), "tool.tar.gz")
+			if err != nil {
+				t.Fatalf("ListEntries() error = %v", err)
+			}
+			if got := DetectBinariesFromEntries(entries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectBinariesFromEntries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListFilesZstd(t *testing.T) {
+	var tarBuf bytes.Buffer
+	tw := tar.NewWriter(&tarBuf)
+	for _, name := range []string{"tool-1.0.0/tool", "tool-1.0.0/README.md"} {
+		data := []byte("content of " + name)
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatalf("Failed to write tar entry: %v", err)
+		}
+	}
+	tw.Close()
+
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("Failed to create zstd encoder: %v", err)
+	}
+	compressed := enc.EncodeAll(tarBuf.Bytes(), nil)
+	enc.Close()
+
+	for _, filename := range []string{"tool-1.0.0-linux-amd64.tar.zst", "tool-1.0.0-linux-amd64.tzst"} {
+		t.Run(filename, func(t *testing.T) {
+			files, err := ListFiles(compressed, filename)
+			if err != nil {
+				t.Fatalf("ListFiles() error = %v", err)
+			}
+			want := []string{"tool-1.0.0/tool", "tool-1.0.0/README.md"}
+			if !reflect.DeepEqual(files, want) {
+				t.Errorf("ListFiles() = %v, want %v", files, want)
+			}
+
+			content, err := ReadFile(compressed, filename, "tool-1.0.0/tool")
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if string(content) != "content of tool-1.0.0/tool" {
+				t.Errorf("ReadFile() = %q", content)
+			}
+		})
+	}
+
+	t.Run("Corrupt data", func(t *testing.T) {
+		if _, err := ListFiles([]byte("not zstd"), "tool.tar.zst"); err == nil {
+			t.Error("ListFiles() expected error for corrupt zstd data")
+		}
+	})
+}
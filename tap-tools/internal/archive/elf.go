@@ -0,0 +1,138 @@
+package archive
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/castrojo/tap-tools/internal/platform"
+	"github.com/castrojo/tap-tools/internal/version"
+)
+
+// ELFHeaderSize is enough bytes to read the machine type from an ELF header
+const ELFHeaderSize = 20
+
+// DetectELFArch reads the machine type from an ELF header
+// Returns ArchUnknown if data is not an ELF binary or the machine is unrecognized
+func DetectELFArch(data []byte) platform.Architecture {
+	if len(data) < ELFHeaderSize || !bytes.HasPrefix(data, []byte(elf.ELFMAG)) {
+		return platform.ArchUnknown
+	}
+
+	var order binary.ByteOrder
+	switch elf.Data(data[elf.EI_DATA]) {
+	case elf.ELFDATA2LSB:
+		order = binary.LittleEndian
+	case elf.ELFDATA2MSB:
+		order = binary.BigEndian
+	default:
+		return platform.ArchUnknown
+	}
+
+	// e_machine follows e_type at offset 18 in both 32- and 64-bit headers
+	switch elf.Machine(order.Uint16(data[18:20])) {
+	case elf.EM_X86_64:
+		return platform.ArchX86_64
+	case elf.EM_AARCH64:
+		return platform.ArchARM64
+	case elf.EM_ARM:
+		return platform.ArchARM
+	default:
+		return platform.ArchUnknown
+	}
+}
+
+// DetectMinGlibc returns the newest glibc symbol version a dynamically linked
+// ELF binary requires (e.g. "2.34"), or "" if it needs no versioned glibc
+// symbols, as with static or musl binaries
+func DetectMinGlibc(data []byte) (string, error) {
+	f, err := elf.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse ELF binary: %w", err)
+	}
+	defer f.Close()
+
+	symbols, err := f.ImportedSymbols()
+	if err != nil {
+		// Static binaries have no dynamic symbol table
+		if err == elf.ErrNoSymbols {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read imported symbols: %w", err)
+	}
+
+	minGlibc := ""
+	for _, sym := range symbols {
+		v, ok := strings.CutPrefix(sym.Version, "GLIBC_")
+		if !ok || !version.IsVersion(v) {
+			continue
+		}
+		if minGlibc == "" || version.Compare(v, minGlibc) > 0 {
+			minGlibc = v
+		}
+	}
+
+	return minGlibc, nil
+}
+
+// IsStaticELF reports whether an ELF binary is statically linked: it has no
+// program interpreter (PT_INTERP) and needs no shared libraries. Static-PIE
+// binaries count as static. Returns false for data that is not ELF.
+func IsStaticELF(data []byte) bool {
+	f, err := elf.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	for _, prog := range f.Progs {
+		if prog.Type == elf.PT_INTERP {
+			return false
+		}
+	}
+
+	libs, err := f.ImportedLibraries()
+	return err == nil && len(libs) == 0
+}
+
+// cRuntimeLibraries are provided by every glibc or musl system
+var cRuntimeLibraries = []string{"libc.so", "libm.so", "libpthread.so", "libdl.so", "librt.so", "libutil.so", "libgcc_s.so", "ld-linux", "ld-musl"}
+
+// NeededLibraries returns the shared libraries an ELF binary links against
+// (DT_NEEDED), leaving out the C runtime every Linux system provides
+func NeededLibraries(data []byte) ([]string, error) {
+	f, err := elf.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ELF binary: %w", err)
+	}
+	defer f.Close()
+
+	libs, err := f.ImportedLibraries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read needed libraries: %w", err)
+	}
+
+	var needed []string
+	for _, lib := range libs {
+		if !slices.ContainsFunc(cRuntimeLibraries, func(prefix string) bool { return strings.HasPrefix(lib, prefix) }) {
+			needed = append(needed, lib)
+		}
+	}
+	return needed, nil
+}
+
+// HasBundledLibraries reports whether an archive ships its own shared
+// libraries (e.g. lib/libfoo.so.1) for a dynamically linked binary
+func HasBundledLibraries(files []string) bool {
+	for _, file := range files {
+		name := path.Base(file)
+		if strings.HasSuffix(name, ".so") || strings.Contains(name, ".so.") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/castrojo/tap-tools/internal/platform"
+)
+
+// elfHeader builds the first bytes of a little-endian 64-bit ELF executable
+func elfHeader(machine uint16) []byte {
+	header := make([]byte, 64)
+	copy(header, "\x7fELF")
+	header[4] = 2  // ELFCLASS64
+	header[5] = 1  // ELFDATA2LSB
+	header[6] = 1  // EV_CURRENT
+	header[16] = 2 // ET_EXEC
+	header[18] = byte(machine)
+	header[19] = byte(machine >> 8)
+	return header
+}
+
+func TestDetectELFArch(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want platform.Architecture
+	}{
+		{"x86_64", elfHeader(62), platform.ArchX86_64},
+		{"aarch64", elfHeader(183), platform.ArchARM64},
+		{"arm", elfHeader(40), platform.ArchARM},
+		{"Unrecognized machine", elfHeader(243), platform.ArchUnknown},
+		{"Shell script", []byte("#!/bin/sh\necho hello world\n"), platform.ArchUnknown},
+		{"Truncated header", []byte("\x7fELF\x02\x01"), platform.ArchUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectELFArch(tt.data); got != tt.want {
+				t.Errorf("DetectELFArch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFileHeaderELF(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	binary := append(elfHeader(183), make([]byte, 1024)...)
+	entries := []struct {
+		name string
+		data []byte
+	}{
+		{"tool-1.0.0/README.md", []byte("# tool")},
+		{"tool-1.0.0/tool", binary},
+	}
+	for _, e := range entries {
+		if err := tw.WriteHeader(&tar.Header{Name: e.name, Mode: 0755, Size: int64(len(e.data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+		if _, err := tw.Write(e.data); err != nil {
+			t.Fatalf("Failed to write tar entry: %v", err)
+		}
+	}
+	tw.Close()
+	gz.Close()
+
+	header, err := ReadFileHeader(buf.Bytes(), "tool-1.0.0-linux-amd64.tar.gz", "tool-1.0.0/tool", ELFHeaderSize)
+	if err != nil {
+		t.Fatalf("ReadFileHeader() error = %v", err)
+	}
+	if len(header) != ELFHeaderSize {
+		t.Errorf("ReadFileHeader() returned %d bytes, want %d", len(header), ELFHeaderSize)
+	}
+	if got := DetectELFArch(header); got != platform.ArchARM64 {
+		t.Errorf("DetectELFArch() = %v, want %v", got, platform.ArchARM64)
+	}
+
+	if _, err := ReadFileHeader(buf.Bytes(), "tool-1.0.0-linux-amd64.tar.gz", "tool-1.0.0/missing", ELFHeaderSize); err == nil {
+		t.Error("ReadFileHeader() should fail for a missing file")
+	}
+}
+
+func TestDetectMinGlibc(t *testing.T) {
+	// Built from testdata/glibc-2.34-x86_64.c against glibc 2.36;
+	// __libc_start_main@GLIBC_2.34 is the newest symbol it imports
+	fixture, err := os.ReadFile("testdata/glibc-2.34-x86_64")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	got, err := DetectMinGlibc(fixture)
+	if err != nil {
+		t.Fatalf("DetectMinGlibc() error = %v", err)
+	}
+	if got != "2.34" {
+		t.Errorf("DetectMinGlibc() = %q, want %q", got, "2.34")
+	}
+
+	if got := DetectELFArch(fixture); got != platform.ArchX86_64 {
+		t.Errorf("DetectELFArch() = %v, want %v", got, platform.ArchX86_64)
+	}
+
+	if _, err := DetectMinGlibc([]byte("#!/bin/sh\necho hello\n")); err == nil {
+		t.Error("DetectMinGlibc() should fail for non-ELF data")
+	}
+}
+
+func TestIsStaticELF(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		want    bool
+	}{
+		// gcc -static -nostdlib -Os -s -Wl,-n testdata/static-x86_64.c
+		{"Static", "testdata/static-x86_64", true},
+		{"Dynamic glibc", "testdata/glibc-2.34-x86_64", false},
+		// gcc -Os -s testdata/zlib-x86_64.c -lz -Wl,--no-as-needed -lm
+		{"Dynamic with extra library", "testdata/zlib-x86_64", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile(tt.fixture)
+			if err != nil {
+				t.Fatalf("Failed to read fixture: %v", err)
+			}
+			if got := IsStaticELF(data); got != tt.want {
+				t.Errorf("IsStaticELF() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if IsStaticELF([]byte("#!/bin/sh\necho hello\n")) {
+		t.Error("IsStaticELF() should be false for non-ELF data")
+	}
+}
+
+func TestNeededLibraries(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []string
+	}{
+		{"testdata/static-x86_64", nil},
+		{"testdata/glibc-2.34-x86_64", nil},
+		{"testdata/zlib-x86_64", []string{"libz.so.1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			data, err := os.ReadFile(tt.fixture)
+			if err != nil {
+				t.Fatalf("Failed to read fixture: %v", err)
+			}
+			got, err := NeededLibraries(data)
+			if err != nil {
+				t.Fatalf("NeededLibraries() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("NeededLibraries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NeededLibraries([]byte("not an ELF")); err == nil {
+		t.Error("NeededLibraries() should fail for non-ELF data")
+	}
+}
+
+func TestHasBundledLibraries(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  bool
+	}{
+		{"Versioned shared library", []string{"tool/bin/tool", "tool/lib/libz.so.1"}, true},
+		{"Unversioned shared library", []string{"tool/tool", "tool/libfoo.so"}, true},
+		{"Binary only", []string{"tool/tool", "tool/README.md"}, false},
+		{"Lookalike names", []string{"tool/tool", "tool/docs/setup.sh", "tool/so.txt"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasBundledLibraries(tt.files); got != tt.want {
+				t.Errorf("HasBundledLibraries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+#include <sys/random.h>
+#include <stdio.h>
+int main(void) {
+	unsigned char b[4];
+	getrandom(b, sizeof b, 0);
+	printf("%d\n", b[0]);
+	return 0;
+}
@@ -5,6 +5,9 @@ package buildsystem
 
 import (
 	"fmt"
+	"path"
+	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -40,22 +43,69 @@ type InstallOptions struct {
 
 	// LDFlags are additional linker flags (for Go builds)
 	LDFlags []string
+
+	// MainPackage is the package to build when main is not at the
+	// repository root, e.g. "./cmd/tool" (for Go builds)
+	MainPackage string
+
+	// Commands are the commands of a monorepo, e.g. "cmd/tool", each built
+	// into a binary named after its directory (for Go builds, see
+	// FindGoCommands)
+	Commands []string
+
+	// Completions is how the project provides shell completions, or nil
+	// if it has none (for Go and Rust builds)
+	Completions *Completions
+
+	// Makefile is the text of the repository's Makefile, or "" if it was
+	// not fetched (for Makefile builds)
+	Makefile string
+}
+
+// Completions is a hint for installing shell completions: either a
+// subcommand that makes the installed binary print them, or completion
+// files shipped in the source tree
+type Completions struct {
+	// Subcommand generates completions, e.g. "completion" for
+	// generate_completions_from_executable; it takes precedence over files
+	Subcommand string
+
+	// Bash, Zsh and Fish are completion files in the repository
+	Bash string
+	Zsh  string
+	Fish string
+}
+
+// hintScanner is implemented by build systems that refine their
+// dependencies from the full recursive listing once detected
+type hintScanner interface {
+	scanHints(files []string)
 }
 
 // Detect analyzes a list of repository files and returns the detected
-// build system, or nil if none is detected.
+// build system, or nil if none is detected. Only top-level files are
+// used for detection, so a recursive listing can be passed as-is.
 func Detect(files []string) BuildSystem {
+	root := rootFiles(files)
+
 	// Try build systems in order of specificity
 	systems := []BuildSystem{
 		&GoBuildSystem{},
 		&RustBuildSystem{},
+		&ZigBuildSystem{},
+		&PythonBuildSystem{},
 		&MesonBuildSystem{},
 		&CMakeBuildSystem{},
+		&AutotoolsBuildSystem{},
+		&NodeBuildSystem{},
 		&MakefileBuildSystem{},
 	}
 
 	for _, sys := range systems {
-		if sys.Detect(files) {
+		if sys.Detect(root) {
+			if hs, ok := sys.(hintScanner); ok {
+				hs.scanHints(files)
+			}
 			return sys
 		}
 	}
@@ -63,6 +113,40 @@ func Detect(files []string) BuildSystem {
 	return nil
 }
 
+// ReplaceToolchain swaps a build system's default toolchain dependency for a
+// pinned variant, e.g. "go" for "go@1.21". The toolchain's name before "@"
+// must match one of deps, so a typo can't silently add an unrelated dependency.
+func ReplaceToolchain(deps []string, toolchain string) ([]string, error) {
+	base, _, _ := strings.Cut(toolchain, "@")
+
+	replaced := make([]string, len(deps))
+	found := false
+	for i, dep := range deps {
+		if name, _, _ := strings.Cut(dep, "@"); name == base {
+			replaced[i] = toolchain
+			found = true
+		} else {
+			replaced[i] = dep
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("toolchain %q does not match any build dependency (%s)", toolchain, strings.Join(deps, ", "))
+	}
+	return replaced, nil
+}
+
+// rootFiles returns the entries of a file listing that are at the repository root
+func rootFiles(files []string) []string {
+	root := make([]string, 0, len(files))
+	for _, f := range files {
+		if !strings.Contains(f, "/") {
+			root = append(root, f)
+		}
+	}
+	return root
+}
+
 // containsFile checks if a filename exists in the list
 func containsFile(files []string, target string) bool {
 	for _, f := range files {
@@ -84,7 +168,11 @@ func containsAnyFile(files []string, targets []string) bool {
 }
 
 // GoBuildSystem represents a Go-based project
-type GoBuildSystem struct{}
+type GoBuildSystem struct {
+	// CGO is set when the sources use cgo (see UsesCGO), which usually
+	// needs pkg-config and system libraries
+	CGO bool
+}
 
 func (g *GoBuildSystem) Name() string {
 	return "Go"
@@ -98,32 +186,186 @@ func (g *GoBuildSystem) GenerateInstallBlock(opts InstallOptions) string {
 	var b strings.Builder
 
 	b.WriteString("def install\n")
+	if g.CGO {
+		b.WriteString("    # TODO: This project uses cgo; add depends_on for the system libraries it links\n")
+	}
+
+	mainPackage := ""
+	if opts.MainPackage != "" {
+		mainPackage = fmt.Sprintf(", \"%s\"", opts.MainPackage)
+	}
 
+	goArgs := ""
 	if len(opts.LDFlags) > 0 {
 		b.WriteString(fmt.Sprintf("    ldflags = %s\n", formatLDFlags(opts.LDFlags)))
-		b.WriteString("    system \"go\", \"build\", *std_go_args(ldflags: ldflags)\n")
-	} else {
-		b.WriteString("    system \"go\", \"build\", *std_go_args\n")
+		goArgs = "ldflags: ldflags"
 	}
 
-	if opts.MultipleOutputs {
-		b.WriteString("    # TODO: Install additional binaries if present\n")
-		b.WriteString("    # bin.install Dir[\"bin/*\"]\n")
+	// Build every command of a monorepo, each named after its directory
+	if len(opts.Commands) > 0 {
+		if goArgs != "" {
+			goArgs += ", "
+		}
+		for _, command := range opts.Commands {
+			b.WriteString(fmt.Sprintf("    system \"go\", \"build\", *std_go_args(%soutput: bin/\"%s\"), \"./%s\"\n", goArgs, path.Base(command), command))
+		}
+	} else if goArgs != "" {
+		b.WriteString(fmt.Sprintf("    system \"go\", \"build\", *std_go_args(%s)%s\n", goArgs, mainPackage))
+	} else {
+		b.WriteString(fmt.Sprintf("    system \"go\", \"build\", *std_go_args%s\n", mainPackage))
 	}
 
+	b.WriteString(opts.Completions.InstallLines("bin", opts.BinaryName))
 	b.WriteString("  end")
 
 	return b.String()
 }
 
 func (g *GoBuildSystem) GenerateDependencies() []string {
+	if g.CGO {
+		return []string{"go", "pkg-config"}
+	}
 	return []string{"go"}
 }
 
+// maxCGOScanFiles bounds how many Go files are fetched to look for cgo
+const maxCGOScanFiles = 20
+
+var (
+	// cgoImportRe matches the import of the cgo pseudo-package
+	cgoImportRe = regexp.MustCompile(`(?m)^\s*import\s+"C"\s*$`)
+
+	// cgoDirectiveRe matches a #cgo directive in a preamble comment
+	cgoDirectiveRe = regexp.MustCompile(`(?m)^\s*(?://\s*)?#cgo\s`)
+)
+
+// GoSourcesToScan picks the Go files worth fetching to check for cgo:
+// non-test files outside vendor and testdata, shallowest first, at most
+// maxCGOScanFiles of them
+func GoSourcesToScan(files []string) []string {
+	var sources []string
+	for _, f := range files {
+		if !strings.HasSuffix(f, ".go") || strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+		dirs := strings.Split(path.Dir(f), "/")
+		if slices.Contains(dirs, "vendor") || slices.Contains(dirs, "testdata") {
+			continue
+		}
+		sources = append(sources, f)
+	}
+
+	slices.SortStableFunc(sources, func(a, b string) int {
+		return strings.Count(a, "/") - strings.Count(b, "/")
+	})
+	if len(sources) > maxCGOScanFiles {
+		sources = sources[:maxCGOScanFiles]
+	}
+	return sources
+}
+
+// UsesCGO reports whether any of the Go sources imports "C" or has a #cgo
+// directive. It is a substring scan, not a parse, so a match inside a
+// string literal counts too.
+func UsesCGO(sources map[string]string) bool {
+	for name, text := range sources {
+		if strings.HasSuffix(name, ".go") && (cgoImportRe.MatchString(text) || cgoDirectiveRe.MatchString(text)) {
+			return true
+		}
+	}
+	return false
+}
+
 func (g *GoBuildSystem) GenerateTestBlock(binaryName string) string {
 	return fmt.Sprintf("test do\n    system \"#{bin}/%s\", \"--version\"\n  end", binaryName)
 }
 
+// FindGoMainPackage locates the main package in a recursive repository
+// listing and returns it as a build path like "./cmd/tool". It returns ""
+// when main lives at the root or no single candidate can be picked.
+func FindGoMainPackage(files []string, binaryName string) string {
+	mainDirs := make(map[string]bool)
+	cmdDirs := make(map[string]bool)
+
+	for _, f := range files {
+		if !strings.HasSuffix(f, ".go") || strings.HasSuffix(f, "_test.go") || isIgnoredGoPath(f) {
+			continue
+		}
+		dir := path.Dir(f)
+		if path.Base(f) == "main.go" {
+			mainDirs[dir] = true
+		}
+		if parts := strings.Split(dir, "/"); len(parts) == 2 && parts[0] == "cmd" {
+			cmdDirs[dir] = true
+		}
+	}
+
+	// main at the root builds with a plain `go build`
+	if mainDirs["."] {
+		return ""
+	}
+
+	// Prefer the directory named after the binary
+	if binaryName != "" {
+		if dir := "cmd/" + binaryName; cmdDirs[dir] || mainDirs[dir] {
+			return "./" + dir
+		}
+	}
+
+	if dir, ok := single(mainDirs); ok {
+		return "./" + dir
+	}
+	if dir, ok := single(cmdDirs); ok {
+		return "./" + dir
+	}
+
+	return ""
+}
+
+// FindGoCommands returns the commands of a Go monorepo: the directories
+// under cmd/ holding a main.go, like "cmd/tool", sorted. A repository with
+// main at the root has one primary binary and returns nil.
+func FindGoCommands(files []string) []string {
+	var commands []string
+	for _, f := range files {
+		if f == "main.go" {
+			return nil
+		}
+		dir := path.Dir(f)
+		if path.Base(f) != "main.go" || path.Dir(dir) != "cmd" || isIgnoredGoPath(f) {
+			continue
+		}
+		if !slices.Contains(commands, dir) {
+			commands = append(commands, dir)
+		}
+	}
+	slices.Sort(commands)
+	return commands
+}
+
+// isIgnoredGoPath reports whether a Go file lives somewhere that never
+// holds the primary binary (vendored code, test fixtures, examples)
+func isIgnoredGoPath(f string) bool {
+	for _, part := range strings.Split(path.Dir(f), "/") {
+		switch part {
+		case "vendor", "testdata", "examples", "example", "internal":
+			return true
+		}
+	}
+	return false
+}
+
+// single returns the only key of a set, if it has exactly one
+func single(set map[string]bool) (string, bool) {
+	if len(set) != 1 {
+		return "", false
+	}
+	for k := range set {
+		return k, true
+	}
+	return "", false
+}
+
 // formatLDFlags formats a list of linker flags for Go build
 func formatLDFlags(flags []string) string {
 	quoted := make([]string, len(flags))
@@ -133,6 +375,84 @@ func formatLDFlags(flags []string) string {
 	return "[" + strings.Join(quoted, ", ") + "]"
 }
 
+// DetectCompletions looks for shell completion files in a recursive
+// repository listing, under a directory named completions or completion.
+// Files mentioning binaryName are preferred when there are several.
+// It returns nil if there are none.
+func DetectCompletions(files []string, binaryName string) *Completions {
+	c := &Completions{}
+	for _, f := range files {
+		if !inCompletionsDir(f) || isIgnoredGoPath(f) {
+			continue
+		}
+
+		var slot *string
+		base := path.Base(f)
+		dir := path.Dir(f)
+		switch {
+		case strings.HasSuffix(base, ".fish") || path.Base(dir) == "fish":
+			slot = &c.Fish
+		case strings.HasSuffix(base, ".zsh") || strings.HasPrefix(base, "_") || path.Base(dir) == "zsh":
+			slot = &c.Zsh
+		case strings.HasSuffix(base, ".bash") || strings.HasSuffix(base, ".bash-completion") ||
+			path.Base(dir) == "bash" || base == binaryName:
+			slot = &c.Bash
+		default:
+			continue
+		}
+
+		if *slot == "" || (!strings.Contains(path.Base(*slot), binaryName) && strings.Contains(base, binaryName)) {
+			*slot = f
+		}
+	}
+
+	if c.Bash == "" && c.Zsh == "" && c.Fish == "" {
+		return nil
+	}
+	return c
+}
+
+// inCompletionsDir reports whether a file lives under a completions
+// directory, e.g. "completions/tool.bash" or "contrib/completion/_tool"
+func inCompletionsDir(f string) bool {
+	for _, part := range strings.Split(path.Dir(f), "/") {
+		if part == "completions" || part == "completion" {
+			return true
+		}
+	}
+	return false
+}
+
+// InstallLines returns the install block lines for the completions of
+// binary, installed into binDir ("bin" or "sbin"); a subcommand takes
+// precedence over completion files. A nil Completions has no lines.
+func (c *Completions) InstallLines(binDir, binary string) string {
+	if c == nil || binary == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	if args := strings.Fields(c.Subcommand); len(args) > 0 {
+		b.WriteString(fmt.Sprintf("    generate_completions_from_executable(%s/%q", binDir, binary))
+		for _, arg := range args {
+			b.WriteString(fmt.Sprintf(", %q", arg))
+		}
+		b.WriteString(")\n")
+		return b.String()
+	}
+
+	if c.Bash != "" {
+		b.WriteString(fmt.Sprintf("    bash_completion.install %q => %q\n", c.Bash, binary))
+	}
+	if c.Zsh != "" {
+		b.WriteString(fmt.Sprintf("    zsh_completion.install %q => %q\n", c.Zsh, "_"+binary))
+	}
+	if c.Fish != "" {
+		b.WriteString(fmt.Sprintf("    fish_completion.install %q => %q\n", c.Fish, binary+".fish"))
+	}
+	return b.String()
+}
+
 // RustBuildSystem represents a Rust/Cargo project
 type RustBuildSystem struct{}
 
@@ -155,6 +475,7 @@ func (r *RustBuildSystem) GenerateInstallBlock(opts InstallOptions) string {
 		b.WriteString("    # bin.install Dir[\"target/release/other-binary\"]\n")
 	}
 
+	b.WriteString(opts.Completions.InstallLines("bin", opts.BinaryName))
 	b.WriteString("  end")
 
 	return b.String()
@@ -168,8 +489,110 @@ func (r *RustBuildSystem) GenerateTestBlock(binaryName string) string {
 	return fmt.Sprintf("test do\n    system \"#{bin}/%s\", \"--version\"\n  end", binaryName)
 }
 
+// ZigBuildSystem represents a Zig project built with zig build
+type ZigBuildSystem struct{}
+
+func (z *ZigBuildSystem) Name() string {
+	return "Zig"
+}
+
+func (z *ZigBuildSystem) Detect(files []string) bool {
+	return containsFile(files, "build.zig")
+}
+
+func (z *ZigBuildSystem) GenerateInstallBlock(opts InstallOptions) string {
+	var b strings.Builder
+
+	b.WriteString("def install\n")
+	b.WriteString("    system \"zig\", \"build\", \"--prefix\", prefix, \"-Doptimize=ReleaseSafe\"\n")
+	b.WriteString("  end")
+
+	return b.String()
+}
+
+func (z *ZigBuildSystem) GenerateDependencies() []string {
+	return []string{"zig"}
+}
+
+func (z *ZigBuildSystem) GenerateTestBlock(binaryName string) string {
+	return fmt.Sprintf("test do\n    system \"#{bin}/%s\", \"--version\"\n  end", binaryName)
+}
+
+// PythonBuildSystem represents a Python package built with pip
+type PythonBuildSystem struct{}
+
+func (p *PythonBuildSystem) Name() string {
+	return "Python"
+}
+
+func (p *PythonBuildSystem) Detect(files []string) bool {
+	return containsAnyFile(files, []string{"pyproject.toml", "setup.py", "setup.cfg"})
+}
+
+func (p *PythonBuildSystem) GenerateInstallBlock(opts InstallOptions) string {
+	var b strings.Builder
+
+	b.WriteString("def install\n")
+	b.WriteString("    system \"python3.12\", \"-m\", \"venv\", libexec\n")
+	b.WriteString("    system libexec/\"bin/pip\", \"install\", buildpath\n")
+	b.WriteString(fmt.Sprintf("    bin.install_symlink libexec/\"bin/%s\"\n", opts.BinaryName))
+
+	if opts.MultipleOutputs {
+		b.WriteString("    # TODO: Symlink additional entry points if present\n")
+		b.WriteString("    # bin.install_symlink libexec/\"bin/other-tool\"\n")
+	}
+
+	b.WriteString("  end")
+
+	return b.String()
+}
+
+func (p *PythonBuildSystem) GenerateDependencies() []string {
+	return []string{"python@3.12"}
+}
+
+func (p *PythonBuildSystem) GenerateTestBlock(binaryName string) string {
+	return fmt.Sprintf("test do\n    system \"#{bin}/%s\", \"--version\"\n  end", binaryName)
+}
+
+// NodeBuildSystem represents a Node.js package installed with npm
+type NodeBuildSystem struct{}
+
+func (n *NodeBuildSystem) Name() string {
+	return "Node"
+}
+
+func (n *NodeBuildSystem) Detect(files []string) bool {
+	return containsFile(files, "package.json") && containsFile(files, "package-lock.json")
+}
+
+func (n *NodeBuildSystem) GenerateInstallBlock(opts InstallOptions) string {
+	var b strings.Builder
+
+	b.WriteString("def install\n")
+	b.WriteString("    system \"npm\", \"install\", *std_npm_args\n")
+	b.WriteString("    bin.install_symlink Dir[\"#{libexec}/bin/*\"]\n")
+	b.WriteString("  end")
+
+	return b.String()
+}
+
+func (n *NodeBuildSystem) GenerateDependencies() []string {
+	return []string{"node"}
+}
+
+func (n *NodeBuildSystem) GenerateTestBlock(binaryName string) string {
+	return fmt.Sprintf("test do\n    system \"#{bin}/%s\", \"--version\"\n  end", binaryName)
+}
+
 // CMakeBuildSystem represents a CMake-based project
-type CMakeBuildSystem struct{}
+type CMakeBuildSystem struct {
+	// PkgConfig is set when the project ships pkg-config files or modules
+	PkgConfig bool
+
+	// Ninja selects the Ninja generator instead of Unix Makefiles
+	Ninja bool
+}
 
 func (c *CMakeBuildSystem) Name() string {
 	return "CMake"
@@ -183,7 +606,11 @@ func (c *CMakeBuildSystem) GenerateInstallBlock(opts InstallOptions) string {
 	var b strings.Builder
 
 	b.WriteString("def install\n")
-	b.WriteString(fmt.Sprintf("    system \"cmake\", \"-S\", \".\", \"-B\", \"build\", *std_cmake_args\n"))
+	if c.Ninja {
+		b.WriteString("    system \"cmake\", \"-S\", \".\", \"-B\", \"build\", \"-G\", \"Ninja\", *std_cmake_args\n")
+	} else {
+		b.WriteString("    system \"cmake\", \"-S\", \".\", \"-B\", \"build\", *std_cmake_args\n")
+	}
 	b.WriteString("    system \"cmake\", \"--build\", \"build\"\n")
 	b.WriteString("    system \"cmake\", \"--install\", \"build\"\n")
 	b.WriteString("  end")
@@ -192,7 +619,29 @@ func (c *CMakeBuildSystem) GenerateInstallBlock(opts InstallOptions) string {
 }
 
 func (c *CMakeBuildSystem) GenerateDependencies() []string {
-	return []string{"cmake"}
+	deps := []string{"cmake"}
+	if c.Ninja {
+		deps = append(deps, "ninja")
+	}
+	if c.PkgConfig {
+		deps = append(deps, "pkg-config")
+	}
+	return deps
+}
+
+// scanHints looks for pkg-config templates or modules anywhere in the
+// repository, and for CMake presets, which usually pin the Ninja generator
+func (c *CMakeBuildSystem) scanHints(files []string) {
+	for _, f := range files {
+		base := path.Base(f)
+		switch {
+		case strings.HasSuffix(base, ".pc.in"), strings.HasSuffix(base, ".pc.cmake"),
+			strings.HasSuffix(base, ".pc.cmake.in"), base == "FindPkgConfig.cmake":
+			c.PkgConfig = true
+		case f == "CMakePresets.json":
+			c.Ninja = true
+		}
+	}
 }
 
 func (c *CMakeBuildSystem) GenerateTestBlock(binaryName string) string {
@@ -230,6 +679,66 @@ func (m *MesonBuildSystem) GenerateTestBlock(binaryName string) string {
 	return fmt.Sprintf("test do\n    system \"#{bin}/%s\", \"--version\"\n  end", binaryName)
 }
 
+// AutotoolsBuildSystem represents a GNU Autotools (./configure) project
+type AutotoolsBuildSystem struct {
+	// AutoGen is set when the project ships an autogen.sh bootstrap script
+	AutoGen bool
+
+	// Configure is set when a generated configure script is checked in
+	Configure bool
+}
+
+func (a *AutotoolsBuildSystem) Name() string {
+	return "Autotools"
+}
+
+func (a *AutotoolsBuildSystem) Detect(files []string) bool {
+	return containsAnyFile(files, []string{"configure.ac", "configure", "autogen.sh"})
+}
+
+func (a *AutotoolsBuildSystem) GenerateInstallBlock(opts InstallOptions) string {
+	var b strings.Builder
+
+	b.WriteString("def install\n")
+	switch {
+	case a.AutoGen:
+		b.WriteString("    system \"./autogen.sh\"\n")
+	case !a.Configure:
+		b.WriteString("    system \"autoreconf\", \"--force\", \"--install\", \"--verbose\"\n")
+	}
+	b.WriteString("    system \"./configure\", \"--prefix=#{prefix}\"\n")
+	b.WriteString("    system \"make\"\n")
+	b.WriteString("    system \"make\", \"install\"\n")
+	b.WriteString("  end")
+
+	return b.String()
+}
+
+// GenerateDependencies adds the autotools themselves when configure has to
+// be generated at build time
+func (a *AutotoolsBuildSystem) GenerateDependencies() []string {
+	if a.Configure && !a.AutoGen {
+		return []string{}
+	}
+	return []string{"autoconf", "automake", "libtool"}
+}
+
+// scanHints records which bootstrap files are present at the repository root
+func (a *AutotoolsBuildSystem) scanHints(files []string) {
+	for _, f := range files {
+		switch f {
+		case "autogen.sh":
+			a.AutoGen = true
+		case "configure":
+			a.Configure = true
+		}
+	}
+}
+
+func (a *AutotoolsBuildSystem) GenerateTestBlock(binaryName string) string {
+	return fmt.Sprintf("test do\n    system \"#{bin}/%s\", \"--version\"\n  end", binaryName)
+}
+
 // MakefileBuildSystem represents a traditional Makefile-based project
 type MakefileBuildSystem struct{}
 
@@ -238,13 +747,54 @@ func (mk *MakefileBuildSystem) Name() string {
 }
 
 func (mk *MakefileBuildSystem) Detect(files []string) bool {
-	return containsAnyFile(files, []string{"Makefile", "makefile", "GNUmakefile"})
+	return containsAnyFile(files, makefileNames)
+}
+
+// makefileNames are the files make reads, in the order it looks for them
+var makefileNames = []string{"GNUmakefile", "makefile", "Makefile"}
+
+// FindMakefile returns the root Makefile make would use, or "" if there
+// is none
+func FindMakefile(files []string) string {
+	for _, name := range makefileNames {
+		if containsFile(files, name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// HasMakeTarget reports whether a Makefile defines target in a rule, such
+// as "install:" or "install uninstall::". Variable assignments and recipe
+// lines are not rules.
+func HasMakeTarget(makefile, target string) bool {
+	for _, line := range strings.Split(makefile, "\n") {
+		if line == "" || line[0] == '\t' || line[0] == ' ' || line[0] == '#' {
+			continue
+		}
+		colon := strings.IndexByte(line, ':')
+		if colon == -1 || strings.ContainsAny(line[:colon], "=$") || strings.HasPrefix(line[colon:], ":=") {
+			continue
+		}
+		if slices.Contains(strings.Fields(line[:colon]), target) {
+			return true
+		}
+	}
+	return false
 }
 
 func (mk *MakefileBuildSystem) GenerateInstallBlock(opts InstallOptions) string {
 	var b strings.Builder
 
 	b.WriteString("def install\n")
+	if opts.Makefile != "" && !HasMakeTarget(opts.Makefile, "install") {
+		b.WriteString("    # TODO: The Makefile has no install target; check where the binary is built\n")
+		b.WriteString("    system \"make\"\n")
+		b.WriteString(fmt.Sprintf("    bin.install %q\n", opts.BinaryName))
+		b.WriteString("  end")
+		return b.String()
+	}
+
 	b.WriteString("    # TODO: Check if configure script exists and run it\n")
 	b.WriteString("    # system \"./configure\", \"--prefix=#{prefix}\"\n")
 	b.WriteString("    system \"make\", \"install\", \"PREFIX=#{prefix}\"\n")
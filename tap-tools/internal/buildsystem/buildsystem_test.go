@@ -1,6 +1,8 @@
 package buildsystem
 
 import (
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -51,6 +53,51 @@ func TestDetect(t *testing.T) {
 			files:    []string{"main.go", "go.mod", "Makefile"},
 			expected: "Go",
 		},
+		{
+			name:     "Python project",
+			files:    []string{"mytool/__init__.py", "pyproject.toml"},
+			expected: "Python",
+		},
+		{
+			name:     "Go takes priority over Python",
+			files:    []string{"main.go", "go.mod", "setup.py"},
+			expected: "Go",
+		},
+		{
+			name:     "Python takes priority over Makefile",
+			files:    []string{"setup.py", "Makefile"},
+			expected: "Python",
+		},
+		{
+			name:     "Autotools project",
+			files:    []string{"configure.ac", "Makefile.am", "src/main.c"},
+			expected: "Autotools",
+		},
+		{
+			name:     "Autotools takes priority over Makefile",
+			files:    []string{"configure", "Makefile", "main.c"},
+			expected: "Autotools",
+		},
+		{
+			name:     "Zig project",
+			files:    []string{"build.zig", "src/main.zig"},
+			expected: "Zig",
+		},
+		{
+			name:     "Node project",
+			files:    []string{"index.js", "package.json", "package-lock.json"},
+			expected: "Node",
+		},
+		{
+			name:     "Go takes priority over Node",
+			files:    []string{"main.go", "go.mod", "package.json", "package-lock.json"},
+			expected: "Go",
+		},
+		{
+			name:     "Node takes priority over Makefile",
+			files:    []string{"package.json", "package-lock.json", "Makefile"},
+			expected: "Node",
+		},
 		{
 			name:     "Rust takes priority over Makefile",
 			files:    []string{"src/main.rs", "Cargo.toml", "Cargo.lock", "Makefile"},
@@ -138,6 +185,54 @@ func TestGoBuildSystem(t *testing.T) {
 		}
 	})
 
+	t.Run("CGO", func(t *testing.T) {
+		cgo := &GoBuildSystem{CGO: true}
+		if deps := cgo.GenerateDependencies(); !reflect.DeepEqual(deps, []string{"go", "pkg-config"}) {
+			t.Errorf("Expected dependencies [go pkg-config], got %v", deps)
+		}
+		if result := cgo.GenerateInstallBlock(InstallOptions{BinaryName: "myapp"}); !strings.Contains(result, "# TODO: This project uses cgo") {
+			t.Errorf("Install block should note the system libraries, got:\n%s", result)
+		}
+		if result := bs.GenerateInstallBlock(InstallOptions{BinaryName: "myapp"}); strings.Contains(result, "cgo") {
+			t.Errorf("Pure Go install block should not mention cgo, got:\n%s", result)
+		}
+	})
+
+	t.Run("GenerateInstallBlock with multiple commands", func(t *testing.T) {
+		result := bs.GenerateInstallBlock(InstallOptions{BinaryName: "myapp", Commands: []string{"cmd/bar", "cmd/foo"}})
+
+		want := "    system \"go\", \"build\", *std_go_args(output: bin/\"bar\"), \"./cmd/bar\"\n" +
+			"    system \"go\", \"build\", *std_go_args(output: bin/\"foo\"), \"./cmd/foo\"\n"
+		if !strings.Contains(result, want) {
+			t.Errorf("Install block should build each command, got:\n%s", result)
+		}
+	})
+
+	t.Run("GenerateInstallBlock with multiple commands and ldflags", func(t *testing.T) {
+		result := bs.GenerateInstallBlock(InstallOptions{
+			BinaryName: "myapp",
+			Commands:   []string{"cmd/myapp"},
+			LDFlags:    []string{"-s", "-w"},
+		})
+
+		if !strings.Contains(result, `*std_go_args(ldflags: ldflags, output: bin/"myapp"), "./cmd/myapp"`) {
+			t.Errorf("Install block should pass ldflags to each command, got:\n%s", result)
+		}
+	})
+
+	t.Run("GenerateInstallBlock with main package", func(t *testing.T) {
+		opts := InstallOptions{
+			BinaryName:  "myapp",
+			Prefix:      "#{prefix}",
+			MainPackage: "./cmd/myapp",
+		}
+		result := bs.GenerateInstallBlock(opts)
+
+		if !strings.Contains(result, `system "go", "build", *std_go_args, "./cmd/myapp"`) {
+			t.Errorf("Install block should build the main package, got:\n%s", result)
+		}
+	})
+
 	t.Run("GenerateDependencies", func(t *testing.T) {
 		deps := bs.GenerateDependencies()
 		if len(deps) != 1 || deps[0] != "go" {
@@ -160,6 +255,191 @@ func TestGoBuildSystem(t *testing.T) {
 	})
 }
 
+func TestDetectIgnoresNestedFiles(t *testing.T) {
+	files := []string{"Cargo.toml", "Cargo.lock", "src/main.rs", "tools/gen/go.mod", "tools/gen/main.go"}
+	bs := Detect(files)
+	if bs == nil || bs.Name() != "Rust" {
+		t.Errorf("Detect() should ignore nested go.mod, got %v", bs)
+	}
+}
+
+func TestFindGoMainPackage(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      []string
+		binaryName string
+		want       string
+	}{
+		{
+			name:       "Main under cmd/tool",
+			files:      []string{"go.mod", "go.sum", "README.md", "cmd/tool/main.go", "cmd/tool/flags.go", "pkg/lib/lib.go"},
+			binaryName: "tool",
+			want:       "./cmd/tool",
+		},
+		{
+			name:       "Main at root",
+			files:      []string{"go.mod", "main.go", "cmd/helper/main.go"},
+			binaryName: "tool",
+			want:       "",
+		},
+		{
+			name:       "Prefers directory named after binary",
+			files:      []string{"go.mod", "cmd/tool/main.go", "cmd/tool-server/main.go"},
+			binaryName: "tool",
+			want:       "./cmd/tool",
+		},
+		{
+			name:       "Single cmd directory without main.go",
+			files:      []string{"go.mod", "cmd/tool/root.go", "internal/app/app.go"},
+			binaryName: "other",
+			want:       "./cmd/tool",
+		},
+		{
+			name:       "Single main outside cmd",
+			files:      []string{"go.mod", "app/main.go", "lib/lib.go"},
+			binaryName: "tool",
+			want:       "./app",
+		},
+		{
+			name:       "Ignores examples, testdata, and vendor",
+			files:      []string{"go.mod", "cmd/tool/main.go", "examples/demo/main.go", "testdata/fake/main.go", "vendor/x/main.go"},
+			binaryName: "other",
+			want:       "./cmd/tool",
+		},
+		{
+			name:       "Ambiguous cmd directories",
+			files:      []string{"go.mod", "cmd/a/main.go", "cmd/b/main.go"},
+			binaryName: "tool",
+			want:       "",
+		},
+		{
+			name:       "Root listing only",
+			files:      []string{"go.mod", "go.sum", "README.md"},
+			binaryName: "tool",
+			want:       "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindGoMainPackage(tt.files, tt.binaryName); got != tt.want {
+				t.Errorf("FindGoMainPackage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindGoCommands(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{
+			name:  "Monorepo",
+			files: []string{"go.mod", "cmd/foo/main.go", "cmd/bar/main.go", "cmd/bar/flags.go", "pkg/lib.go"},
+			want:  []string{"cmd/bar", "cmd/foo"},
+		},
+		{
+			name:  "Single command",
+			files: []string{"go.mod", "cmd/foo/main.go"},
+			want:  []string{"cmd/foo"},
+		},
+		{
+			name:  "Main at the root",
+			files: []string{"go.mod", "cmd/foo/main.go", "cmd/bar/main.go", "main.go"},
+			want:  nil,
+		},
+		{
+			name:  "Nested and ignored directories",
+			files: []string{"go.mod", "cmd/foo/sub/main.go", "examples/cmd/demo/main.go", "tools/cmd/gen/main.go"},
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindGoCommands(tt.files); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindGoCommands() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsesCGO(t *testing.T) {
+	pureGo := "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n\nfunc main() { fmt.Println(os.Args) }\n"
+	cgoImport := "package sqlite\n\n// #include <sqlite3.h>\nimport \"C\"\n"
+	cgoDirective := "package gui\n\n/*\n#cgo pkg-config: gtk4\n#include <gtk/gtk.h>\n*/\nimport \"C\"\n"
+	cgoLineComment := "package audio\n\n// #cgo LDFLAGS: -lasound\n"
+
+	tests := []struct {
+		name    string
+		sources map[string]string
+		want    bool
+	}{
+		{"Pure Go", map[string]string{"main.go": pureGo, "cmd/tool/main.go": pureGo}, false},
+		{"Import C", map[string]string{"main.go": pureGo, "internal/db/sqlite.go": cgoImport}, true},
+		{"Cgo directive block", map[string]string{"gui/window.go": cgoDirective}, true},
+		{"Cgo directive line comment", map[string]string{"audio/alsa.go": cgoLineComment}, true},
+		{"Not a Go file", map[string]string{"Makefile": "import \"C\"\n"}, false},
+		{"No sources", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UsesCGO(tt.sources); got != tt.want {
+				t.Errorf("UsesCGO() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoSourcesToScan(t *testing.T) {
+	files := []string{"go.mod", "internal/db/sqlite.go", "main.go", "main_test.go",
+		"vendor/x/y.go", "testdata/fixture.go", "cmd/tool/root.go", "README.md"}
+	want := []string{"main.go", "internal/db/sqlite.go", "cmd/tool/root.go"}
+	if got := GoSourcesToScan(files); !reflect.DeepEqual(got, want) {
+		t.Errorf("GoSourcesToScan() = %v, want %v", got, want)
+	}
+
+	var many []string
+	for i := range maxCGOScanFiles + 5 {
+		many = append(many, fmt.Sprintf("pkg/file%d.go", i))
+	}
+	if got := GoSourcesToScan(many); len(got) != maxCGOScanFiles {
+		t.Errorf("GoSourcesToScan() returned %d files, want at most %d", len(got), maxCGOScanFiles)
+	}
+}
+
+func TestReplaceToolchain(t *testing.T) {
+	tests := []struct {
+		name      string
+		deps      []string
+		toolchain string
+		want      []string
+		wantErr   bool
+	}{
+		{"Pin go", []string{"go"}, "go@1.21", []string{"go@1.21"}, false},
+		{"Pin rust", []string{"rust"}, "rust@1.75", []string{"rust@1.75"}, false},
+		{"Keep other deps", []string{"meson", "ninja"}, "meson@1.3", []string{"meson@1.3", "ninja"}, false},
+		{"Repin a pinned dep", []string{"go@1.20"}, "go@1.21", []string{"go@1.21"}, false},
+		{"Unrelated toolchain", []string{"go"}, "rust@1.75", nil, true},
+		{"No deps", []string{}, "go@1.21", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReplaceToolchain(tt.deps, tt.toolchain)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReplaceToolchain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ReplaceToolchain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRustBuildSystem(t *testing.T) {
 	bs := &RustBuildSystem{}
 
@@ -208,6 +488,263 @@ func TestRustBuildSystem(t *testing.T) {
 	})
 }
 
+func TestGenerateInstallBlockCompletions(t *testing.T) {
+	files := &Completions{
+		Bash: "completions/tool.bash",
+		Zsh:  "completions/_tool",
+		Fish: "completions/tool.fish",
+	}
+	fileLines := []string{
+		`    bash_completion.install "completions/tool.bash" => "tool"`,
+		`    zsh_completion.install "completions/_tool" => "_tool"`,
+		`    fish_completion.install "completions/tool.fish" => "tool.fish"`,
+	}
+	generateLine := `    generate_completions_from_executable(bin/"tool", "completion")`
+
+	tests := []struct {
+		name        string
+		completions *Completions
+		want        []string
+	}{
+		{"no hint", nil, nil},
+		{"files", files, fileLines},
+		{"subcommand", &Completions{Subcommand: "completion"}, []string{generateLine}},
+		{"subcommand wins over files", &Completions{Subcommand: "completion", Bash: "completions/tool.bash"}, []string{generateLine}},
+	}
+
+	for _, bs := range []BuildSystem{&GoBuildSystem{}, &RustBuildSystem{}} {
+		for _, tt := range tests {
+			t.Run(bs.Name()+"/"+tt.name, func(t *testing.T) {
+				result := bs.GenerateInstallBlock(InstallOptions{BinaryName: "tool", Completions: tt.completions})
+
+				for _, line := range tt.want {
+					if !strings.Contains(result, line+"\n") {
+						t.Errorf("Install block missing %q:\n%s", line, result)
+					}
+				}
+				if len(tt.want) == 0 && strings.Contains(result, "completion") {
+					t.Errorf("Install block should not install completions without a hint:\n%s", result)
+				}
+				if !strings.HasSuffix(result, "\n  end") {
+					t.Errorf("Install block should still end with end:\n%s", result)
+				}
+			})
+		}
+	}
+}
+
+func TestDetectCompletions(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  *Completions
+	}{
+		{
+			name:  "none",
+			files: []string{"go.mod", "main.go", "docs/completion.md"},
+			want:  nil,
+		},
+		{
+			name:  "by extension",
+			files: []string{"go.mod", "completions/tool.bash", "completions/_tool", "completions/tool.fish"},
+			want:  &Completions{Bash: "completions/tool.bash", Zsh: "completions/_tool", Fish: "completions/tool.fish"},
+		},
+		{
+			name:  "by shell directory",
+			files: []string{"contrib/completion/bash/tool", "contrib/completion/zsh/tool", "contrib/completion/fish/tool"},
+			want:  &Completions{Bash: "contrib/completion/bash/tool", Zsh: "contrib/completion/zsh/tool", Fish: "contrib/completion/fish/tool"},
+		},
+		{
+			name:  "prefers the binary",
+			files: []string{"completions/helper.bash", "completions/tool.bash"},
+			want:  &Completions{Bash: "completions/tool.bash"},
+		},
+		{
+			name:  "ignores vendored completions",
+			files: []string{"vendor/github.com/x/y/completions/y.bash"},
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectCompletions(tt.files, "tool")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectCompletions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestZigBuildSystem(t *testing.T) {
+	bs := &ZigBuildSystem{}
+
+	t.Run("Name", func(t *testing.T) {
+		if bs.Name() != "Zig" {
+			t.Errorf("Expected name 'Zig', got %s", bs.Name())
+		}
+	})
+
+	t.Run("Detect without build.zig", func(t *testing.T) {
+		files := []string{"src/main.zig", "README.md"}
+		if bs.Detect(files) {
+			t.Error("Should not detect Zig project without build.zig")
+		}
+	})
+
+	t.Run("GenerateInstallBlock", func(t *testing.T) {
+		result := bs.GenerateInstallBlock(InstallOptions{BinaryName: "mytool"})
+
+		if !strings.Contains(result, `system "zig", "build", "--prefix", prefix, "-Doptimize=ReleaseSafe"`) {
+			t.Errorf("Install block should run zig build, got:\n%s", result)
+		}
+	})
+
+	t.Run("GenerateDependencies", func(t *testing.T) {
+		deps := bs.GenerateDependencies()
+		if len(deps) != 1 || deps[0] != "zig" {
+			t.Errorf("Expected dependencies [\"zig\"], got %v", deps)
+		}
+	})
+}
+
+func TestPythonBuildSystem(t *testing.T) {
+	bs := &PythonBuildSystem{}
+
+	t.Run("Name", func(t *testing.T) {
+		if bs.Name() != "Python" {
+			t.Errorf("Expected name 'Python', got %s", bs.Name())
+		}
+	})
+
+	t.Run("Detect with pyproject.toml", func(t *testing.T) {
+		files := []string{"src/tool/__init__.py", "pyproject.toml"}
+		if !bs.Detect(files) {
+			t.Error("Expected to detect Python project with pyproject.toml")
+		}
+	})
+
+	t.Run("Detect with setup.py", func(t *testing.T) {
+		files := []string{"tool/__init__.py", "setup.py"}
+		if !bs.Detect(files) {
+			t.Error("Expected to detect Python project with setup.py")
+		}
+	})
+
+	t.Run("Detect with setup.cfg", func(t *testing.T) {
+		files := []string{"tool/__init__.py", "setup.cfg"}
+		if !bs.Detect(files) {
+			t.Error("Expected to detect Python project with setup.cfg")
+		}
+	})
+
+	t.Run("Detect without Python files", func(t *testing.T) {
+		files := []string{"main.c", "Makefile"}
+		if bs.Detect(files) {
+			t.Error("Should not detect Python project without packaging files")
+		}
+	})
+
+	t.Run("GenerateInstallBlock", func(t *testing.T) {
+		opts := InstallOptions{
+			BinaryName: "mytool",
+			Prefix:     "#{prefix}",
+		}
+		result := bs.GenerateInstallBlock(opts)
+
+		if !strings.Contains(result, "def install") {
+			t.Error("Install block should contain 'def install'")
+		}
+		if !strings.Contains(result, `system "python3.12", "-m", "venv", libexec`) {
+			t.Errorf("Install block should create a virtualenv in libexec, got:\n%s", result)
+		}
+		if !strings.Contains(result, `system libexec/"bin/pip", "install", buildpath`) {
+			t.Errorf("Install block should pip install the package, got:\n%s", result)
+		}
+		if !strings.Contains(result, `bin.install_symlink libexec/"bin/mytool"`) {
+			t.Errorf("Install block should symlink the entry point, got:\n%s", result)
+		}
+	})
+
+	t.Run("GenerateDependencies", func(t *testing.T) {
+		deps := bs.GenerateDependencies()
+		if len(deps) != 1 || deps[0] != "python@3.12" {
+			t.Errorf("Expected dependencies [\"python@3.12\"], got %v", deps)
+		}
+	})
+
+	t.Run("GenerateTestBlock", func(t *testing.T) {
+		result := bs.GenerateTestBlock("mytool")
+
+		if !strings.Contains(result, "test do") {
+			t.Error("Test block should contain 'test do'")
+		}
+		if !strings.Contains(result, "mytool") {
+			t.Error("Test block should contain binary name")
+		}
+	})
+}
+
+func TestNodeBuildSystem(t *testing.T) {
+	bs := &NodeBuildSystem{}
+
+	t.Run("Name", func(t *testing.T) {
+		if bs.Name() != "Node" {
+			t.Errorf("Expected name 'Node', got %s", bs.Name())
+		}
+	})
+
+	t.Run("Detect with package.json and package-lock.json", func(t *testing.T) {
+		files := []string{"bin/cli.js", "package.json", "package-lock.json"}
+		if !bs.Detect(files) {
+			t.Error("Expected to detect Node project with package.json and package-lock.json")
+		}
+	})
+
+	t.Run("Detect without package-lock.json", func(t *testing.T) {
+		files := []string{"bin/cli.js", "package.json"}
+		if bs.Detect(files) {
+			t.Error("Should not detect Node project without package-lock.json")
+		}
+	})
+
+	t.Run("GenerateInstallBlock", func(t *testing.T) {
+		opts := InstallOptions{
+			BinaryName: "mytool",
+			Prefix:     "#{prefix}",
+		}
+		result := bs.GenerateInstallBlock(opts)
+
+		if !strings.Contains(result, "def install") {
+			t.Error("Install block should contain 'def install'")
+		}
+		if !strings.Contains(result, `system "npm", "install", *std_npm_args`) {
+			t.Errorf("Install block should npm install the package, got:\n%s", result)
+		}
+		if !strings.Contains(result, `bin.install_symlink Dir["#{libexec}/bin/*"]`) {
+			t.Errorf("Install block should symlink the package binaries, got:\n%s", result)
+		}
+	})
+
+	t.Run("GenerateDependencies", func(t *testing.T) {
+		deps := bs.GenerateDependencies()
+		if len(deps) != 1 || deps[0] != "node" {
+			t.Errorf("Expected dependencies [\"node\"], got %v", deps)
+		}
+	})
+
+	t.Run("GenerateTestBlock", func(t *testing.T) {
+		result := bs.GenerateTestBlock("mytool")
+
+		if !strings.Contains(result, "test do") {
+			t.Error("Test block should contain 'test do'")
+		}
+		if !strings.Contains(result, "mytool") {
+			t.Error("Test block should contain binary name")
+		}
+	})
+}
+
 func TestCMakeBuildSystem(t *testing.T) {
 	bs := &CMakeBuildSystem{}
 
@@ -252,6 +789,73 @@ func TestCMakeBuildSystem(t *testing.T) {
 	})
 }
 
+func TestCMakeHints(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         []string
+		wantDeps      []string
+		wantGenerator bool
+	}{
+		{
+			name:     "Plain CMake",
+			files:    []string{"CMakeLists.txt", "src/main.c"},
+			wantDeps: []string{"cmake"},
+		},
+		{
+			name:     "pkg-config template at root",
+			files:    []string{"CMakeLists.txt", "libfoo.pc.in", "src/foo.c"},
+			wantDeps: []string{"cmake", "pkg-config"},
+		},
+		{
+			name:     "pkg-config template in subdirectory",
+			files:    []string{"CMakeLists.txt", "cmake/foo.pc.cmake.in", "src/foo.c"},
+			wantDeps: []string{"cmake", "pkg-config"},
+		},
+		{
+			name:     "FindPkgConfig module",
+			files:    []string{"CMakeLists.txt", "cmake/modules/FindPkgConfig.cmake"},
+			wantDeps: []string{"cmake", "pkg-config"},
+		},
+		{
+			name:          "Presets select Ninja",
+			files:         []string{"CMakeLists.txt", "CMakePresets.json", "src/main.c"},
+			wantDeps:      []string{"cmake", "ninja"},
+			wantGenerator: true,
+		},
+		{
+			name:          "Nested presets are ignored",
+			files:         []string{"CMakeLists.txt", "third_party/lib/CMakePresets.json"},
+			wantDeps:      []string{"cmake"},
+			wantGenerator: false,
+		},
+		{
+			name:          "pkg-config and Ninja",
+			files:         []string{"CMakeLists.txt", "CMakePresets.json", "foo.pc.in"},
+			wantDeps:      []string{"cmake", "ninja", "pkg-config"},
+			wantGenerator: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bs := Detect(tt.files)
+			if bs == nil || bs.Name() != "CMake" {
+				t.Fatalf("Detect() = %v, want CMake", bs)
+			}
+
+			deps := bs.GenerateDependencies()
+			if strings.Join(deps, ",") != strings.Join(tt.wantDeps, ",") {
+				t.Errorf("GenerateDependencies() = %v, want %v", deps, tt.wantDeps)
+			}
+
+			block := bs.GenerateInstallBlock(InstallOptions{BinaryName: "foo"})
+			if got := strings.Contains(block, `"-G", "Ninja"`); got != tt.wantGenerator {
+				t.Errorf("Ninja generator in install block = %v, want %v:\n%s", got, tt.wantGenerator, block)
+			}
+		})
+	}
+}
+
 func TestMesonBuildSystem(t *testing.T) {
 	bs := &MesonBuildSystem{}
 
@@ -293,6 +897,80 @@ func TestMesonBuildSystem(t *testing.T) {
 	})
 }
 
+func TestAutotoolsBuildSystem(t *testing.T) {
+	t.Run("Name", func(t *testing.T) {
+		bs := &AutotoolsBuildSystem{}
+		if bs.Name() != "Autotools" {
+			t.Errorf("Expected name 'Autotools', got %s", bs.Name())
+		}
+	})
+
+	t.Run("Detect without autotools files", func(t *testing.T) {
+		bs := &AutotoolsBuildSystem{}
+		if bs.Detect([]string{"main.c", "Makefile"}) {
+			t.Error("Should not detect Autotools project from a plain Makefile")
+		}
+	})
+
+	tests := []struct {
+		name      string
+		files     []string
+		wantSteps []string
+		wantDeps  []string
+	}{
+		{
+			name:      "configure.ac",
+			files:     []string{"configure.ac", "Makefile.am"},
+			wantSteps: []string{`system "autoreconf", "--force", "--install", "--verbose"`},
+			wantDeps:  []string{"autoconf", "automake", "libtool"},
+		},
+		{
+			name:     "configure",
+			files:    []string{"configure", "Makefile.in"},
+			wantDeps: []string{},
+		},
+		{
+			name:      "autogen.sh",
+			files:     []string{"autogen.sh", "configure.ac"},
+			wantSteps: []string{`system "./autogen.sh"`},
+			wantDeps:  []string{"autoconf", "automake", "libtool"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("Detect "+tt.name, func(t *testing.T) {
+			result := Detect(tt.files)
+			bs, ok := result.(*AutotoolsBuildSystem)
+			if !ok {
+				t.Fatalf("Detect(%v) = %v, want Autotools", tt.files, result)
+			}
+
+			block := bs.GenerateInstallBlock(InstallOptions{BinaryName: "myapp"})
+			steps := append(tt.wantSteps,
+				`system "./configure", "--prefix=#{prefix}"`,
+				`system "make"`,
+				`system "make", "install"`,
+			)
+			last := -1
+			for _, step := range steps {
+				i := strings.Index(block, step)
+				if i <= last {
+					t.Errorf("Install block should contain %q after the previous step, got:\n%s", step, block)
+				}
+				last = i
+			}
+			if tt.wantSteps == nil && (strings.Contains(block, "autogen") || strings.Contains(block, "autoreconf")) {
+				t.Errorf("Install block should use the shipped configure script, got:\n%s", block)
+			}
+
+			deps := bs.GenerateDependencies()
+			if !reflect.DeepEqual(deps, tt.wantDeps) {
+				t.Errorf("GenerateDependencies() = %v, want %v", deps, tt.wantDeps)
+			}
+		})
+	}
+}
+
 func TestMakefileBuildSystem(t *testing.T) {
 	bs := &MakefileBuildSystem{}
 
@@ -337,6 +1015,29 @@ func TestMakefileBuildSystem(t *testing.T) {
 		}
 	})
 
+	t.Run("GenerateInstallBlock with install target", func(t *testing.T) {
+		makefile := "PREFIX ?= /usr/local\n\nall: myapp\n\ninstall: all\n\tinstall -m755 myapp $(PREFIX)/bin\n"
+		result := bs.GenerateInstallBlock(InstallOptions{BinaryName: "myapp", Makefile: makefile})
+
+		if !strings.Contains(result, `system "make", "install", "PREFIX=#{prefix}"`) {
+			t.Errorf("Install block should run make install, got:\n%s", result)
+		}
+	})
+
+	t.Run("GenerateInstallBlock without install target", func(t *testing.T) {
+		makefile := "CFLAGS := -O2\n\nmyapp: main.c\n\t$(CC) $(CFLAGS) -o myapp main.c\n\n# install: not provided\n"
+		result := bs.GenerateInstallBlock(InstallOptions{BinaryName: "myapp", Makefile: makefile})
+
+		want := "def install\n" +
+			"    # TODO: The Makefile has no install target; check where the binary is built\n" +
+			"    system \"make\"\n" +
+			"    bin.install \"myapp\"\n" +
+			"  end"
+		if result != want {
+			t.Errorf("Install block = %q, want %q", result, want)
+		}
+	})
+
 	t.Run("GenerateDependencies", func(t *testing.T) {
 		deps := bs.GenerateDependencies()
 		if len(deps) != 0 {
@@ -345,6 +1046,32 @@ func TestMakefileBuildSystem(t *testing.T) {
 	})
 }
 
+func TestHasMakeTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		makefile string
+		want     bool
+	}{
+		{"Install rule", "install: all\n\tcp tool /usr/local/bin\n", true},
+		{"Install among targets", ".PHONY: all\ninstall uninstall:\n\t./setup.sh $@\n", true},
+		{"Double-colon rule", "install::\n\tcp tool $(DESTDIR)\n", true},
+		{"No install rule", "all: tool\ntool: main.c\n\tcc -o tool main.c\n", false},
+		{"Phony declaration only", ".PHONY: all install\nall:\n\tcc main.c\n", false},
+		{"Recipe mentioning install", "all:\n\tinstall: -m755 tool bin\n", false},
+		{"Variable assignment", "install_dir := /usr\nINSTALL = install: x\n", false},
+		{"Similar target name", "install-bin:\n\tcp tool bin\n", false},
+		{"Comment", "# install:\nall:\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasMakeTarget(tt.makefile, "install"); got != tt.want {
+				t.Errorf("HasMakeTarget() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestContainsFile(t *testing.T) {
 	tests := []struct {
 		name     string
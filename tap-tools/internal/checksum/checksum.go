@@ -1,33 +1,203 @@
 package checksum
 
 import (
+	"bytes"
+	"context"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path"
 	"regexp"
+	"slices"
 	"strings"
 )
 
 // DownloadFile downloads a file from the given URL and returns its content
+// Transient failures (connection errors, HTTP 5xx and 429) are retried with
+// backoff, within the overall download timeout; other statuses fail fast.
 func DownloadFile(url string) ([]byte, error) {
-	resp, err := http.Get(url)
+	return DownloadFileWithProgress(url, nil)
+}
+
+// ProgressFunc is called as a download proceeds with the bytes received so
+// far and the expected total, which is -1 when the server sends no
+// Content-Length. A retry starts counting from zero again.
+type ProgressFunc func(downloaded, total int64)
+
+// DownloadFileWithProgress is DownloadFile, reporting progress to a non-nil
+// progress func
+func DownloadFileWithProgress(url string, progress ProgressFunc) ([]byte, error) {
+	var buf bytes.Buffer
+	err := download(url, progress, func() (io.Writer, error) {
+		buf.Reset()
+		return &buf, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DownloadAndHash streams a download into a temporary file, hashing it on the
+// way, so large assets are never held in memory. It returns the SHA256 and
+// size of the download and the file, which the caller inspects (e.g. with
+// archive.ListEntriesFromFile) and removes. Retries follow DownloadFile.
+func DownloadAndHash(url string) (sha256sum string, size int64, tmpPath string, err error) {
+	return DownloadAndHashWithProgress(url, nil)
+}
+
+// DownloadAndHashWithProgress is DownloadAndHash, reporting progress to a
+// non-nil progress func
+func DownloadAndHashWithProgress(url string, progress ProgressFunc) (sha256sum string, size int64, tmpPath string, err error) {
+	file, err := os.CreateTemp("", "tap-download-*"+path.Ext(path.Base(url)))
 	if err != nil {
-		return nil, fmt.Errorf("failed to download file: %w", err)
+		return "", 0, "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer func() {
+		file.Close()
+		if err != nil {
+			os.Remove(file.Name())
+		}
+	}()
+
+	hash := sha256.New()
+	counter := &countingWriter{}
+	err = download(url, progress, func() (io.Writer, error) {
+		// Start over on retry
+		if err := file.Truncate(0); err != nil {
+			return nil, fmt.Errorf("failed to reset temp file: %w", err)
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to reset temp file: %w", err)
+		}
+		hash.Reset()
+		counter.n = 0
+		return io.MultiWriter(file, hash, counter), nil
+	})
+	if err != nil {
+		return "", 0, "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", 0, "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), counter.n, file.Name(), nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// download fetches url into the writer returned by reset, which is called
+// before every attempt so a retry starts from an empty destination
+func download(url string, progress ProgressFunc, reset func() (io.Writer, error)) error {
+	ctx := context.Background()
+	if downloadTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, downloadTimeout)
+		defer cancel()
+	}
+
+	var lastErr error
+	for attempt := range downloadAttempts {
+		if attempt > 0 {
+			if err := backoff(ctx, attempt); err != nil {
+				return fmt.Errorf("%w (download timed out while retrying)", lastErr)
+			}
+		}
+
+		w, err := reset()
+		if err != nil {
+			return err
+		}
+		retry, err := downloadOnce(ctx, url, w, progress)
+		if err == nil {
+			return nil
+		}
+		if !retry {
+			return err
+		}
+		lastErr = err
+	}
+
+	return fmt.Errorf("%w (gave up after %d attempts)", lastErr, downloadAttempts)
+}
+
+// downloadOnce makes a single download attempt into w and reports whether a
+// failure is transient and worth retrying
+func downloadOnce(ctx context.Context, url string, w io.Writer, progress ProgressFunc) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to download file: %w", err)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("failed to download file: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to download file: HTTP %d", resp.StatusCode)
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		return retry, fmt.Errorf("failed to download file: %w", &StatusError{StatusCode: resp.StatusCode})
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	// A reset connection part way through a large asset is also transient,
+	// but a failing destination is not
+	var werr error
+	var downloaded int64
+	if progress != nil {
+		progress(0, resp.ContentLength)
+	}
+	_, err = io.Copy(writerFunc(func(p []byte) (int, error) {
+		n, err := w.Write(p)
+		werr = err
+		downloaded += int64(n)
+		if progress != nil {
+			progress(downloaded, resp.ContentLength)
+		}
+		return n, err
+	}), resp.Body)
+	if werr != nil {
+		return false, fmt.Errorf("failed to save download: %w", werr)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return ctx.Err() == nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	return data, nil
+	return false, nil
+}
+
+// StatusError is a download that failed with a non-200 HTTP status
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d", e.StatusCode)
+}
+
+// IsNotFound reports whether a download failed with HTTP 404
+func IsNotFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+// writerFunc adapts a function to io.Writer
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
 }
 
 // CalculateSHA256 calculates the SHA256 checksum of the given data
@@ -36,6 +206,73 @@ func CalculateSHA256(data []byte) string {
 	return hex.EncodeToString(hash[:])
 }
 
+// Hash algorithms of upstream checksums
+const (
+	SHA256 = "sha256"
+	SHA512 = "sha512"
+)
+
+// Algorithm reports the hash algorithm of a hex checksum from its length,
+// or "" if it is neither a SHA256 nor a SHA512
+func Algorithm(hash string) string {
+	switch len(hash) {
+	case sha256.Size * 2:
+		return SHA256
+	case sha512.Size * 2:
+		return SHA512
+	}
+	return ""
+}
+
+// fileSHA512 streams a file through SHA512
+func fileSHA512(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read downloaded file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha512.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read downloaded file: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// VerifyUpstream checks a download against an upstream checksum of either
+// algorithm. sha256sum is the download's already calculated SHA256; the
+// downloaded file at path is only read for SHA512 and may be "" when the
+// download was skipped, in which case a SHA512 checksum cannot be verified
+// and verified is false.
+func VerifyUpstream(path, sha256sum, expected string) (verified bool, err error) {
+	calculated := sha256sum
+	if Algorithm(expected) == SHA512 {
+		if path == "" {
+			return false, nil
+		}
+		if calculated, err = fileSHA512(path); err != nil {
+			return false, err
+		}
+	}
+
+	if calculated != strings.ToLower(expected) {
+		return false, fmt.Errorf("checksum mismatch: expected %s, got %s", expected, calculated)
+	}
+	return true, nil
+}
+
+// sha256Re matches a hex-encoded SHA256 checksum
+var sha256Re = regexp.MustCompile(`^[a-fA-F0-9]{64}$`)
+
+// ParseSHA256 checks that s is a hex-encoded SHA256 checksum and returns it
+// in lowercase
+func ParseSHA256(s string) (string, error) {
+	if !sha256Re.MatchString(s) {
+		return "", fmt.Errorf("invalid SHA256 %q: want 64 hex characters", s)
+	}
+	return strings.ToLower(s), nil
+}
+
 // VerifyChecksum verifies that the calculated checksum matches the expected one
 func VerifyChecksum(data []byte, expected string) error {
 	calculated := CalculateSHA256(data)
@@ -45,28 +282,52 @@ func VerifyChecksum(data []byte, expected string) error {
 	return nil
 }
 
-// FindUpstreamChecksum searches for upstream checksums in common locations
-// Returns a map of filename -> checksum
-func FindUpstreamChecksum(releaseURL string) (map[string]string, error) {
-	// Common checksum file patterns
-	patterns := []string{
-		"checksums.txt",
-		"sha256sums.txt",
-		"SHA256SUMS",
-		"SHA256SUMS.txt",
-		"checksums.sha256",
-	}
-
-	// Extract base URL from release URL
-	// e.g., https://github.com/owner/repo/releases/download/v1.0.0/
+// checksumPatterns are common checksum file names published alongside release assets
+var checksumPatterns = []string{
+	"checksums.txt",
+	"sha256sums.txt",
+	"SHA256SUMS",
+	"SHA256SUMS.txt",
+	"checksums.sha256",
+	"SHA512SUMS",
+	"sha512sums.txt",
+}
+
+// ChecksumPatterns returns the checksum file names to try: extra names, e.g.
+// for an org's naming convention, followed by the built-in patterns
+func ChecksumPatterns(extra ...string) []string {
+	var custom []string
+	for _, name := range extra {
+		name = strings.TrimSpace(name)
+		if name != "" && !slices.Contains(custom, name) && !slices.Contains(checksumPatterns, name) {
+			custom = append(custom, name)
+		}
+	}
+	return append(custom, checksumPatterns...)
+}
+
+// checksumURLs returns the candidate checksum file URLs for a release asset URL
+// e.g., https://github.com/owner/repo/releases/download/v1.0.0/checksums.txt
+func checksumURLs(releaseURL string, extra []string) []string {
 	baseURL := releaseURL
 	if idx := strings.LastIndex(releaseURL, "/"); idx != -1 {
 		baseURL = releaseURL[:idx+1]
 	}
 
+	patterns := ChecksumPatterns(extra...)
+	urls := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		urls[i] = baseURL + pattern
+	}
+	return urls
+}
+
+// FindUpstreamChecksum searches for upstream checksums in common locations,
+// trying the extra checksum file names first
+// Returns a map of filename -> checksum
+func FindUpstreamChecksum(releaseURL string, extra ...string) (map[string]string, error) {
 	// Try each pattern
-	for _, pattern := range patterns {
-		checksumURL := baseURL + pattern
+	for _, checksumURL := range checksumURLs(releaseURL, extra) {
 		data, err := DownloadFile(checksumURL)
 		if err != nil {
 			continue // Try next pattern
@@ -82,17 +343,82 @@ func FindUpstreamChecksum(releaseURL string) (map[string]string, error) {
 	return nil, fmt.Errorf("no upstream checksums found")
 }
 
+// ChecksumAttempt records the outcome of looking up one checksum file
+type ChecksumAttempt struct {
+	URL      string // Checksum file URL tried
+	Err      error  // Download error, if the file could not be fetched
+	Entries  int    // Number of checksums parsed from the file
+	Expected string // Checksum listed for the asset, if present
+}
+
+// ExplainUpstreamChecksum tries every checksum location for an asset and
+// records each attempt, unlike FindUpstreamChecksum which stops at the first hit
+// Used to troubleshoot why a checksum did or did not verify
+func ExplainUpstreamChecksum(releaseURL, assetName string, extra ...string) []ChecksumAttempt {
+	var attempts []ChecksumAttempt
+
+	for _, checksumURL := range checksumURLs(releaseURL, extra) {
+		attempt := ChecksumAttempt{URL: checksumURL}
+
+		data, err := DownloadFile(checksumURL)
+		if err != nil {
+			attempt.Err = err
+		} else {
+			checksums := parseChecksumFile(string(data))
+			attempt.Entries = len(checksums)
+			attempt.Expected, _ = LookupChecksum(checksums, assetName)
+		}
+
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts
+}
+
+// WriteExplanation writes a human-readable report of checksum attempts,
+// comparing any listed checksum against the calculated one
+func WriteExplanation(w io.Writer, attempts []ChecksumAttempt, assetName, calculated string) {
+	fmt.Fprintf(w, "Checksum sources for %s:\n", assetName)
+
+	for _, a := range attempts {
+		var outcome string
+		switch {
+		case a.Err != nil:
+			outcome = fmt.Sprintf("not available (%v)", a.Err)
+		case a.Entries == 0:
+			outcome = "downloaded, no checksums parsed"
+		case a.Expected == "":
+			outcome = fmt.Sprintf("%d checksum(s), asset not listed", a.Entries)
+		case Algorithm(a.Expected) == SHA512:
+			outcome = "asset listed with a SHA512 checksum (not compared here)"
+		case a.Expected == calculated:
+			outcome = "asset listed, checksum matches"
+		default:
+			outcome = fmt.Sprintf("asset listed, checksum MISMATCH (expected %s)", a.Expected)
+		}
+		fmt.Fprintf(w, "  %s: %s\n", a.URL, outcome)
+	}
+}
+
 // parseChecksumFile parses a checksum file in various formats
 // Supports:
 // - "checksum  filename" (two spaces, common in sha256sum output)
 // - "checksum *filename" (asterisk for binary mode)
 // - "checksum filename" (single space)
+// Checksums may be SHA256 or SHA512 (see Algorithm); when a file is listed
+// with both, the SHA256 is kept since that is what Homebrew uses.
 func parseChecksumFile(content string) map[string]string {
 	checksums := make(map[string]string)
 
 	// Regular expression to match checksum lines
-	// Matches: <64-char hex> <whitespace or *> <filename>
-	re := regexp.MustCompile(`([a-fA-F0-9]{64})\s+[\*]?(.+)`)
+	// Matches: <64 or 128-char hex> <whitespace or *> <filename>
+	// The hash must not be part of a longer one, like a SHA384
+	re := regexp.MustCompile(`(?:^|[^a-fA-F0-9])([a-fA-F0-9]{128}|[a-fA-F0-9]{64})\s+[\*]?(.+)`)
+
+	// Files authored on Windows use CRLF and some tools write bare CR;
+	// treat both as line breaks so a \r can't glue entries into one filename
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
 
 	lines := strings.Split(content, "\n")
 	for _, line := range lines {
@@ -104,7 +430,12 @@ func parseChecksumFile(content string) map[string]string {
 		matches := re.FindStringSubmatch(line)
 		if len(matches) == 3 {
 			checksum := strings.ToLower(matches[1])
+			// Keep the listed path so linux/tool and darwin/tool stay
+			// distinct; LookupChecksum matches on the basename
 			filename := strings.TrimSpace(matches[2])
+			if Algorithm(checksums[filename]) == SHA256 && Algorithm(checksum) == SHA512 {
+				continue
+			}
 			checksums[filename] = checksum
 		}
 	}
@@ -112,16 +443,86 @@ func parseChecksumFile(content string) map[string]string {
 	return checksums
 }
 
+// ParseChecksumsFromBody parses checksums pasted into release notes, e.g.
+// sha256sum output in a fenced code block. Markdown backticks around the
+// hash or filename are ignored.
+// Returns a map of filename -> checksum
+func ParseChecksumsFromBody(body string) map[string]string {
+	return parseChecksumFile(strings.ReplaceAll(body, "`", ""))
+}
+
+// LookupUpstreamChecksum finds the checksum listed for an asset in the
+// checksum files published next to it, falling back to checksums pasted
+// into the release notes. extra checksum file names are tried first. source
+// describes where it was found.
+func LookupUpstreamChecksum(releaseURL, releaseBody, assetName string, extra ...string) (expected, source string, found bool) {
+	if checksums, err := FindUpstreamChecksum(releaseURL, extra...); err == nil {
+		if expected, found := LookupChecksum(checksums, assetName); found {
+			return expected, "upstream checksum file", true
+		}
+	}
+
+	if expected, found := LookupChecksum(ParseChecksumsFromBody(releaseBody), assetName); found {
+		return expected, "release notes", true
+	}
+
+	return "", "", false
+}
+
+// CheckUpstream looks up the upstream checksum of a download with
+// LookupUpstreamChecksum and verifies the download against it. assetPath is
+// the downloaded file, "" when the download was skipped. It returns a
+// message for the user; found is false when no checksum is published, and
+// warn is set when only a SHA512 checksum is, which Homebrew cannot use.
+func CheckUpstream(downloadURL, releaseBody, assetName, assetPath, sha256sum string, extra ...string) (message string, found, warn bool, err error) {
+	expected, source, found := LookupUpstreamChecksum(downloadURL, releaseBody, assetName, extra...)
+	if !found {
+		return "No upstream checksum listed for this file (not an error)", false, false, nil
+	}
+
+	verified, err := VerifyUpstream(assetPath, sha256sum, expected)
+	if err != nil {
+		return "", true, false, fmt.Errorf("failed to verify against %s: %w", source, err)
+	}
+	if Algorithm(expected) == SHA512 {
+		// Homebrew only understands sha256, which is calculated locally
+		if verified {
+			return fmt.Sprintf("Only a SHA512 checksum is published; verified against %s, using the calculated SHA256", source), true, true, nil
+		}
+		return fmt.Sprintf("Only a SHA512 checksum is published in %s; it needs the download to verify (skipped with --sha256)", source), true, true, nil
+	}
+	return fmt.Sprintf("Checksum verified against %s!", source), true, false, nil
+}
+
+// LookupChecksum finds the checksum listed for an asset, falling back to a
+// case-insensitive match on the basename of each entry. An ambiguous
+// fallback match is treated as not found.
+func LookupChecksum(checksums map[string]string, assetName string) (string, bool) {
+	if expected, found := checksums[assetName]; found {
+		return expected, true
+	}
+
+	var match string
+	for name, expected := range checksums {
+		if !strings.EqualFold(path.Base(name), assetName) {
+			continue
+		}
+		if match != "" && match != expected {
+			return "", false
+		}
+		match = expected
+	}
+	return match, match != ""
+}
+
 // VerifyFromUpstream downloads a file and verifies it against upstream checksums
 func VerifyFromUpstream(downloadURL, filename string, releaseURL string) (sha256sum string, verified bool, err error) {
-	// Download the file
-	data, err := DownloadFile(downloadURL)
+	// Download the file and calculate its checksum
+	calculated, _, tmpPath, err := DownloadAndHash(downloadURL)
 	if err != nil {
 		return "", false, fmt.Errorf("failed to download file: %w", err)
 	}
-
-	// Calculate checksum
-	calculated := CalculateSHA256(data)
+	defer os.Remove(tmpPath)
 
 	// Try to find upstream checksum
 	upstreamChecksums, err := FindUpstreamChecksum(releaseURL)
@@ -131,11 +532,9 @@ func VerifyFromUpstream(downloadURL, filename string, releaseURL string) (sha256
 	}
 
 	// Look for this file in upstream checksums
-	if expected, found := upstreamChecksums[filename]; found {
-		if calculated != expected {
-			return calculated, false, fmt.Errorf("checksum mismatch: expected %s, got %s", expected, calculated)
-		}
-		return calculated, true, nil
+	if expected, found := LookupChecksum(upstreamChecksums, filename); found {
+		verified, err := VerifyUpstream(tmpPath, calculated, expected)
+		return calculated, verified, err
 	}
 
 	// File not in upstream checksums, but we have calculated one
@@ -1,6 +1,20 @@
 package checksum
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 )
 
@@ -37,6 +51,12 @@ func TestCalculateSHA256(t *testing.T) {
 	}
 }
 
+// sha512Hex returns the hex-encoded SHA512 checksum of data
+func sha512Hex(data []byte) string {
+	hash := sha512.Sum512(data)
+	return hex.EncodeToString(hash[:])
+}
+
 func TestVerifyChecksum(t *testing.T) {
 	data := []byte("Hello World")
 	correctSum := "a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e"
@@ -78,6 +98,172 @@ func TestVerifyChecksum(t *testing.T) {
 	}
 }
 
+func TestParseSHA256(t *testing.T) {
+	const sum = "a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e"
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"Lowercase", sum, sum, false},
+		{"Uppercase is normalized", strings.ToUpper(sum), sum, false},
+		{"Too short", sum[:63], "", true},
+		{"Non-hex", "z" + sum[1:], "", true},
+		{"Empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSHA256(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSHA256() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSHA256() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// patternReader yields an endless repeating byte pattern
+type patternReader struct {
+	next byte
+}
+
+func (r *patternReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.next
+		r.next++
+	}
+	return len(p), nil
+}
+
+func TestDownloadAndHash(t *testing.T) {
+	fastRetries(t)
+
+	// Large enough that holding it in memory would show up, small enough
+	// to stay quick
+	const size = 64 << 20
+	hash := sha256.New()
+	io.CopyN(hash, &patternReader{}, size)
+	want := hex.EncodeToString(hash.Sum(nil))
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(size))
+		if requests.Add(1) == 1 {
+			// Drop the connection part way through; the retry must not
+			// append to the partial file
+			io.CopyN(w, &patternReader{next: 7}, size/4)
+			panic(http.ErrAbortHandler)
+		}
+		io.CopyN(w, &patternReader{}, size)
+	}))
+	defer server.Close()
+
+	t.Run("Streams to a temp file", func(t *testing.T) {
+		requests.Store(0)
+		sum, gotSize, tmpPath, err := DownloadAndHash(server.URL + "/app.tar.gz")
+		if err != nil {
+			t.Fatalf("DownloadAndHash() error = %v", err)
+		}
+		defer os.Remove(tmpPath)
+
+		if sum != want {
+			t.Errorf("DownloadAndHash() sha256 = %s, want %s", sum, want)
+		}
+		if gotSize != size {
+			t.Errorf("DownloadAndHash() size = %d, want %d", gotSize, size)
+		}
+		if requests.Load() != 2 {
+			t.Errorf("DownloadAndHash() made %d requests, want 2", requests.Load())
+		}
+		if !strings.HasSuffix(tmpPath, ".gz") {
+			t.Errorf("DownloadAndHash() path = %s, want the asset's extension", tmpPath)
+		}
+
+		file, err := os.Open(tmpPath)
+		if err != nil {
+			t.Fatalf("Failed to open downloaded file: %v", err)
+		}
+		defer file.Close()
+		fileHash := sha256.New()
+		n, err := io.Copy(fileHash, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != size || hex.EncodeToString(fileHash.Sum(nil)) != want {
+			t.Errorf("Temp file has %d bytes that do not match the download", n)
+		}
+	})
+
+	t.Run("Removes the file on failure", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("TMPDIR", dir)
+		failing := httptest.NewServer(http.NotFoundHandler())
+		defer failing.Close()
+
+		_, _, _, err := DownloadAndHash(failing.URL + "/app.tar.gz")
+		if err == nil {
+			t.Fatal("DownloadAndHash() expected error for HTTP 404")
+		}
+		if !IsNotFound(err) {
+			t.Errorf("IsNotFound(%v) = false, want true", err)
+		}
+		if left, _ := os.ReadDir(dir); len(left) != 0 {
+			t.Errorf("DownloadAndHash() left %d temp file(s) behind", len(left))
+		}
+	})
+}
+
+func TestDownloadFileWithProgress(t *testing.T) {
+	const size = 1 << 20
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sized" {
+			w.Header().Set("Content-Length", strconv.Itoa(size))
+		} else {
+			// Flushing first makes the response chunked, without a length
+			w.(http.Flusher).Flush()
+		}
+		io.CopyN(w, &patternReader{}, size)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		path      string
+		wantTotal int64
+	}{
+		{"Content-Length", "/sized", size},
+		{"No Content-Length", "/chunked", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			var last, total int64
+			data, err := DownloadFileWithProgress(server.URL+tt.path, func(downloaded, n int64) {
+				if downloaded < last {
+					t.Errorf("progress went back from %d to %d", last, downloaded)
+				}
+				calls++
+				last, total = downloaded, n
+			})
+			if err != nil {
+				t.Fatalf("DownloadFileWithProgress() error = %v", err)
+			}
+			if len(data) != size {
+				t.Errorf("DownloadFileWithProgress() returned %d bytes, want %d", len(data), size)
+			}
+			if calls < 2 || last != size || total != tt.wantTotal {
+				t.Errorf("progress ended at %d/%d after %d calls, want %d/%d", last, total, calls, size, tt.wantTotal)
+			}
+		})
+	}
+}
+
 func TestParseChecksumFile(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -111,6 +297,40 @@ d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592  file2.deb`,
 				"file2.deb":    "d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592",
 			},
 		},
+		{
+			name:    "Windows CRLF line endings",
+			content: "# SHA256 checksums\r\na591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e  file1.tar.gz\r\nd7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592 *file2.deb\r\n",
+			want: map[string]string{
+				"file1.tar.gz": "a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e",
+				"file2.deb":    "d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592",
+			},
+		},
+		{
+			name: "Leading ./ and directory prefixes",
+			content: `a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e  ./file1.tar.gz
+d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592 *dist/linux/file2.deb`,
+			want: map[string]string{
+				"./file1.tar.gz":       "a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e",
+				"dist/linux/file2.deb": "d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592",
+			},
+		},
+		{
+			name: "Same basename in different directories",
+			content: `a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e  linux/tool
+d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592  darwin/tool`,
+			want: map[string]string{
+				"linux/tool":  "a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e",
+				"darwin/tool": "d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592",
+			},
+		},
+		{
+			name:    "Bare CR line endings",
+			content: "a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e  file1.tar.gz\rd7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592  file2.deb\r",
+			want: map[string]string{
+				"file1.tar.gz": "a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e",
+				"file2.deb":    "d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592",
+			},
+		},
 		{
 			name:    "Empty content",
 			content: "",
@@ -129,6 +349,11 @@ d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592  file2.deb`,
 			if len(got) != len(tt.want) {
 				t.Errorf("parseChecksumFile() returned %d items, want %d", len(got), len(tt.want))
 			}
+			for filename := range got {
+				if strings.ContainsRune(filename, '\r') {
+					t.Errorf("parseChecksumFile() filename %q contains a carriage return", filename)
+				}
+			}
 			for filename, checksum := range tt.want {
 				if got[filename] != checksum {
 					t.Errorf("parseChecksumFile()[%q] = %q, want %q", filename, got[filename], checksum)
@@ -137,3 +362,361 @@ d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592  file2.deb`,
 		})
 	}
 }
+
+func TestParseChecksumFileSHA512(t *testing.T) {
+	data := []byte("Hello World")
+	sum256 := CalculateSHA256(data)
+	sum512 := sha512Hex(data)
+	other512 := sha512Hex([]byte("other"))
+
+	content := fmt.Sprintf("%s  tool.tar.gz\n%s  tool.tar.gz\n%s *tool.deb\n%s  tool.sha384\n",
+		sum512, sum256, other512, strings.Repeat("a", 96))
+
+	got := parseChecksumFile(content)
+	want := map[string]string{
+		"tool.tar.gz": sum256,
+		"tool.deb":    other512,
+	}
+	if len(got) != len(want) {
+		t.Errorf("parseChecksumFile() = %v, want %d entries", got, len(want))
+	}
+	for filename, checksum := range want {
+		if got[filename] != checksum {
+			t.Errorf("parseChecksumFile()[%q] = %q, want %q", filename, got[filename], checksum)
+		}
+	}
+}
+
+func TestAlgorithm(t *testing.T) {
+	tests := []struct {
+		hash string
+		want string
+	}{
+		{CalculateSHA256([]byte("x")), SHA256},
+		{sha512Hex([]byte("x")), SHA512},
+		{"abc123", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Algorithm(tt.hash); got != tt.want {
+			t.Errorf("Algorithm(%q) = %q, want %q", tt.hash, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyUpstream(t *testing.T) {
+	data := []byte("Hello World")
+	sum256 := CalculateSHA256(data)
+	sum512 := sha512Hex(data)
+	wrong512 := sha512Hex([]byte("other"))
+	path := filepath.Join(t.TempDir(), "asset")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		expected     string
+		wantVerified bool
+		wantErr      bool
+	}{
+		{"SHA256 match", path, sum256, true, false},
+		{"SHA256 match uppercase", path, strings.ToUpper(sum256), true, false},
+		{"SHA256 mismatch", path, strings.Repeat("0", 64), false, true},
+		{"SHA256 without the download", "", sum256, true, false},
+		{"SHA512 match", path, sum512, true, false},
+		{"SHA512 mismatch", path, wrong512, false, true},
+		{"SHA512 without the download", "", sum512, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verified, err := VerifyUpstream(tt.path, sum256, tt.expected)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyUpstream() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if verified != tt.wantVerified {
+				t.Errorf("VerifyUpstream() verified = %v, want %v", verified, tt.wantVerified)
+			}
+		})
+	}
+}
+
+func TestFindUpstreamChecksumSHA512Only(t *testing.T) {
+	const asset = "tool-1.0.0-linux-amd64.tar.gz"
+	data := []byte("Hello World")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/releases/download/v1.0.0/SHA512SUMS", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  %s\n", sha512Hex(data), asset)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	checksums, err := FindUpstreamChecksum(server.URL + "/releases/download/v1.0.0/" + asset)
+	if err != nil {
+		t.Fatalf("FindUpstreamChecksum() error = %v", err)
+	}
+	expected, found := LookupChecksum(checksums, asset)
+	if !found || Algorithm(expected) != SHA512 {
+		t.Fatalf("LookupChecksum() = %q, %v, want a SHA512 checksum", expected, found)
+	}
+	path := filepath.Join(t.TempDir(), asset)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if verified, err := VerifyUpstream(path, CalculateSHA256(data), expected); !verified || err != nil {
+		t.Errorf("VerifyUpstream() = %v, %v, want verified", verified, err)
+	}
+}
+
+func TestParseChecksumsFromBody(t *testing.T) {
+	const (
+		sumAMD64 = "a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e"
+		sumARM64 = "d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592"
+		sumDeb   = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	)
+	sha512 := strings.Repeat("f", 64) + sumAMD64
+
+	body := "## What's Changed\r\n" +
+		"* Fix crash on startup by @someone in https://github.com/example/tool/pull/42\r\n" +
+		"\r\n" +
+		"## Checksums\r\n" +
+		"\r\n" +
+		"```\r\n" +
+		sumAMD64 + "  tool-1.2.0-linux-amd64.tar.gz\r\n" +
+		sumARM64 + "  tool-1.2.0-linux-arm64.tar.gz\r\n" +
+		"```\r\n" +
+		"\r\n" +
+		"SHA512:\r\n" +
+		"```\r\n" +
+		sha512 + "  tool-1.2.0-linux-arm64.tar.gz\r\n" +
+		"```\r\n" +
+		"\r\n" +
+		"- `" + sumDeb + "` tool_1.2.0_amd64.deb\r\n" +
+		"\r\n" +
+		"**Full Changelog**: https://github.com/example/tool/compare/v1.1.0...v1.2.0\r\n"
+
+	got := ParseChecksumsFromBody(body)
+	want := map[string]string{
+		"tool-1.2.0-linux-amd64.tar.gz": sumAMD64,
+		"tool-1.2.0-linux-arm64.tar.gz": sumARM64,
+		"tool_1.2.0_amd64.deb":          sumDeb,
+	}
+	if len(got) != len(want) {
+		t.Errorf("ParseChecksumsFromBody() = %v, want %d entries", got, len(want))
+	}
+	for filename, checksum := range want {
+		if got[filename] != checksum {
+			t.Errorf("ParseChecksumsFromBody()[%q] = %q, want %q", filename, got[filename], checksum)
+		}
+	}
+
+	if got := ParseChecksumsFromBody("Bug fixes and performance improvements."); len(got) != 0 {
+		t.Errorf("ParseChecksumsFromBody() = %v, want no checksums", got)
+	}
+}
+
+func TestLookupUpstreamChecksum(t *testing.T) {
+	const asset = "tool-1.0.0-linux-amd64.tar.gz"
+	sum := CalculateSHA256([]byte("Hello World"))
+	body := "Checksums:\n```\n" + sum + "  " + asset + "\n```\n"
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/with-file/checksums.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  %s\n", strings.Repeat("1", 64), asset)
+	})
+	mux.HandleFunc("/other-file/checksums.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  other.tar.gz\n", strings.Repeat("1", 64))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		dir        string
+		body       string
+		want       string
+		wantSource string
+		wantFound  bool
+	}{
+		{"Checksum file wins over release notes", "with-file", body, strings.Repeat("1", 64), "upstream checksum file", true},
+		{"Release notes when asset not in checksum file", "other-file", body, sum, "release notes", true},
+		{"Release notes when no checksum file", "none", body, sum, "release notes", true},
+		{"Not listed anywhere", "none", "Bug fixes.", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, source, found := LookupUpstreamChecksum(server.URL+"/"+tt.dir+"/"+asset, tt.body, asset)
+			if got != tt.want || source != tt.wantSource || found != tt.wantFound {
+				t.Errorf("LookupUpstreamChecksum() = %q, %q, %v, want %q, %q, %v",
+					got, source, found, tt.want, tt.wantSource, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestCheckUpstream(t *testing.T) {
+	const asset = "tool-1.0.0-linux-amd64.tar.gz"
+	data := []byte("Hello World")
+	sum := CalculateSHA256(data)
+	path := filepath.Join(t.TempDir(), asset)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	listed := func(hash string) string { return hash + "  " + asset + "\n" }
+
+	tests := []struct {
+		name      string
+		body      string
+		path      string
+		wantFound bool
+		wantWarn  bool
+		wantErr   bool
+		want      string
+	}{
+		{"Not listed", "Bug fixes.", path, false, false, false, "No upstream checksum listed"},
+		{"SHA256 verified", listed(sum), path, true, false, false, "Checksum verified against release notes"},
+		{"SHA256 mismatch", listed(strings.Repeat("0", 64)), path, true, false, true, ""},
+		{"SHA512 verified", listed(sha512Hex(data)), path, true, true, false, "verified against release notes"},
+		{"SHA512 without the download", listed(sha512Hex(data)), "", true, true, false, "needs the download to verify"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, found, warn, err := CheckUpstream(server.URL+"/"+asset, tt.body, asset, tt.path, sum)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckUpstream() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if found != tt.wantFound || warn != tt.wantWarn {
+				t.Errorf("CheckUpstream() found, warn = %v, %v, want %v, %v", found, warn, tt.wantFound, tt.wantWarn)
+			}
+			if !strings.Contains(message, tt.want) {
+				t.Errorf("CheckUpstream() message = %q, want it to contain %q", message, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupChecksum(t *testing.T) {
+	const (
+		sumA = "a591a6d40bf420404a011733cfb7b190d62c65bf0bcda32b57b277d9ad9f146e"
+		sumB = "d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592"
+	)
+
+	tests := []struct {
+		name      string
+		checksums map[string]string
+		asset     string
+		want      string
+		wantFound bool
+	}{
+		{"Exact match", map[string]string{"Tool.tar.gz": sumA, "tool.tar.gz": sumB}, "tool.tar.gz", sumB, true},
+		{"Case mismatch", map[string]string{"Tool-Linux-AMD64.tar.gz": sumA}, "tool-linux-amd64.tar.gz", sumA, true},
+		{"Path-prefixed key", map[string]string{"dist/tool.tar.gz": sumA}, "tool.tar.gz", sumA, true},
+		{"Path-prefixed key with case mismatch", map[string]string{"./dist/TOOL.tar.gz": sumA}, "tool.tar.gz", sumA, true},
+		{"Ambiguous fallback", map[string]string{"a/TOOL.tar.gz": sumA, "b/tool.TAR.gz": sumB}, "tool.tar.gz", "", false},
+		{"Not listed", map[string]string{"other.tar.gz": sumA}, "tool.tar.gz", "", false},
+		{"Empty map", nil, "tool.tar.gz", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := LookupChecksum(tt.checksums, tt.asset)
+			if found != tt.wantFound || got != tt.want {
+				t.Errorf("LookupChecksum() = %q, %v, want %q, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestExplainUpstreamChecksum(t *testing.T) {
+	const asset = "tool-1.0.0-linux-amd64.tar.gz"
+	sum := CalculateSHA256([]byte("Hello World"))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/releases/download/v1.0.0/checksums.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  tool-1.0.0-darwin-arm64.tar.gz\n", strings.Repeat("0", 64))
+	})
+	mux.HandleFunc("/releases/download/v1.0.0/SHA256SUMS", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  %s\n", sum, asset)
+	})
+	mux.HandleFunc("/releases/download/v1.0.0/checksums.sha256", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  %s\n", strings.Repeat("1", 64), asset)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	attempts := ExplainUpstreamChecksum(server.URL+"/releases/download/v1.0.0/"+asset, asset)
+	if len(attempts) != len(checksumPatterns) {
+		t.Fatalf("ExplainUpstreamChecksum() tried %d sources, want %d", len(attempts), len(checksumPatterns))
+	}
+
+	var buf bytes.Buffer
+	WriteExplanation(&buf, attempts, asset, sum)
+	out := buf.String()
+
+	wantLines := []string{
+		"checksums.txt: 1 checksum(s), asset not listed",
+		"sha256sums.txt: not available",
+		"SHA256SUMS: asset listed, checksum matches",
+		"SHA256SUMS.txt: not available",
+		"checksums.sha256: asset listed, checksum MISMATCH",
+	}
+	for _, line := range wantLines {
+		if !strings.Contains(out, line) {
+			t.Errorf("WriteExplanation() missing %q in:\n%s", line, out)
+		}
+	}
+}
+
+func TestChecksumPatterns(t *testing.T) {
+	original := slices.Clone(checksumPatterns)
+
+	patterns := ChecksumPatterns("tool_1.0.0_SHA256.txt", " ", "checksums.txt", "tool_1.0.0_SHA256.txt")
+	if len(patterns) != len(original)+1 {
+		t.Errorf("ChecksumPatterns() has %d entries, want %d (duplicates and blanks skipped)", len(patterns), len(original)+1)
+	}
+	if patterns[0] != "tool_1.0.0_SHA256.txt" {
+		t.Errorf("custom pattern should be tried first, got %v", patterns)
+	}
+	if !slices.Equal(checksumPatterns, original) {
+		t.Errorf("ChecksumPatterns() changed the built-in patterns to %v", checksumPatterns)
+	}
+}
+
+func TestFindUpstreamChecksumExtraPattern(t *testing.T) {
+	const asset = "tool-1.0.0-linux-amd64.tar.gz"
+	sum := CalculateSHA256([]byte("Hello World"))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/releases/download/v1.0.0/tool_1.0.0_SHA256.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  %s\n", sum, asset)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	releaseURL := server.URL + "/releases/download/v1.0.0/" + asset
+
+	// The org-specific name is not among the built-in patterns
+	if _, err := FindUpstreamChecksum(releaseURL); err == nil {
+		t.Fatal("FindUpstreamChecksum() found checksums without the custom pattern")
+	}
+
+	checksums, err := FindUpstreamChecksum(releaseURL, "tool_1.0.0_SHA256.txt")
+	if err != nil {
+		t.Fatalf("FindUpstreamChecksum() error = %v", err)
+	}
+	if checksums[asset] != sum {
+		t.Errorf("FindUpstreamChecksum()[%q] = %q, want %q", asset, checksums[asset], sum)
+	}
+
+	if got, source, found := LookupUpstreamChecksum(releaseURL, "", asset, "tool_1.0.0_SHA256.txt"); !found || got != sum {
+		t.Errorf("LookupUpstreamChecksum() = %q, %q, %v, want %q from the checksum file", got, source, found, sum)
+	}
+}
@@ -0,0 +1,103 @@
+package checksum
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"time"
+)
+
+// CABundleEnv names the environment variable holding an extra CA bundle
+const CABundleEnv = "TAP_CA_BUNDLE"
+
+// httpClient is used for all downloads
+var httpClient = http.DefaultClient
+
+// downloadAttempts is how many times a transient download failure is tried
+const downloadAttempts = 3
+
+var (
+	// retryBaseDelay is the wait before the first retry, doubled for each
+	// further retry
+	retryBaseDelay = time.Second
+
+	// downloadTimeout bounds a whole download, including retries
+	downloadTimeout = 10 * time.Minute
+)
+
+// ConfigureDownloadTimeout sets how long a download may take in total,
+// including retries. Zero disables the limit.
+func ConfigureDownloadTimeout(timeout time.Duration) {
+	downloadTimeout = timeout
+}
+
+// backoff waits before the given retry (1 for the first), returning early
+// with an error if ctx ends. Up to 50% jitter spreads out concurrent retries.
+func backoff(ctx context.Context, retry int) error {
+	delay := retryBaseDelay << (retry - 1)
+	delay += rand.N(delay/2 + 1)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// NewHTTPClient returns an HTTP client that trusts the system roots plus the
+// PEM certificates in caFile, for hosts behind a corporate CA
+func NewHTTPClient(caFile string) (*http.Client, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", caFile)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// ConfigureCABundle makes downloads, and API clients created afterwards,
+// trust the certificates in caFile, or in the file named by TAP_CA_BUNDLE
+// when caFile is empty. With neither set, only the system roots are trusted.
+// The error names the flag or variable the bundle came from.
+func ConfigureCABundle(caFile string) error {
+	source := "--ca-cert"
+	if caFile == "" {
+		caFile = os.Getenv(CABundleEnv)
+		source = "$" + CABundleEnv
+	}
+	if caFile == "" {
+		return nil
+	}
+
+	client, err := NewHTTPClient(caFile)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", source, err)
+	}
+	httpClient = client
+	return nil
+}
+
+// HTTPClient returns the client set up by ConfigureCABundle, so API clients
+// trust the same hosts as downloads
+func HTTPClient() *http.Client {
+	return httpClient
+}
@@ -0,0 +1,216 @@
+package checksum
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// writeServerCA writes the test server's self-signed certificate as a PEM bundle
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write CA bundle: %v", err)
+	}
+	return path
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "asset")
+	}))
+	defer server.Close()
+
+	// The self-signed certificate is untrusted without the bundle
+	if _, err := http.Get(server.URL); err == nil {
+		t.Fatal("Expected TLS verification to fail without the CA bundle")
+	}
+
+	client, err := NewHTTPClient(writeServerCA(t, server))
+	if err != nil {
+		t.Fatalf("NewHTTPClient() error = %v", err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() with CA bundle error = %v", err)
+	}
+	resp.Body.Close()
+
+	t.Run("Missing file", func(t *testing.T) {
+		if _, err := NewHTTPClient(filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+			t.Error("NewHTTPClient() expected error for a missing file")
+		}
+	})
+
+	t.Run("No certificates", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "empty.pem")
+		if err := os.WriteFile(path, []byte("not a certificate"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewHTTPClient(path); err == nil {
+			t.Error("NewHTTPClient() expected error for a file without certificates")
+		}
+	})
+}
+
+func TestConfigureCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "asset")
+	}))
+	defer server.Close()
+
+	original := httpClient
+	t.Cleanup(func() { httpClient = original })
+
+	t.Run("Unset keeps the default client", func(t *testing.T) {
+		t.Setenv(CABundleEnv, "")
+		if err := ConfigureCABundle(""); err != nil {
+			t.Fatalf("ConfigureCABundle() error = %v", err)
+		}
+		if httpClient != original {
+			t.Error("ConfigureCABundle() replaced the client without a bundle")
+		}
+	})
+
+	t.Run("Environment variable", func(t *testing.T) {
+		t.Setenv(CABundleEnv, writeServerCA(t, server))
+		if err := ConfigureCABundle(""); err != nil {
+			t.Fatalf("ConfigureCABundle() error = %v", err)
+		}
+
+		data, err := DownloadFile(server.URL)
+		if err != nil {
+			t.Fatalf("DownloadFile() error = %v", err)
+		}
+		if string(data) != "asset" {
+			t.Errorf("DownloadFile() = %q, want %q", data, "asset")
+		}
+		if HTTPClient() != httpClient {
+			t.Error("HTTPClient() does not return the configured client")
+		}
+	})
+
+	t.Run("Errors name the source", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing.pem")
+		t.Setenv(CABundleEnv, missing)
+		if err := ConfigureCABundle(""); err == nil || !strings.Contains(err.Error(), "invalid $"+CABundleEnv) {
+			t.Errorf("ConfigureCABundle() error = %v, want it to name $%s", err, CABundleEnv)
+		}
+		if err := ConfigureCABundle(missing); err == nil || !strings.Contains(err.Error(), "invalid --ca-cert") {
+			t.Errorf("ConfigureCABundle() error = %v, want it to name --ca-cert", err)
+		}
+	})
+}
+
+// fastRetries shortens the retry delay for the duration of a test
+func fastRetries(t *testing.T) {
+	t.Helper()
+	original := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	t.Cleanup(func() { retryBaseDelay = original })
+}
+
+func TestDownloadFileRetries(t *testing.T) {
+	fastRetries(t)
+
+	tests := []struct {
+		name         string
+		failures     int
+		status       int
+		wantRequests int32
+		wantErr      string
+	}{
+		{"Succeeds after two 5xx", 2, http.StatusBadGateway, 3, ""},
+		{"Succeeds after rate limiting", 1, http.StatusTooManyRequests, 2, ""},
+		{"Gives up after three 5xx", 3, http.StatusServiceUnavailable, 3, "gave up after 3 attempts"},
+		{"404 fails fast", 3, http.StatusNotFound, 1, "HTTP 404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if int(requests.Add(1)) <= tt.failures {
+					w.WriteHeader(tt.status)
+					return
+				}
+				fmt.Fprint(w, "asset")
+			}))
+			defer server.Close()
+
+			data, err := DownloadFile(server.URL)
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("DownloadFile() made %d requests, want %d", got, tt.wantRequests)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("DownloadFile() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DownloadFile() error = %v", err)
+			}
+			if string(data) != "asset" {
+				t.Errorf("DownloadFile() = %q, want %q", data, "asset")
+			}
+		})
+	}
+}
+
+func TestDownloadFileRetriesConnectionErrors(t *testing.T) {
+	fastRetries(t)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			// Drop the connection without a response
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		fmt.Fprint(w, "asset")
+	}))
+	defer server.Close()
+
+	data, err := DownloadFile(server.URL)
+	if err != nil {
+		t.Fatalf("DownloadFile() error = %v", err)
+	}
+	if string(data) != "asset" || requests.Load() != 2 {
+		t.Errorf("DownloadFile() = %q after %d requests, want %q after 2", data, requests.Load(), "asset")
+	}
+}
+
+func TestConfigureDownloadTimeout(t *testing.T) {
+	originalDelay, originalTimeout := retryBaseDelay, downloadTimeout
+	retryBaseDelay = time.Minute
+	t.Cleanup(func() { retryBaseDelay, downloadTimeout = originalDelay, originalTimeout })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// The total timeout cuts the backoff short instead of waiting a minute
+	ConfigureDownloadTimeout(50 * time.Millisecond)
+	start := time.Now()
+	_, err := DownloadFile(server.URL)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("DownloadFile() error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("DownloadFile() took %v, want it bounded by the timeout", elapsed)
+	}
+}
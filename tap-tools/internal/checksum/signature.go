@@ -0,0 +1,160 @@
+package checksum
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"golang.org/x/crypto/blake2b"
+)
+
+// ErrNoSignature is returned when no signature is published next to a download
+var ErrNoSignature = errors.New("no signature file found")
+
+// VerifySignature checks the file at path, downloaded from downloadURL, against a
+// detached signature published next to it. The public key file selects the
+// scheme: an armored OpenPGP key looks for <url>.asc and <url>.sig, anything
+// else is read as a minisign key and looks for <url>.minisig.
+// Returns the URL of the signature that verified.
+func VerifySignature(downloadURL, path, keyFile string) (string, error) {
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read public key: %w", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read downloaded file: %w", err)
+	}
+	defer file.Close()
+
+	var verify func(data io.Reader, sig []byte) error
+	var exts []string
+	if bytes.Contains(key, []byte("-----BEGIN PGP PUBLIC KEY BLOCK-----")) {
+		keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(key))
+		if err != nil {
+			return "", fmt.Errorf("failed to parse OpenPGP key: %w", err)
+		}
+		verify = func(data io.Reader, sig []byte) error { return verifyPGP(keyring, data, sig) }
+		exts = []string{".asc", ".sig"}
+	} else {
+		pub, err := parseMinisignKey(key)
+		if err != nil {
+			return "", err
+		}
+		verify = pub.verify
+		exts = []string{".minisig"}
+	}
+
+	for _, ext := range exts {
+		sigURL := downloadURL + ext
+		sig, err := DownloadFile(sigURL)
+		if err != nil {
+			continue
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return "", fmt.Errorf("failed to read downloaded file: %w", err)
+		}
+		if err := verify(file, sig); err != nil {
+			return "", fmt.Errorf("bad signature %s: %w", sigURL, err)
+		}
+		return sigURL, nil
+	}
+
+	return "", fmt.Errorf("%w for %s (tried %s)", ErrNoSignature, downloadURL, strings.Join(exts, ", "))
+}
+
+// verifyPGP checks an armored (.asc) or binary (.sig) detached signature
+func verifyPGP(keyring openpgp.EntityList, data io.Reader, sig []byte) error {
+	var err error
+	if bytes.HasPrefix(bytes.TrimSpace(sig), []byte("-----BEGIN PGP SIGNATURE-----")) {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyring, data, bytes.NewReader(sig), nil)
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyring, data, bytes.NewReader(sig), nil)
+	}
+	return err
+}
+
+// minisignKey is a minisign Ed25519 public key
+type minisignKey struct {
+	id  []byte
+	key ed25519.PublicKey
+}
+
+// parseMinisignKey reads a minisign public key file, or a bare base64 key
+func parseMinisignKey(data []byte) (*minisignKey, error) {
+	var encoded string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "untrusted comment:") {
+			encoded = line
+		}
+	}
+
+	// "Ed" algorithm, 8-byte key ID, 32-byte Ed25519 key
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) != 2+8+ed25519.PublicKeySize || string(raw[:2]) != "Ed" {
+		return nil, fmt.Errorf("public key is neither an armored OpenPGP key nor a minisign key")
+	}
+
+	return &minisignKey{id: raw[2:10], key: ed25519.PublicKey(raw[10:])}, nil
+}
+
+// verify checks a .minisig file: the signature over the file (or its BLAKE2b
+// hash for prehashed signatures) and the signature over the trusted comment.
+// Only legacy non-prehashed signatures need the whole file in memory.
+func (k *minisignKey) verify(data io.Reader, sigFile []byte) error {
+	lines := strings.Split(strings.ReplaceAll(string(sigFile), "\r\n", "\n"), "\n")
+	if len(lines) < 4 {
+		return fmt.Errorf("malformed minisign signature")
+	}
+
+	// Algorithm, 8-byte key ID, 64-byte signature
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return fmt.Errorf("malformed minisign signature")
+	}
+	if !bytes.Equal(sig[2:10], k.id) {
+		return fmt.Errorf("signed with a different key (ID %X)", sig[2:10])
+	}
+
+	var message []byte
+	switch string(sig[:2]) {
+	case "ED":
+		hash, _ := blake2b.New512(nil)
+		if _, err := io.Copy(hash, data); err != nil {
+			return fmt.Errorf("failed to read downloaded file: %w", err)
+		}
+		message = hash.Sum(nil)
+	case "Ed":
+		if message, err = io.ReadAll(data); err != nil {
+			return fmt.Errorf("failed to read downloaded file: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported minisign algorithm %q", sig[:2])
+	}
+	if !ed25519.Verify(k.key, message, sig[10:]) {
+		return fmt.Errorf("signature does not match")
+	}
+
+	trusted, ok := strings.CutPrefix(lines[2], "trusted comment: ")
+	if !ok {
+		return fmt.Errorf("malformed minisign signature: missing trusted comment")
+	}
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil {
+		return fmt.Errorf("malformed minisign signature: %w", err)
+	}
+	signed := append(append([]byte{}, sig[10:]...), trusted...)
+	if !ed25519.Verify(k.key, signed, global) {
+		return fmt.Errorf("trusted comment signature does not match")
+	}
+
+	return nil
+}
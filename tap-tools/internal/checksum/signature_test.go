@@ -0,0 +1,98 @@
+package checksum
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// serveSignatures serves the signed fixture asset and the given sidecar
+// files from testdata
+func serveSignatures(t *testing.T, sidecars ...string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	for _, name := range append([]string{"signed-asset.txt"}, sidecars...) {
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("Failed to read fixture: %v", err)
+		}
+		mux.HandleFunc("/"+name, func(w http.ResponseWriter, r *http.Request) {
+			w.Write(data)
+		})
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVerifySignature(t *testing.T) {
+	asset, err := os.ReadFile(filepath.Join("testdata", "signed-asset.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := append([]byte("tampered "), asset...)
+
+	tests := []struct {
+		name     string
+		key      string
+		sidecars []string
+		data     []byte
+		wantSig  string
+		wantErr  string
+	}{
+		{"minisign", "minisign.pub", []string{"signed-asset.txt.minisig"}, asset, ".minisig", ""},
+		{"OpenPGP armored", "pgp.pub", []string{"signed-asset.txt.asc"}, asset, ".asc", ""},
+		{"OpenPGP binary", "pgp.pub", []string{"signed-asset.txt.sig"}, asset, ".sig", ""},
+		{"minisign tampered", "minisign.pub", []string{"signed-asset.txt.minisig"}, tampered, "", "bad signature"},
+		{"OpenPGP tampered", "pgp.pub", []string{"signed-asset.txt.asc"}, tampered, "", "bad signature"},
+		{"minisign key ignores OpenPGP signatures", "minisign.pub", []string{"signed-asset.txt.asc"}, asset, "", "no signature file found"},
+		{"No signature", "pgp.pub", nil, asset, "", "no signature file found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := serveSignatures(t, tt.sidecars...)
+			path := filepath.Join(t.TempDir(), "signed-asset.txt")
+			if err := os.WriteFile(path, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			sigURL, err := VerifySignature(server.URL+"/signed-asset.txt", path, filepath.Join("testdata", tt.key))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("VerifySignature() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifySignature() error = %v", err)
+			}
+			if !strings.HasSuffix(sigURL, tt.wantSig) {
+				t.Errorf("VerifySignature() = %s, want a %s signature", sigURL, tt.wantSig)
+			}
+		})
+	}
+
+	t.Run("Missing signature is ErrNoSignature", func(t *testing.T) {
+		server := serveSignatures(t)
+		_, err := VerifySignature(server.URL+"/signed-asset.txt", filepath.Join("testdata", "signed-asset.txt"), filepath.Join("testdata", "minisign.pub"))
+		if !errors.Is(err, ErrNoSignature) {
+			t.Errorf("VerifySignature() error = %v, want ErrNoSignature", err)
+		}
+	})
+
+	t.Run("Invalid key file", func(t *testing.T) {
+		server := serveSignatures(t, "signed-asset.txt.minisig")
+		key := filepath.Join(t.TempDir(), "key.pub")
+		if err := os.WriteFile(key, []byte("not a key"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := VerifySignature(server.URL+"/signed-asset.txt", filepath.Join("testdata", "signed-asset.txt"), key); err == nil {
+			t.Error("VerifySignature() expected error for an invalid key")
+		}
+	})
+}
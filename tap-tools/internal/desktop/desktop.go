@@ -2,7 +2,11 @@ package desktop
 
 import (
 	"fmt"
+	"math"
+	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -12,6 +16,13 @@ type DesktopFileInfo struct {
 	Filename string // Just the filename
 }
 
+// MimeFileInfo represents a detected shared-mime-info package, the XML
+// that declares the file types an app opens
+type MimeFileInfo struct {
+	Path     string // Relative path in archive
+	Filename string // Just the filename
+}
+
 // IconInfo represents a detected icon file
 type IconInfo struct {
 	Path     string // Relative path in archive
@@ -19,22 +30,150 @@ type IconInfo struct {
 	Size     string // Size like "128x128" or "hicolor"
 }
 
-// DetectDesktopFile searches for .desktop files in archive file list
+// DetectDesktopFile returns the first .desktop file of DetectDesktopFiles
 func DetectDesktopFile(archiveFiles []string) (*DesktopFileInfo, error) {
+	desktopFiles, err := DetectDesktopFiles(archiveFiles)
+	if err != nil {
+		return nil, err
+	}
+	return desktopFiles[0], nil
+}
+
+// DetectDesktopFiles searches for .desktop files in archive file list, for
+// apps that register several launchers such as a URL handler. They are
+// sorted by name, then path, so the result does not depend on archive order
+// and the main launcher (app.desktop) comes before app-url-handler.desktop.
+func DetectDesktopFiles(archiveFiles []string) ([]*DesktopFileInfo, error) {
+	var desktopFiles []*DesktopFileInfo
 	for _, file := range archiveFiles {
 		if strings.HasSuffix(strings.ToLower(file), ".desktop") {
-			return &DesktopFileInfo{
+			desktopFiles = append(desktopFiles, &DesktopFileInfo{
 				Path:     file,
 				Filename: filepath.Base(file),
-			}, nil
+			})
 		}
 	}
-	return nil, fmt.Errorf("no .desktop file found")
+	if len(desktopFiles) == 0 {
+		return nil, fmt.Errorf("no .desktop file found")
+	}
+
+	sort.Slice(desktopFiles, func(i, j int) bool {
+		a, b := desktopFiles[i], desktopFiles[j]
+		nameA := strings.TrimSuffix(strings.ToLower(a.Filename), ".desktop")
+		nameB := strings.TrimSuffix(strings.ToLower(b.Filename), ".desktop")
+		if nameA != nameB {
+			return nameA < nameB
+		}
+		return a.Path < b.Path
+	})
+	return desktopFiles, nil
+}
+
+// DetectMimeFiles searches for mime/packages/*.xml files in archive file
+// list, sorted by path
+func DetectMimeFiles(archiveFiles []string) ([]*MimeFileInfo, error) {
+	var mimeFiles []*MimeFileInfo
+	for _, file := range archiveFiles {
+		lower := strings.ToLower(file)
+		dir := path.Dir(lower)
+		if (dir == "mime/packages" || strings.HasSuffix(dir, "/mime/packages")) && strings.HasSuffix(lower, ".xml") {
+			mimeFiles = append(mimeFiles, &MimeFileInfo{
+				Path:     file,
+				Filename: filepath.Base(file),
+			})
+		}
+	}
+	if len(mimeFiles) == 0 {
+		return nil, fmt.Errorf("no MIME package found")
+	}
+
+	sort.Slice(mimeFiles, func(i, j int) bool {
+		return mimeFiles[i].Path < mimeFiles[j].Path
+	})
+	return mimeFiles, nil
 }
 
 // DetectIcon searches for icon files in archive file list
 // Prefers larger icons (256x256, 128x128) and common formats (png, svg)
 func DetectIcon(archiveFiles []string) (*IconInfo, error) {
+	candidates := iconCandidates(archiveFiles)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no icon file found")
+	}
+
+	// Select best icon (prefer larger sizes, then SVG, then PNG)
+	return selectBestIcon(candidates), nil
+}
+
+// DetectIcons returns one icon per size for installation into the hicolor
+// icon theme, smallest first with the scalable one last. Size is the theme
+// directory, like "48x48" or "scalable"; icons whose size cannot be told
+// from their path are left to DetectIcon. Within a size, SVG beats PNG.
+func DetectIcons(archiveFiles []string) []*IconInfo {
+	bySize := map[string][]*IconInfo{}
+	for _, icon := range iconCandidates(archiveFiles) {
+		size := iconThemeSize(icon.Path)
+		if size == "" || strings.HasSuffix(strings.ToLower(icon.Filename), ".ico") {
+			continue
+		}
+		bySize[size] = append(bySize[size], &IconInfo{Path: icon.Path, Filename: icon.Filename, Size: size})
+	}
+
+	var icons []*IconInfo
+	for _, group := range bySize {
+		icons = append(icons, selectBestIcon(group))
+	}
+	sort.Slice(icons, func(i, j int) bool {
+		a, b := iconSizeOrder(icons[i].Size), iconSizeOrder(icons[j].Size)
+		if a != b {
+			return a < b
+		}
+		return icons[i].Size < icons[j].Size
+	})
+	return icons
+}
+
+// iconThemeSize returns the hicolor size directory of an icon path, a square
+// size such as 48x48 or 48x48@2, or "scalable"
+func iconThemeSize(iconPath string) string {
+	for _, part := range strings.Split(strings.ToLower(iconPath), "/") {
+		if part == "scalable" {
+			return part
+		}
+		size, scale, scaled := strings.Cut(part, "@")
+		width, height, ok := strings.Cut(size, "x")
+		if ok && width == height && isDigits(width) && (!scaled || isDigits(scale)) {
+			return part
+		}
+	}
+	return ""
+}
+
+// iconSizeOrder sorts theme sizes by width, with scalable after all of them
+func iconSizeOrder(size string) int {
+	width, _, _ := strings.Cut(size, "x")
+	n, err := strconv.Atoi(width)
+	if err != nil {
+		return math.MaxInt
+	}
+	return n
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// iconCandidates returns the icon files of an archive file list
+func iconCandidates(archiveFiles []string) []*IconInfo {
 	var candidates []*IconInfo
 
 	// Common icon extensions
@@ -84,13 +223,7 @@ func DetectIcon(archiveFiles []string) (*IconInfo, error) {
 			Size:     size,
 		})
 	}
-
-	if len(candidates) == 0 {
-		return nil, fmt.Errorf("no icon file found")
-	}
-
-	// Select best icon (prefer larger sizes, then SVG, then PNG)
-	return selectBestIcon(candidates), nil
+	return candidates
 }
 
 // extractIconSize tries to extract size from icon path (e.g., "128x128")
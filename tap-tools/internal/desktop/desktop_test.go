@@ -1,6 +1,7 @@
 package desktop
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -51,6 +52,103 @@ func TestDetectDesktopFile(t *testing.T) {
 	}
 }
 
+func TestDetectDesktopFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "Main app and URL handler",
+			files: []string{"app/bin/app", "app/share/applications/app.desktop", "app/share/applications/app-url-handler.desktop"},
+			want:  []string{"app/share/applications/app.desktop", "app/share/applications/app-url-handler.desktop"},
+		},
+		{
+			name:  "Same files in another archive order",
+			files: []string{"app/share/applications/app-url-handler.desktop", "app/bin/app", "app/share/applications/app.desktop"},
+			want:  []string{"app/share/applications/app.desktop", "app/share/applications/app-url-handler.desktop"},
+		},
+		{
+			name:  "Same name in two directories",
+			files: []string{"b/app.desktop", "a/app.desktop"},
+			want:  []string{"a/app.desktop", "b/app.desktop"},
+		},
+		{
+			name:  "Single desktop file",
+			files: []string{"app/app.desktop", "app/bin/app"},
+			want:  []string{"app/app.desktop"},
+		},
+		{
+			name:    "No desktop file",
+			files:   []string{"app/bin/app"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectDesktopFiles(tt.files)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DetectDesktopFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var paths []string
+			for _, file := range got {
+				paths = append(paths, file.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("DetectDesktopFiles() = %v, want %v", paths, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectMimeFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "Shared mime packages",
+			files: []string{
+				"app/bin/app",
+				"app/share/mime/packages/app-workspace.xml",
+				"app/share/mime/packages/app-project.xml",
+				"app/share/applications/app.desktop",
+			},
+			want: []string{"app/share/mime/packages/app-project.xml", "app/share/mime/packages/app-workspace.xml"},
+		},
+		{
+			name:  "Top-level mime directory",
+			files: []string{"mime/packages/app.xml", "app"},
+			want:  []string{"mime/packages/app.xml"},
+		},
+		{
+			name:    "Other XML files are ignored",
+			files:   []string{"app/resources/config.xml", "app/mime/app.xml", "app/mime/packages/nested/app.xml", "app/mime/packages/README"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectMimeFiles(tt.files)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DetectMimeFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var paths []string
+			for _, file := range got {
+				paths = append(paths, file.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("DetectMimeFiles() = %v, want %v", paths, tt.want)
+			}
+		})
+	}
+}
+
 func TestDetectIcon(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -107,6 +205,59 @@ func TestDetectIcon(t *testing.T) {
 	}
 }
 
+func TestDetectIcons(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  []string // Size:Path of each icon, in order
+	}{
+		{
+			name: "Every PNG size",
+			files: []string{
+				"app/share/icons/hicolor/128x128/apps/app.png",
+				"app/share/icons/hicolor/16x16/apps/app.png",
+				"app/share/icons/hicolor/48x48/apps/app.png",
+				"app/bin/app",
+			},
+			want: []string{
+				"16x16:app/share/icons/hicolor/16x16/apps/app.png",
+				"48x48:app/share/icons/hicolor/48x48/apps/app.png",
+				"128x128:app/share/icons/hicolor/128x128/apps/app.png",
+			},
+		},
+		{
+			name: "Scalable last and SVG preferred within a size",
+			files: []string{
+				"app/icons/scalable/app.svg",
+				"app/icons/256x256/app.png",
+				"app/icons/256x256/app.svg",
+				"app/icons/256x256@2/app.png",
+			},
+			want: []string{
+				"256x256:app/icons/256x256/app.svg",
+				"256x256@2:app/icons/256x256@2/app.png",
+				"scalable:app/icons/scalable/app.svg",
+			},
+		},
+		{
+			name:  "Unsized icons are left to DetectIcon",
+			files: []string{"app/share/pixmaps/app.png", "app/icon.ico", "app/icons/32x32/app.ico"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, icon := range DetectIcons(tt.files) {
+				got = append(got, icon.Size+":"+icon.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectIcons() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestExtractIconSize(t *testing.T) {
 	tests := []struct {
 		path string
@@ -0,0 +1,95 @@
+package desktop
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// desktopEntrySection is the group holding the application's own keys
+const desktopEntrySection = "[Desktop Entry]"
+
+// DesktopEntry holds the keys of a .desktop file's [Desktop Entry] section
+// that describe the application
+type DesktopEntry struct {
+	Name       string   // Application name, e.g. "Visual Studio Code"
+	Comment    string   // Short description
+	Categories []string // Menu categories, e.g. ["Development", "IDE"]
+	Icon       string   // Icon name or absolute path
+}
+
+// ParseDesktopFile reads the [Desktop Entry] section of a .desktop file.
+// Localized keys like Name[de] are ignored in favor of the unlocalized ones,
+// as are other sections such as [Desktop Action new-window].
+func ParseDesktopFile(content []byte) (*DesktopEntry, error) {
+	var entry *DesktopEntry
+	inEntry := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			inEntry = line == desktopEntrySection
+			if inEntry && entry == nil {
+				entry = &DesktopEntry{}
+			}
+			continue
+		}
+		if !inEntry {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = unescapeValue(strings.TrimSpace(value))
+
+		switch strings.TrimSpace(key) {
+		case "Name":
+			entry.Name = value
+		case "Comment":
+			entry.Comment = value
+		case "Icon":
+			entry.Icon = value
+		case "Categories":
+			entry.Categories = splitList(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read desktop file: %w", err)
+	}
+
+	if entry == nil {
+		return nil, fmt.Errorf("no %s section found", desktopEntrySection)
+	}
+	if entry.Name == "" {
+		return nil, fmt.Errorf("%s has no Name", desktopEntrySection)
+	}
+	return entry, nil
+}
+
+// unescapeValue expands the \s, \n, \t, \r and \\ escapes of a string value
+func unescapeValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	return strings.NewReplacer(`\s`, " ", `\n`, "\n", `\t`, "\t", `\r`, "\r", `\\`, `\`).Replace(value)
+}
+
+// splitList splits a semicolon-separated list, dropping the trailing empty
+// element the spec allows
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ";") {
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,80 @@
+package desktop
+
+import (
+	"reflect"
+	"testing"
+)
+
+const codeDesktopFile = `[Desktop Entry]
+Name=Visual Studio Code
+Name[de]=Visual Studio Code (Deutsch)
+Comment=Code Editing. Redefined.
+Comment[de]=Code-Bearbeitung. Neu definiert.
+GenericName=Text Editor
+Exec=/usr/share/code/code %F
+Icon=vscode
+Type=Application
+StartupNotify=false
+StartupWMClass=Code
+Categories=TextEditor;Development;IDE;
+MimeType=application/x-code-workspace;
+Actions=new-empty-window;
+Keywords=vscode;
+
+[Desktop Action new-empty-window]
+Name=New Empty Window
+Exec=/usr/share/code/code --new-window %F
+Icon=vscode-window
+`
+
+func TestParseDesktopFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *DesktopEntry
+		wantErr bool
+	}{
+		{
+			name:    "Realistic desktop file",
+			content: codeDesktopFile,
+			want: &DesktopEntry{
+				Name:       "Visual Studio Code",
+				Comment:    "Code Editing. Redefined.",
+				Categories: []string{"TextEditor", "Development", "IDE"},
+				Icon:       "vscode",
+			},
+		},
+		{
+			name:    "Localized key before the unlocalized one",
+			content: "# Generated\n[Desktop Entry]\nName[fr]=Éditeur\nName = Editor\nComment=Edit\\stext\n",
+			want:    &DesktopEntry{Name: "Editor", Comment: "Edit text"},
+		},
+		{
+			name:    "Keys outside the entry section are ignored",
+			content: "[Desktop Action open]\nName=Open\n\n[Desktop Entry]\nName=App\nCategories=Utility\n",
+			want:    &DesktopEntry{Name: "App", Categories: []string{"Utility"}},
+		},
+		{
+			name:    "No entry section",
+			content: "Name=App\n",
+			wantErr: true,
+		},
+		{
+			name:    "Only localized names",
+			content: "[Desktop Entry]\nName[de]=Anwendung\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDesktopFile([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDesktopFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDesktopFile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
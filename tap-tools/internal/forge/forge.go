@@ -0,0 +1,21 @@
+// Package forge picks the API client for the host of a repository URL
+package forge
+
+import (
+	"fmt"
+
+	"github.com/castrojo/tap-tools/internal/github"
+	"github.com/castrojo/tap-tools/internal/gitlab"
+)
+
+// New returns the client for the host from github.ParseRepoURL: GitHub for
+// github.com, GitLab for the hosts github.IsGitLab accepts
+func New(host string) (github.Forge, error) {
+	switch {
+	case host == "" || host == github.GitHubHost:
+		return github.NewClient(), nil
+	case github.IsGitLab(host):
+		return gitlab.NewClient(host), nil
+	}
+	return nil, fmt.Errorf("unsupported host %s: only GitHub and GitLab repositories are supported", host)
+}
@@ -0,0 +1,41 @@
+package forge
+
+import (
+	"testing"
+
+	"github.com/castrojo/tap-tools/internal/github"
+	"github.com/castrojo/tap-tools/internal/gitlab"
+)
+
+func TestNew(t *testing.T) {
+	t.Setenv(github.GitLabHostEnv, "git.example.org")
+
+	tests := []struct {
+		host       string
+		wantGitLab bool
+		wantErr    bool
+	}{
+		{"github.com", false, false},
+		{"", false, false},
+		{"gitlab.com", true, false},
+		{"gitlab.gnome.org", true, false},
+		{"git.example.org", true, false},
+		{"gitlab.example.org", false, true},
+		{"codeberg.org", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			client, err := New(tt.host)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if _, isGitLab := client.(*gitlab.Client); isGitLab != tt.wantGitLab {
+				t.Errorf("New() = %T, want GitLab: %v", client, tt.wantGitLab)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Package forgetest provides a fake forge for testing the generate commands
+// without network access
+package forgetest
+
+import (
+	"io"
+	"testing"
+
+	"github.com/castrojo/tap-tools/internal/github"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Forge serves a repository with one release and records the release and
+// tag lookups made. Methods it does not implement panic.
+type Forge struct {
+	github.Forge
+	Release *github.Release
+	Calls   []string
+}
+
+func (f *Forge) GetRepository(owner, repo string) (*github.Repository, error) {
+	return &github.Repository{Owner: owner, Name: repo, Description: "A tool", License: "MIT"}, nil
+}
+
+func (f *Forge) GetLatestRelease(owner, repo string) (*github.Release, error) {
+	f.Calls = append(f.Calls, "latest")
+	return f.Release, nil
+}
+
+func (f *Forge) GetReleaseByTag(owner, repo, tag string) (*github.Release, error) {
+	f.Calls = append(f.Calls, "tag "+tag)
+	if tag != f.Release.TagName {
+		return nil, github.ErrTagNotFound
+	}
+	return f.Release, nil
+}
+
+func (f *Forge) ListTags(owner, repo string) ([]string, error) {
+	f.Calls = append(f.Calls, "tags")
+	return []string{f.Release.TagName}, nil
+}
+
+func (f *Forge) GetRepoTree(owner, repo string) ([]string, error) {
+	return nil, nil
+}
+
+// NewRelease returns a GitHub release of tag with the named assets
+func NewRelease(tag string, names ...string) *github.Release {
+	release := &github.Release{TagName: tag}
+	for _, name := range names {
+		url := "https://github.com/owner/tool/releases/download/" + tag + "/" + name
+		release.Assets = append(release.Assets, &github.Asset{Name: name, DownloadURL: url, BrowserDownloadURL: url})
+	}
+	return release
+}
+
+// Run executes root with args, quietly, with *newForge returning f. Cobra
+// keeps flag values between runs, so every flag is reset afterwards.
+func Run(t testing.TB, root *cobra.Command, newForge *func(string) (github.Forge, error), f *Forge, args ...string) error {
+	t.Helper()
+
+	saved := *newForge
+	*newForge = func(host string) (github.Forge, error) { return f, nil }
+	t.Cleanup(func() {
+		*newForge = saved
+		resetFlags(root)
+	})
+
+	root.SetArgs(append(args, "--quiet"))
+	root.SetOut(io.Discard)
+	root.SetErr(io.Discard)
+	return root.Execute()
+}
+
+// resetFlags sets every flag of cmd and its subcommands back to its default
+func resetFlags(cmd *cobra.Command) {
+	reset := func(flag *pflag.Flag) {
+		if !flag.Changed {
+			return
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			slice.Replace(nil)
+		} else {
+			flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	}
+	cmd.PersistentFlags().VisitAll(reset)
+	cmd.Flags().VisitAll(reset)
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub)
+	}
+}
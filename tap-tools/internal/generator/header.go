@@ -1,8 +1,10 @@
 package generator
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 	"time"
 )
@@ -34,6 +36,30 @@ func WriteHeader(w io.Writer, toolName, sourceURL string) error {
 	return err
 }
 
+// WriteNotes writes maintainer notes as comment lines, placed after the
+// header so the reasoning behind a package is kept in the generated file.
+// Empty notes write nothing.
+func WriteNotes(w io.Writer, notes string) error {
+	notes = strings.TrimRight(strings.ReplaceAll(notes, "\r\n", "\n"), " \t\n")
+	if strings.TrimSpace(notes) == "" {
+		return nil
+	}
+
+	var b strings.Builder
+	b.WriteString("# Maintainer notes:\n")
+	for _, line := range strings.Split(notes, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			b.WriteString("#\n")
+			continue
+		}
+		b.WriteString("#   " + line + "\n")
+	}
+
+	_, err := io.WriteString(w, b.String()+"\n")
+	return err
+}
+
 // ValidateHeader checks if a file has the required "Generated by" header
 // This is used by CI and pre-commit hooks to detect manual file creation
 func ValidateHeader(content string) bool {
@@ -53,3 +79,26 @@ func ValidateHeader(content string) bool {
 		(strings.Contains(firstLine, "Generated by tap-cask") ||
 			strings.Contains(firstLine, "Generated by tap-formula"))
 }
+
+// CheckOverwrite reports whether it is safe to write a generated file to path
+// Missing files and files carrying the generated header may be overwritten;
+// hand-written files are protected unless force is set
+func CheckOverwrite(path string, force bool) error {
+	if force {
+		return nil
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read existing %s: %w", path, err)
+	}
+
+	if !ValidateHeader(string(content)) {
+		return fmt.Errorf("%s exists and was not generated by tap-tools (use --force to overwrite)", path)
+	}
+
+	return nil
+}
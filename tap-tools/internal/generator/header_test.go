@@ -2,6 +2,8 @@ package generator
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -71,6 +73,33 @@ func TestWriteHeader(t *testing.T) {
 	}
 }
 
+func TestWriteNotes(t *testing.T) {
+	tests := []struct {
+		name  string
+		notes string
+		want  string
+	}{
+		{"Empty", "", ""},
+		{"Whitespace only", " \n\n", ""},
+		{"Single line", "Built from source: the prebuilt binary needs glibc 2.38+\n",
+			"# Maintainer notes:\n#   Built from source: the prebuilt binary needs glibc 2.38+\n\n"},
+		{"Blank lines and CRLF", "First reason\r\n\r\nSecond reason  \r\n",
+			"# Maintainer notes:\n#   First reason\n#\n#   Second reason\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteNotes(&buf, tt.notes); err != nil {
+				t.Fatalf("WriteNotes() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriteNotes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestValidateHeader(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -128,3 +157,44 @@ end`,
 		})
 	}
 }
+
+func TestCheckOverwrite(t *testing.T) {
+	dir := t.TempDir()
+
+	var generated bytes.Buffer
+	if err := WriteHeader(&generated, "tap-formula", "https://github.com/user/repo"); err != nil {
+		t.Fatalf("WriteHeader() error = %v", err)
+	}
+	generated.WriteString("class Repo < Formula\nend\n")
+
+	handWritten := "class Repo < Formula\n  desc \"Written by hand\"\n  homepage \"https://example.com\"\nend\n"
+
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		force   bool
+		wantErr bool
+	}{
+		{"Missing file", filepath.Join(dir, "missing.rb"), false, false},
+		{"Generated file", writeFile("generated.rb", generated.String()), false, false},
+		{"Hand-written file", writeFile("manual.rb", handWritten), false, true},
+		{"Hand-written file with force", writeFile("forced.rb", handWritten), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckOverwrite(tt.path, tt.force)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckOverwrite() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package github
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/castrojo/tap-tools/internal/checksum"
+)
+
+// CacheDirEnv names the environment variable that enables the API response
+// cache and sets its directory
+const CacheDirEnv = "TAP_CACHE_DIR"
+
+// cacheDir is where API responses are cached; empty disables the cache
+var cacheDir string
+
+// ConfigureCache enables the on-disk API response cache for clients created
+// afterwards. TAP_CACHE_DIR enables it in that directory; otherwise enabled
+// (the --cache flag) uses tap-tools/github under the user cache directory.
+func ConfigureCache(enabled bool) error {
+	dir := os.Getenv(CacheDirEnv)
+	if dir == "" && enabled {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return fmt.Errorf("failed to find cache directory: %w", err)
+		}
+		dir = filepath.Join(base, "tap-tools", "github")
+	}
+	if dir == "" {
+		cacheDir = ""
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	cacheDir = dir
+	return nil
+}
+
+// CachedHTTPClient returns base wrapped in the response cache, or base itself
+// when the cache is disabled. A nil base means the client trusting the CA
+// bundle from checksum.ConfigureCABundle. Other GitHub API clients, such as
+// the issues client, use it to share the cache.
+func CachedHTTPClient(base *http.Client) *http.Client {
+	if base == nil {
+		base = checksum.HTTPClient()
+	}
+	if cacheDir == "" {
+		return base
+	}
+
+	client := *base
+	client.Transport = &cacheTransport{dir: cacheDir, base: base.Transport}
+	return &client
+}
+
+// cacheEntry is a cached response, stored as JSON
+type cacheEntry struct {
+	URL    string      `json:"url"`
+	ETag   string      `json:"etag"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// cacheTransport revalidates GET requests against cached responses with
+// If-None-Match. GitHub does not count a 304 against the rate limit, so
+// repeated runs only spend requests on data that changed.
+type cacheTransport struct {
+	dir  string
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if req.Method != http.MethodGet {
+		return base.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	path := t.path(key)
+	entry := readCacheEntry(path, key)
+	if entry != nil {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		// Reuse the cached body, keeping fresh headers such as the rate limit
+		resp.Body.Close()
+		header := entry.Header.Clone()
+		for name, values := range resp.Header {
+			header[name] = values
+		}
+		header.Del("Content-Length")
+		resp.StatusCode = http.StatusOK
+		resp.Status = "200 OK"
+		resp.Header = header
+		resp.Body = io.NopCloser(bytes.NewReader(entry.Body))
+		resp.ContentLength = int64(len(entry.Body))
+
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		// The cache is best effort; a failed write only costs a refetch
+		writeCacheEntry(path, &cacheEntry{URL: key, ETag: resp.Header.Get("ETag"), Header: resp.Header, Body: body})
+	}
+
+	return resp, nil
+}
+
+// path returns the cache file for a request URL, which identifies the
+// owner, repository and endpoint
+func (t *cacheTransport) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(t.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// readCacheEntry loads a cached response, or nil if there is no usable one
+func readCacheEntry(path, key string) *cacheEntry {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.URL != key || entry.ETag == "" {
+		return nil
+	}
+	return &entry
+}
+
+// writeCacheEntry stores a response, replacing the file atomically so that
+// concurrent runs never read a partial entry
+func writeCacheEntry(path string, entry *cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
@@ -0,0 +1,144 @@
+package github
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+)
+
+func TestCacheTransport(t *testing.T) {
+	var requests, notModified int
+	var gotETags []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		gotETags = append(gotETags, r.Header.Get("If-None-Match"))
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"tag_name": "v1.0.0"}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &cacheTransport{dir: t.TempDir()}}
+	get := func() string {
+		t.Helper()
+		resp, err := client.Get(server.URL + "/repos/owner/repo/releases/latest")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("StatusCode = %d, want 200", resp.StatusCode)
+		}
+		if got := resp.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q, want the cached header", got)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		return string(body)
+	}
+
+	first, second := get(), get()
+	if first != second || second != `{"tag_name": "v1.0.0"}` {
+		t.Errorf("Bodies = %q, %q, want the same release twice", first, second)
+	}
+	if requests != 2 || notModified != 1 {
+		t.Errorf("Server saw %d requests with %d revalidated, want 2 and 1", requests, notModified)
+	}
+	if gotETags[0] != "" || gotETags[1] != `"v1"` {
+		t.Errorf("If-None-Match = %q, want none and then the stored ETag", gotETags)
+	}
+}
+
+func TestCacheTransportSkipsUncacheable(t *testing.T) {
+	var gotETags []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotETags = append(gotETags, r.Header.Get("If-None-Match"))
+		if r.URL.Path == "/tagged" {
+			w.Header().Set("ETag", `"v1"`)
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &cacheTransport{dir: t.TempDir()}}
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, "/untagged"}, {http.MethodGet, "/untagged"},
+		{http.MethodPost, "/tagged"}, {http.MethodPost, "/tagged"},
+	} {
+		r, _ := http.NewRequest(req.method, server.URL+req.path, nil)
+		resp, err := client.Do(r)
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	for i, etag := range gotETags {
+		if etag != "" {
+			t.Errorf("Request %d sent If-None-Match %q, want none", i, etag)
+		}
+	}
+}
+
+func TestConfigureCache(t *testing.T) {
+	t.Cleanup(func() { cacheDir = "" })
+
+	t.Setenv(CacheDirEnv, "")
+	if err := ConfigureCache(false); err != nil || cacheDir != "" {
+		t.Errorf("ConfigureCache(false) = %v, dir %q, want disabled", err, cacheDir)
+	}
+	if CachedHTTPClient(nil) != http.DefaultClient {
+		t.Error("CachedHTTPClient() should not wrap the client when the cache is disabled")
+	}
+
+	dir := t.TempDir()
+	t.Setenv(CacheDirEnv, dir)
+	if err := ConfigureCache(false); err != nil || cacheDir != dir {
+		t.Errorf("ConfigureCache() with %s = %v, dir %q, want %q", CacheDirEnv, err, cacheDir, dir)
+	}
+
+	// A GitHub client built now revalidates its second request
+	var gotETags []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotETags = append(gotETags, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == `"abc"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"abc"`)
+		w.Write([]byte(`{"tag_name": "v2.0.0", "assets": []}`))
+	}))
+	defer server.Close()
+
+	gh := github.NewClient(CachedHTTPClient(nil))
+	gh.BaseURL, _ = url.Parse(server.URL + "/")
+	client := &Client{gh: gh, ctx: context.Background(), rate: newRateLimitTracker(DefaultRateLimitOptions())}
+	client.rate.checkedAt = client.rate.now()
+	client.rate.remaining = 5000
+
+	for range 2 {
+		release, err := client.GetLatestRelease("owner", "repo")
+		if err != nil {
+			t.Fatalf("GetLatestRelease() error = %v", err)
+		}
+		if release.TagName != "v2.0.0" {
+			t.Errorf("TagName = %s, want v2.0.0", release.TagName)
+		}
+	}
+	if len(gotETags) != 2 || gotETags[1] != `"abc"` {
+		t.Errorf("If-None-Match = %q, want the stored ETag on the second call", gotETags)
+	}
+}
@@ -2,29 +2,61 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"slices"
 	"strings"
-	"time"
+	"sync"
 
+	"github.com/castrojo/tap-tools/internal/checksum"
+	"github.com/castrojo/tap-tools/internal/platform"
+	"github.com/castrojo/tap-tools/internal/version"
 	"github.com/google/go-github/v60/github"
 	"golang.org/x/oauth2"
 )
 
+// ErrRepoNotFound is returned by GetRepository for a repository that does
+// not exist or that the token cannot see
+var ErrRepoNotFound = errors.New("repository not found")
+
+// ErrTagNotFound is returned by GetReleaseByTag for a tag the repository
+// does not have
+var ErrTagNotFound = errors.New("tag not found")
+
+// ErrNotFound is wrapped by the errors of other forges for a 404 from their
+// API, so IsNotFound recognizes them
+var ErrNotFound = errors.New("not found")
+
 // Client wraps the GitHub API client
 type Client struct {
-	gh  *github.Client
-	ctx context.Context
+	gh   *github.Client
+	ctx  context.Context
+	rate *rateLimitTracker
+
+	// branches holds the default branch of each "owner/repo" that
+	// GetRepository fetched, so file listings need no extra request
+	branches sync.Map
 }
 
 // Repository represents a GitHub repository
 type Repository struct {
-	Owner       string
-	Name        string
-	Description string
-	Homepage    string
-	License     string
-	Stars       int
+	Owner         string
+	Name          string
+	Description   string
+	Homepage      string
+	License       string
+	Stars         int
+	DefaultBranch string
+}
+
+// License represents a repository's detected license
+type License struct {
+	SPDXID  string // SPDX identifier, or "NOASSERTION" for unrecognized licenses
+	Name    string // Human-readable license name
+	Path    string // Path to the license file in the repository
+	HasFile bool   // Whether the repository has a license file at all
 }
 
 // Release represents a GitHub release
@@ -36,6 +68,7 @@ type Release struct {
 	Draft       bool
 	PublishedAt string
 	Assets      []*Asset
+	FromTag     bool // Synthesized by TagRelease for a tag without a release
 }
 
 // Asset represents a release asset
@@ -47,6 +80,19 @@ type Asset struct {
 	BrowserDownloadURL string
 }
 
+// PlatformAssets detects the platform of each of the release's assets
+func (r *Release) PlatformAssets() []*platform.Asset {
+	var assets []*platform.Asset
+	for _, ghAsset := range r.Assets {
+		asset := platform.DetectPlatform(ghAsset.Name)
+		asset.URL = ghAsset.URL
+		asset.DownloadURL = ghAsset.BrowserDownloadURL
+		asset.Size = ghAsset.Size
+		assets = append(assets, asset)
+	}
+	return assets
+}
+
 // detectEnvironment returns the execution environment
 func detectEnvironment() string {
 	if os.Getenv("GITHUB_ACTIONS") == "true" {
@@ -124,15 +170,17 @@ func NewClient() *Client {
 		ts := oauth2.StaticTokenSource(
 			&oauth2.Token{AccessToken: token},
 		)
-		tc := oauth2.NewClient(ctx, ts)
-		client = github.NewClient(tc)
+		// The token transport wraps the client trusting the CA bundle
+		tc := oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, checksum.HTTPClient()), ts)
+		client = github.NewClient(CachedHTTPClient(tc))
 	} else {
-		client = github.NewClient(nil)
+		client = github.NewClient(CachedHTTPClient(nil))
 	}
 
 	return &Client{
-		gh:  client,
-		ctx: ctx,
+		gh:   client,
+		ctx:  ctx,
+		rate: newRateLimitTracker(DefaultRateLimitOptions()),
 	}
 }
 
@@ -145,69 +193,112 @@ func NewClientWithTokenCheck() (*Client, error) {
 	return NewClient(), nil
 }
 
-// CheckRateLimit monitors GitHub API rate limit and warns if running low
-func (c *Client) CheckRateLimit() error {
-	rateLimit, _, err := c.gh.RateLimits(c.ctx)
+// CheckPullRequestAccess verifies the token can push a branch and open a pull
+// request on a repository, so commands like tap-issue --create-pr fail
+// before running the whole pipeline rather than at the final step
+func (c *Client) CheckPullRequestAccess(owner, repo string) error {
+	r, resp, err := c.gh.Repositories.Get(c.ctx, owner, repo)
 	if err != nil {
-		// Warn that the rate limit check failed, but don't block execution.
-		fmt.Fprintf(os.Stderr, "⚠️  Could not check GitHub API rate limit: %v\n", err)
-		return nil
+		return fmt.Errorf("failed to fetch repository: %w", err)
 	}
 
-	remaining := rateLimit.Core.Remaining
-	limit := rateLimit.Core.Limit
-	resetTime := rateLimit.Core.Reset.Time
+	return checkPullRequestScopes(resp.Header, owner+"/"+repo, r.GetPrivate(), r.GetPermissions()["push"])
+}
 
-	// Warn if less than 100 requests remaining or less than 10% of limit
-	threshold := 100
-	if limit < 1000 {
-		threshold = limit / 10
-	}
+// checkPullRequestScopes maps token scopes and repository permissions to an
+// actionable error. Classic tokens report their scopes in X-OAuth-Scopes;
+// fine-grained and Actions tokens do not, so only push access can be checked.
+func checkPullRequestScopes(header http.Header, fullName string, private, canPush bool) error {
+	if scopes, classic := header[http.CanonicalHeaderKey("X-OAuth-Scopes")]; classic {
+		hasScope := false
+		for _, scope := range strings.Split(strings.Join(scopes, ","), ",") {
+			scope = strings.TrimSpace(scope)
+			if scope == "repo" || (scope == "public_repo" && !private) {
+				hasScope = true
+			}
+		}
+		if !hasScope {
+			return fmt.Errorf(`GITHUB_TOKEN is missing the scope needed to create pull requests on %s
 
-	if remaining < threshold {
-		fmt.Fprintf(os.Stderr, "⚠️  GitHub API rate limit low: %d/%d remaining\n", remaining, limit)
-		fmt.Fprintf(os.Stderr, "   Resets at: %s (in %s)\n",
-			resetTime.Format(time.RFC3339),
-			time.Until(resetTime).Round(time.Minute))
+Classic personal access tokens need the 'repo' scope ('public_repo' for public repositories).
 
-		if limit == 60 {
-			fmt.Fprintf(os.Stderr, "   ℹ️  Using unauthenticated rate limit (60/hour)\n")
-			fmt.Fprintf(os.Stderr, "   💡 Set GITHUB_TOKEN to increase limit to 5,000/hour\n")
-			fmt.Fprintf(os.Stderr, "      Run: export GITHUB_TOKEN=$(gh auth token)\n")
+Solutions:
+  1. Use gh CLI: gh auth refresh -s repo && export GITHUB_TOKEN=$(gh auth token)
+  2. Edit the token at https://github.com/settings/tokens and add the 'repo' scope`, fullName)
 		}
 	}
 
+	if !canPush {
+		return fmt.Errorf(`GITHUB_TOKEN cannot push to %s
+
+Creating a pull request needs write access to the repository.
+
+Solutions:
+  1. Fine-grained tokens: grant 'Contents: write' and 'Pull requests: write' for %s
+  2. GitHub Actions: add 'contents: write' and 'pull-requests: write' to the workflow permissions
+  3. Fork the repository and run against your fork`, fullName, fullName)
+	}
+
 	return nil
 }
 
-// ParseRepoURL extracts owner and repo name from a GitHub URL
-// Supports: https://github.com/owner/repo, github.com/owner/repo, owner/repo
-func ParseRepoURL(url string) (owner, repo string, err error) {
+// ParseRepoURL extracts the host, owner and repo name from a repository URL
+// Supports: https://github.com/owner/repo, github.com/owner/repo, owner/repo,
+// git@host:owner/repo.git, ssh://git@host/owner/repo and GitLab URLs, whose
+// owner may be a nested group (gitlab.com/group/subgroup/repo).
+// A URL without a host is on GitHub.
+func ParseRepoURL(url string) (host, owner, repo string, err error) {
 	// Remove trailing slashes
 	url = strings.TrimRight(url, "/")
 
-	// Remove protocol
-	url = strings.TrimPrefix(url, "https://")
-	url = strings.TrimPrefix(url, "http://")
-	url = strings.TrimPrefix(url, "github.com/")
+	// Remove protocol, turning the SSH shorthand's "host:" into "host/"
+	scheme := false
+	for _, prefix := range []string{"https://", "http://", "ssh://", "git://"} {
+		if rest, ok := strings.CutPrefix(url, prefix); ok {
+			url, scheme = rest, true
+		}
+	}
+	if rest, ok := strings.CutPrefix(url, "git@"); ok {
+		url = rest
+		if !scheme {
+			url = strings.Replace(rest, ":", "/", 1)
+		}
+	}
 
-	// Split into parts
+	// Split into parts; owner names cannot contain dots, hosts always do
 	parts := strings.Split(url, "/")
+	host = GitHubHost
+	if strings.Contains(parts[0], ".") {
+		host = strings.ToLower(strings.TrimPrefix(parts[0], "www."))
+		host, _, _ = strings.Cut(host, ":") // Drop an SSH port
+		parts = parts[1:]
+	}
 	if len(parts) < 2 {
-		return "", "", fmt.Errorf("invalid GitHub URL: %s (expected format: owner/repo)", url)
+		return "", "", "", fmt.Errorf("invalid repository URL: %s (expected format: owner/repo)", url)
 	}
 
 	owner = parts[0]
 	repo = parts[1]
+	if IsGitLab(host) {
+		// Groups nest; GitLab's own pages start after a "-" segment
+		if dash := slices.Index(parts, "-"); dash >= 0 {
+			parts = parts[:dash]
+		}
+		if len(parts) < 2 {
+			return "", "", "", fmt.Errorf("invalid repository URL: %s (expected format: group/repo)", url)
+		}
+		owner = strings.Join(parts[:len(parts)-1], "/")
+		repo = parts[len(parts)-1]
+	}
 
 	// Remove .git suffix if present
 	repo = strings.TrimSuffix(repo, ".git")
 
 	if owner == "" || repo == "" {
-		return "", "", fmt.Errorf("invalid GitHub URL: owner or repo cannot be empty")
+		return "", "", "", fmt.Errorf("invalid repository URL: owner or repo cannot be empty")
 	}
 
-	return owner, repo, nil
+	return host, owner, repo, nil
 }
 
 // GetRepository fetches repository metadata
@@ -216,6 +307,12 @@ func (c *Client) GetRepository(owner, repo string) (*Repository, error) {
 	c.CheckRateLimit()
 
 	ghRepo, _, err := c.gh.Repositories.Get(c.ctx, owner, repo)
+	if IsNotFound(err) {
+		// GitHub answers 404 rather than 403 for private repositories
+		return nil, fmt.Errorf("%w: github.com/%s/%s does not exist or is private; "+
+			"check the owner and name in the URL, and for a private repository "+
+			"that GITHUB_TOKEN has the repo scope", ErrRepoNotFound, owner, repo)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch repository: %w", err)
 	}
@@ -225,16 +322,66 @@ func (c *Client) GetRepository(owner, repo string) (*Repository, error) {
 		license = *ghRepo.License.SPDXID
 	}
 
+	c.branches.Store(owner+"/"+repo, ghRepo.GetDefaultBranch())
+
 	return &Repository{
-		Owner:       owner,
-		Name:        repo,
-		Description: ghRepo.GetDescription(),
-		Homepage:    ghRepo.GetHomepage(),
-		License:     license,
-		Stars:       ghRepo.GetStargazersCount(),
+		Owner:         owner,
+		Name:          repo,
+		Description:   ghRepo.GetDescription(),
+		Homepage:      ghRepo.GetHomepage(),
+		License:       license,
+		Stars:         ghRepo.GetStargazersCount(),
+		DefaultBranch: ghRepo.GetDefaultBranch(),
 	}, nil
 }
 
+// GetLicense fetches the repository license via the license endpoint
+// A repository without a license file returns HasFile=false and no error.
+// A custom license file that GitHub cannot identify reports "NOASSERTION".
+func (c *Client) GetLicense(owner, repo string) (*License, error) {
+	// Check rate limit before making API call
+	c.CheckRateLimit()
+
+	ghLicense, resp, err := c.gh.Repositories.License(c.ctx, owner, repo)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return &License{HasFile: false}, nil
+		}
+		return nil, fmt.Errorf("failed to fetch license: %w", err)
+	}
+
+	license := &License{
+		Path:    ghLicense.GetPath(),
+		HasFile: true,
+	}
+	if ghLicense.License != nil {
+		license.SPDXID = ghLicense.License.GetSPDXID()
+		license.Name = ghLicense.License.GetName()
+	}
+
+	return license, nil
+}
+
+// GetFileContent fetches the decoded content of a file on the default branch
+func (c *Client) GetFileContent(owner, repo, path string) (string, error) {
+	// Check rate limit before making API call
+	c.CheckRateLimit()
+
+	fileContent, _, _, err := c.gh.Repositories.GetContents(c.ctx, owner, repo, path, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", path, err)
+	}
+	if fileContent == nil {
+		return "", fmt.Errorf("failed to fetch %s: not a file", path)
+	}
+
+	content, err := fileContent.GetContent()
+	if err != nil {
+		return "", fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return content, nil
+}
+
 // GetLatestRelease fetches the latest release (excluding prereleases and drafts)
 func (c *Client) GetLatestRelease(owner, repo string) (*Release, error) {
 	// Check rate limit before making API call
@@ -248,25 +395,155 @@ func (c *Client) GetLatestRelease(owner, repo string) (*Release, error) {
 	return c.convertRelease(ghRelease), nil
 }
 
-// GetAllReleases fetches all releases (including prereleases)
-func (c *Client) GetAllReleases(owner, repo string) ([]*Release, error) {
+// GetReleaseByTag fetches the release of a tag, prereleases included. A tag
+// without a release gets one synthesized from its source tarball, like
+// GetLatestTag; a missing tag is an error wrapping ErrTagNotFound.
+func (c *Client) GetReleaseByTag(owner, repo, tag string) (*Release, error) {
 	// Check rate limit before making API call
 	c.CheckRateLimit()
 
-	opts := &github.ListOptions{PerPage: 100}
-	ghReleases, _, err := c.gh.Repositories.ListReleases(c.ctx, owner, repo, opts)
+	ghRelease, _, err := c.gh.Repositories.GetReleaseByTag(c.ctx, owner, repo, tag)
+	if IsNotFound(err) {
+		c.CheckRateLimit()
+		_, _, err := c.gh.Git.GetRef(c.ctx, owner, repo, "tags/"+tag)
+		if IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s/%s has no release or tag %q", ErrTagNotFound, owner, repo, tag)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch tag %s: %w", tag, err)
+		}
+		archiveURL := fmt.Sprintf("https://%s/%s/%s/archive/refs/tags/%s.tar.gz", GitHubHost, owner, repo, tag)
+		return TagRelease(repo, tag, archiveURL), nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch releases: %w", err)
+		return nil, fmt.Errorf("failed to fetch release %s: %w", tag, err)
 	}
 
-	releases := make([]*Release, 0, len(ghReleases))
-	for _, ghRelease := range ghReleases {
-		releases = append(releases, c.convertRelease(ghRelease))
+	return c.convertRelease(ghRelease), nil
+}
+
+// GetAllReleases fetches all releases (including prereleases)
+func (c *Client) GetAllReleases(owner, repo string) ([]*Release, error) {
+	// Follow the pagination cursor; repositories can have hundreds of releases
+	var releases []*Release
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		// Check rate limit before making API call
+		c.CheckRateLimit()
+
+		ghReleases, resp, err := c.gh.Repositories.ListReleases(c.ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch releases: %w", err)
+		}
+		for _, ghRelease := range ghReleases {
+			releases = append(releases, c.convertRelease(ghRelease))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
 	}
 
 	return releases, nil
 }
 
+// ListTags fetches every tag name of a repository
+func (c *Client) ListTags(owner, repo string) ([]string, error) {
+	// Follow the pagination cursor; the API does not sort tags by version, so
+	// the newest one can be on any page
+	var tags []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		// Check rate limit before making API call
+		c.CheckRateLimit()
+
+		ghTags, resp, err := c.gh.Repositories.ListTags(c.ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch tags: %w", err)
+		}
+		for _, ghTag := range ghTags {
+			tags = append(tags, ghTag.GetName())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return tags, nil
+}
+
+// GetLatestTag synthesizes a release from the newest tag, for repositories
+// that tag versions without publishing GitHub releases. Its only asset is
+// the tag's auto-generated source tarball.
+func (c *Client) GetLatestTag(owner, repo string) (*Release, error) {
+	tags, err := c.ListTags(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	tag := LatestTag(tags)
+	if tag == "" {
+		return nil, fmt.Errorf("repository has no releases or tags")
+	}
+	archiveURL := fmt.Sprintf("https://%s/%s/%s/archive/refs/tags/%s.tar.gz", GitHubHost, owner, repo, tag)
+	return TagRelease(repo, tag, archiveURL), nil
+}
+
+// LatestTag returns the highest version tag, or the most recent tag when
+// none look like versions. It returns "" for no tags.
+func LatestTag(tags []string) string {
+	latest := ""
+	for _, tag := range tags {
+		if !version.IsVersion(tag) {
+			continue
+		}
+		if latest == "" || version.Compare(tag, latest) > 0 {
+			latest = tag
+		}
+	}
+	if latest == "" && len(tags) > 0 {
+		latest = tags[0]
+	}
+	return latest
+}
+
+// TagRelease builds a release for a tag with no published release, with the
+// source tarball at archiveURL as its only asset. The asset is named
+// <repo>-<version>.tar.gz, the version being the tag without its "v" prefix.
+func TagRelease(repo, tag, archiveURL string) *Release {
+	return &Release{
+		TagName: tag,
+		Name:    tag,
+		FromTag: true,
+		Assets: []*Asset{{
+			Name:               fmt.Sprintf("%s-%s.tar.gz", repo, version.Normalize(tag)),
+			URL:                archiveURL,
+			DownloadURL:        archiveURL,
+			BrowserDownloadURL: archiveURL,
+		}},
+	}
+}
+
+// NewerTag returns the newest version tag that is newer than the latest
+// release tag, or "" if the latest release is up to date.
+// GitHub's "latest" release is whichever one the maintainer marked, which
+// is not always the highest version.
+func NewerTag(latestTag string, tags []string) string {
+	newest := ""
+	for _, tag := range tags {
+		if !version.IsVersion(tag) {
+			continue
+		}
+		if version.Compare(tag, latestTag) <= 0 {
+			continue
+		}
+		if newest == "" || version.Compare(tag, newest) > 0 {
+			newest = tag
+		}
+	}
+	return newest
+}
+
 // convertRelease converts a GitHub release to our internal representation
 func (c *Client) convertRelease(ghRelease *github.RepositoryRelease) *Release {
 	assets := make([]*Asset, 0, len(ghRelease.Assets))
@@ -297,12 +574,18 @@ func (c *Client) convertRelease(ghRelease *github.RepositoryRelease) *Release {
 }
 
 // GetRepoFiles fetches the list of files in the repository root
-// Used for build system detection
+// Used for build system detection. An empty repository yields an empty
+// list rather than an error, so generation can fall back to a skeleton.
 func (c *Client) GetRepoFiles(owner, repo string) ([]string, error) {
+	opts := &github.RepositoryContentGetOptions{Ref: c.defaultBranch(owner, repo)}
+
 	// Check rate limit before making API call
 	c.CheckRateLimit()
 
-	_, dirContent, _, err := c.gh.Repositories.GetContents(c.ctx, owner, repo, "", nil)
+	_, dirContent, _, err := c.gh.Repositories.GetContents(c.ctx, owner, repo, "", opts)
+	if isEmptyRepoError(err) {
+		return []string{}, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch repository contents: %w", err)
 	}
@@ -316,3 +599,64 @@ func (c *Client) GetRepoFiles(owner, repo string) ([]string, error) {
 
 	return files, nil
 }
+
+// GetRepoTree fetches the full recursive file listing of the default branch
+// Paths are relative to the repository root, e.g. "cmd/tool/main.go"
+// An empty repository yields an empty list rather than an error.
+func (c *Client) GetRepoTree(owner, repo string) ([]string, error) {
+	ref := c.defaultBranch(owner, repo)
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	// Check rate limit before making API call
+	c.CheckRateLimit()
+
+	tree, _, err := c.gh.Git.GetTree(c.ctx, owner, repo, ref, true)
+	if isEmptyRepoError(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repository tree: %w", err)
+	}
+
+	files := make([]string, 0, len(tree.Entries))
+	for _, entry := range tree.Entries {
+		if entry.GetType() == "blob" {
+			files = append(files, entry.GetPath())
+		}
+	}
+
+	return files, nil
+}
+
+// defaultBranch returns the repository's default branch as recorded by
+// GetRepository, or "" when it was not fetched so callers fall back to the
+// server's default
+func (c *Client) defaultBranch(owner, repo string) string {
+	branch, _ := c.branches.Load(owner + "/" + repo)
+	name, _ := branch.(string)
+	return name
+}
+
+// IsNotFound reports whether err is a 404 from GitHub or GitLab, such as
+// GetLatestRelease for a repository that has never published a release
+func IsNotFound(err error) bool {
+	if errors.Is(err, ErrNotFound) {
+		return true
+	}
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil &&
+		errResp.Response.StatusCode == http.StatusNotFound
+}
+
+// isEmptyRepoError reports whether err is GitHub's response for a repository
+// with no commits: "Git Repository is empty." (409 for git trees) or "This
+// repository is empty." (404 for contents)
+func isEmptyRepoError(err error) bool {
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) {
+		return false
+	}
+	return strings.Contains(strings.ToLower(errResp.Message), "repository is empty")
+}
@@ -1,13 +1,41 @@
 package github
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+
+	"github.com/castrojo/tap-tools/internal/platform"
+	"github.com/google/go-github/v60/github"
 )
 
+// newTestClient returns a Client that talks to a local test server
+func newTestClient(t *testing.T, mux *http.ServeMux) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	gh := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to parse test server URL: %v", err)
+	}
+	gh.BaseURL = baseURL
+
+	return &Client{gh: gh, ctx: context.Background(), rate: newRateLimitTracker(DefaultRateLimitOptions())}
+}
+
 func TestParseRepoURL(t *testing.T) {
 	tests := []struct {
 		name      string
 		url       string
+		wantHost  string
 		wantOwner string
 		wantRepo  string
 		wantErr   bool
@@ -15,6 +43,7 @@ func TestParseRepoURL(t *testing.T) {
 		{
 			name:      "Full HTTPS URL",
 			url:       "https://github.com/castrojo/homebrew-tap",
+			wantHost:  "github.com",
 			wantOwner: "castrojo",
 			wantRepo:  "homebrew-tap",
 			wantErr:   false,
@@ -22,6 +51,7 @@ func TestParseRepoURL(t *testing.T) {
 		{
 			name:      "Full HTTPS URL with trailing slash",
 			url:       "https://github.com/castrojo/homebrew-tap/",
+			wantHost:  "github.com",
 			wantOwner: "castrojo",
 			wantRepo:  "homebrew-tap",
 			wantErr:   false,
@@ -29,6 +59,7 @@ func TestParseRepoURL(t *testing.T) {
 		{
 			name:      "Without protocol",
 			url:       "github.com/sublimehq/sublime_text",
+			wantHost:  "github.com",
 			wantOwner: "sublimehq",
 			wantRepo:  "sublime_text",
 			wantErr:   false,
@@ -36,6 +67,7 @@ func TestParseRepoURL(t *testing.T) {
 		{
 			name:      "Short format",
 			url:       "BurntSushi/ripgrep",
+			wantHost:  "github.com",
 			wantOwner: "BurntSushi",
 			wantRepo:  "ripgrep",
 			wantErr:   false,
@@ -43,6 +75,7 @@ func TestParseRepoURL(t *testing.T) {
 		{
 			name:      "With .git suffix",
 			url:       "https://github.com/user/repo.git",
+			wantHost:  "github.com",
 			wantOwner: "user",
 			wantRepo:  "repo",
 			wantErr:   false,
@@ -50,6 +83,7 @@ func TestParseRepoURL(t *testing.T) {
 		{
 			name:      "Invalid - missing repo",
 			url:       "github.com/user",
+			wantHost:  "",
 			wantOwner: "",
 			wantRepo:  "",
 			wantErr:   true,
@@ -57,6 +91,7 @@ func TestParseRepoURL(t *testing.T) {
 		{
 			name:      "Invalid - only username",
 			url:       "user",
+			wantHost:  "",
 			wantOwner: "",
 			wantRepo:  "",
 			wantErr:   true,
@@ -64,6 +99,87 @@ func TestParseRepoURL(t *testing.T) {
 		{
 			name:      "Invalid - empty",
 			url:       "",
+			wantHost:  "",
+			wantOwner: "",
+			wantRepo:  "",
+			wantErr:   true,
+		},
+		{
+			name:      "GitHub release page",
+			url:       "https://github.com/user/repo/releases/tag/v1.0.0",
+			wantHost:  "github.com",
+			wantOwner: "user",
+			wantRepo:  "repo",
+			wantErr:   false,
+		},
+		{
+			name:      "GitHub SSH",
+			url:       "git@github.com:user/repo.git",
+			wantHost:  "github.com",
+			wantOwner: "user",
+			wantRepo:  "repo",
+			wantErr:   false,
+		},
+		{
+			name:      "GitLab HTTPS",
+			url:       "https://gitlab.com/inkscape/inkscape",
+			wantHost:  "gitlab.com",
+			wantOwner: "inkscape",
+			wantRepo:  "inkscape",
+			wantErr:   false,
+		},
+		{
+			name:      "GitLab without protocol",
+			url:       "gitlab.com/owner/repo",
+			wantHost:  "gitlab.com",
+			wantOwner: "owner",
+			wantRepo:  "repo",
+			wantErr:   false,
+		},
+		{
+			name:      "GitLab nested groups",
+			url:       "https://gitlab.com/group/subgroup/repo",
+			wantHost:  "gitlab.com",
+			wantOwner: "group/subgroup",
+			wantRepo:  "repo",
+			wantErr:   false,
+		},
+		{
+			name:      "GitLab release page",
+			url:       "https://gitlab.com/group/repo/-/releases/v1.0.0",
+			wantHost:  "gitlab.com",
+			wantOwner: "group",
+			wantRepo:  "repo",
+			wantErr:   false,
+		},
+		{
+			name:      "GitLab SSH",
+			url:       "git@gitlab.com:group/subgroup/repo.git",
+			wantHost:  "gitlab.com",
+			wantOwner: "group/subgroup",
+			wantRepo:  "repo",
+			wantErr:   false,
+		},
+		{
+			name:      "GitLab SSH URL with port",
+			url:       "ssh://git@gitlab.gnome.org:2222/owner/repo.git",
+			wantHost:  "gitlab.gnome.org",
+			wantOwner: "owner",
+			wantRepo:  "repo",
+			wantErr:   false,
+		},
+		{
+			name:      "Self-managed GitLab",
+			url:       "https://gitlab.gnome.org/GNOME/gnome-builder",
+			wantHost:  "gitlab.gnome.org",
+			wantOwner: "GNOME",
+			wantRepo:  "gnome-builder",
+			wantErr:   false,
+		},
+		{
+			name:      "Invalid - GitLab group only",
+			url:       "https://gitlab.com/group/-/releases",
+			wantHost:  "",
 			wantOwner: "",
 			wantRepo:  "",
 			wantErr:   true,
@@ -72,11 +188,14 @@ func TestParseRepoURL(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			owner, repo, err := ParseRepoURL(tt.url)
+			host, owner, repo, err := ParseRepoURL(tt.url)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ParseRepoURL() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if host != tt.wantHost {
+				t.Errorf("ParseRepoURL() host = %v, want %v", host, tt.wantHost)
+			}
 			if owner != tt.wantOwner {
 				t.Errorf("ParseRepoURL() owner = %v, want %v", owner, tt.wantOwner)
 			}
@@ -87,6 +206,540 @@ func TestParseRepoURL(t *testing.T) {
 	}
 }
 
+func TestNewerTag(t *testing.T) {
+	tests := []struct {
+		name      string
+		latestTag string
+		tags      []string
+		want      string
+	}{
+		{
+			name:      "Tag newer than latest release",
+			latestTag: "v1.2.0",
+			tags:      []string{"v1.3.1", "v1.3.0", "v1.2.0", "v1.1.0"},
+			want:      "v1.3.1",
+		},
+		{
+			name:      "Latest release is newest",
+			latestTag: "v1.2.0",
+			tags:      []string{"v1.2.0", "v1.1.0", "v1.0.0"},
+			want:      "",
+		},
+		{
+			name:      "Numeric ordering",
+			latestTag: "v1.9.0",
+			tags:      []string{"v1.9.0", "v1.10.0"},
+			want:      "v1.10.0",
+		},
+		{
+			name:      "Non-version tags ignored",
+			latestTag: "v2.0.0",
+			tags:      []string{"nightly", "latest", "v2.0.0"},
+			want:      "",
+		},
+		{
+			name:      "No tags",
+			latestTag: "v1.0.0",
+			tags:      nil,
+			want:      "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewerTag(tt.latestTag, tt.tags); got != tt.want {
+				t.Errorf("NewerTag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatestTag(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want string
+	}{
+		{"Highest version wins", []string{"v1.9.0", "v1.10.0", "v1.2.0"}, "v1.10.0"},
+		{"Non-version tags ignored", []string{"nightly", "v2.0.0", "latest"}, "v2.0.0"},
+		{"Only non-version tags", []string{"stable", "old"}, "stable"},
+		{"No tags", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LatestTag(tt.tags); got != tt.want {
+				t.Errorf("LatestTag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagRelease(t *testing.T) {
+	tests := []struct {
+		tag       string
+		wantAsset string
+	}{
+		{"v1.2.3", "tool-1.2.3.tar.gz"},
+		{"1.2.3", "tool-1.2.3.tar.gz"},
+		{"V2.0", "tool-2.0.tar.gz"},
+		{"version-x", "tool-version-x.tar.gz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			archiveURL := "https://github.com/owner/tool/archive/refs/tags/" + tt.tag + ".tar.gz"
+			release := TagRelease("tool", tt.tag, archiveURL)
+			if release.TagName != tt.tag {
+				t.Errorf("TagName = %v, want %v", release.TagName, tt.tag)
+			}
+			if len(release.Assets) != 1 {
+				t.Fatalf("TagRelease() returned %d assets, want 1", len(release.Assets))
+			}
+			asset := release.Assets[0]
+			if asset.Name != tt.wantAsset {
+				t.Errorf("Asset name = %v, want %v", asset.Name, tt.wantAsset)
+			}
+			if asset.DownloadURL != archiveURL || asset.BrowserDownloadURL != archiveURL {
+				t.Errorf("Asset URLs = %v, %v, want %v", asset.DownloadURL, asset.BrowserDownloadURL, archiveURL)
+			}
+		})
+	}
+}
+
+func TestListTagsPaginates(t *testing.T) {
+	mux := http.NewServeMux()
+	var server string
+	mux.HandleFunc("/repos/owner/tool/tags", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`[{"name": "v0.1.0"}]`))
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/repos/owner/tool/tags?page=2>; rel="next"`, server))
+		w.Write([]byte(`[{"name": "v1.0.0"}, {"name": "v0.9.0"}]`))
+	})
+	client := newTestClient(t, mux)
+	server = strings.TrimSuffix(client.gh.BaseURL.String(), "/")
+
+	tags, err := client.ListTags("owner", "tool")
+	if err != nil {
+		t.Fatalf("ListTags() error = %v", err)
+	}
+	if got := strings.Join(tags, ","); got != "v1.0.0,v0.9.0,v0.1.0" {
+		t.Errorf("ListTags() = %v, want the tags of both pages", tags)
+	}
+}
+
+func TestGetLatestTag(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/tool/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/repos/owner/tool/tags", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"name": "v0.9.0"}, {"name": "v0.10.0"}, {"name": "snapshot"}]`))
+	})
+	client := newTestClient(t, mux)
+
+	_, err := client.GetLatestRelease("owner", "tool")
+	if !IsNotFound(err) {
+		t.Fatalf("GetLatestRelease() error = %v, want a not found error", err)
+	}
+
+	release, err := client.GetLatestTag("owner", "tool")
+	if err != nil {
+		t.Fatalf("GetLatestTag() error = %v", err)
+	}
+	if release.TagName != "v0.10.0" {
+		t.Errorf("TagName = %v, want v0.10.0", release.TagName)
+	}
+	want := "https://github.com/owner/tool/archive/refs/tags/v0.10.0.tar.gz"
+	if got := release.Assets[0].DownloadURL; got != want {
+		t.Errorf("DownloadURL = %v, want %v", got, want)
+	}
+}
+
+func TestGetReleaseByTag(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/tool/releases/tags/v1.0.0", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"tag_name": "v1.0.0", "name": "1.0.0", "assets": [
+			{"name": "tool-linux-amd64.tar.gz", "browser_download_url": "https://example.com/tool-linux-amd64.tar.gz"}]}`))
+	})
+	mux.HandleFunc("/repos/owner/tool/git/ref/tags/v0.9.0", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ref": "refs/tags/v0.9.0", "object": {"type": "commit", "sha": "abc123"}}`))
+	})
+	client := newTestClient(t, mux)
+
+	tests := []struct {
+		tag         string
+		wantURL     string
+		wantFromTag bool
+		wantErr     error
+	}{
+		{"v1.0.0", "https://example.com/tool-linux-amd64.tar.gz", false, nil},
+		{"v0.9.0", "https://github.com/owner/tool/archive/refs/tags/v0.9.0.tar.gz", true, nil},
+		{"v0.1.0", "", false, ErrTagNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			release, err := client.GetReleaseByTag("owner", "tool", tt.tag)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetReleaseByTag() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetReleaseByTag() error = %v", err)
+			}
+			if release.TagName != tt.tag || release.FromTag != tt.wantFromTag {
+				t.Errorf("GetReleaseByTag() = %s (from tag: %v), want %s (from tag: %v)", release.TagName, release.FromTag, tt.tag, tt.wantFromTag)
+			}
+			if got := release.Assets[0].DownloadURL; got != tt.wantURL {
+				t.Errorf("DownloadURL = %v, want %v", got, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestGetRepository(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/tool", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"description": "A tool", "homepage": "https://tool.dev", "stargazers_count": 7,
+			"default_branch": "trunk", "license": {"spdx_id": "MIT"}}`))
+	})
+	client := newTestClient(t, mux)
+
+	got, err := client.GetRepository("owner", "tool")
+	if err != nil {
+		t.Fatalf("GetRepository() error = %v", err)
+	}
+	want := &Repository{Owner: "owner", Name: "tool", Description: "A tool", Homepage: "https://tool.dev",
+		License: "MIT", Stars: 7, DefaultBranch: "trunk"}
+	if *got != *want {
+		t.Errorf("GetRepository() = %+v, want %+v", got, want)
+	}
+}
+
+func TestGetRepositoryNotFound(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		wantNotFound bool
+	}{
+		{"missing or private", http.StatusNotFound, true},
+		{"server error", http.StatusInternalServerError, false},
+		{"forbidden", http.StatusForbidden, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/owner/tool", func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"message": "failed"}`, tt.status)
+			})
+			client := newTestClient(t, mux)
+
+			_, err := client.GetRepository("owner", "tool")
+			if err == nil {
+				t.Fatal("GetRepository() expected an error")
+			}
+			if got := errors.Is(err, ErrRepoNotFound); got != tt.wantNotFound {
+				t.Errorf("GetRepository() error = %v, ErrRepoNotFound = %v, want %v", err, got, tt.wantNotFound)
+			}
+			if tt.wantNotFound && !strings.Contains(err.Error(), "GITHUB_TOKEN") {
+				t.Errorf("GetRepository() error %q should point at the token scope", err)
+			}
+		})
+	}
+}
+
+func TestGetAllReleases(t *testing.T) {
+	var pages []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/tool/releases", func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		switch page {
+		case "", "1":
+			w.Header().Set("Link", `<https://api.github.com/repositories/1/releases?per_page=100&page=2>; rel="next", `+
+				`<https://api.github.com/repositories/1/releases?per_page=100&page=2>; rel="last"`)
+			w.Write([]byte(`[{"tag_name": "v3.0.0"}, {"tag_name": "v2.0.0"}]`))
+		case "2":
+			w.Header().Set("Link", `<https://api.github.com/repositories/1/releases?per_page=100&page=1>; rel="first"`)
+			w.Write([]byte(`[{"tag_name": "v1.0.0", "prerelease": true}]`))
+		default:
+			t.Errorf("Unexpected page %q", page)
+			w.Write([]byte(`[]`))
+		}
+	})
+	client := newTestClient(t, mux)
+
+	releases, err := client.GetAllReleases("owner", "tool")
+	if err != nil {
+		t.Fatalf("GetAllReleases() error = %v", err)
+	}
+
+	var tags []string
+	for _, release := range releases {
+		tags = append(tags, release.TagName)
+	}
+	if want := []string{"v3.0.0", "v2.0.0", "v1.0.0"}; strings.Join(tags, ",") != strings.Join(want, ",") {
+		t.Errorf("GetAllReleases() tags = %v, want %v", tags, want)
+	}
+	if !releases[2].Prerelease {
+		t.Error("GetAllReleases() lost the prerelease flag on the second page")
+	}
+	if len(pages) != 2 {
+		t.Errorf("GetAllReleases() fetched pages %q, want 2 pages", pages)
+	}
+}
+
+func TestGetLicense(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/user/mit/license", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"LICENSE","path":"LICENSE","license":{"key":"mit","name":"MIT License","spdx_id":"MIT"}}`))
+	})
+	mux.HandleFunc("/repos/user/custom/license", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"LICENSE.txt","path":"LICENSE.txt","license":{"key":"other","name":"Other","spdx_id":"NOASSERTION"}}`))
+	})
+	mux.HandleFunc("/repos/user/unlicensed/license", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	})
+
+	client := newTestClient(t, mux)
+
+	tests := []struct {
+		name        string
+		repo        string
+		wantSPDX    string
+		wantPath    string
+		wantHasFile bool
+	}{
+		{"Recognized license", "mit", "MIT", "LICENSE", true},
+		{"Custom license file", "custom", "NOASSERTION", "LICENSE.txt", true},
+		{"No license file", "unlicensed", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			license, err := client.GetLicense("user", tt.repo)
+			if err != nil {
+				t.Fatalf("GetLicense() error = %v", err)
+			}
+			if license.SPDXID != tt.wantSPDX {
+				t.Errorf("GetLicense() SPDXID = %v, want %v", license.SPDXID, tt.wantSPDX)
+			}
+			if license.Path != tt.wantPath {
+				t.Errorf("GetLicense() Path = %v, want %v", license.Path, tt.wantPath)
+			}
+			if license.HasFile != tt.wantHasFile {
+				t.Errorf("GetLicense() HasFile = %v, want %v", license.HasFile, tt.wantHasFile)
+			}
+		})
+	}
+}
+
+func TestGetFileContent(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/user/tool/contents/COPYING", func(w http.ResponseWriter, r *http.Request) {
+		// "MIT License\n" base64-encoded, wrapped like the API does
+		w.Write([]byte(`{"type":"file","name":"COPYING","path":"COPYING","encoding":"base64","content":"TUlUIExp\nY2Vuc2UK\n"}`))
+	})
+	mux.HandleFunc("/repos/user/tool/contents/docs", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"type":"file","name":"index.md","path":"docs/index.md"}]`))
+	})
+	mux.HandleFunc("/repos/user/tool/contents/LICENSE", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	})
+
+	client := newTestClient(t, mux)
+
+	content, err := client.GetFileContent("user", "tool", "COPYING")
+	if err != nil {
+		t.Fatalf("GetFileContent() error = %v", err)
+	}
+	if content != "MIT License\n" {
+		t.Errorf("GetFileContent() = %q, want %q", content, "MIT License\n")
+	}
+
+	for _, path := range []string{"LICENSE", "docs"} {
+		if _, err := client.GetFileContent("user", "tool", path); err == nil {
+			t.Errorf("GetFileContent(%q) expected error", path)
+		}
+	}
+}
+
+func TestGetRepoTree(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/user/tool/git/trees/HEAD", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("recursive") == "" {
+			t.Error("GetRepoTree() should request a recursive tree")
+		}
+		w.Write([]byte(`{"sha":"abc","tree":[
+			{"path":"go.mod","type":"blob"},
+			{"path":"cmd","type":"tree"},
+			{"path":"cmd/tool","type":"tree"},
+			{"path":"cmd/tool/main.go","type":"blob"}
+		]}`))
+	})
+
+	client := newTestClient(t, mux)
+
+	files, err := client.GetRepoTree("user", "tool")
+	if err != nil {
+		t.Fatalf("GetRepoTree() error = %v", err)
+	}
+
+	want := []string{"go.mod", "cmd/tool/main.go"}
+	if len(files) != len(want) {
+		t.Fatalf("GetRepoTree() = %v, want %v", files, want)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("GetRepoTree()[%d] = %v, want %v", i, files[i], want[i])
+		}
+	}
+}
+
+func TestGetRepoTreeDefaultBranch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/user/tool", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"default_branch":"trunk"}`))
+	})
+	mux.HandleFunc("/repos/user/tool/git/trees/trunk", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"sha":"abc","tree":[{"path":"Cargo.toml","type":"blob"}]}`))
+	})
+
+	client := newTestClient(t, mux)
+
+	if _, err := client.GetRepository("user", "tool"); err != nil {
+		t.Fatalf("GetRepository() error = %v", err)
+	}
+	files, err := client.GetRepoTree("user", "tool")
+	if err != nil {
+		t.Fatalf("GetRepoTree() error = %v", err)
+	}
+	if len(files) != 1 || files[0] != "Cargo.toml" {
+		t.Errorf("GetRepoTree() = %v, want [Cargo.toml]", files)
+	}
+}
+
+func TestEmptyRepository(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/user/empty", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"default_branch":"main"}`))
+	})
+	mux.HandleFunc("/repos/user/empty/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"message":"Git Repository is empty."}`))
+	})
+	mux.HandleFunc("/repos/user/empty/contents/", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("ref"); got != "main" {
+			t.Errorf("GetRepoFiles() ref = %q, want main", got)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"This repository is empty."}`))
+	})
+	mux.HandleFunc("/repos/user/broken/contents/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/repos/user/missing/contents/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"Not Found"}`))
+	})
+
+	client := newTestClient(t, mux)
+	if _, err := client.GetRepository("user", "empty"); err != nil {
+		t.Fatalf("GetRepository() error = %v", err)
+	}
+
+	for name, fetch := range map[string]func(owner, repo string) ([]string, error){
+		"GetRepoTree":  client.GetRepoTree,
+		"GetRepoFiles": client.GetRepoFiles,
+	} {
+		t.Run(name, func(t *testing.T) {
+			files, err := fetch("user", "empty")
+			if err != nil {
+				t.Fatalf("%s() error = %v", name, err)
+			}
+			if files == nil || len(files) != 0 {
+				t.Errorf("%s() = %#v, want empty list", name, files)
+			}
+		})
+	}
+
+	t.Run("Server error", func(t *testing.T) {
+		if _, err := client.GetRepoFiles("user", "broken"); err == nil {
+			t.Error("GetRepoFiles() expected error for a server error")
+		}
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		if _, err := client.GetRepoFiles("user", "missing"); err == nil {
+			t.Error("GetRepoFiles() expected error for a 404 that is not an empty repository")
+		}
+	})
+}
+
+func TestCheckPullRequestScopes(t *testing.T) {
+	classic := func(scopes string) http.Header {
+		h := http.Header{}
+		h.Set("X-OAuth-Scopes", scopes)
+		return h
+	}
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		private bool
+		canPush bool
+		wantErr string
+	}{
+		{"Classic token with repo scope", classic("repo, read:org"), true, true, ""},
+		{"Classic token with public_repo on public repo", classic("public_repo"), false, true, ""},
+		{"Classic token with public_repo on private repo", classic("public_repo"), true, true, "'repo' scope"},
+		{"Classic token without scopes", classic(""), false, true, "'repo' scope"},
+		{"Fine-grained token with write access", http.Header{}, false, true, ""},
+		{"Fine-grained token without write access", http.Header{}, false, false, "Pull requests: write"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPullRequestScopes(tt.header, "user/tap", tt.private, tt.canPush)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkPullRequestScopes() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkPullRequestScopes() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckPullRequestAccess(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/user/readonly", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"full_name":"user/readonly","private":false,"permissions":{"pull":true,"push":false}}`))
+	})
+	mux.HandleFunc("/repos/user/writable", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-OAuth-Scopes", "repo")
+		w.Write([]byte(`{"full_name":"user/writable","private":true,"permissions":{"pull":true,"push":true}}`))
+	})
+
+	client := newTestClient(t, mux)
+
+	if err := client.CheckPullRequestAccess("user", "readonly"); err == nil {
+		t.Error("CheckPullRequestAccess() should fail without push access")
+	}
+	if err := client.CheckPullRequestAccess("user", "writable"); err != nil {
+		t.Errorf("CheckPullRequestAccess() error = %v", err)
+	}
+}
+
 func TestNewClient(t *testing.T) {
 	// Test that we can create a client without errors
 	client := NewClient()
@@ -105,6 +758,28 @@ func TestNewClient(t *testing.T) {
 // mocking the GitHub API or using integration tests with real API calls.
 // For unit tests, we focus on testing the parsing and conversion logic.
 
+func TestPlatformAssets(t *testing.T) {
+	release := &Release{Assets: []*Asset{
+		{Name: "tool-linux-amd64.tar.gz", URL: "api/1", BrowserDownloadURL: "https://example.com/tool-linux-amd64.tar.gz", Size: 42},
+		{Name: "tool-darwin-arm64.zip", URL: "api/2", BrowserDownloadURL: "https://example.com/tool-darwin-arm64.zip"},
+	}}
+
+	assets := release.PlatformAssets()
+	if len(assets) != 2 {
+		t.Fatalf("PlatformAssets() returned %d assets, want 2", len(assets))
+	}
+	got := assets[0]
+	if got.Name != "tool-linux-amd64.tar.gz" || got.URL != "api/1" || got.DownloadURL != "https://example.com/tool-linux-amd64.tar.gz" || got.Size != 42 {
+		t.Errorf("PlatformAssets()[0] = %+v, want the release asset's name, URLs and size", got)
+	}
+	if got.Platform != platform.PlatformLinux || got.Format != platform.FormatTarGz {
+		t.Errorf("PlatformAssets()[0] = %s %s, want a Linux tarball", got.Platform, got.Format)
+	}
+	if assets[1].Platform == platform.PlatformLinux {
+		t.Errorf("PlatformAssets()[1] platform = %s, want non-Linux", assets[1].Platform)
+	}
+}
+
 func TestConvertRelease(t *testing.T) {
 	// This is tested indirectly through the other methods
 	// We would need to mock github.RepositoryRelease for full coverage
@@ -0,0 +1,78 @@
+package github
+
+import (
+	"fmt"
+	"os"
+)
+
+// GitHubHost is the host of repositories given without one
+const GitHubHost = "github.com"
+
+// Forge is a code hosting service that packages are generated from
+// *Client implements it for GitHub and the gitlab package for GitLab.
+type Forge interface {
+	GetRepository(owner, repo string) (*Repository, error)
+	GetLatestRelease(owner, repo string) (*Release, error)
+	GetReleaseByTag(owner, repo, tag string) (*Release, error)
+	GetLatestTag(owner, repo string) (*Release, error)
+	GetRepoFiles(owner, repo string) ([]string, error)
+	GetRepoTree(owner, repo string) ([]string, error)
+	GetLicense(owner, repo string) (*License, error)
+	GetFileContent(owner, repo, path string) (string, error)
+	ListTags(owner, repo string) ([]string, error)
+}
+
+// Client must satisfy Forge
+var _ Forge = (*Client)(nil)
+
+// GetRelease returns the release of tag, or the latest release when tag is
+// empty, for commands that can pin a version
+func GetRelease(f Forge, owner, repo, tag string) (*Release, error) {
+	if tag == "" {
+		return f.GetLatestRelease(owner, repo)
+	}
+	return f.GetReleaseByTag(owner, repo, tag)
+}
+
+// GitLabHostEnv names the environment variable holding the host of a
+// self-managed GitLab instance that is not in gitLabHosts
+const GitLabHostEnv = "GITLAB_HOST"
+
+// gitLabHosts are the public GitLab instances that projects are packaged from
+var gitLabHosts = map[string]bool{
+	"gitlab.com":             true,
+	"gitlab.gnome.org":       true,
+	"gitlab.freedesktop.org": true,
+	"gitlab.archlinux.org":   true,
+	"invent.kde.org":         true,
+	"salsa.debian.org":       true,
+	"framagit.org":           true,
+}
+
+// IsGitLab reports whether host is gitlab.com, a well-known public GitLab
+// instance, or the self-managed instance named by GITLAB_HOST
+func IsGitLab(host string) bool {
+	return gitLabHosts[host] || (host != "" && host == os.Getenv(GitLabHostEnv))
+}
+
+// RepoURL returns the web page of a repository
+func RepoURL(host, owner, repo string) string {
+	return fmt.Sprintf("https://%s/%s/%s", host, owner, repo)
+}
+
+// SourceArchiveURL returns the source tarball of a tag
+func SourceArchiveURL(host, owner, repo, tag string) string {
+	if IsGitLab(host) {
+		return fmt.Sprintf("https://%s/%s/%s/-/archive/%s/%s-%s.tar.gz", host, owner, repo, tag, repo, tag)
+	}
+	return fmt.Sprintf("https://%s/%s/%s/archive/%s.tar.gz", host, owner, repo, tag)
+}
+
+// ReleasesFeedURL returns an Atom feed of a repository's releases, for
+// livecheck. GitLab has no releases feed, so its tags feed is used.
+func ReleasesFeedURL(host, owner, repo string) string {
+	if IsGitLab(host) {
+		return fmt.Sprintf("https://%s/%s/%s/-/tags?format=atom", host, owner, repo)
+	}
+	return fmt.Sprintf("https://%s/%s/%s/releases.atom", host, owner, repo)
+}
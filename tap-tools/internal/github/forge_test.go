@@ -0,0 +1,95 @@
+package github
+
+import "testing"
+
+func TestIsGitLab(t *testing.T) {
+	tests := []struct {
+		host    string
+		envHost string
+		want    bool
+	}{
+		{"gitlab.com", "", true},
+		{"gitlab.gnome.org", "", true},
+		{"invent.kde.org", "", true},
+		{"github.com", "", false},
+		{"gitlab.example.org", "", false},
+		{"gitlab.com.evil.example", "", false},
+		{"git.example.org", "git.example.org", true},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			t.Setenv(GitLabHostEnv, tt.envHost)
+			if got := IsGitLab(tt.host); got != tt.want {
+				t.Errorf("IsGitLab(%q) = %v, want %v", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForgeURLs(t *testing.T) {
+	tests := []struct {
+		name        string
+		host, owner string
+		wantArchive string
+		wantFeed    string
+	}{
+		{"GitHub", "github.com", "owner", "https://github.com/owner/tool/archive/v1.0.0.tar.gz", "https://github.com/owner/tool/releases.atom"},
+		{"GitLab", "gitlab.com", "group/sub", "https://gitlab.com/group/sub/tool/-/archive/v1.0.0/tool-v1.0.0.tar.gz", "https://gitlab.com/group/sub/tool/-/tags?format=atom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SourceArchiveURL(tt.host, tt.owner, "tool", "v1.0.0"); got != tt.wantArchive {
+				t.Errorf("SourceArchiveURL() = %s, want %s", got, tt.wantArchive)
+			}
+			if got := ReleasesFeedURL(tt.host, tt.owner, "tool"); got != tt.wantFeed {
+				t.Errorf("ReleasesFeedURL() = %s, want %s", got, tt.wantFeed)
+			}
+		})
+	}
+}
+
+// fakeForge records which release lookup GetRelease used
+type fakeForge struct {
+	Forge
+	calls []string
+}
+
+func (f *fakeForge) GetLatestRelease(owner, repo string) (*Release, error) {
+	f.calls = append(f.calls, "latest")
+	return &Release{TagName: "v2.0.0"}, nil
+}
+
+func (f *fakeForge) GetReleaseByTag(owner, repo, tag string) (*Release, error) {
+	f.calls = append(f.calls, "tag "+tag)
+	return &Release{TagName: tag}, nil
+}
+
+func TestGetRelease(t *testing.T) {
+	tests := []struct {
+		version  string
+		wantTag  string
+		wantCall string
+	}{
+		{"", "v2.0.0", "latest"},
+		{"v1.0.0", "v1.0.0", "tag v1.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantCall, func(t *testing.T) {
+			forge := &fakeForge{}
+			release, err := GetRelease(forge, "owner", "tool", tt.version)
+			if err != nil {
+				t.Fatalf("GetRelease() error = %v", err)
+			}
+			if release.TagName != tt.wantTag {
+				t.Errorf("GetRelease() tag = %s, want %s", release.TagName, tt.wantTag)
+			}
+			if len(forge.calls) != 1 || forge.calls[0] != tt.wantCall {
+				t.Errorf("GetRelease() calls = %v, want [%s]", forge.calls, tt.wantCall)
+			}
+		})
+	}
+}
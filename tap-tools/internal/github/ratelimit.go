@@ -0,0 +1,177 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// RateLimitOptions controls how the client tracks the GitHub API rate limit
+type RateLimitOptions struct {
+	// MaxAge is how long a fetched rate limit is trusted before re-checking
+	MaxAge time.Duration
+
+	// Reserve is the remaining request count at which the client stops and
+	// sleeps until the limit resets
+	Reserve int
+}
+
+// DefaultRateLimitOptions returns the rate limit options used by NewClient
+func DefaultRateLimitOptions() RateLimitOptions {
+	return RateLimitOptions{
+		MaxAge:  time.Minute,
+		Reserve: 5,
+	}
+}
+
+// rateLimitTracker caches the last known core rate limit so that a batch of
+// calls shares one RateLimits request instead of issuing one per call.
+// It is safe for concurrent use.
+type rateLimitTracker struct {
+	mu   sync.Mutex
+	opts RateLimitOptions
+
+	remaining int
+	limit     int
+	reset     time.Time
+	checkedAt time.Time
+	warnedFor time.Time // reset time of the window already warned about
+
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// newRateLimitTracker creates a tracker that has not fetched the limit yet
+func newRateLimitTracker(opts RateLimitOptions) *rateLimitTracker {
+	return &rateLimitTracker{
+		opts:  opts,
+		now:   time.Now,
+		sleep: sleepContext,
+	}
+}
+
+// sleepContext waits for d, returning early with an error if ctx ends
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// stale reports whether the cached limit must be fetched again: it was never
+// fetched, it is older than MaxAge, or its reset time has passed
+func (r *rateLimitTracker) stale(now time.Time) bool {
+	if r.checkedAt.IsZero() {
+		return true
+	}
+	if now.Sub(r.checkedAt) >= r.opts.MaxAge {
+		return true
+	}
+	return !r.reset.IsZero() && !now.Before(r.reset)
+}
+
+// record stores a freshly fetched rate limit
+func (r *rateLimitTracker) record(remaining, limit int, reset, now time.Time) {
+	r.remaining = remaining
+	r.limit = limit
+	r.reset = reset
+	r.checkedAt = now
+}
+
+// consume counts one request against the cached remaining budget so the
+// estimate stays close to the server's count between fetches
+func (r *rateLimitTracker) consume() {
+	if r.remaining > 0 {
+		r.remaining--
+	}
+}
+
+// throttleDelay returns how long to wait before the next request, or zero
+// when the remaining budget is above the reserve or the reset has passed
+func (r *rateLimitTracker) throttleDelay(now time.Time) time.Duration {
+	if r.checkedAt.IsZero() || r.remaining > r.opts.Reserve {
+		return 0
+	}
+	if !now.Before(r.reset) {
+		return 0
+	}
+	// Wait a little past the reset so the server has rolled the window over
+	return r.reset.Sub(now) + time.Second
+}
+
+// shouldWarn reports whether the remaining budget is low enough to warn
+// about, at most once per reset window
+func (r *rateLimitTracker) shouldWarn() bool {
+	// Warn if less than 100 requests remaining or less than 10% of limit
+	threshold := 100
+	if r.limit < 1000 {
+		threshold = r.limit / 10
+	}
+
+	if r.remaining >= threshold || r.warnedFor.Equal(r.reset) {
+		return false
+	}
+	r.warnedFor = r.reset
+	return true
+}
+
+// CheckRateLimit monitors GitHub API rate limit and warns if running low.
+// The limit is fetched only when the cached value is stale, and the call
+// sleeps until the reset when the remaining budget hits the reserve, or
+// until the client's context ends.
+func (c *Client) CheckRateLimit() error {
+	delay := c.rateLimitDelay()
+	if delay == 0 {
+		return nil
+	}
+	// Sleep without the lock so other calls are not queued behind it
+	fmt.Fprintf(os.Stderr, "⏳ GitHub API rate limit nearly exhausted, waiting %s for reset\n", delay.Round(time.Second))
+	return c.rate.sleep(c.ctx, delay)
+}
+
+// rateLimitDelay refreshes the cached limit when stale, warns when it runs
+// low, and returns how long to wait for the reset before the next request
+func (c *Client) rateLimitDelay() time.Duration {
+	r := c.rate
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stale(r.now()) {
+		rateLimit, _, err := c.gh.RateLimits(c.ctx)
+		if err != nil {
+			// Warn that the rate limit check failed, but don't block execution.
+			fmt.Fprintf(os.Stderr, "⚠️  Could not check GitHub API rate limit: %v\n", err)
+			return 0
+		}
+		r.record(rateLimit.Core.Remaining, rateLimit.Core.Limit, rateLimit.Core.Reset.Time, r.now())
+	}
+
+	if r.shouldWarn() {
+		fmt.Fprintf(os.Stderr, "⚠️  GitHub API rate limit low: %d/%d remaining\n", r.remaining, r.limit)
+		fmt.Fprintf(os.Stderr, "   Resets at: %s (in %s)\n",
+			r.reset.Format(time.RFC3339),
+			time.Until(r.reset).Round(time.Minute))
+
+		if r.limit == 60 {
+			fmt.Fprintf(os.Stderr, "   ℹ️  Using unauthenticated rate limit (60/hour)\n")
+			fmt.Fprintf(os.Stderr, "   💡 Set GITHUB_TOKEN to increase limit to 5,000/hour\n")
+			fmt.Fprintf(os.Stderr, "      Run: export GITHUB_TOKEN=$(gh auth token)\n")
+		}
+	}
+
+	if delay := r.throttleDelay(r.now()); delay > 0 {
+		// Force a fresh fetch on the next call
+		r.checkedAt = time.Time{}
+		return delay
+	}
+
+	r.consume()
+	return 0
+}
@@ -0,0 +1,177 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitTrackerStale(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		checkedAt time.Time
+		reset     time.Time
+		want      bool
+	}{
+		{"never fetched", time.Time{}, time.Time{}, true},
+		{"fresh", now.Add(-10 * time.Second), now.Add(time.Hour), false},
+		{"older than max age", now.Add(-2 * time.Minute), now.Add(time.Hour), true},
+		{"exactly max age", now.Add(-time.Minute), now.Add(time.Hour), true},
+		{"reset passed", now.Add(-10 * time.Second), now.Add(-time.Second), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRateLimitTracker(RateLimitOptions{MaxAge: time.Minute, Reserve: 5})
+			r.checkedAt = tt.checkedAt
+			r.reset = tt.reset
+			if got := r.stale(now); got != tt.want {
+				t.Errorf("stale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitTrackerThrottleDelay(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		remaining int
+		reset     time.Time
+		checked   bool
+		want      time.Duration
+	}{
+		{"plenty remaining", 4000, now.Add(time.Hour), true, 0},
+		{"just above reserve", 6, now.Add(time.Hour), true, 0},
+		{"at reserve", 5, now.Add(10 * time.Minute), true, 10*time.Minute + time.Second},
+		{"exhausted", 0, now.Add(30 * time.Second), true, 31 * time.Second},
+		{"exhausted but reset passed", 0, now.Add(-time.Second), true, 0},
+		{"never fetched", 0, time.Time{}, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRateLimitTracker(RateLimitOptions{MaxAge: time.Minute, Reserve: 5})
+			if tt.checked {
+				r.record(tt.remaining, 5000, tt.reset, now)
+			}
+			if got := r.throttleDelay(now); got != tt.want {
+				t.Errorf("throttleDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitTrackerShouldWarn(t *testing.T) {
+	reset := time.Date(2025, 1, 1, 13, 0, 0, 0, time.UTC)
+	r := newRateLimitTracker(DefaultRateLimitOptions())
+
+	r.record(4000, 5000, reset, reset.Add(-time.Hour))
+	if r.shouldWarn() {
+		t.Error("shouldWarn() = true with plenty remaining")
+	}
+
+	r.record(50, 5000, reset, reset.Add(-time.Hour))
+	if !r.shouldWarn() {
+		t.Error("shouldWarn() = false with 50/5000 remaining")
+	}
+	if r.shouldWarn() {
+		t.Error("shouldWarn() warned twice in the same reset window")
+	}
+
+	r.record(40, 5000, reset.Add(time.Hour), reset)
+	if !r.shouldWarn() {
+		t.Error("shouldWarn() = false in a new reset window")
+	}
+}
+
+// rateLimitHandler serves a fixed core rate limit and counts requests
+func rateLimitHandler(calls *int32, remaining int, reset time.Time) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		fmt.Fprintf(w, `{"resources":{"core":{"limit":5000,"remaining":%d,"reset":%d}}}`, remaining, reset.Unix())
+	}
+}
+
+func TestCheckRateLimitCaches(t *testing.T) {
+	var calls int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rate_limit", rateLimitHandler(&calls, 4000, time.Now().Add(time.Hour)))
+	client := newTestClient(t, mux)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.CheckRateLimit()
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("RateLimits fetched %d times, want 1", calls)
+	}
+	if client.rate.remaining != 4000-20 {
+		t.Errorf("remaining = %d, want %d", client.rate.remaining, 4000-20)
+	}
+}
+
+func TestCheckRateLimitThrottles(t *testing.T) {
+	var calls int32
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rate_limit", rateLimitHandler(&calls, 2, reset))
+	client := newTestClient(t, mux)
+
+	var slept time.Duration
+	client.rate.sleep = func(ctx context.Context, d time.Duration) error {
+		slept = d
+		return nil
+	}
+
+	client.CheckRateLimit()
+	if slept <= 0 || slept > time.Hour+time.Second {
+		t.Errorf("slept %v, want until reset (~1h)", slept)
+	}
+
+	// After sleeping the limit must be fetched again
+	client.CheckRateLimit()
+	if calls != 2 {
+		t.Errorf("RateLimits fetched %d times, want 2", calls)
+	}
+}
+
+func TestCheckRateLimitCancelled(t *testing.T) {
+	client := newTestClient(t, http.NewServeMux())
+	client.rate.record(2, 5000, time.Now().Add(time.Hour), time.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client.ctx = ctx
+
+	done := make(chan error, 1)
+	go func() { done <- client.CheckRateLimit() }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("CheckRateLimit() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("CheckRateLimit() kept sleeping after the context ended")
+	}
+
+	// The lock is not held while sleeping
+	if !client.rate.mu.TryLock() {
+		t.Fatal("rate limit lock still held after CheckRateLimit()")
+	}
+	client.rate.mu.Unlock()
+}
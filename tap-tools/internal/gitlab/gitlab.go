@@ -0,0 +1,387 @@
+// Package gitlab implements the github.Forge interface for gitlab.com and
+// self-managed GitLab instances
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/castrojo/tap-tools/internal/github"
+)
+
+// TokenEnv names the environment variable holding an optional GitLab token,
+// needed for private projects and higher rate limits
+const TokenEnv = "GITLAB_TOKEN"
+
+// Client talks to the REST API of gitlab.com or a self-managed instance
+type Client struct {
+	host    string
+	baseURL string // API root, e.g. https://gitlab.com/api/v4
+	token   string
+	http    *http.Client
+	ctx     context.Context
+}
+
+// Client must satisfy Forge
+var _ github.Forge = (*Client)(nil)
+
+// gitlabProject is the subset of the GitLab project API used here
+type gitlabProject struct {
+	Description   string `json:"description"`
+	WebURL        string `json:"web_url"`
+	StarCount     int    `json:"star_count"`
+	DefaultBranch string `json:"default_branch"`
+	LicenseURL    string `json:"license_url"`
+	License       *struct {
+		Name string `json:"name"`
+	} `json:"license"`
+}
+
+// gitlabRelease is the subset of the GitLab release API used here
+type gitlabRelease struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	ReleasedAt  time.Time `json:"released_at"`
+	Upcoming    bool      `json:"upcoming_release"`
+	Assets      struct {
+		Links []struct {
+			Name           string `json:"name"`
+			URL            string `json:"url"`
+			DirectAssetURL string `json:"direct_asset_url"`
+		} `json:"links"`
+	} `json:"assets"`
+}
+
+// gitlabTreeEntry is one entry of the GitLab repository tree API
+type gitlabTreeEntry struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+// NewClient creates a client for the GitLab instance at host
+// It will use the GITLAB_TOKEN environment variable if set
+func NewClient(host string) *Client {
+	return &Client{
+		host:    host,
+		baseURL: "https://" + host + "/api/v4",
+		token:   os.Getenv(TokenEnv),
+		http:    github.CachedHTTPClient(nil),
+		ctx:     context.Background(),
+	}
+}
+
+// projectPath returns the API path of a project; GitLab identifies projects
+// by their URL-encoded full path, nested groups included
+func projectPath(owner, repo string) string {
+	return "/projects/" + url.PathEscape(owner+"/"+repo)
+}
+
+// do makes a GET request to the API and returns the response for the caller
+// to close. A 404 is reported as github.ErrNotFound.
+func (c *Client) do(path string, query url.Values) (*http.Response, error) {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("GET %s: %w", path, github.ErrNotFound)
+		}
+		return nil, fmt.Errorf("GET %s: HTTP %d", path, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// get decodes a JSON API response into v and returns the next page number
+// from the pagination headers, or "" on the last page
+func (c *Client) get(path string, query url.Values, v any) (string, error) {
+	resp, err := c.do(path, query)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return "", fmt.Errorf("GET %s: %w", path, err)
+	}
+	return resp.Header.Get("X-Next-Page"), nil
+}
+
+// project fetches a project, including its detected license
+func (c *Client) project(owner, repo string) (*gitlabProject, error) {
+	var p gitlabProject
+	if _, err := c.get(projectPath(owner, repo), url.Values{"license": {"true"}}, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetRepository fetches project metadata
+// GitLab has no separate homepage field, so the project page is used.
+// The license is left for GetLicense, as GitLab does not report SPDX IDs.
+func (c *Client) GetRepository(owner, repo string) (*github.Repository, error) {
+	p, err := c.project(owner, repo)
+	if errors.Is(err, github.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s/%s does not exist or is private; "+
+			"check the group and name in the URL, and for a private project "+
+			"that %s has the read_api scope", github.ErrRepoNotFound, owner, repo, TokenEnv)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repository: %w", err)
+	}
+
+	return &github.Repository{
+		Owner:         owner,
+		Name:          repo,
+		Description:   p.Description,
+		Homepage:      p.WebURL,
+		Stars:         p.StarCount,
+		DefaultBranch: p.DefaultBranch,
+	}, nil
+}
+
+// GetLicense reports the project's license file. GitLab names the license
+// but gives no SPDX ID, so SPDXID is empty and callers guess it from Path.
+func (c *Client) GetLicense(owner, repo string) (*github.License, error) {
+	p, err := c.project(owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch license: %w", err)
+	}
+	if p.LicenseURL == "" {
+		return &github.License{HasFile: false}, nil
+	}
+
+	license := &github.License{
+		Path:    strings.TrimPrefix(p.LicenseURL, p.WebURL+"/-/blob/"+p.DefaultBranch+"/"),
+		HasFile: true,
+	}
+	if p.License != nil {
+		license.Name = p.License.Name
+	}
+	return license, nil
+}
+
+// GetFileContent fetches the raw content of a file on the default branch
+func (c *Client) GetFileContent(owner, repo, path string) (string, error) {
+	resp, err := c.do(projectPath(owner, repo)+"/repository/files/"+url.PathEscape(path)+"/raw", url.Values{"ref": {"HEAD"}})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", path, err)
+	}
+	return string(content), nil
+}
+
+// GetLatestRelease fetches the most recent release
+func (c *Client) GetLatestRelease(owner, repo string) (*github.Release, error) {
+	var r gitlabRelease
+	if _, err := c.get(projectPath(owner, repo)+"/releases/permalink/latest", nil, &r); err != nil {
+		return nil, fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	return c.convertRelease(&r), nil
+}
+
+// GetReleaseByTag fetches the release of a tag. A tag without a release
+// gets one synthesized from its source tarball; a missing tag is an error
+// wrapping github.ErrTagNotFound.
+func (c *Client) GetReleaseByTag(owner, repo, tag string) (*github.Release, error) {
+	var r gitlabRelease
+	_, err := c.get(projectPath(owner, repo)+"/releases/"+url.PathEscape(tag), nil, &r)
+	if errors.Is(err, github.ErrNotFound) {
+		var glTag struct {
+			Name string `json:"name"`
+		}
+		_, err := c.get(projectPath(owner, repo)+"/repository/tags/"+url.PathEscape(tag), nil, &glTag)
+		if errors.Is(err, github.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s/%s has no release or tag %q", github.ErrTagNotFound, owner, repo, tag)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch tag %s: %w", tag, err)
+		}
+		return github.TagRelease(repo, tag, github.SourceArchiveURL(c.host, owner, repo, tag)), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release %s: %w", tag, err)
+	}
+	return c.convertRelease(&r), nil
+}
+
+// GetLatestTag synthesizes a release from the newest tag, for projects that
+// tag versions without creating GitLab releases
+func (c *Client) GetLatestTag(owner, repo string) (*github.Release, error) {
+	tags, err := c.ListTags(owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	tag := github.LatestTag(tags)
+	if tag == "" {
+		return nil, fmt.Errorf("project has no releases or tags")
+	}
+	return github.TagRelease(repo, tag, github.SourceArchiveURL(c.host, owner, repo, tag)), nil
+}
+
+// ListTags fetches every tag name of a project, following pagination
+func (c *Client) ListTags(owner, repo string) ([]string, error) {
+	query := url.Values{"per_page": {"100"}}
+
+	var tags []string
+	for page := "1"; page != ""; {
+		query.Set("page", page)
+		var batch []struct {
+			Name string `json:"name"`
+		}
+		next, err := c.get(projectPath(owner, repo)+"/repository/tags", query, &batch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch tags: %w", err)
+		}
+		for _, tag := range batch {
+			tags = append(tags, tag.Name)
+		}
+		page = next
+	}
+	return tags, nil
+}
+
+// convertRelease converts a GitLab release to our internal representation
+// Release assets are links; direct asset URLs are preferred as they stay
+// stable when the link target moves.
+func (c *Client) convertRelease(r *gitlabRelease) *github.Release {
+	assets := make([]*github.Asset, 0, len(r.Assets.Links))
+	for _, link := range r.Assets.Links {
+		downloadURL := link.DirectAssetURL
+		if downloadURL == "" {
+			downloadURL = link.URL
+		}
+		assets = append(assets, &github.Asset{
+			Name:               link.Name,
+			URL:                link.URL,
+			DownloadURL:        downloadURL,
+			BrowserDownloadURL: downloadURL,
+			Size:               c.assetSize(downloadURL),
+		})
+	}
+
+	publishedAt := ""
+	if !r.ReleasedAt.IsZero() {
+		publishedAt = r.ReleasedAt.Format("2006-01-02")
+	}
+
+	return &github.Release{
+		TagName:     r.TagName,
+		Name:        r.Name,
+		Body:        r.Description,
+		Prerelease:  r.Upcoming,
+		PublishedAt: publishedAt,
+		Assets:      assets,
+	}
+}
+
+// assetSize asks the server hosting a release link for its size, as the
+// links API does not report one. An unknown size is 0.
+func (c *Client) assetSize(downloadURL string) int64 {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodHead, downloadURL, nil)
+	if err != nil {
+		return 0
+	}
+	// Only send the token to the GitLab instance itself
+	if c.token != "" && req.URL.Host == c.host {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.ContentLength < 0 {
+		return 0
+	}
+	return resp.ContentLength
+}
+
+// GetRepoFiles fetches the list of files in the repository root
+// An empty repository yields an empty list rather than an error.
+func (c *Client) GetRepoFiles(owner, repo string) ([]string, error) {
+	entries, err := c.tree(owner, repo, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repository contents: %w", err)
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type == "blob" {
+			files = append(files, entry.Name)
+		}
+	}
+	return files, nil
+}
+
+// GetRepoTree fetches the full recursive file listing of the default branch
+// An empty repository yields an empty list rather than an error.
+func (c *Client) GetRepoTree(owner, repo string) ([]string, error) {
+	entries, err := c.tree(owner, repo, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repository tree: %w", err)
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type == "blob" {
+			files = append(files, entry.Path)
+		}
+	}
+	return files, nil
+}
+
+// tree lists the default branch, following pagination. GitLab answers 404
+// for a repository with no commits.
+func (c *Client) tree(owner, repo string, recursive bool) ([]gitlabTreeEntry, error) {
+	query := url.Values{"per_page": {"100"}}
+	if recursive {
+		query.Set("recursive", "true")
+	}
+
+	entries := []gitlabTreeEntry{}
+	for page := "1"; page != ""; {
+		query.Set("page", page)
+		var batch []gitlabTreeEntry
+		next, err := c.get(projectPath(owner, repo)+"/repository/tree", query, &batch)
+		if errors.Is(err, github.ErrNotFound) {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, batch...)
+		page = next
+	}
+	return entries, nil
+}
@@ -0,0 +1,221 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/castrojo/tap-tools/internal/checksum"
+	"github.com/castrojo/tap-tools/internal/github"
+)
+
+// newTestClient returns a Client for a fake API serving the given responses
+// by escaped path, with SERVER replaced by the server's URL. Unknown paths
+// answer 404.
+func newTestClient(t *testing.T, responses map[string]string) *Client {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.EscapedPath()
+		if page := r.URL.Query().Get("page"); page != "" && page != "1" {
+			key += "?page=" + page
+		}
+		body, ok := responses[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if next, ok := responses[key+"#next"]; ok {
+			w.Header().Set("X-Next-Page", next)
+		}
+		w.Write([]byte(strings.ReplaceAll(body, "SERVER", server.URL)))
+	}))
+	t.Cleanup(server.Close)
+
+	return &Client{host: "gitlab.com", baseURL: server.URL + "/api/v4", http: server.Client(), ctx: context.Background()}
+}
+
+const gitlabProjectPath = "/api/v4/projects/group%2Fsub%2Frepo"
+
+func TestGitLabGetRepository(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		gitlabProjectPath: `{"description": "A tool", "web_url": "https://gitlab.com/group/sub/repo", "star_count": 42,
+			"default_branch": "main", "license_url": "https://gitlab.com/group/sub/repo/-/blob/main/COPYING",
+			"license": {"key": "gpl-3.0", "name": "GNU General Public License v3.0"}}`,
+	})
+
+	repository, err := client.GetRepository("group/sub", "repo")
+	if err != nil {
+		t.Fatalf("GetRepository() error = %v", err)
+	}
+	want := &github.Repository{Owner: "group/sub", Name: "repo", Description: "A tool", Homepage: "https://gitlab.com/group/sub/repo", Stars: 42, DefaultBranch: "main"}
+	if !reflect.DeepEqual(repository, want) {
+		t.Errorf("GetRepository() = %+v, want %+v", repository, want)
+	}
+
+	license, err := client.GetLicense("group/sub", "repo")
+	if err != nil {
+		t.Fatalf("GetLicense() error = %v", err)
+	}
+	if !license.HasFile || license.Path != "COPYING" || license.SPDXID != "" {
+		t.Errorf("GetLicense() = %+v, want COPYING with no SPDX ID", license)
+	}
+
+	if _, err := client.GetRepository("missing", "repo"); !errors.Is(err, github.ErrRepoNotFound) {
+		t.Errorf("GetRepository() error = %v, want github.ErrRepoNotFound for a missing project", err)
+	}
+}
+
+func TestGitLabGetLatestRelease(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		gitlabProjectPath + "/releases/permalink/latest": `{"tag_name": "v1.2.0", "name": "1.2.0", "description": "Notes",
+			"released_at": "2024-05-01T10:00:00Z", "assets": {"links": [
+				{"name": "repo-linux-amd64.tar.gz", "url": "SERVER/link/repo-linux-amd64.tar.gz",
+				 "direct_asset_url": "SERVER/downloads/repo-linux-amd64.tar.gz"},
+				{"name": "repo-linux-arm64.tar.gz", "url": "SERVER/link/repo-linux-arm64.tar.gz"}]}}`,
+		"/downloads/repo-linux-amd64.tar.gz": "0123456789",
+	})
+	server := strings.TrimSuffix(client.baseURL, "/api/v4")
+
+	release, err := client.GetLatestRelease("group/sub", "repo")
+	if err != nil {
+		t.Fatalf("GetLatestRelease() error = %v", err)
+	}
+	if release.TagName != "v1.2.0" || release.Body != "Notes" || release.PublishedAt != "2024-05-01" {
+		t.Errorf("GetLatestRelease() = %+v", release)
+	}
+	if len(release.Assets) != 2 {
+		t.Fatalf("GetLatestRelease() returned %d assets, want 2", len(release.Assets))
+	}
+	if got := release.Assets[0].DownloadURL; got != server+"/downloads/repo-linux-amd64.tar.gz" {
+		t.Errorf("Assets[0].DownloadURL = %s, want the direct asset URL", got)
+	}
+	if got := release.Assets[0].Size; got != 10 {
+		t.Errorf("Assets[0].Size = %d, want 10 from the HEAD response", got)
+	}
+	if got := release.Assets[1].DownloadURL; got != server+"/link/repo-linux-arm64.tar.gz" {
+		t.Errorf("Assets[1].DownloadURL = %s, want the link URL", got)
+	}
+	if got := release.Assets[1].Size; got != 0 {
+		t.Errorf("Assets[1].Size = %d, want 0 for an unreachable link", got)
+	}
+}
+
+func TestGitLabGetLatestTag(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		gitlabProjectPath + "/repository/tags":        `[{"name": "v1.1.0"}]`,
+		gitlabProjectPath + "/repository/tags#next":   "2",
+		gitlabProjectPath + "/repository/tags?page=2": `[{"name": "v1.2.0"}]`,
+	})
+
+	_, err := client.GetLatestRelease("group/sub", "repo")
+	if !github.IsNotFound(err) {
+		t.Fatalf("GetLatestRelease() error = %v, want a not found error", err)
+	}
+
+	release, err := client.GetLatestTag("group/sub", "repo")
+	if err != nil {
+		t.Fatalf("GetLatestTag() error = %v", err)
+	}
+	want := "https://gitlab.com/group/sub/repo/-/archive/v1.2.0/repo-v1.2.0.tar.gz"
+	if release.TagName != "v1.2.0" || release.Assets[0].DownloadURL != want {
+		t.Errorf("GetLatestTag() = %s %s, want v1.2.0 %s", release.TagName, release.Assets[0].DownloadURL, want)
+	}
+}
+
+func TestGitLabGetReleaseByTag(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		gitlabProjectPath + "/releases/v1.2.0":        `{"tag_name": "v1.2.0", "released_at": "2024-05-01T10:00:00Z"}`,
+		gitlabProjectPath + "/repository/tags/v1.1.0": `{"name": "v1.1.0"}`,
+	})
+
+	release, err := client.GetReleaseByTag("group/sub", "repo", "v1.2.0")
+	if err != nil {
+		t.Fatalf("GetReleaseByTag() error = %v", err)
+	}
+	if release.TagName != "v1.2.0" || release.FromTag {
+		t.Errorf("GetReleaseByTag() = %+v, want the v1.2.0 release", release)
+	}
+
+	release, err = client.GetReleaseByTag("group/sub", "repo", "v1.1.0")
+	if err != nil {
+		t.Fatalf("GetReleaseByTag() error = %v", err)
+	}
+	want := "https://gitlab.com/group/sub/repo/-/archive/v1.1.0/repo-v1.1.0.tar.gz"
+	if !release.FromTag || release.Assets[0].DownloadURL != want {
+		t.Errorf("GetReleaseByTag() = %+v, want a tag release for %s", release, want)
+	}
+
+	if _, err := client.GetReleaseByTag("group/sub", "repo", "v0.1.0"); !errors.Is(err, github.ErrTagNotFound) {
+		t.Errorf("GetReleaseByTag() error = %v, want github.ErrTagNotFound", err)
+	}
+}
+
+func TestGitLabRepoFiles(t *testing.T) {
+	client := newTestClient(t, map[string]string{
+		gitlabProjectPath + "/repository/tree":              `[{"name": "go.mod", "path": "go.mod", "type": "blob"}, {"name": "cmd", "path": "cmd", "type": "tree"}]`,
+		gitlabProjectPath + "/repository/tree#next":         "2",
+		gitlabProjectPath + "/repository/tree?page=2":       `[{"name": "main.go", "path": "cmd/main.go", "type": "blob"}]`,
+		gitlabProjectPath + "/repository/files/LICENSE/raw": "MIT License",
+	})
+
+	files, err := client.GetRepoFiles("group/sub", "repo")
+	if err != nil {
+		t.Fatalf("GetRepoFiles() error = %v", err)
+	}
+	if want := []string{"go.mod", "main.go"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("GetRepoFiles() = %v, want %v", files, want)
+	}
+
+	tree, err := client.GetRepoTree("group/sub", "repo")
+	if err != nil {
+		t.Fatalf("GetRepoTree() error = %v", err)
+	}
+	if want := []string{"go.mod", "cmd/main.go"}; !reflect.DeepEqual(tree, want) {
+		t.Errorf("GetRepoTree() = %v, want %v", tree, want)
+	}
+
+	content, err := client.GetFileContent("group/sub", "repo", "LICENSE")
+	if err != nil || content != "MIT License" {
+		t.Errorf("GetFileContent() = %q, %v", content, err)
+	}
+
+	t.Run("Empty repository", func(t *testing.T) {
+		files, err := client.GetRepoFiles("empty", "repo")
+		if err != nil {
+			t.Fatalf("GetRepoFiles() error = %v", err)
+		}
+		if len(files) != 0 {
+			t.Errorf("GetRepoFiles() = %v, want none", files)
+		}
+	})
+}
+
+func TestClientTrustsCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"description": "A tool", "web_url": "https://gitlab.example.com/group/repo"}`))
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	block := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, block, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := checksum.ConfigureCABundle(caFile); err != nil {
+		t.Fatalf("ConfigureCABundle() error = %v", err)
+	}
+
+	client := NewClient(strings.TrimPrefix(server.URL, "https://"))
+	if _, err := client.GetRepository("group", "repo"); err != nil {
+		t.Fatalf("GetRepository() error = %v, want the CA bundle to be trusted", err)
+	}
+}
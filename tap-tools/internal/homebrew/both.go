@@ -0,0 +1,96 @@
+package homebrew
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/castrojo/tap-tools/internal/platform"
+)
+
+// PackageInfo holds release metadata shared by formulas and casks
+// Used when generating both package types for the same release
+type PackageInfo struct {
+	Name        string // Package name (lowercase with hyphens)
+	AppName     string // Original app name
+	Version     string // Version number (without "v" prefix)
+	SHA256      string // SHA256 checksum of the asset
+	URL         string // Download URL
+	Description string // Short description
+	Homepage    string // Project homepage
+	License     string // SPDX license ID (formula only)
+	BinaryPath  string // Path to binary in archive
+	ArchiveRoot string // Directory wrapping the archive, which formulas strip
+	BinaryName  string // Name of binary to install
+	SourceURL   string // Repository URL for regeneration instructions
+	Notes       string // Maintainer notes, added to both packages
+
+	Caveats []string // Custom caveat texts, added to both packages
+
+	NoMagicComments bool // Omit the Sorbet and frozen_string_literal comments
+}
+
+// GenerateBoth generates a formula and a cask skeleton from the same release
+// This is used for ambiguous tools where the user picks the package type
+func GenerateBoth(info *PackageInfo) (formula, cask string, err error) {
+	binaryName := info.BinaryName
+	if binaryName == "" {
+		binaryName = info.Name
+	}
+
+	formulaData, err := NewFormulaDataSimple(
+		info.Name,
+		info.Version,
+		info.SHA256,
+		info.URL,
+		info.Description,
+		info.Homepage,
+		info.License,
+		binaryName,
+	)
+	if err != nil {
+		return "", "", err
+	}
+	// Homebrew strips the archive root for formulas but not for casks
+	if info.BinaryPath != "" {
+		formulaData.InstallBlock = binaryInstallBlock(strings.TrimPrefix(info.BinaryPath, info.ArchiveRoot), binaryName)
+	}
+	formulaData.SourceURL = info.SourceURL
+	formulaData.Notes = info.Notes
+	formulaData.NoMagicComments = info.NoMagicComments
+	for _, caveat := range info.Caveats {
+		if err := formulaData.AddCaveat(caveat); err != nil {
+			return "", "", fmt.Errorf("invalid caveat: %w", err)
+		}
+	}
+
+	formula, err = GenerateFormula(formulaData)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate formula: %w", err)
+	}
+
+	caskData := NewCaskData(platform.EnsureLinuxSuffix(info.Name), info.Version, info.SHA256, info.URL)
+	caskData.AppName = info.AppName
+	caskData.Description = info.Description
+	caskData.Homepage = info.Homepage
+	caskData.SourceURL = info.SourceURL
+	caskData.Notes = info.Notes
+	caskData.NoMagicComments = info.NoMagicComments
+	caskData.BinaryPath = info.BinaryPath
+	if caskData.BinaryPath == "" {
+		caskData.BinaryPath = binaryName
+	}
+	caskData.BinaryName = binaryName
+	caskData.InferZapTrash()
+	for _, caveat := range info.Caveats {
+		if err := caskData.AddCaveat(caveat); err != nil {
+			return "", "", fmt.Errorf("invalid caveat: %w", err)
+		}
+	}
+
+	cask, err = GenerateCask(caskData)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate cask: %w", err)
+	}
+
+	return formula, cask, nil
+}
@@ -0,0 +1,124 @@
+package homebrew
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateBoth(t *testing.T) {
+	info := &PackageInfo{
+		Name:        "lazygit",
+		AppName:     "lazygit",
+		Version:     "0.40.2",
+		SHA256:      "abc123",
+		URL:         "https://github.com/jesseduffield/lazygit/releases/download/v0.40.2/lazygit_0.40.2_Linux_x86_64.tar.gz",
+		Description: "Simple terminal UI for git commands",
+		Homepage:    "https://github.com/jesseduffield/lazygit",
+		License:     "MIT",
+		BinaryPath:  "lazygit",
+		SourceURL:   "https://github.com/jesseduffield/lazygit",
+	}
+
+	formula, cask, err := GenerateBoth(info)
+	if err != nil {
+		t.Fatalf("GenerateBoth() error = %v", err)
+	}
+
+	formulaRequired := []string{
+		"class Lazygit < Formula",
+		`sha256 "abc123"`,
+		`license "MIT"`,
+		`bin.install "lazygit"`,
+		"Generated by tap-formula",
+	}
+	for _, req := range formulaRequired {
+		if !strings.Contains(formula, req) {
+			t.Errorf("Generated formula missing required content: %q", req)
+		}
+	}
+
+	caskRequired := []string{
+		`cask "lazygit-linux"`,
+		`version "0.40.2"`,
+		`sha256 "abc123"`,
+		`binary "lazygit", target: "lazygit"`,
+		"Generated by tap-cask",
+	}
+	for _, req := range caskRequired {
+		if !strings.Contains(cask, req) {
+			t.Errorf("Generated cask missing required content: %q", req)
+		}
+	}
+
+	if strings.Contains(formula, "caveats") || strings.Contains(cask, "caveats") {
+		t.Error("Packages should not have caveats unless provided")
+	}
+}
+
+func TestGenerateBothNestedBinary(t *testing.T) {
+	tests := []struct {
+		name        string
+		binaryPath  string
+		archiveRoot string
+		wantFormula string
+		wantCask    string
+	}{
+		{"Wrapped in a root directory", "tool-1.0/bin/tool", "tool-1.0/", `bin.install "bin/tool"`, `binary "tool-1.0/bin/tool", target: "tool"`},
+		{"No root directory", "bin/tool", "", `bin.install "bin/tool"`, `binary "bin/tool", target: "tool"`},
+		{"Top-level binary", "tool", "", `bin.install "tool"`, `binary "tool", target: "tool"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := &PackageInfo{
+				Name:        "tool",
+				Version:     "1.0.0",
+				SHA256:      "abc123",
+				URL:         "https://example.com/tool-1.0.tar.gz",
+				Description: "A tool",
+				Homepage:    "https://example.com",
+				BinaryPath:  tt.binaryPath,
+				BinaryName:  "tool",
+				ArchiveRoot: tt.archiveRoot,
+			}
+
+			formula, cask, err := GenerateBoth(info)
+			if err != nil {
+				t.Fatalf("GenerateBoth() error = %v", err)
+			}
+			if !strings.Contains(formula, tt.wantFormula) {
+				t.Errorf("Formula missing %q:\n%s", tt.wantFormula, formula)
+			}
+			if !strings.Contains(formula, `system "#{bin}/tool", "--version"`) {
+				t.Errorf("Formula should test the installed name:\n%s", formula)
+			}
+			if !strings.Contains(cask, tt.wantCask) {
+				t.Errorf("Cask missing %q:\n%s", tt.wantCask, cask)
+			}
+		})
+	}
+}
+
+func TestGenerateBothCaveats(t *testing.T) {
+	info := &PackageInfo{
+		Name:       "tool",
+		AppName:    "tool",
+		Version:    "1.0.0",
+		SHA256:     "abc123",
+		URL:        "https://example.com/tool.tar.gz",
+		BinaryPath: "tool",
+		Caveats:    []string{"Add ~/.local/bin to your PATH."},
+	}
+
+	formula, cask, err := GenerateBoth(info)
+	if err != nil {
+		t.Fatalf("GenerateBoth() error = %v", err)
+	}
+
+	if !strings.Contains(formula, "  def caveats\n    <<~EOS\n      Add ~/.local/bin to your PATH.\n    EOS") {
+		t.Errorf("Generated formula missing caveats:\n%s", formula)
+	}
+	if !strings.Contains(cask, "  caveats <<~EOS\n    Add ~/.local/bin to your PATH.\n  EOS") {
+		t.Errorf("Generated cask missing caveats:\n%s", cask)
+	}
+}
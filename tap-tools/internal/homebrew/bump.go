@@ -0,0 +1,220 @@
+package homebrew
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/castrojo/tap-tools/internal/version"
+)
+
+var (
+	// urlStanzaRe matches a url stanza, capturing the indent and the URL
+	urlStanzaRe = regexp.MustCompile(`^(\s*)url "([^"]+)"`)
+
+	// sha256StanzaRe matches a sha256 stanza, capturing the indent
+	sha256StanzaRe = regexp.MustCompile(`^(\s*)sha256 "[0-9a-fA-F]+"`)
+
+	// versionStanzaRe matches a version stanza, capturing the indent and version
+	versionStanzaRe = regexp.MustCompile(`^(\s*)version "([^"]+)"`)
+
+	// sourceHeaderRe matches the Source line of the generation header
+	sourceHeaderRe = regexp.MustCompile(`(?m)^# Source: (\S+)`)
+
+	// tagSegmentRe captures the tag in GitHub and GitLab release download and
+	// source archive URLs
+	tagSegmentRe = regexp.MustCompile(`/(?:releases/download|-/archive|archive/refs/tags|archive)/([^/]+?)(?:\.tar\.gz|\.zip)?(?:/|$)`)
+
+	// dottedVersionRe matches a dotted version number anywhere in a URL
+	dottedVersionRe = regexp.MustCompile(`\d+(?:\.\d+)+`)
+)
+
+// FormulaSource is what bump needs from an existing formula: its version,
+// where it was generated from, and the downloads that carry the version
+type FormulaSource struct {
+	Version string   // Current version, from the version stanza or the first url
+	RepoURL string   // Repository from the generation header, or "" if missing
+	URLs    []string // url stanzas containing the version, in file order
+}
+
+// ParseFormulaSource extracts the current version and versioned download
+// URLs from formula content. Multi-arch formulas yield one URL per
+// architecture; resources from the same release are included too.
+func ParseFormulaSource(content string) (*FormulaSource, error) {
+	src := &FormulaSource{}
+	if m := sourceHeaderRe.FindStringSubmatch(content); m != nil {
+		src.RepoURL = m[1]
+	}
+
+	var urls []string
+	for _, line := range strings.Split(content, "\n") {
+		if m := versionStanzaRe.FindStringSubmatch(line); m != nil && src.Version == "" {
+			src.Version = m[2]
+		}
+		if m := urlStanzaRe.FindStringSubmatch(line); m != nil {
+			urls = append(urls, m[2])
+		}
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("formula has no url stanza")
+	}
+	if src.Version == "" {
+		src.Version = VersionFromURL(urls[0])
+	}
+	if src.Version == "" {
+		return nil, fmt.Errorf("cannot determine the version from %s; add a version stanza", urls[0])
+	}
+
+	for _, url := range urls {
+		if strings.Contains(url, src.Version) || strings.Contains(url, "#{version}") {
+			src.URLs = append(src.URLs, url)
+		}
+	}
+	return src, nil
+}
+
+// VersionFromURL guesses the version a download URL is for, preferring the
+// release tag segment of GitHub and GitLab URLs. It returns "" if the URL
+// has no version in it.
+func VersionFromURL(url string) string {
+	if m := tagSegmentRe.FindStringSubmatch(url); m != nil && version.IsVersion(m[1]) {
+		return version.Normalize(m[1])
+	}
+	return dottedVersionRe.FindString(url[strings.LastIndex(url, "/")+1:])
+}
+
+// BumpURL returns the download URL for another version: each occurrence of
+// from that is not part of a longer number is replaced, and version
+// interpolations such as #{version} are expanded
+func BumpURL(url, from, to string) string {
+	url, _ = replaceVersion(url, from, to)
+	return ExpandVersion(url, to)
+}
+
+// ExpandVersion expands the cask version interpolations that TemplateURL
+// writes, e.g. "#{version.no_dots}", with version exactly as given, the
+// way Homebrew expands them with the version stanza
+func ExpandVersion(s, version string) string {
+	return strings.NewReplacer(
+		"#{version}", version,
+		"#{version.dots_to_underscores}", strings.ReplaceAll(version, ".", "_"),
+		"#{version.no_dots}", strings.ReplaceAll(version, ".", ""),
+	).Replace(s)
+}
+
+// BumpFormula rewrites the version, url and sha256 stanzas of src for a new
+// version, leaving every other line, including install and test blocks
+// and comments, untouched. sha256s maps each of src.URLs to the checksum
+// of its BumpURL download.
+func BumpFormula(content string, src *FormulaSource, newVersion string, sha256s map[string]string) (string, error) {
+	lines := strings.Split(content, "\n")
+	pending := "" // Checksum for the next sha256 stanza after a bumped url
+	bumped := 0
+
+	for i, line := range lines {
+		if m := versionStanzaRe.FindStringSubmatch(line); m != nil && m[2] == src.Version {
+			lines[i] = fmt.Sprintf("%sversion %q", m[1], newVersion)
+			continue
+		}
+		if m := urlStanzaRe.FindStringSubmatch(line); m != nil {
+			pending = ""
+			sum, ok := sha256s[m[2]]
+			if !ok {
+				continue
+			}
+			// A #{version} URL follows the version stanza on its own
+			newURL, _ := replaceVersion(m[2], src.Version, newVersion)
+			lines[i] = strings.Replace(line, fmt.Sprintf("%q", m[2]), fmt.Sprintf("%q", newURL), 1)
+			pending = sum
+			bumped++
+			continue
+		}
+		if m := sha256StanzaRe.FindStringSubmatch(line); m != nil && pending != "" {
+			lines[i] = fmt.Sprintf("%ssha256 %q", m[1], pending)
+			pending = ""
+		}
+	}
+
+	if bumped != len(src.URLs) {
+		return "", fmt.Errorf("updated %d of %d url stanzas", bumped, len(src.URLs))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// caskTopLevelRe matches a top-level cask stanza, indented by two spaces;
+// the livecheck block has its own url one level deeper
+var caskTopLevelRe = regexp.MustCompile(`^  (version|sha256|url) (.+)$`)
+
+// CaskSource is what bump needs from an existing cask
+type CaskSource struct {
+	Version string // Current version, as written, e.g. "v1.2.0" or "1.2.0"
+	URL     string // Download URL, possibly with version interpolations
+	RepoURL string // Repository from the generation header, or "" if missing
+}
+
+// ParseCaskSource extracts the version and download URL of a cask. Casks
+// with version :latest have no version to bump and return an error.
+func ParseCaskSource(content string) (*CaskSource, error) {
+	src := &CaskSource{}
+	if m := sourceHeaderRe.FindStringSubmatch(content); m != nil {
+		src.RepoURL = m[1]
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		m := caskTopLevelRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		value := m[2]
+		switch {
+		case m[1] == "version" && value == ":latest":
+			return nil, fmt.Errorf("cask uses version :latest, there is no version to bump")
+		case m[1] == "version" && src.Version == "":
+			src.Version = strings.Trim(value, `"`)
+		case m[1] == "url" && src.URL == "":
+			src.URL = strings.Trim(strings.SplitN(value, ",", 2)[0], `"`)
+		}
+	}
+
+	if src.Version == "" {
+		return nil, fmt.Errorf("cask has no version stanza")
+	}
+	if src.URL == "" {
+		return nil, fmt.Errorf("cask has no url stanza")
+	}
+	return src, nil
+}
+
+// BumpCask rewrites the top-level version, sha256 and url stanzas of a
+// cask, leaving binary, artifact, zap and every other line untouched.
+// An unchanged newURL leaves the url line as it is, which keeps a
+// #{version} template.
+func BumpCask(content string, src *CaskSource, newVersion, newURL, sha256 string) (string, error) {
+	lines := strings.Split(content, "\n")
+	updated := map[string]bool{}
+
+	for i, line := range lines {
+		m := caskTopLevelRe.FindStringSubmatch(line)
+		if m == nil || updated[m[1]] {
+			continue
+		}
+		switch m[1] {
+		case "version":
+			lines[i] = fmt.Sprintf("  version %q", newVersion)
+		case "sha256":
+			lines[i] = fmt.Sprintf("  sha256 %q", sha256)
+		case "url":
+			if newURL != src.URL {
+				lines[i] = strings.Replace(line, fmt.Sprintf("%q", src.URL), fmt.Sprintf("%q", newURL), 1)
+			}
+		}
+		updated[m[1]] = true
+	}
+
+	for _, stanza := range []string{"version", "sha256", "url"} {
+		if !updated[stanza] {
+			return "", fmt.Errorf("cask has no top-level %s stanza", stanza)
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,311 @@
+package homebrew
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const bumpSampleFormula = `# Generated by tap-formula v1.0.0 on 2026-01-10
+# Source: https://github.com/example/tool
+# DO NOT EDIT - Regenerate with: ./tap-formula generate https://github.com/example/tool
+# Validation: Auto-validated with tap-validate --fix
+
+# A tool
+class Tool < Formula
+  desc "A tool"
+  homepage "https://github.com/example/tool"
+  url "https://github.com/example/tool/releases/download/v1.2.0/tool_1.2.0_linux_amd64.tar.gz"
+  sha256 "1111111111111111111111111111111111111111111111111111111111111111"
+  license "MIT"
+
+  resource "plugin" do
+    url "https://example.com/plugin-0.4.tar.gz"
+    sha256 "2222222222222222222222222222222222222222222222222222222222222222"
+  end
+
+  def install
+    # Hand-tuned: the tarball ships a completion script too
+    bin.install "tool"
+    bash_completion.install "completions/tool.bash" => "tool"
+  end
+
+  test do
+    assert_match "1.2.0", shell_output("#{bin}/tool --version")
+  end
+end
+`
+
+const bumpMultiArchFormula = `class Tool < Formula
+  desc "A tool"
+  homepage "https://github.com/example/tool"
+  version "1.2.0"
+
+  on_arm do
+    url "https://github.com/example/tool/releases/download/v1.2.0/tool-linux-arm64.tar.gz"
+    sha256 "3333333333333333333333333333333333333333333333333333333333333333"
+  end
+
+  on_intel do
+    url "https://github.com/example/tool/releases/download/v#{version}/tool-linux-amd64.tar.gz"
+    sha256 "4444444444444444444444444444444444444444444444444444444444444444"
+  end
+
+  def install
+    bin.install "tool"
+  end
+end
+`
+
+func TestParseFormulaSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    *FormulaSource
+		wantErr bool
+	}{
+		{
+			name:    "Version from the url",
+			content: bumpSampleFormula,
+			want: &FormulaSource{
+				Version: "1.2.0",
+				RepoURL: "https://github.com/example/tool",
+				URLs:    []string{"https://github.com/example/tool/releases/download/v1.2.0/tool_1.2.0_linux_amd64.tar.gz"},
+			},
+		},
+		{
+			name:    "Version stanza with per-arch urls",
+			content: bumpMultiArchFormula,
+			want: &FormulaSource{
+				Version: "1.2.0",
+				URLs: []string{
+					"https://github.com/example/tool/releases/download/v1.2.0/tool-linux-arm64.tar.gz",
+					"https://github.com/example/tool/releases/download/v#{version}/tool-linux-amd64.tar.gz",
+				},
+			},
+		},
+		{
+			name:    "No url",
+			content: "class Tool < Formula\n  desc \"A tool\"\nend\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFormulaSource(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFormulaSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFormulaSource() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://github.com/o/r/releases/download/v1.2.0/r-linux-amd64.tar.gz", "1.2.0"},
+		{"https://github.com/o/r/archive/refs/tags/v0.9.1.tar.gz", "0.9.1"},
+		{"https://github.com/o/r/archive/2.0.tar.gz", "2.0"},
+		{"https://gitlab.com/g/r/-/archive/v3.1.0/r-v3.1.0.tar.gz", "3.1.0"},
+		{"https://example.com/downloads/tool-4.5.6-x86_64.tar.xz", "4.5.6"},
+		{"https://example.com/downloads/tool-latest.tar.gz", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := VersionFromURL(tt.url); got != tt.want {
+				t.Errorf("VersionFromURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBumpFormula(t *testing.T) {
+	src, err := ParseFormulaSource(bumpSampleFormula)
+	if err != nil {
+		t.Fatalf("ParseFormulaSource() error = %v", err)
+	}
+	newSum := strings.Repeat("a", 64)
+	bumped, err := BumpFormula(bumpSampleFormula, src, "1.3.0", map[string]string{src.URLs[0]: newSum})
+	if err != nil {
+		t.Fatalf("BumpFormula() error = %v", err)
+	}
+
+	want := strings.NewReplacer(
+		`url "https://github.com/example/tool/releases/download/v1.2.0/tool_1.2.0_linux_amd64.tar.gz"`,
+		`url "https://github.com/example/tool/releases/download/v1.3.0/tool_1.3.0_linux_amd64.tar.gz"`,
+		`sha256 "1111111111111111111111111111111111111111111111111111111111111111"`,
+		`sha256 "`+newSum+`"`,
+	).Replace(bumpSampleFormula)
+	if bumped != want {
+		t.Errorf("BumpFormula() changed more than the url and sha256:\n%s", bumped)
+	}
+}
+
+func TestBumpFormulaMultiArch(t *testing.T) {
+	src, err := ParseFormulaSource(bumpMultiArchFormula)
+	if err != nil {
+		t.Fatalf("ParseFormulaSource() error = %v", err)
+	}
+	armSum, intelSum := strings.Repeat("b", 64), strings.Repeat("c", 64)
+	bumped, err := BumpFormula(bumpMultiArchFormula, src, "1.10.0", map[string]string{
+		src.URLs[0]: armSum,
+		src.URLs[1]: intelSum,
+	})
+	if err != nil {
+		t.Fatalf("BumpFormula() error = %v", err)
+	}
+
+	for _, want := range []string{
+		`  version "1.10.0"`,
+		`    url "https://github.com/example/tool/releases/download/v1.10.0/tool-linux-arm64.tar.gz"` + "\n    sha256 \"" + armSum + `"`,
+		`    url "https://github.com/example/tool/releases/download/v#{version}/tool-linux-amd64.tar.gz"` + "\n    sha256 \"" + intelSum + `"`,
+	} {
+		if !strings.Contains(bumped, want) {
+			t.Errorf("BumpFormula() missing %q:\n%s", want, bumped)
+		}
+	}
+
+	if got := BumpURL(src.URLs[1], src.Version, "1.10.0"); got != "https://github.com/example/tool/releases/download/v1.10.0/tool-linux-amd64.tar.gz" {
+		t.Errorf("BumpURL() = %s", got)
+	}
+}
+
+const bumpSampleCask = `# Source: https://github.com/example/tool
+cask "tool-linux" do
+  version "v1.2.0"
+  sha256 "1111111111111111111111111111111111111111111111111111111111111111"
+
+  url "https://github.com/example/tool/releases/download/v1.2.0/tool_1.2.0_linux_amd64.tar.gz"
+  name "Tool"
+  desc "A tool"
+  homepage "https://github.com/example/tool"
+
+  livecheck do
+    url "https://github.com/example/tool/releases.atom"
+    strategy :github_latest
+  end
+
+  binary "tool-#{version}/tool"
+  artifact "tool-1.2.0/share/tool.desktop", target: "#{Dir.home}/.local/share/applications/tool.desktop"
+
+  zap trash: "~/.config/tool"
+end
+`
+
+func TestBumpURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		from string
+		to   string
+		want string
+	}{
+		{
+			name: "tag and filename",
+			url:  "https://github.com/o/r/releases/download/v1.2.0/r_1.2.0_linux_amd64.tar.gz",
+			from: "1.2.0", to: "1.3.0",
+			want: "https://github.com/o/r/releases/download/v1.3.0/r_1.3.0_linux_amd64.tar.gz",
+		},
+		{
+			name: "longer number is kept",
+			url:  "https://example.com/r-1.2.0-glibc1.2.05.tar.gz",
+			from: "1.2.0", to: "1.2.1",
+			want: "https://example.com/r-1.2.1-glibc1.2.05.tar.gz",
+		},
+		{
+			name: "version interpolation",
+			url:  "https://github.com/o/r/releases/download/v#{version}/r-#{version}-x86_64.AppImage",
+			from: "1.2.0", to: "1.3.0",
+			want: "https://github.com/o/r/releases/download/v1.3.0/r-1.3.0-x86_64.AppImage",
+		},
+		{
+			name: "dots to underscores",
+			url:  "https://example.com/r_#{version.dots_to_underscores}.tar.gz",
+			from: "1.2.0", to: "2.0.1",
+			want: "https://example.com/r_2_0_1.tar.gz",
+		},
+		{
+			name: "no dots",
+			url:  "https://example.com/r#{version.no_dots}.zip",
+			from: "1.2.0", to: "2.0.1",
+			want: "https://example.com/r201.zip",
+		},
+		{
+			name: "v prefix in version",
+			url:  "https://example.com/r-#{version}.tar.gz",
+			from: "v1.2.0", to: "v1.3.0",
+			want: "https://example.com/r-v1.3.0.tar.gz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BumpURL(tt.url, tt.from, tt.to); got != tt.want {
+				t.Errorf("BumpURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCaskSource(t *testing.T) {
+	src, err := ParseCaskSource(bumpSampleCask)
+	if err != nil {
+		t.Fatalf("ParseCaskSource() error = %v", err)
+	}
+	want := &CaskSource{
+		Version: "v1.2.0",
+		URL:     "https://github.com/example/tool/releases/download/v1.2.0/tool_1.2.0_linux_amd64.tar.gz",
+		RepoURL: "https://github.com/example/tool",
+	}
+	if !reflect.DeepEqual(src, want) {
+		t.Errorf("ParseCaskSource() = %+v, want %+v", src, want)
+	}
+
+	latest := strings.Replace(bumpSampleCask, `version "v1.2.0"`, "version :latest", 1)
+	if _, err := ParseCaskSource(latest); err == nil {
+		t.Error("ParseCaskSource() with version :latest should fail")
+	}
+}
+
+func TestBumpCask(t *testing.T) {
+	src, err := ParseCaskSource(bumpSampleCask)
+	if err != nil {
+		t.Fatalf("ParseCaskSource() error = %v", err)
+	}
+	newURL := BumpURL(src.URL, "1.2.0", "1.3.0")
+	newSum := strings.Repeat("a", 64)
+	bumped, err := BumpCask(bumpSampleCask, src, "v1.3.0", newURL, newSum)
+	if err != nil {
+		t.Fatalf("BumpCask() error = %v", err)
+	}
+
+	want := strings.NewReplacer(
+		`version "v1.2.0"`, `version "v1.3.0"`,
+		`sha256 "1111111111111111111111111111111111111111111111111111111111111111"`, `sha256 "`+newSum+`"`,
+		src.URL, newURL,
+	).Replace(bumpSampleCask)
+	if bumped != want {
+		t.Errorf("BumpCask() changed more than version, sha256 and url:\n%s", bumped)
+	}
+
+	// A templated url that still matches is kept as written
+	templated := strings.Replace(bumpSampleCask, src.URL, "https://github.com/example/tool/releases/download/v#{version}/tool_#{version}_linux_amd64.tar.gz", 1)
+	tsrc, err := ParseCaskSource(templated)
+	if err != nil {
+		t.Fatalf("ParseCaskSource() error = %v", err)
+	}
+	bumped, err = BumpCask(templated, tsrc, "v1.3.0", tsrc.URL, newSum)
+	if err != nil {
+		t.Fatalf("BumpCask() error = %v", err)
+	}
+	if !strings.Contains(bumped, `  url "`+tsrc.URL+`"`) {
+		t.Errorf("BumpCask() rewrote a templated url:\n%s", bumped)
+	}
+}
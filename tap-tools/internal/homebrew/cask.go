@@ -3,10 +3,13 @@ package homebrew
 import (
 	"bytes"
 	"fmt"
+	"path"
 	"strings"
 	"text/template"
+	"unicode"
 
 	"github.com/castrojo/tap-tools/internal/generator"
+	"github.com/castrojo/tap-tools/internal/github"
 )
 
 // CaskData represents data for generating a Homebrew cask
@@ -17,17 +20,49 @@ type CaskData struct {
 	URL         string
 	Description string
 	Homepage    string
-	AppName     string // Original app name
+	AppName     string // Repository name, for the homepage fallback and zap paths
+	DisplayName string // Name from the desktop file, shown instead of AppName
 	BinaryPath  string // Path to binary in archive
 	BinaryName  string // Name of binary to install
 
+	// IsAppImage marks BinaryPath as an AppImage, which the preflight makes
+	// executable and, with a desktop file, gives a launcher (see SetAppImage)
+	IsAppImage bool
+
+	// BinaryPaths are further executables in the archive, each installed
+	// under its file name (see AddBinaries)
+	BinaryPaths []string
+
 	// Desktop integration
 	HasDesktopFile    bool
 	DesktopFilePath   string
 	DesktopFileSource string // Original path in archive
-	HasIcon           bool
-	IconPath          string
-	IconSource        string // Original path in archive
+
+	// ExtraDesktopFiles are further launchers in the archive, such as a URL
+	// handler (see AddDesktopFile)
+	ExtraDesktopFiles []DesktopFile
+
+	HasIcon    bool
+	IconPath   string
+	IconSource string // Original path in archive
+
+	// ThemeIcons are installed into the hicolor icon theme, one per size,
+	// under IconName (see AddThemeIcon)
+	ThemeIcons []ThemeIcon
+	IconName   string
+
+	// MimeFiles declare the file types the app opens (see AddMimeFile)
+	MimeFiles []MimeFile
+
+	// Postflight refreshes desktop and icon caches after install
+	Postflight bool
+
+	// Livecheck adds a livecheck block watching the repository's releases
+	Livecheck bool
+
+	// Latest emits version :latest and sha256 :no_check for rolling
+	// downloads that have no stable version
+	Latest bool
 
 	// XDG directories to create
 	XDGDirs []string
@@ -35,26 +70,49 @@ type CaskData struct {
 	// Zap configuration
 	ZapTrash []string
 
+	// Caveats are custom lines for the caveats stanza, after the generated
+	// launcher note for casks with a desktop file
+	Caveats []string
+
 	// Generation metadata
-	SourceURL string // Repository URL for regeneration instructions
+	SourceURL       string // Repository URL for regeneration instructions
+	Notes           string // Maintainer notes, rendered as comments after the header
+	NoMagicComments bool   // Omit the Sorbet and frozen_string_literal comments
 }
 
+// xdgDataHome is the Ruby expression for the user's XDG data directory
+const xdgDataHome = `#{ENV.fetch("XDG_DATA_HOME", "#{Dir.home}/.local/share")}`
+
 // caskTemplate is the template for generating Homebrew casks
-const caskTemplate = `# typed: strict
+const caskTemplate = `{{ if not .NoMagicComments -}}
+# typed: strict
 # frozen_string_literal: true
 
+{{ end -}}
 cask "{{ .Token }}" do
+{{- if .Latest }}
+  version :latest
+  sha256 :no_check
+{{- else }}
   version "{{ .Version }}"
   sha256 "{{ .SHA256 }}"
+{{- end }}
 
   url "{{ .URL }}"
-  name "{{ .AppName }}"
-  desc "{{ cleanDesc .Description }}"
-  homepage "{{ if .Homepage }}{{ .Homepage }}{{ else }}https://github.com/{{ .AppName }}{{ end }}"
+  name "{{ rubyString (or .DisplayName .AppName) }}"
+  desc "{{ rubyString (cleanDesc .Description) }}"
+  homepage "{{ if .Homepage }}{{ rubyString .Homepage }}{{ else }}https://github.com/{{ rubyString .AppName }}{{ end }}"
+{{- if and .Livecheck (not .Latest) }}{{ with .LivecheckURL }}
+
+  livecheck do
+    url "{{ . }}"
+    regex(/^v?(\d+(?:\.\d+)+)$/i)
+  end
+{{- end }}{{ end }}
 
   # Linux-only cask
   depends_on formula: "bash"
-{{- if or .HasDesktopFile .HasIcon }}
+{{- if or .HasDesktopFile .HasIcons .MimeFiles .IsAppImage }}
 
   preflight do
     {{- if .XDGDirs }}
@@ -64,30 +122,86 @@ cask "{{ .Token }}" do
     system_command "mkdir", args: ["-p", "#{xdg_data_home}/{{ . }}"]
     {{- end }}
     {{- end }}
+    {{- if .IsAppImage }}
+
+    # Make the AppImage executable
+    FileUtils.chmod "a+x", staged_path.join("{{ .BinaryPath }}")
+    {{- if .WritesAppImageDesktopFile }}
+
+    # AppImages keep their desktop file inside the image
+    File.write(staged_path.join("{{ .AppImageDesktopFile }}"), <<~EOS)
+      [Desktop Entry]
+      Type=Application
+      Name={{ rubyString (or .DisplayName .AppName) }}
+      Exec=#{HOMEBREW_PREFIX}/bin/{{ .BinaryName }} %U
+      Terminal=false
+    EOS
+    {{- end }}
+    {{- end }}
     {{- if .HasDesktopFile }}
 
     # Fix desktop file paths
-    desktop_file = staged_path.join("{{ .DesktopFileSource }}")
+    {{- range $i, $file := .DesktopFiles }}
+    {{- if $i }}
+{{ end }}
+    desktop_file = staged_path.join("{{ $file.Source }}")
     if desktop_file.exist?
       content = desktop_file.read
-      content.gsub!(%r{Exec=.*}, "Exec=#{HOMEBREW_PREFIX}/bin/{{ .BinaryName }}")
-      {{- if .HasIcon }}
-      content.gsub!(%r{Icon=.*}, "Icon=#{xdg_data_home}/icons/{{ .IconSource }}")
+      {{ $.DesktopExecGsub }}
+      {{- if $.ThemeIcons }}
+      content.gsub!(%r{^Icon=.*$}, "Icon={{ rubyString $.IconName }}")
+      {{- else if $.HasIcon }}
+      content.gsub!(%r{^Icon=.*$}, "Icon=#{xdg_data_home}/icons/{{ rubyString $.IconPath }}")
       {{- end }}
       desktop_file.write(content)
     end
     {{- end }}
+    {{- end }}
   end
   {{- end }}
 
   {{- if .BinaryPath }}
   binary "{{ .BinaryPath }}", target: "{{ .BinaryName }}"
   {{- end }}
-  {{- if .HasDesktopFile }}
-  artifact "{{ .DesktopFileSource }}", target: "#{ENV.fetch("XDG_DATA_HOME", "#{Dir.home}/.local/share")}/applications/{{ .DesktopFilePath }}"
+  {{- range .BinaryPaths }}
+  binary "{{ . }}", target: "{{ base . }}"
+  {{- end }}
+  {{- range .DesktopFiles }}
+  artifact "{{ .Source }}", target: "{{ .Target }}"
   {{- end }}
   {{- if .HasIcon }}
-  artifact "{{ .IconSource }}", target: "#{ENV.fetch("XDG_DATA_HOME", "#{Dir.home}/.local/share")}/icons/{{ .IconPath }}"
+  artifact "{{ .IconSource }}", target: "{{ .IconTarget }}"
+  {{- end }}
+  {{- range .ThemeIcons }}
+  artifact "{{ .Source }}", target: "{{ .Target }}"
+  {{- end }}
+  {{- range .MimeFiles }}
+  artifact "{{ .Source }}", target: "{{ .Target }}"
+  {{- end }}
+{{- if and .Postflight (or .HasDesktopFile .HasIcons) }}
+
+  postflight do
+    xdg_data_home = ENV.fetch("XDG_DATA_HOME", "#{Dir.home}/.local/share")
+    {{- if .HasDesktopFile }}
+    if which("update-desktop-database")
+      system_command "update-desktop-database", args: ["#{xdg_data_home}/applications"], must_succeed: false
+    end
+    {{- end }}
+    {{- if .HasIcons }}
+    if which("gtk-update-icon-cache")
+      system_command "gtk-update-icon-cache", args: ["-f", "-t", "#{xdg_data_home}/{{ .IconCacheDir }}"], must_succeed: false
+    end
+    {{- end }}
+  end
+{{- end }}
+
+  {{- if .ArtifactTargets }}
+
+  uninstall delete: [
+    {{- range .ArtifactTargets }}
+    "{{ . }}",
+    {{- end }}
+  ]
   {{- end }}
 
   {{- if .ZapTrash }}
@@ -100,6 +214,26 @@ cask "{{ .Token }}" do
     {{- end }},
   ]
   {{- end }}
+{{- if or .HasDesktopFile .MimeFiles .Caveats }}
+
+  caveats <<~EOS
+{{- if .HasDesktopFile }}
+    If {{ rubyString (or .DisplayName .AppName) }} does not appear in your application launcher,
+    log out and back in so the desktop environment picks it up.
+{{- if or .MimeFiles .Caveats }}
+{{ end }}
+{{- end }}
+{{- if .MimeFiles }}
+    To open files with {{ rubyString (or .DisplayName .AppName) }}, register its file types by running:
+      update-mime-database "${XDG_DATA_HOME:-$HOME/.local/share}/mime"
+{{- if .Caveats }}
+{{ end }}
+{{- end }}
+{{- range .Caveats }}
+{{ if . }}    {{ . }}{{ end }}
+{{- end }}
+  EOS
+{{- end }}
 end
 `
 
@@ -116,6 +250,27 @@ func cleanDesc(desc string) string {
 	return desc
 }
 
+// rubyInterpolation are the replacements that keep backslashes and
+// interpolation literal in double-quoted Ruby strings and heredocs
+var rubyInterpolation = []string{`\`, `\\`, `#{`, `\#{`, `#@`, `\#@`, `#$`, `\#$`}
+
+// rubyEscaper escapes text for a double-quoted Ruby string, so quotes,
+// backslashes and interpolation stay literal
+var rubyEscaper = strings.NewReplacer(append([]string{`"`, `\"`}, rubyInterpolation...)...)
+
+// heredocEscaper escapes text for a Ruby heredoc, where quotes are literal
+var heredocEscaper = strings.NewReplacer(rubyInterpolation...)
+
+// rubyString escapes text from release metadata or a desktop file for a
+// double-quoted Ruby string or heredoc. Control characters such as newlines
+// would break out of the line, so they are an error.
+func rubyString(s string) (string, error) {
+	if strings.ContainsFunc(s, unicode.IsControl) {
+		return "", fmt.Errorf("%q contains a control character", s)
+	}
+	return rubyEscaper.Replace(s), nil
+}
+
 // sortStrings returns a sorted copy of a string slice
 func sortStrings(strs []string) []string {
 	sorted := make([]string, len(strs))
@@ -136,7 +291,9 @@ func GenerateCask(data *CaskData) (string, error) {
 	// Parse template with custom functions
 	tmpl, err := template.New("cask").Funcs(template.FuncMap{
 		"cleanDesc":   cleanDesc,
+		"rubyString":  rubyString,
 		"sortStrings": sortStrings,
+		"base":        path.Base,
 	}).Parse(caskTemplate)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template: %w", err)
@@ -151,6 +308,9 @@ func GenerateCask(data *CaskData) (string, error) {
 			return "", fmt.Errorf("failed to write header: %w", err)
 		}
 	}
+	if err := generator.WriteNotes(&buf, data.Notes); err != nil {
+		return "", fmt.Errorf("failed to write notes: %w", err)
+	}
 
 	// Then write cask content
 	if err := tmpl.Execute(&buf, data); err != nil {
@@ -163,13 +323,27 @@ func GenerateCask(data *CaskData) (string, error) {
 // NewCaskData creates a new CaskData with sensible defaults
 func NewCaskData(token, version, sha256, url string) *CaskData {
 	return &CaskData{
-		Token:    token,
-		Version:  version,
-		SHA256:   sha256,
-		URL:      url,
-		XDGDirs:  []string{},
-		ZapTrash: []string{},
+		Token:     token,
+		Version:   version,
+		SHA256:    sha256,
+		URL:       url,
+		XDGDirs:   []string{},
+		ZapTrash:  []string{},
+		Livecheck: true,
+	}
+}
+
+// LivecheckURL returns the releases feed for the repository in SourceURL,
+// or "" if SourceURL is not a GitHub or GitLab repository
+func (c *CaskData) LivecheckURL() string {
+	host, owner, repo, err := github.ParseRepoURL(c.SourceURL)
+	if err != nil || !strings.Contains(c.SourceURL, host+"/") {
+		return ""
+	}
+	if host != github.GitHubHost && !github.IsGitLab(host) {
+		return ""
 	}
+	return github.ReleasesFeedURL(host, owner, repo)
 }
 
 // AddXDGDir adds an XDG directory to create in preflight
@@ -177,6 +351,38 @@ func (c *CaskData) AddXDGDir(dir string) {
 	c.XDGDirs = append(c.XDGDirs, dir)
 }
 
+// AddBinaries installs every executable in paths next to the main binary,
+// for suites that ship several commands. The main BinaryPath and any
+// executable whose file name is already a target are skipped, as two
+// binary stanzas cannot link the same name. It returns the paths added.
+func (c *CaskData) AddBinaries(paths []string) []string {
+	targets := map[string]bool{c.BinaryName: true}
+	for _, p := range c.BinaryPaths {
+		targets[path.Base(p)] = true
+	}
+
+	var added []string
+	for _, p := range paths {
+		if p == c.BinaryPath || targets[path.Base(p)] {
+			continue
+		}
+		targets[path.Base(p)] = true
+		c.BinaryPaths = append(c.BinaryPaths, p)
+		added = append(added, p)
+	}
+	return added
+}
+
+// AddCaveat appends custom text to the caveats stanza
+func (c *CaskData) AddCaveat(text string) error {
+	lines, err := appendCaveat(c.Caveats, text)
+	if err != nil {
+		return err
+	}
+	c.Caveats = lines
+	return nil
+}
+
 // AddZapTrash adds a path to remove on uninstall
 func (c *CaskData) AddZapTrash(path string) {
 	c.ZapTrash = append(c.ZapTrash, path)
@@ -190,6 +396,70 @@ func (c *CaskData) SetDesktopFile(sourcePathInArchive, targetFilename string) {
 	c.AddXDGDir("applications")
 }
 
+// AddDesktopFile installs a further desktop file next to the one set with
+// SetDesktopFile, which it becomes if none is set yet. A file whose name is
+// already a target is skipped. It reports whether the file was added.
+func (c *CaskData) AddDesktopFile(sourcePathInArchive, targetFilename string) bool {
+	if !c.HasDesktopFile {
+		c.SetDesktopFile(sourcePathInArchive, targetFilename)
+		return true
+	}
+	for _, file := range c.DesktopFiles() {
+		if file.Filename == targetFilename {
+			return false
+		}
+	}
+	c.ExtraDesktopFiles = append(c.ExtraDesktopFiles, DesktopFile{Source: sourcePathInArchive, Filename: targetFilename})
+	return true
+}
+
+// DesktopFile is a desktop file installed by an artifact stanza
+type DesktopFile struct {
+	Source   string // Original path in archive
+	Filename string // Installed file name
+}
+
+// Target returns the installed location of the desktop file
+func (d DesktopFile) Target() string {
+	return fmt.Sprintf(`%s/applications/%s`, xdgDataHome, rubyEscaper.Replace(d.Filename))
+}
+
+// DesktopFiles returns the main desktop file followed by the extra ones
+func (c *CaskData) DesktopFiles() []DesktopFile {
+	if !c.HasDesktopFile {
+		return nil
+	}
+	main := DesktopFile{Source: c.DesktopFileSource, Filename: c.DesktopFilePath}
+	return append([]DesktopFile{main}, c.ExtraDesktopFiles...)
+}
+
+// MimeFile is a shared-mime-info package installed by an artifact stanza
+type MimeFile struct {
+	Source   string // Original path in archive
+	Filename string // Installed file name
+}
+
+// Target returns the installed location of the MIME package
+func (m MimeFile) Target() string {
+	return fmt.Sprintf(`%s/mime/packages/%s`, xdgDataHome, rubyEscaper.Replace(m.Filename))
+}
+
+// AddMimeFile installs a MIME package so file associations work. A file
+// whose name is already a target is skipped. It reports whether the file
+// was added.
+func (c *CaskData) AddMimeFile(sourcePathInArchive, targetFilename string) bool {
+	for _, file := range c.MimeFiles {
+		if file.Filename == targetFilename {
+			return false
+		}
+	}
+	if len(c.MimeFiles) == 0 {
+		c.AddXDGDir("mime/packages")
+	}
+	c.MimeFiles = append(c.MimeFiles, MimeFile{Source: sourcePathInArchive, Filename: targetFilename})
+	return true
+}
+
 // SetIcon configures icon integration
 func (c *CaskData) SetIcon(sourcePathInArchive, targetFilename string) {
 	c.HasIcon = true
@@ -198,6 +468,120 @@ func (c *CaskData) SetIcon(sourcePathInArchive, targetFilename string) {
 	c.AddXDGDir("icons")
 }
 
+// SetAppImage installs an AppImage, downloaded as is or unpacked from a
+// zip, as the cask's binary. Its desktop file is sealed inside the image, so
+// call AddDesktopFile with AppImageDesktopFile to have the preflight write
+// a launcher.
+func (c *CaskData) SetAppImage(appImagePath, binaryName string) {
+	c.IsAppImage = true
+	c.BinaryPath = appImagePath
+	c.BinaryName = binaryName
+}
+
+// AppImageDesktopFile returns the launcher the preflight writes for an
+// AppImage, in the staged path
+func (c *CaskData) AppImageDesktopFile() string {
+	return c.BinaryName + ".desktop"
+}
+
+// WritesAppImageDesktopFile reports whether the preflight writes the
+// AppImage's launcher, i.e. it was added with AddDesktopFile
+func (c *CaskData) WritesAppImageDesktopFile() bool {
+	if !c.IsAppImage {
+		return false
+	}
+	for _, file := range c.DesktopFiles() {
+		if file.Source == c.AppImageDesktopFile() {
+			return true
+		}
+	}
+	return false
+}
+
+// ThemeIcon is an icon installed into the hicolor theme by an artifact stanza
+type ThemeIcon struct {
+	Source   string // Original path in archive
+	Size     string // Theme size directory, like "48x48" or "scalable"
+	Filename string // Installed file name
+}
+
+// Target returns the installed location of the icon. The file name, from
+// the desktop file's Icon key, is escaped as it is put in a Ruby string.
+func (i ThemeIcon) Target() string {
+	return fmt.Sprintf(`%s/%s/%s`, xdgDataHome, i.dir(), rubyEscaper.Replace(i.Filename))
+}
+
+// dir returns the icon's directory below the XDG data home
+func (i ThemeIcon) dir() string {
+	return "icons/hicolor/" + i.Size + "/apps"
+}
+
+// AddThemeIcon installs one size of the app's icon as
+// icons/hicolor/<size>/apps/<name><ext>. Desktop files refer to a theme
+// icon by name, so every size shares the name of the first one added.
+func (c *CaskData) AddThemeIcon(sourcePathInArchive, size, name string) {
+	if c.IconName == "" {
+		c.IconName = name
+	}
+	icon := ThemeIcon{Source: sourcePathInArchive, Size: size, Filename: c.IconName + path.Ext(sourcePathInArchive)}
+	c.ThemeIcons = append(c.ThemeIcons, icon)
+	c.AddXDGDir(icon.dir())
+}
+
+// HasIcons reports whether the cask installs an icon of either kind
+func (c *CaskData) HasIcons() bool {
+	return c.HasIcon || len(c.ThemeIcons) > 0
+}
+
+// IconCacheDir returns the icon directory below the XDG data home whose
+// cache the postflight refreshes
+func (c *CaskData) IconCacheDir() string {
+	if len(c.ThemeIcons) > 0 {
+		return "icons/hicolor"
+	}
+	return "icons"
+}
+
+// DesktopFileTarget returns the installed location of the desktop file
+func (c *CaskData) DesktopFileTarget() string {
+	return DesktopFile{Filename: c.DesktopFilePath}.Target()
+}
+
+// desktopExecPattern matches the program of an Exec or TryExec line, quoted
+// or not, leaving its arguments and field codes like %U in place. It is valid
+// as both a Ruby and a Go regexp.
+const desktopExecPattern = `^(Exec|TryExec)=(?:"[^"]*"|[^\s"]+)`
+
+// DesktopExecGsub returns the preflight Ruby that points every Exec line of
+// the desktop file, including those of its actions, at the installed binary
+func (c *CaskData) DesktopExecGsub() string {
+	return fmt.Sprintf(`content.gsub!(%%r{%s}, "\\1=#{HOMEBREW_PREFIX}/bin/%s")`, desktopExecPattern, c.BinaryName)
+}
+
+// IconTarget returns the installed location of the icon
+func (c *CaskData) IconTarget() string {
+	return fmt.Sprintf(`%s/icons/%s`, xdgDataHome, rubyEscaper.Replace(c.IconPath))
+}
+
+// ArtifactTargets returns the files placed outside the staged path by
+// artifact stanzas, which must be deleted explicitly on uninstall
+func (c *CaskData) ArtifactTargets() []string {
+	var targets []string
+	for _, file := range c.DesktopFiles() {
+		targets = append(targets, file.Target())
+	}
+	if c.HasIcon {
+		targets = append(targets, c.IconTarget())
+	}
+	for _, icon := range c.ThemeIcons {
+		targets = append(targets, icon.Target())
+	}
+	for _, file := range c.MimeFiles {
+		targets = append(targets, file.Target())
+	}
+	return targets
+}
+
 // InferZapTrash infers common config/cache paths to add to zap trash
 func (c *CaskData) InferZapTrash() {
 	// Convert app name to lowercase with hyphens for common config patterns
@@ -209,10 +593,159 @@ func (c *CaskData) InferZapTrash() {
 	commonPaths := []string{
 		fmt.Sprintf(`#{ENV.fetch("XDG_CONFIG_HOME", "#{Dir.home}/.config")}/%s`, appSlug),
 		fmt.Sprintf(`#{ENV.fetch("XDG_CACHE_HOME", "#{Dir.home}/.cache")}/%s`, appSlug),
-		fmt.Sprintf(`#{ENV.fetch("XDG_DATA_HOME", "#{Dir.home}/.local/share")}/%s`, appSlug),
+		fmt.Sprintf(`%s/%s`, xdgDataHome, appSlug),
 	}
 
 	for _, path := range commonPaths {
 		c.AddZapTrash(path)
 	}
 }
+
+// TemplateURL replaces the version embedded in a download URL with the
+// Ruby interpolation "#{version}" so livecheck and bump can reuse it.
+// Only the release path (after /releases/download/) or the filename is
+// rewritten, never the host or repository name. Versions written with
+// underscores or without dots use the matching cask version helpers.
+// The version is matched exactly as given, so that ExpandVersion with
+// the same version restores the URL. Returns the URL unchanged if the
+// version cannot be found.
+func TemplateURL(url, version string) string {
+	if version == "" {
+		return url
+	}
+
+	// Split off the part of the URL that is safe to rewrite
+	prefix, rest := url, ""
+	if idx := strings.Index(url, "/releases/download/"); idx != -1 {
+		prefix, rest = url[:idx+len("/releases/download/")], url[idx+len("/releases/download/"):]
+	} else if idx := strings.LastIndex(url, "/"); idx != -1 {
+		prefix, rest = url[:idx+1], url[idx+1:]
+	}
+
+	if replaced, ok := templateVersion(rest, version); ok {
+		return prefix + replaced
+	}
+
+	return url
+}
+
+// TemplateVersionedRoot replaces the version in an archive root directory
+// like "tool-1.2.3/" with "#{version}" in the binary and artifact paths, so
+// the cask keeps working after a version bump. If the root only contains
+// the version without its "v" prefix, the prefix is dropped from Version.
+// Returns false when the root does not contain the version.
+func (c *CaskData) TemplateVersionedRoot(rootDir string) bool {
+	if rootDir == "" || c.Latest {
+		return false
+	}
+
+	version := c.Version
+	templated, ok := templateVersion(rootDir, version)
+	if !ok && strings.HasPrefix(version, "v") {
+		version = strings.TrimPrefix(version, "v")
+		templated, ok = templateVersion(rootDir, version)
+	}
+	if !ok {
+		return false
+	}
+
+	c.Version = version
+	paths := []*string{&c.BinaryPath, &c.DesktopFileSource, &c.IconSource}
+	for i := range c.BinaryPaths {
+		paths = append(paths, &c.BinaryPaths[i])
+	}
+	for i := range c.ExtraDesktopFiles {
+		paths = append(paths, &c.ExtraDesktopFiles[i].Source)
+	}
+	for i := range c.ThemeIcons {
+		paths = append(paths, &c.ThemeIcons[i].Source)
+	}
+	for i := range c.MimeFiles {
+		paths = append(paths, &c.MimeFiles[i].Source)
+	}
+	for _, p := range paths {
+		if strings.HasPrefix(*p, rootDir) {
+			*p = templated + strings.TrimPrefix(*p, rootDir)
+		}
+	}
+	return true
+}
+
+// templateVersion replaces version in s with the matching cask version
+// interpolation, trying the dotted form first, then underscores and no dots
+func templateVersion(s, version string) (string, bool) {
+	if version == "" {
+		return s, false
+	}
+
+	type candidate struct {
+		literal     string
+		replacement string
+	}
+	candidates := []candidate{{version, "#{version}"}}
+	if strings.Contains(version, ".") {
+		candidates = append(candidates,
+			candidate{strings.ReplaceAll(version, ".", "_"), "#{version.dots_to_underscores}"},
+			candidate{strings.ReplaceAll(version, ".", ""), "#{version.no_dots}"},
+		)
+	}
+
+	for _, c := range candidates {
+		if replaced, ok := replaceVersion(s, c.literal, c.replacement); ok {
+			return replaced, true
+		}
+	}
+
+	return s, false
+}
+
+// replaceVersion replaces every occurrence of version in s that is not part
+// of a longer number (e.g. "1.2.0" inside "11.2.0")
+func replaceVersion(s, version, replacement string) (string, bool) {
+	var b strings.Builder
+	found := false
+
+	for {
+		idx := strings.Index(s, version)
+		if idx == -1 {
+			b.WriteString(s)
+			break
+		}
+
+		end := idx + len(version)
+		var before, after byte
+		if idx > 0 {
+			before = s[idx-1]
+		}
+		if end < len(s) {
+			after = s[end]
+		}
+
+		// Reject matches inside a longer number or word (e.g. "tool2"),
+		// but allow a "v" prefix as in "v1.2.3"
+		partOfWord := isAlnum(before) && before != 'v' && before != 'V'
+		continues := isDigit(after) || (after == '.' && end+1 < len(s) && isDigit(s[end+1]))
+		if before == '.' || partOfWord || continues {
+			b.WriteString(s[:idx+1])
+			s = s[idx+1:]
+			continue
+		}
+
+		b.WriteString(s[:idx])
+		b.WriteString(replacement)
+		s = s[end:]
+		found = true
+	}
+
+	return b.String(), found
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isAlnum reports whether c is an ASCII letter or digit
+func isAlnum(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -1,10 +1,28 @@
 package homebrew
 
 import (
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 )
 
+// newTestCaskData returns the cask most tests start from, with its binary
+// at app/bin/app
+func newTestCaskData() *CaskData {
+	return &CaskData{
+		Token:       "test-app-linux",
+		Version:     "1.0.0",
+		SHA256:      "abc123",
+		URL:         "https://example.com/app.tar.gz",
+		Description: "Test app",
+		Homepage:    "https://example.com",
+		AppName:     "Test App",
+		BinaryPath:  "app/bin/app",
+		BinaryName:  "test-app",
+	}
+}
+
 func TestGenerateCask(t *testing.T) {
 	data := &CaskData{
 		Token:       "sublime-text-linux",
@@ -79,6 +97,7 @@ func TestGenerateCaskWithDesktopFile(t *testing.T) {
 		"desktop_file",
 		`artifact "app/app.desktop"`,
 		`artifact "app/icons/128x128/app.png"`,
+		`content.gsub!(%r{^(Exec|TryExec)=(?:"[^"]*"|[^\s"]+)}, "\\1=#{HOMEBREW_PREFIX}/bin/test-app")`,
 	}
 
 	for _, req := range required {
@@ -88,6 +107,725 @@ func TestGenerateCaskWithDesktopFile(t *testing.T) {
 	}
 }
 
+func TestGenerateCaskIconLineAnchored(t *testing.T) {
+	data := newTestCaskData()
+	data.SetDesktopFile("app/app.desktop", "test-app.desktop")
+	data.SetIcon("app/icons/128x128/app.png", "test-app.png")
+
+	cask, err := GenerateCask(data)
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+
+	// Only whole Icon= lines are rewritten, not keys like X-Icon=, and they
+	// point at the installed icon
+	want := `content.gsub!(%r{^Icon=.*$}, "Icon=#{xdg_data_home}/icons/test-app.png")`
+	if !strings.Contains(cask, want) {
+		t.Errorf("Generated cask missing %q:\n%s", want, cask)
+	}
+}
+
+func TestDesktopExecPattern(t *testing.T) {
+	// The preflight gsub uses the same pattern in Ruby, where ^ always
+	// anchors at line starts
+	re := regexp.MustCompile("(?m)" + desktopExecPattern)
+	const target = "/home/linuxbrew/.linuxbrew/bin/tool"
+
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"Bare program", "Exec=tool", "Exec=" + target},
+		{"Field code", "Exec=/opt/tool/tool %U", "Exec=" + target + " %U"},
+		{"Arguments and field code", "Exec=/opt/tool/tool --new-window --profile=default %F", "Exec=" + target + " --new-window --profile=default %F"},
+		{"Quoted program with spaces", `Exec="/opt/My Tool/tool" %u`, "Exec=" + target + " %u"},
+		{"TryExec", "TryExec=/opt/tool/tool", "TryExec=" + target},
+		{"Other keys untouched", "Icon=tool", "Icon=tool"},
+		{"Not at line start", "X-Exec=tool", "X-Exec=tool"},
+		{
+			"Every action",
+			"[Desktop Entry]\nExec=tool %U\n\n[Desktop Action new-window]\nExec=tool --new-window\n",
+			"[Desktop Entry]\nExec=" + target + " %U\n\n[Desktop Action new-window]\nExec=" + target + " --new-window\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := re.ReplaceAllString(tt.line, "${1}="+target); got != tt.want {
+				t.Errorf("rewrite of %q = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateCaskPostflight(t *testing.T) {
+	newData := func() *CaskData {
+		data := NewCaskData("test-app-linux", "1.0.0", "abc123", "https://example.com/app.tar.gz")
+		data.AppName = "Test App"
+		data.BinaryPath = "app/app"
+		data.BinaryName = "test-app"
+		return data
+	}
+
+	t.Run("Desktop file and icon", func(t *testing.T) {
+		data := newData()
+		data.SetDesktopFile("app/app.desktop", "test-app.desktop")
+		data.SetIcon("app/app.png", "test-app.png")
+		data.Postflight = true
+
+		cask, err := GenerateCask(data)
+		if err != nil {
+			t.Fatalf("GenerateCask() error = %v", err)
+		}
+		for _, want := range []string{
+			"postflight do",
+			`system_command "update-desktop-database", args: ["#{xdg_data_home}/applications"]`,
+			`system_command "gtk-update-icon-cache", args: ["-f", "-t", "#{xdg_data_home}/icons"]`,
+		} {
+			if !strings.Contains(cask, want) {
+				t.Errorf("Generated cask missing %q:\n%s", want, cask)
+			}
+		}
+	})
+
+	t.Run("Icon only", func(t *testing.T) {
+		data := newData()
+		data.SetIcon("app/app.png", "test-app.png")
+		data.Postflight = true
+
+		cask, err := GenerateCask(data)
+		if err != nil {
+			t.Fatalf("GenerateCask() error = %v", err)
+		}
+		if !strings.Contains(cask, "gtk-update-icon-cache") {
+			t.Errorf("Generated cask missing icon cache refresh:\n%s", cask)
+		}
+		if strings.Contains(cask, "update-desktop-database") {
+			t.Errorf("Generated cask should not refresh desktop database without a desktop file:\n%s", cask)
+		}
+	})
+
+	t.Run("No desktop integration", func(t *testing.T) {
+		data := newData()
+		data.Postflight = true
+
+		cask, err := GenerateCask(data)
+		if err != nil {
+			t.Fatalf("GenerateCask() error = %v", err)
+		}
+		if strings.Contains(cask, "postflight do") {
+			t.Errorf("Generated cask should not have postflight without desktop integration:\n%s", cask)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		data := newData()
+		data.SetDesktopFile("app/app.desktop", "test-app.desktop")
+
+		cask, err := GenerateCask(data)
+		if err != nil {
+			t.Fatalf("GenerateCask() error = %v", err)
+		}
+		if strings.Contains(cask, "postflight do") {
+			t.Errorf("Generated cask should not have postflight when disabled:\n%s", cask)
+		}
+	})
+}
+
+func TestGenerateCaskUninstallArtifacts(t *testing.T) {
+	data := NewCaskData("test-app-linux", "1.0.0", "abc123", "https://example.com/app.tar.gz")
+	data.AppName = "Test App"
+	data.BinaryPath = "app/bin/app"
+	data.BinaryName = "test-app"
+	data.SetDesktopFile("app/app.desktop", "test-app.desktop")
+	data.SetIcon("app/icons/128x128/app.png", "test-app.png")
+	data.InferZapTrash()
+
+	cask, err := GenerateCask(data)
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+
+	if !strings.Contains(cask, "uninstall delete: [") {
+		t.Fatalf("Generated cask missing uninstall stanza:\n%s", cask)
+	}
+
+	// Every artifact target must be deleted on uninstall, at the same path
+	artifactTarget := regexp.MustCompile(`(?m)^  artifact "[^"]+", target: "(.+)"$`)
+	matches := artifactTarget.FindAllStringSubmatch(cask, -1)
+	if len(matches) != 2 {
+		t.Fatalf("Expected 2 artifact stanzas, got %d:\n%s", len(matches), cask)
+	}
+	uninstall := cask[strings.Index(cask, "uninstall delete: ["):]
+	uninstall = uninstall[:strings.Index(uninstall, "]\n")]
+	for _, m := range matches {
+		if !strings.Contains(uninstall, `"`+m[1]+`"`) {
+			t.Errorf("Uninstall stanza missing artifact target %q:\n%s", m[1], uninstall)
+		}
+	}
+
+	// uninstall comes before zap
+	if strings.Index(cask, "uninstall delete:") > strings.Index(cask, "zap trash:") {
+		t.Errorf("uninstall stanza should precede zap:\n%s", cask)
+	}
+}
+
+func TestArtifactTargets(t *testing.T) {
+	data := NewCaskData("test-app-linux", "1.0.0", "abc123", "https://example.com/app.tar.gz")
+	if targets := data.ArtifactTargets(); len(targets) != 0 {
+		t.Errorf("ArtifactTargets() = %v, want none", targets)
+	}
+
+	data.SetIcon("app/app.png", "test-app.png")
+	want := []string{`#{ENV.fetch("XDG_DATA_HOME", "#{Dir.home}/.local/share")}/icons/test-app.png`}
+	if targets := data.ArtifactTargets(); len(targets) != 1 || targets[0] != want[0] {
+		t.Errorf("ArtifactTargets() = %v, want %v", targets, want)
+	}
+
+	data.SetDesktopFile("app/app.desktop", "test-app.desktop")
+	targets := data.ArtifactTargets()
+	if len(targets) != 2 || targets[0] != data.DesktopFileTarget() || targets[1] != data.IconTarget() {
+		t.Errorf("ArtifactTargets() = %v, want desktop file and icon targets", targets)
+	}
+}
+
+func TestGenerateCaskMagicComments(t *testing.T) {
+	data := NewCaskData("tool-linux", "1.0.0", "abc123", "https://example.com/tool.tar.gz")
+	data.AppName = "tool"
+	data.BinaryPath = "tool"
+	data.BinaryName = "tool"
+
+	cask, err := GenerateCask(data)
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+	if !strings.HasPrefix(cask, "# typed: strict\n# frozen_string_literal: true\n\ncask \"tool-linux\" do") {
+		t.Errorf("Cask should start with magic comments by default:\n%s", cask)
+	}
+
+	data.NoMagicComments = true
+	cask, err = GenerateCask(data)
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+	if strings.Contains(cask, "# typed:") || strings.Contains(cask, "frozen_string_literal") {
+		t.Errorf("Cask should omit magic comments:\n%s", cask)
+	}
+	if !strings.HasPrefix(cask, "cask \"tool-linux\" do") {
+		t.Errorf("Cask should start with the cask block:\n%s", cask)
+	}
+}
+
+func TestGenerateCaskLivecheck(t *testing.T) {
+	newData := func() *CaskData {
+		data := NewCaskData("lazygit-linux", "0.40.2", "abc123", "https://example.com/lazygit.tar.gz")
+		data.AppName = "lazygit"
+		data.BinaryPath = "lazygit"
+		data.BinaryName = "lazygit"
+		data.SourceURL = "https://github.com/jesseduffield/lazygit"
+		return data
+	}
+
+	t.Run("Enabled by default", func(t *testing.T) {
+		cask, err := GenerateCask(newData())
+		if err != nil {
+			t.Fatalf("GenerateCask() error = %v", err)
+		}
+
+		want := `  livecheck do
+    url "https://github.com/jesseduffield/lazygit/releases.atom"
+    regex(/^v?(\d+(?:\.\d+)+)$/i)
+  end`
+		if !strings.Contains(cask, want) {
+			t.Errorf("Generated cask missing livecheck block:\n%s", cask)
+		}
+		if strings.Index(cask, "livecheck do") < strings.Index(cask, "homepage ") {
+			t.Errorf("livecheck should follow homepage:\n%s", cask)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		data := newData()
+		data.Livecheck = false
+
+		cask, err := GenerateCask(data)
+		if err != nil {
+			t.Fatalf("GenerateCask() error = %v", err)
+		}
+		if strings.Contains(cask, "livecheck") {
+			t.Errorf("Generated cask should not contain livecheck:\n%s", cask)
+		}
+	})
+
+	t.Run("No GitHub source", func(t *testing.T) {
+		data := newData()
+		data.SourceURL = ""
+
+		cask, err := GenerateCask(data)
+		if err != nil {
+			t.Fatalf("GenerateCask() error = %v", err)
+		}
+		if strings.Contains(cask, "livecheck") {
+			t.Errorf("Generated cask should not contain livecheck without a source URL:\n%s", cask)
+		}
+	})
+}
+
+func TestGenerateCaskVersionLatest(t *testing.T) {
+	data := NewCaskData("nightly-app-linux", "", "", "https://example.com/nightly-app-latest.tar.gz")
+	data.AppName = "nightly-app"
+	data.BinaryPath = "nightly-app"
+	data.BinaryName = "nightly-app"
+	data.SourceURL = "https://github.com/example/nightly-app"
+	data.Latest = true
+
+	cask, err := GenerateCask(data)
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+
+	want := `cask "nightly-app-linux" do
+  version :latest
+  sha256 :no_check
+
+  url "https://example.com/nightly-app-latest.tar.gz"`
+	if !strings.Contains(cask, want) {
+		t.Errorf("Generated cask missing :latest/:no_check stanzas:\n%s", cask)
+	}
+	for _, unwanted := range []string{`version "`, `sha256 "`, "livecheck"} {
+		if strings.Contains(cask, unwanted) {
+			t.Errorf("Generated cask should not contain %q:\n%s", unwanted, cask)
+		}
+	}
+
+	if data.TemplateVersionedRoot("nightly-app-latest/") {
+		t.Error("TemplateVersionedRoot() should not template a :latest cask")
+	}
+}
+
+func TestGenerateCaskCaveats(t *testing.T) {
+	data := NewCaskData("tool-linux", "1.0.0", "abc123", "https://example.com/tool.tar.gz")
+	data.AppName = "tool"
+	data.BinaryPath = "tool"
+	data.BinaryName = "tool"
+
+	cask, err := GenerateCask(data)
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+	if strings.Contains(cask, "caveats") {
+		t.Errorf("Generated cask should not contain caveats by default:\n%s", cask)
+	}
+
+	data.AddCaveat("Run `tool --setup` first.\nThen restart your session.")
+	data.AddZapTrash("~/.config/tool")
+	cask, err = GenerateCask(data)
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+
+	want := `  caveats <<~EOS
+    Run ` + "`tool --setup`" + ` first.
+    Then restart your session.
+  EOS
+end
+`
+	if !strings.HasSuffix(cask, want) {
+		t.Errorf("Generated cask should end with the caveats stanza:\n%s", cask)
+	}
+	if strings.Index(cask, "zap trash:") > strings.Index(cask, "caveats") {
+		t.Errorf("caveats should follow zap:\n%s", cask)
+	}
+}
+
+func TestGenerateCaskDesktopCaveat(t *testing.T) {
+	note := "    If tool does not appear in your application launcher,\n" +
+		"    log out and back in so the desktop environment picks it up.\n"
+
+	tests := []struct {
+		name    string
+		desktop bool
+		custom  string
+		want    string // Caveats stanza, or "" for none
+	}{
+		{name: "No desktop file", want: ""},
+		{name: "Desktop file", desktop: true, want: "  caveats <<~EOS\n" + note + "  EOS\n"},
+		{
+			name:    "Desktop file and custom caveat",
+			desktop: true,
+			custom:  "Run tool --setup first.",
+			want:    "  caveats <<~EOS\n" + note + "\n    Run tool --setup first.\n  EOS\n",
+		},
+		{name: "Custom caveat only", custom: "Run tool --setup first.", want: "  caveats <<~EOS\n    Run tool --setup first.\n  EOS\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := NewCaskData("tool-linux", "1.0.0", "abc123", "https://example.com/tool.tar.gz")
+			data.AppName = "tool"
+			data.BinaryPath = "tool"
+			data.BinaryName = "tool"
+			if tt.desktop {
+				data.SetDesktopFile("tool.desktop", "tool.desktop")
+			}
+			data.AddCaveat(tt.custom)
+
+			cask, err := GenerateCask(data)
+			if err != nil {
+				t.Fatalf("GenerateCask() error = %v", err)
+			}
+			if tt.want == "" {
+				if strings.Contains(cask, "caveats") {
+					t.Errorf("Generated cask should not contain caveats:\n%s", cask)
+				}
+				return
+			}
+			if !strings.HasSuffix(cask, tt.want+"end\n") {
+				t.Errorf("Generated cask should end with caveats %q:\n%s", tt.want, cask)
+			}
+		})
+	}
+}
+
+func TestGenerateCaskMultipleBinaries(t *testing.T) {
+	data := NewCaskData("suite-linux", "1.2.0", "abc123", "https://example.com/suite-1.2.0.tar.gz")
+	data.AppName = "suite"
+	data.BinaryPath = "suite-1.2.0/bin/suite"
+	data.BinaryName = "suite"
+
+	added := data.AddBinaries([]string{
+		"suite-1.2.0/bin/suite",
+		"suite-1.2.0/bin/suite-server",
+		"suite-1.2.0/bin/suitectl",
+		"suite-1.2.0/libexec/suitectl", // Same target as bin/suitectl
+	})
+	if want := []string{"suite-1.2.0/bin/suite-server", "suite-1.2.0/bin/suitectl"}; !reflect.DeepEqual(added, want) {
+		t.Errorf("AddBinaries() = %v, want %v", added, want)
+	}
+	data.TemplateVersionedRoot("suite-1.2.0/")
+
+	cask, err := GenerateCask(data)
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+
+	want := `  binary "suite-#{version}/bin/suite", target: "suite"
+  binary "suite-#{version}/bin/suite-server", target: "suite-server"
+  binary "suite-#{version}/bin/suitectl", target: "suitectl"
+`
+	if !strings.Contains(cask, want) {
+		t.Errorf("Generated cask should have one binary stanza per executable:\n%s", cask)
+	}
+	if strings.Contains(cask, "libexec") {
+		t.Errorf("Generated cask should skip a duplicate target:\n%s", cask)
+	}
+}
+
+func TestGenerateCaskMultipleDesktopFiles(t *testing.T) {
+	data := newTestCaskData()
+	if !data.AddDesktopFile("app/app.desktop", "app.desktop") {
+		t.Fatal("AddDesktopFile() should set the first desktop file")
+	}
+	if !data.AddDesktopFile("app/app-url-handler.desktop", "app-url-handler.desktop") {
+		t.Fatal("AddDesktopFile() should add a second desktop file")
+	}
+	if data.AddDesktopFile("app/share/app.desktop", "app.desktop") {
+		t.Error("AddDesktopFile() should skip a file name that is already a target")
+	}
+
+	cask, err := GenerateCask(data)
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+
+	want := `    # Fix desktop file paths
+    desktop_file = staged_path.join("app/app.desktop")
+    if desktop_file.exist?
+      content = desktop_file.read
+      content.gsub!(%r{^(Exec|TryExec)=(?:"[^"]*"|[^\s"]+)}, "\\1=#{HOMEBREW_PREFIX}/bin/test-app")
+      desktop_file.write(content)
+    end
+
+    desktop_file = staged_path.join("app/app-url-handler.desktop")
+    if desktop_file.exist?
+      content = desktop_file.read
+      content.gsub!(%r{^(Exec|TryExec)=(?:"[^"]*"|[^\s"]+)}, "\\1=#{HOMEBREW_PREFIX}/bin/test-app")
+      desktop_file.write(content)
+    end
+  end
+`
+	if !strings.Contains(cask, want) {
+		t.Errorf("Generated cask missing a preflight fix per desktop file:\n%s", cask)
+	}
+
+	for _, artifact := range []string{
+		`artifact "app/app.desktop", target: "#{ENV.fetch("XDG_DATA_HOME", "#{Dir.home}/.local/share")}/applications/app.desktop"`,
+		`artifact "app/app-url-handler.desktop", target: "#{ENV.fetch("XDG_DATA_HOME", "#{Dir.home}/.local/share")}/applications/app-url-handler.desktop"`,
+	} {
+		if !strings.Contains(cask, artifact) {
+			t.Errorf("Generated cask missing %q", artifact)
+		}
+	}
+	if got := len(data.ArtifactTargets()); got != 2 {
+		t.Errorf("ArtifactTargets() has %d targets, want 2", got)
+	}
+}
+
+func TestGenerateCaskMimeFiles(t *testing.T) {
+	data := newTestCaskData()
+	data.AddMimeFile("app/share/mime/packages/app-project.xml", "app-project.xml")
+	data.AddMimeFile("app/share/mime/packages/app-workspace.xml", "app-workspace.xml")
+	if data.AddMimeFile("app/mime/packages/app-project.xml", "app-project.xml") {
+		t.Error("AddMimeFile() should skip a file name that is already a target")
+	}
+
+	wantTargets := []string{
+		`#{ENV.fetch("XDG_DATA_HOME", "#{Dir.home}/.local/share")}/mime/packages/app-project.xml`,
+		`#{ENV.fetch("XDG_DATA_HOME", "#{Dir.home}/.local/share")}/mime/packages/app-workspace.xml`,
+	}
+	if got := data.ArtifactTargets(); !reflect.DeepEqual(got, wantTargets) {
+		t.Errorf("ArtifactTargets() = %v, want %v", got, wantTargets)
+	}
+
+	cask, err := GenerateCask(data)
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+
+	required := []string{
+		`system_command "mkdir", args: ["-p", "#{xdg_data_home}/mime/packages"]`,
+		`artifact "app/share/mime/packages/app-project.xml", target: "` + wantTargets[0] + `"`,
+		`artifact "app/share/mime/packages/app-workspace.xml", target: "` + wantTargets[1] + `"`,
+		`  caveats <<~EOS
+    To open files with Test App, register its file types by running:
+      update-mime-database "${XDG_DATA_HOME:-$HOME/.local/share}/mime"
+  EOS`,
+	}
+	for _, req := range required {
+		if !strings.Contains(cask, req) {
+			t.Errorf("Generated cask missing %q:\n%s", req, cask)
+		}
+	}
+	if strings.Count(cask, "mime/packages\"]") != 1 {
+		t.Errorf("Generated cask should create the mime/packages directory once:\n%s", cask)
+	}
+}
+
+func TestGenerateCaskThemeIcons(t *testing.T) {
+	data := newTestCaskData()
+	data.Postflight = true
+	data.SetDesktopFile("app/app.desktop", "test-app.desktop")
+	data.AddThemeIcon("app/icons/16x16/app.png", "16x16", "test-app")
+	data.AddThemeIcon("app/icons/48x48/app.png", "48x48", "ignored")
+	data.AddThemeIcon("app/icons/128x128/app.png", "128x128", "ignored")
+
+	cask, err := GenerateCask(data)
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+
+	for _, size := range []string{"16x16", "48x48", "128x128"} {
+		dir := "icons/hicolor/" + size + "/apps"
+		required := []string{
+			`system_command "mkdir", args: ["-p", "#{xdg_data_home}/` + dir + `"]`,
+			`artifact "app/icons/` + size + `/app.png", target: "` + xdgDataHome + "/" + dir + `/test-app.png"`,
+		}
+		for _, req := range required {
+			if !strings.Contains(cask, req) {
+				t.Errorf("Generated cask missing %q", req)
+			}
+		}
+	}
+
+	required := []string{
+		`content.gsub!(%r{^Icon=.*$}, "Icon=test-app")`,
+		`args: ["-f", "-t", "#{xdg_data_home}/icons/hicolor"]`,
+	}
+	for _, req := range required {
+		if !strings.Contains(cask, req) {
+			t.Errorf("Generated cask missing %q:\n%s", req, cask)
+		}
+	}
+	if got := len(data.ArtifactTargets()); got != 4 {
+		t.Errorf("ArtifactTargets() has %d targets, want 4", got)
+	}
+}
+
+func TestGenerateCaskThemeIconNameEscaped(t *testing.T) {
+	data := newTestCaskData()
+	data.SetDesktopFile("app/app.desktop", "test-app.desktop")
+	data.AddThemeIcon("app/icons/48x48/app.png", "48x48", `app"#{exit}`)
+
+	cask, err := GenerateCask(data)
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+	for _, want := range []string{
+		`"Icon=app\"\#{exit}"`,
+		`/icons/hicolor/48x48/apps/app\"\#{exit}.png"`,
+	} {
+		if !strings.Contains(cask, want) {
+			t.Errorf("Generated cask missing %q:\n%s", want, cask)
+		}
+	}
+	if strings.Contains(cask, `app"#{exit}`) {
+		t.Errorf("Generated cask has the icon name unescaped:\n%s", cask)
+	}
+}
+
+func TestGenerateCaskAppImage(t *testing.T) {
+	newData := func() *CaskData {
+		data := newTestCaskData()
+		data.URL = "https://example.com/Test-App-1.0.0-x86_64.zip"
+		data.SetAppImage("Test-App-1.0.0-x86_64.AppImage", "test-app")
+		return data
+	}
+
+	data := newData()
+	data.AddDesktopFile(data.AppImageDesktopFile(), data.AppImageDesktopFile())
+	cask, err := GenerateCask(data)
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+
+	required := []string{
+		`FileUtils.chmod "a+x", staged_path.join("Test-App-1.0.0-x86_64.AppImage")`,
+		`    File.write(staged_path.join("test-app.desktop"), <<~EOS)
+      [Desktop Entry]
+      Type=Application
+      Name=Test App
+      Exec=#{HOMEBREW_PREFIX}/bin/test-app %U
+      Terminal=false
+    EOS`,
+		`desktop_file = staged_path.join("test-app.desktop")`,
+		`binary "Test-App-1.0.0-x86_64.AppImage", target: "test-app"`,
+		`artifact "test-app.desktop", target: "` + xdgDataHome + `/applications/test-app.desktop"`,
+	}
+	for _, req := range required {
+		if !strings.Contains(cask, req) {
+			t.Errorf("Generated cask missing %q:\n%s", req, cask)
+		}
+	}
+
+	// Without a launcher, the AppImage still needs to be executable
+	cask, err = GenerateCask(newData())
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+	if !strings.Contains(cask, `FileUtils.chmod "a+x"`) {
+		t.Errorf("Generated cask should make the AppImage executable:\n%s", cask)
+	}
+	if strings.Contains(cask, "File.write") || strings.Contains(cask, "artifact") {
+		t.Errorf("Generated cask should not write a launcher without a desktop file:\n%s", cask)
+	}
+}
+
+func TestRubyString(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"Plain", "Foo Bar", "Foo Bar", false},
+		{"Quote", `Say "hi"`, `Say \"hi\"`, false},
+		{"Backslash", `C:\Tools`, `C:\\Tools`, false},
+		{"Interpolation", `#{system("id")}`, `\#{system(\"id\")}`, false},
+		{"Instance and global variables", "#@x #$y", `\#@x \#$y`, false},
+		{"Bare hash", "C# editor", "C# editor", false},
+		{"Newline", "Foo\nend", "", true},
+		{"Tab", "Foo\tBar", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rubyString(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("rubyString(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("rubyString(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateCaskDisplayName(t *testing.T) {
+	data := NewCaskData("foo-bar-linux", "1.0.0", "abc123", "https://example.com/foo.AppImage")
+	data.AppName = "foo-bar"
+	data.DisplayName = `Foo "Bar" #{exit}`
+	data.Description = `Edits "files"`
+	data.SetAppImage("foo.AppImage", "foo-bar")
+	data.AddDesktopFile(data.AppImageDesktopFile(), data.AppImageDesktopFile())
+	data.InferZapTrash()
+
+	cask, err := GenerateCask(data)
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+	for _, want := range []string{
+		`name "Foo \"Bar\" \#{exit}"`,
+		`desc "Edits \"files\""`,
+		`homepage "https://github.com/foo-bar"`,
+		`Name=Foo \"Bar\" \#{exit}`,
+		`If Foo \"Bar\" \#{exit} does not appear`,
+		`.config")}/foo-bar"`,
+	} {
+		if !strings.Contains(cask, want) {
+			t.Errorf("GenerateCask() missing %q\n%s", want, cask)
+		}
+	}
+
+	data.DisplayName = "Foo\nend"
+	if _, err := GenerateCask(data); err == nil {
+		t.Error("GenerateCask() expected error for a name with a newline")
+	}
+}
+
+func TestLivecheckURL(t *testing.T) {
+	tests := []struct {
+		sourceURL string
+		want      string
+	}{
+		{"https://github.com/jesseduffield/lazygit", "https://github.com/jesseduffield/lazygit/releases.atom"},
+		{"https://github.com/owner/repo.git/", "https://github.com/owner/repo/releases.atom"},
+		{"https://gitlab.com/group/sub/repo", "https://gitlab.com/group/sub/repo/-/tags?format=atom"},
+		{"https://codeberg.org/owner/repo", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		data := &CaskData{SourceURL: tt.sourceURL}
+		if got := data.LivecheckURL(); got != tt.want {
+			t.Errorf("LivecheckURL() for %q = %q, want %q", tt.sourceURL, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateCaskBinaryOnly(t *testing.T) {
+	// tap-cask --no-desktop skips detection, so no desktop fields are set
+	data := NewCaskData("test-app-linux", "1.0.0", "abc123", "https://example.com/app.tar.gz")
+	data.AppName = "Test App"
+	data.Description = "Test app"
+	data.BinaryPath = "app/bin/app"
+	data.BinaryName = "test-app"
+
+	cask, err := GenerateCask(data)
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+
+	if !strings.Contains(cask, `binary "app/bin/app", target: "test-app"`) {
+		t.Errorf("Binary-only cask missing binary stanza:\n%s", cask)
+	}
+	for _, forbidden := range []string{"preflight do", "artifact ", "desktop_file", "xdg_data_home"} {
+		if strings.Contains(cask, forbidden) {
+			t.Errorf("Binary-only cask should not contain %q:\n%s", forbidden, cask)
+		}
+	}
+}
+
 func TestNewCaskData(t *testing.T) {
 	data := NewCaskData("test-linux", "1.0.0", "abc123", "https://example.com/test.tar.gz")
 
@@ -159,3 +897,190 @@ func TestSetIcon(t *testing.T) {
 		t.Errorf("IconPath = %q, want %q", data.IconPath, "test-icon.png")
 	}
 }
+
+func TestTemplateVersionedRoot(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     string
+		rootDir     string
+		binaryPath  string
+		want        bool
+		wantVersion string
+		wantBinary  string
+	}{
+		{
+			name:        "Dotted version",
+			version:     "1.2.3",
+			rootDir:     "tool-1.2.3/",
+			binaryPath:  "tool-1.2.3/tool",
+			want:        true,
+			wantVersion: "1.2.3",
+			wantBinary:  "tool-#{version}/tool",
+		},
+		{
+			name:        "Tag with v prefix, root without",
+			version:     "v1.2.3",
+			rootDir:     "tool-1.2.3-linux-x86_64/",
+			binaryPath:  "tool-1.2.3-linux-x86_64/bin/tool",
+			want:        true,
+			wantVersion: "1.2.3",
+			wantBinary:  "tool-#{version}-linux-x86_64/bin/tool",
+		},
+		{
+			name:        "Tag and root both with v prefix",
+			version:     "v1.2.3",
+			rootDir:     "tool-v1.2.3/",
+			binaryPath:  "tool-v1.2.3/tool",
+			want:        true,
+			wantVersion: "v1.2.3",
+			wantBinary:  "tool-#{version}/tool",
+		},
+		{
+			name:        "Underscored version",
+			version:     "1.2.3",
+			rootDir:     "tool_1_2_3/",
+			binaryPath:  "tool_1_2_3/tool",
+			want:        true,
+			wantVersion: "1.2.3",
+			wantBinary:  "tool_#{version.dots_to_underscores}/tool",
+		},
+		{
+			name:        "Unversioned root",
+			version:     "1.2.3",
+			rootDir:     "tool/",
+			binaryPath:  "tool/tool",
+			want:        false,
+			wantVersion: "1.2.3",
+			wantBinary:  "tool/tool",
+		},
+		{
+			name:        "Longer number is not a match",
+			version:     "1.2.3",
+			rootDir:     "tool-11.2.3/",
+			binaryPath:  "tool-11.2.3/tool",
+			want:        false,
+			wantVersion: "1.2.3",
+			wantBinary:  "tool-11.2.3/tool",
+		},
+		{
+			name:        "No root directory",
+			version:     "1.2.3",
+			rootDir:     "",
+			binaryPath:  "tool",
+			want:        false,
+			wantVersion: "1.2.3",
+			wantBinary:  "tool",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := NewCaskData("tool-linux", tt.version, "abc123", "https://example.com/tool.tar.gz")
+			data.BinaryPath = tt.binaryPath
+
+			if got := data.TemplateVersionedRoot(tt.rootDir); got != tt.want {
+				t.Errorf("TemplateVersionedRoot() = %v, want %v", got, tt.want)
+			}
+			if data.Version != tt.wantVersion {
+				t.Errorf("Version = %q, want %q", data.Version, tt.wantVersion)
+			}
+			if data.BinaryPath != tt.wantBinary {
+				t.Errorf("BinaryPath = %q, want %q", data.BinaryPath, tt.wantBinary)
+			}
+		})
+	}
+}
+
+func TestGenerateCaskVersionedRoot(t *testing.T) {
+	data := NewCaskData("tool-linux", "1.2.3", "abc123", "https://example.com/tool-1.2.3.tar.gz")
+	data.AppName = "Tool"
+	data.BinaryPath = "tool-1.2.3/bin/tool"
+	data.BinaryName = "tool"
+	data.SetDesktopFile("tool-1.2.3/share/tool.desktop", "tool.desktop")
+	data.SetIcon("tool-1.2.3/share/tool.png", "tool.png")
+
+	if !data.TemplateVersionedRoot("tool-1.2.3/") {
+		t.Fatal("TemplateVersionedRoot() = false, want true")
+	}
+
+	cask, err := GenerateCask(data)
+	if err != nil {
+		t.Fatalf("GenerateCask() error = %v", err)
+	}
+
+	for _, want := range []string{
+		`version "1.2.3"`,
+		`binary "tool-#{version}/bin/tool", target: "tool"`,
+		`artifact "tool-#{version}/share/tool.desktop"`,
+		`artifact "tool-#{version}/share/tool.png"`,
+		`staged_path.join("tool-#{version}/share/tool.desktop")`,
+	} {
+		if !strings.Contains(cask, want) {
+			t.Errorf("Generated cask missing %q:\n%s", want, cask)
+		}
+	}
+	if strings.Contains(cask, "tool-1.2.3/") {
+		t.Errorf("Generated cask still contains the literal versioned root:\n%s", cask)
+	}
+}
+
+func TestTemplateURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		version  string
+		expected string
+	}{
+		{
+			name:     "Tag and filename",
+			url:      "https://github.com/user/tool/releases/download/v1.2.3/tool-1.2.3-linux-x86_64.tar.gz",
+			version:  "1.2.3",
+			expected: "https://github.com/user/tool/releases/download/v#{version}/tool-#{version}-linux-x86_64.tar.gz",
+		},
+		{
+			name:     "Build number",
+			url:      "https://download.sublimetext.com/sublime_text_build_4200_x64.tar.xz",
+			version:  "4200",
+			expected: "https://download.sublimetext.com/sublime_text_build_#{version}_x64.tar.xz",
+		},
+		{
+			name:     "Version with v prefix",
+			url:      "https://github.com/user/tool/releases/download/v0.9.0/tool_0.9.0_linux_amd64.tar.gz",
+			version:  "v0.9.0",
+			expected: "https://github.com/user/tool/releases/download/#{version}/tool_0.9.0_linux_amd64.tar.gz",
+		},
+		{
+			name:     "Dots to underscores",
+			url:      "https://example.com/files/app-2_1_0-linux.tar.gz",
+			version:  "2.1.0",
+			expected: "https://example.com/files/app-#{version.dots_to_underscores}-linux.tar.gz",
+		},
+		{
+			name:     "Not part of longer number",
+			url:      "https://github.com/user/tool/releases/download/v11.2.0/tool-11.2.0.tar.gz",
+			version:  "1.2.0",
+			expected: "https://github.com/user/tool/releases/download/v11.2.0/tool-11.2.0.tar.gz",
+		},
+		{
+			name:     "Repository name untouched",
+			url:      "https://github.com/user/tool2/releases/download/2/tool2-linux.tar.gz",
+			version:  "2",
+			expected: "https://github.com/user/tool2/releases/download/#{version}/tool2-linux.tar.gz",
+		},
+		{
+			name:     "Version not present",
+			url:      "https://github.com/user/tool/releases/download/latest/tool-linux.tar.gz",
+			version:  "1.0.0",
+			expected: "https://github.com/user/tool/releases/download/latest/tool-linux.tar.gz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := TemplateURL(tt.url, tt.version)
+			if result != tt.expected {
+				t.Errorf("TemplateURL() = %s, want %s", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package homebrew
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/castrojo/tap-tools/internal/checksum"
+)
+
+// CoreFormulaAPI is the formulae.brew.sh endpoint for homebrew-core formulas
+var CoreFormulaAPI = "https://formulae.brew.sh/api/formula"
+
+// CoreFormula is the part of a formulae.brew.sh formula we use
+type CoreFormula struct {
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+	Tap      string `json:"tap"`
+	Desc     string `json:"desc"`
+	Homepage string `json:"homepage"`
+}
+
+// LookupCoreFormula returns the homebrew-core formula called name, or nil
+// when homebrew-core has no such formula
+func LookupCoreFormula(name string) (*CoreFormula, error) {
+	data, err := checksum.DownloadFile(fmt.Sprintf("%s/%s.json", CoreFormulaAPI, url.PathEscape(name)))
+	if checksum.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up %s in homebrew-core: %w", name, err)
+	}
+	return parseCoreFormula(data)
+}
+
+// parseCoreFormula decodes a formulae.brew.sh formula JSON document
+func parseCoreFormula(data []byte) (*CoreFormula, error) {
+	var formula CoreFormula
+	if err := json.Unmarshal(data, &formula); err != nil {
+		return nil, fmt.Errorf("failed to parse homebrew-core formula: %w", err)
+	}
+	if formula.Name == "" {
+		return nil, fmt.Errorf("failed to parse homebrew-core formula: missing name")
+	}
+	return &formula, nil
+}
+
+// CoreConflict returns the conflicts_with name and reason for a tap formula
+// that shares its name with core
+func CoreConflict(core *CoreFormula) (name, because string) {
+	name = core.FullName
+	if core.Tap != "" {
+		name = core.Tap + "/" + core.Name
+	}
+	return name, fmt.Sprintf("homebrew-core also has a %s formula", core.Name)
+}
@@ -0,0 +1,91 @@
+package homebrew
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseCoreFormula(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantName string
+		wantTap  string
+		wantErr  bool
+	}{
+		{
+			name:     "core formula",
+			data:     `{"name":"jq","full_name":"jq","tap":"homebrew/core","desc":"Lightweight and flexible command-line JSON processor","versions":{"stable":"1.7.1"}}`,
+			wantName: "jq",
+			wantTap:  "homebrew/core",
+		},
+		{
+			name:    "missing name",
+			data:    `{"tap":"homebrew/core"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON",
+			data:    `<html>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCoreFormula([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCoreFormula() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Name != tt.wantName || got.Tap != tt.wantTap {
+				t.Errorf("parseCoreFormula() = %+v, want name %q tap %q", got, tt.wantName, tt.wantTap)
+			}
+		})
+	}
+}
+
+func TestLookupCoreFormula(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/jq.json":
+			w.Write([]byte(`{"name":"jq","full_name":"jq","tap":"homebrew/core"}`))
+		case "/broken.json":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	orig := CoreFormulaAPI
+	CoreFormulaAPI = server.URL
+	defer func() { CoreFormulaAPI = orig }()
+
+	got, err := LookupCoreFormula("jq")
+	if err != nil || got == nil || got.Name != "jq" {
+		t.Errorf("LookupCoreFormula(jq) = %+v, %v; want jq", got, err)
+	}
+
+	got, err = LookupCoreFormula("not-in-core")
+	if err != nil || got != nil {
+		t.Errorf("LookupCoreFormula(not-in-core) = %+v, %v; want nil, nil", got, err)
+	}
+
+	if _, err := LookupCoreFormula("broken"); err == nil {
+		t.Error("LookupCoreFormula(broken) expected error for HTTP 403")
+	}
+}
+
+func TestCoreConflict(t *testing.T) {
+	name, because := CoreConflict(&CoreFormula{Name: "jq", FullName: "jq", Tap: "homebrew/core"})
+	if name != "homebrew/core/jq" {
+		t.Errorf("CoreConflict() name = %q, want homebrew/core/jq", name)
+	}
+	if because != "homebrew-core also has a jq formula" {
+		t.Errorf("CoreConflict() because = %q", because)
+	}
+}
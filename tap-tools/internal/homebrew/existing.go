@@ -0,0 +1,74 @@
+package homebrew
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// packageDirs are the tap directories that hold formulas and casks
+var packageDirs = []string{"Formula", "Casks"}
+
+// FindExistingPackage returns the formulas and casks in the tap whose name
+// matches name after normalization, so "foo", "foo-linux" and "Foo_Linux"
+// are all treated as the same package. Missing directories are skipped.
+func FindExistingPackage(tapRoot, name string) ([]string, error) {
+	want := normalizePackageToken(name)
+
+	var matches []string
+	for _, dir := range packageDirs {
+		entries, err := os.ReadDir(filepath.Join(tapRoot, dir))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".rb") {
+				continue
+			}
+			if normalizePackageToken(strings.TrimSuffix(entry.Name(), ".rb")) == want {
+				matches = append(matches, filepath.Join(tapRoot, dir, entry.Name()))
+			}
+		}
+	}
+
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// CheckDuplicates looks for near-duplicates of name elsewhere in the tap,
+// ignoring outputs, the files about to be written. Unless allow is set, the
+// first one found is an error; otherwise they are returned for a warning.
+func CheckDuplicates(tapRoot, name string, allow bool, outputs ...string) ([]string, error) {
+	existing, err := FindExistingPackage(tapRoot, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var duplicates []string
+	for _, path := range existing {
+		if slices.ContainsFunc(outputs, func(output string) bool { return filepath.Clean(output) == filepath.Clean(path) }) {
+			continue
+		}
+		if !allow {
+			return nil, fmt.Errorf("%s already exists in the tap (use --allow-duplicate to generate anyway)", path)
+		}
+		duplicates = append(duplicates, path)
+	}
+	return duplicates, nil
+}
+
+// normalizePackageToken reduces a formula name or cask token to a form
+// where near-duplicates compare equal
+func normalizePackageToken(name string) string {
+	name = strings.ToLower(name)
+	name = strings.ReplaceAll(name, "_", "-")
+	return strings.TrimSuffix(name, "-linux")
+}
@@ -0,0 +1,116 @@
+package homebrew
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindExistingPackage(t *testing.T) {
+	tapRoot := t.TempDir()
+	for _, f := range []string{
+		"Formula/foo.rb",
+		"Formula/foobar.rb",
+		"Formula/bar_baz.rb",
+		"Casks/foo-linux.rb",
+		"Casks/qux-linux.rb",
+		"Casks/README.md",
+	} {
+		path := filepath.Join(tapRoot, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("# test\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"foo", []string{"Casks/foo-linux.rb", "Formula/foo.rb"}},
+		{"foo-linux", []string{"Casks/foo-linux.rb", "Formula/foo.rb"}},
+		{"Foo", []string{"Casks/foo-linux.rb", "Formula/foo.rb"}},
+		{"bar-baz", []string{"Formula/bar_baz.rb"}},
+		{"bar-baz-linux", []string{"Formula/bar_baz.rb"}},
+		{"qux", []string{"Casks/qux-linux.rb"}},
+		{"fo", nil},
+		{"readme", nil},
+		{"new-tool", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindExistingPackage(tapRoot, tt.name)
+			if err != nil {
+				t.Fatalf("FindExistingPackage() error = %v", err)
+			}
+
+			var want []string
+			for _, w := range tt.want {
+				want = append(want, filepath.Join(tapRoot, w))
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("FindExistingPackage(%q) = %v, want %v", tt.name, got, want)
+			}
+		})
+	}
+}
+
+func TestFindExistingPackageEmptyTap(t *testing.T) {
+	got, err := FindExistingPackage(t.TempDir(), "foo")
+	if err != nil {
+		t.Fatalf("FindExistingPackage() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("FindExistingPackage() = %v, want none", got)
+	}
+}
+
+func TestCheckDuplicates(t *testing.T) {
+	tapRoot := t.TempDir()
+	for _, f := range []string{"Formula/foo.rb", "Casks/foo-linux.rb"} {
+		path := filepath.Join(tapRoot, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("# test\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	formula := filepath.Join(tapRoot, "Formula", "foo.rb")
+	cask := filepath.Join(tapRoot, "Casks", "foo-linux.rb")
+
+	tests := []struct {
+		name    string
+		pkg     string
+		allow   bool
+		outputs []string
+		want    []string
+		wantErr bool
+	}{
+		{"Near-duplicate is refused", "foo", false, []string{formula}, nil, true},
+		{"Near-duplicate is allowed", "foo", true, []string{formula}, []string{cask}, false},
+		{"Regenerating every output", "foo", false, []string{formula, cask}, nil, false},
+		{"Output path is cleaned", "foo", false, []string{tapRoot + "/Formula/../Formula/foo.rb", cask}, nil, false},
+		{"New package", "bar", false, []string{filepath.Join(tapRoot, "Formula", "bar.rb")}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CheckDuplicates(tapRoot, tt.pkg, tt.allow, tt.outputs...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckDuplicates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), "--allow-duplicate") {
+				t.Errorf("CheckDuplicates() error = %v, want it to mention --allow-duplicate", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckDuplicates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
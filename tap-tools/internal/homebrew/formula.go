@@ -3,6 +3,10 @@ package homebrew
 import (
 	"bytes"
 	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"text/template"
 
@@ -25,30 +29,134 @@ type FormulaData struct {
 	InstallBlock string   // Ruby code for install method
 	TestBlock    string   // Ruby code for test method
 	SourceURL    string   // Repository URL for regeneration instructions
+	HeadURL      string   // Git URL for brew install --HEAD
+	HeadBranch   string   // Branch built by --HEAD
+	Notes        string   // Maintainer notes, rendered as comments after the header
+	MinGlibc     string   // Minimum glibc a prebuilt binary needs (adds a caveat)
+	Caveats      []string // Custom caveat lines, after any generated ones
+
+	Intel *ArchAsset // x86_64 download, rendered in an on_intel block
+	ARM   *ArchAsset // arm64 download, rendered in an on_arm block
+	Arch  string     // Only supported architecture when a single one is available
+
+	Resources []Resource // Extra archives staged into libexec by the install block
+
+	ConflictsWith    []string // Formulas that install the same files
+	ConflictsBecause string   // Reason rendered with conflicts_with
+
+	NoMagicComments bool // Omit the Sorbet and frozen_string_literal comments
+}
+
+// ArchAsset is a prebuilt download for one CPU architecture
+type ArchAsset struct {
+	URL    string
+	SHA256 string
+}
+
+// Resource is an extra download rendered as a resource block
+type Resource struct {
+	Name   string
+	URL    string
+	SHA256 string
+}
+
+// AddResource adds a resource block for an extra archive of a split
+// release and stages it into libexec/<name> at the end of the install block
+func (f *FormulaData) AddResource(name, url, sha256 string) {
+	f.Resources = append(f.Resources, Resource{Name: name, URL: url, SHA256: sha256})
+
+	stage := fmt.Sprintf("\n    resource(%q).stage(libexec/%q)", name, name)
+	if i := strings.LastIndex(f.InstallBlock, "\n  end"); i >= 0 {
+		f.InstallBlock = f.InstallBlock[:i] + stage + f.InstallBlock[i:]
+	}
+}
+
+// SetArchAssets switches the formula to per-architecture downloads
+// With both architectures, url and sha256 move into on_arm/on_intel blocks.
+// With only one, it becomes the formula's url and the formula is restricted
+// to that architecture with depends_on arch.
+func (f *FormulaData) SetArchAssets(intel, arm *ArchAsset) {
+	switch {
+	case intel != nil && arm != nil:
+		f.Intel, f.ARM = intel, arm
+	case intel != nil:
+		f.URL, f.SHA256, f.Arch = intel.URL, intel.SHA256, "x86_64"
+	case arm != nil:
+		f.URL, f.SHA256, f.Arch = arm.URL, arm.SHA256, "arm64"
+	}
 }
 
 // formulaTemplate is the template for generating Homebrew formulas
-const formulaTemplate = `# typed: strict
+const formulaTemplate = `{{ if not .NoMagicComments -}}
+# typed: strict
 # frozen_string_literal: true
 
+{{ end -}}
 # {{ cleanDesc .Description }}
 class {{ .ClassName }} < Formula
   desc "{{ cleanDesc .Description }}"
   homepage "{{ if .Homepage }}{{ .Homepage }}{{ else }}https://github.com/{{ .PackageName }}{{ end }}"
+{{- if and .Intel .ARM }}
+  version "{{ .Version }}"
+{{- else }}
   url "{{ .URL }}"
   sha256 "{{ .SHA256 }}"
+{{- end }}
 {{- if .License }}
 
-  license "{{ .License }}"
+  license {{ rubyLicense .License }}
+{{- end }}
+{{- if .HeadURL }}
+  head "{{ .HeadURL }}"{{ if .HeadBranch }}, branch: "{{ .HeadBranch }}"{{ end }}
 {{- end }}
-{{- if .Dependencies }}
+{{- if or .Dependencies .Arch }}
 
+{{- if .Arch }}
+  depends_on arch: :{{ .Arch }}
+{{- end }}
 {{- range .Dependencies }}
   depends_on "{{ . }}"
 {{- end }}
+{{- end }}
+{{- if and .Intel .ARM }}
+
+  on_arm do
+    url "{{ .ARM.URL }}"
+    sha256 "{{ .ARM.SHA256 }}"
+  end
+
+  on_intel do
+    url "{{ .Intel.URL }}"
+    sha256 "{{ .Intel.SHA256 }}"
+  end
+{{- end }}
+{{- if .ConflictsWith }}
+
+  conflicts_with {{ range $i, $name := .ConflictsWith }}{{ if $i }}, {{ end }}"{{ $name }}"{{ end }}{{ if .ConflictsBecause }}, because: "{{ .ConflictsBecause }}"{{ end }}
+{{- end }}
+{{- range .Resources }}
+
+  resource "{{ .Name }}" do
+    url "{{ .URL }}"
+    sha256 "{{ .SHA256 }}"
+  end
 {{- end }}
 
   {{ .InstallBlock }}
+{{- if or .MinGlibc .Caveats }}
+
+  def caveats
+    <<~EOS
+{{- if .MinGlibc }}
+      This prebuilt binary requires glibc {{ .MinGlibc }} or newer.
+      Check your system version with: ldd --version
+{{- end }}
+{{- range .Caveats }}
+{{ if . }}      {{ . }}{{ end }}
+{{- end }}
+    EOS
+  end
+{{- end }}
 
   {{ .TestBlock }}
 end
@@ -57,7 +165,8 @@ end
 // GenerateFormula generates a Homebrew formula from FormulaData
 func GenerateFormula(data *FormulaData) (string, error) {
 	tmpl, err := template.New("formula").Funcs(template.FuncMap{
-		"cleanDesc": cleanDesc,
+		"cleanDesc":   cleanDesc,
+		"rubyLicense": rubyLicense,
 	}).Parse(formulaTemplate)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse formula template: %w", err)
@@ -71,6 +180,9 @@ func GenerateFormula(data *FormulaData) (string, error) {
 			return "", fmt.Errorf("failed to write header: %w", err)
 		}
 	}
+	if err := generator.WriteNotes(&buf, data.Notes); err != nil {
+		return "", fmt.Errorf("failed to write notes: %w", err)
+	}
 
 	// Then write formula content
 	if err := tmpl.Execute(&buf, data); err != nil {
@@ -80,12 +192,145 @@ func GenerateFormula(data *FormulaData) (string, error) {
 	return buf.String(), nil
 }
 
+// AddCaveat appends custom caveat text, one line per line of text
+func (f *FormulaData) AddCaveat(text string) error {
+	lines, err := appendCaveat(f.Caveats, text)
+	if err != nil {
+		return err
+	}
+	f.Caveats = lines
+	return nil
+}
+
+// binInstallRe matches bin.install and bin.install_symlink, but not sbin.install
+var binInstallRe = regexp.MustCompile(`\bbin\.install`)
+
+// InstallToSbin installs the binaries into sbin instead of bin, for daemons
+// and admin tools, and points the test block at sbin. Install blocks that
+// do not use bin.install (e.g. std_go_args or cargo builds) cannot be
+// rewritten and return an error.
+func (f *FormulaData) InstallToSbin() error {
+	if !binInstallRe.MatchString(f.InstallBlock) {
+		return fmt.Errorf("install block does not use bin.install, so it cannot be moved to sbin")
+	}
+	f.InstallBlock = binInstallRe.ReplaceAllString(f.InstallBlock, "sbin.install")
+	f.InstallBlock = strings.ReplaceAll(f.InstallBlock, "generate_completions_from_executable(bin/", "generate_completions_from_executable(sbin/")
+	f.TestBlock = strings.ReplaceAll(f.TestBlock, "#{bin}/", "#{sbin}/")
+	return nil
+}
+
+// AddCompletions makes the install block generate shell completions by
+// running the installed binary's completion subcommand, e.g. "completion"
+// becomes generate_completions_from_executable(bin/"tool", "completion").
+// A subcommand of several words is passed as separate arguments. Source
+// builds get it from NewFormulaData instead; this is for formulas whose
+// install block does not render InstallOptions.Completions.
+func (f *FormulaData) AddCompletions(binary, subcommand string) error {
+	if strings.TrimSpace(subcommand) == "" {
+		return fmt.Errorf("completion subcommand is empty")
+	}
+	if strings.Contains(f.InstallBlock, "_completion.install") || strings.Contains(f.InstallBlock, "generate_completions_from_executable") {
+		return fmt.Errorf("install block already installs completions")
+	}
+	i := strings.LastIndex(f.InstallBlock, "\n  end")
+	if i < 0 {
+		return fmt.Errorf("install block has no end to add completions before")
+	}
+
+	dir := "bin"
+	if strings.Contains(f.InstallBlock, "sbin.install") {
+		dir = "sbin"
+	}
+	lines := (&buildsystem.Completions{Subcommand: subcommand}).InstallLines(dir, binary)
+	f.InstallBlock = f.InstallBlock[:i] + "\n" + strings.TrimSuffix(lines, "\n") + f.InstallBlock[i:]
+	return nil
+}
+
+// FindManPages returns the man pages in a recursive repository listing:
+// files in a man directory (or a section directory below it, like
+// man/man1) with a section suffix from .1 to .8
+func FindManPages(files []string) []string {
+	var pages []string
+	for _, f := range files {
+		dirs := strings.Split(path.Dir(f), "/")
+		if manSection(f) == "" || !slices.Contains(dirs, "man") ||
+			slices.Contains(dirs, "vendor") || slices.Contains(dirs, "testdata") {
+			continue
+		}
+		pages = append(pages, f)
+	}
+	return pages
+}
+
+// manSection returns the section digit of a man page file name such as
+// "tool.1", or "" if it has none
+func manSection(f string) string {
+	ext := path.Ext(f)
+	if len(ext) != 2 || ext[1] < '1' || ext[1] > '8' {
+		return ""
+	}
+	return ext[1:]
+}
+
+// addManPages appends a manN.install line for each page to the end of an
+// install block, after the build commands
+func addManPages(installBlock string, pages []string) string {
+	i := strings.LastIndex(installBlock, "\n  end")
+	if i < 0 || len(pages) == 0 {
+		return installBlock
+	}
+
+	var b strings.Builder
+	for _, page := range pages {
+		fmt.Fprintf(&b, "\n    man%s.install %q", manSection(page), page)
+	}
+	return installBlock[:i] + b.String() + installBlock[i:]
+}
+
+// appendCaveat adds text to caveat lines, separated from earlier text by a
+// blank line. The lines end up in a Ruby <<~EOS heredoc, so backslashes and
+// interpolation are escaped, and a line reading EOS, which would end the
+// heredoc early, is an error.
+func appendCaveat(lines []string, text string) ([]string, error) {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return lines, nil
+	}
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "EOS" {
+			return nil, fmt.Errorf("caveat line %q would end the caveats heredoc", line)
+		}
+		lines = append(lines, heredocEscaper.Replace(strings.TrimRight(line, " \t")))
+	}
+	return lines, nil
+}
+
+// LicenseCannotRepresent is Homebrew's license symbol for custom licenses
+// that have no SPDX identifier
+const LicenseCannotRepresent = ":cannot_represent"
+
+// rubyLicense renders a license value for the formula license stanza
+// Symbols such as :cannot_represent are emitted bare, SPDX IDs are quoted
+func rubyLicense(license string) string {
+	if strings.HasPrefix(license, ":") {
+		return license
+	}
+	return fmt.Sprintf("%q", license)
+}
+
 // PackageNameToClassName converts a package name to a Ruby class name
+// Names that would start with a digit are prefixed with "X" since Ruby
+// constants must start with a letter.
 // Examples:
 //   - "jq" -> "Jq"
 //   - "ripgrep" -> "Ripgrep"
 //   - "go-task" -> "GoTask"
 //   - "node_exporter" -> "NodeExporter"
+//   - "2fa" -> "X2fa"
 func PackageNameToClassName(name string) string {
 	// Replace hyphens and underscores with spaces for splitting
 	name = strings.ReplaceAll(name, "-", " ")
@@ -102,11 +347,39 @@ func PackageNameToClassName(name string) string {
 	}
 
 	// Join without spaces
-	return strings.Join(words, "")
+	className := strings.Join(words, "")
+
+	// Ruby constants cannot start with a digit
+	if className != "" && className[0] >= '0' && className[0] <= '9' {
+		className = "X" + className
+	}
+
+	return className
+}
+
+// ValidateClassName checks that name is a legal Ruby constant for a formula class
+// It must start with an uppercase letter and contain only letters and digits.
+func ValidateClassName(name string) error {
+	if name == "" {
+		return fmt.Errorf("class name cannot be empty")
+	}
+	if name[0] < 'A' || name[0] > 'Z' {
+		return fmt.Errorf("class name %q must start with an uppercase letter", name)
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') {
+			return fmt.Errorf("class name %q must contain only letters and digits", name)
+		}
+	}
+	return nil
 }
 
 // NewFormulaData creates FormulaData with automatic build system detection
-func NewFormulaData(packageName, version, sha256, url, description, homepage, license string, repoFiles []string, binaryName string) (*FormulaData, error) {
+// sources holds the contents of repository files the build system can use
+// to refine the install block, keyed by path (see SourcesToFetch); it may
+// be nil. completionSubcommand, if set, generates shell completions by
+// running the installed binary (see Completions.Subcommand).
+func NewFormulaData(packageName, version, sha256, url, description, homepage, license string, repoFiles []string, sources map[string]string, binaryName, completionSubcommand string) (*FormulaData, error) {
 	// Detect build system
 	bs := buildsystem.Detect(repoFiles)
 	if bs == nil {
@@ -118,7 +391,27 @@ func NewFormulaData(packageName, version, sha256, url, description, homepage, li
 		BinaryName: binaryName,
 		Prefix:     "#{prefix}",
 	}
-	installBlock := bs.GenerateInstallBlock(installOpts)
+	if bs.Name() == "Go" {
+		if commands := buildsystem.FindGoCommands(repoFiles); len(commands) > 1 {
+			installOpts.Commands = commands
+		} else {
+			installOpts.MainPackage = buildsystem.FindGoMainPackage(repoFiles, binaryName)
+		}
+	}
+	installOpts.Completions = buildsystem.DetectCompletions(repoFiles, binaryName)
+	if completionSubcommand != "" {
+		if installOpts.Completions == nil {
+			installOpts.Completions = &buildsystem.Completions{}
+		}
+		installOpts.Completions.Subcommand = completionSubcommand
+	}
+	if bs.Name() == "Makefile" {
+		installOpts.Makefile = sources[buildsystem.FindMakefile(repoFiles)]
+	}
+	if g, ok := bs.(*buildsystem.GoBuildSystem); ok {
+		g.CGO = buildsystem.UsesCGO(sources)
+	}
+	installBlock := addManPages(bs.GenerateInstallBlock(installOpts), FindManPages(repoFiles))
 
 	// Generate test block
 	testBlock := bs.GenerateTestBlock(binaryName)
@@ -132,8 +425,13 @@ func NewFormulaData(packageName, version, sha256, url, description, homepage, li
 		buildDeps = append(buildDeps, dep)
 	}
 
-	return &FormulaData{
-		ClassName:    PackageNameToClassName(packageName),
+	className := PackageNameToClassName(packageName)
+	if err := ValidateClassName(className); err != nil {
+		return nil, fmt.Errorf("package name %q does not produce a valid class name: %w", packageName, err)
+	}
+
+	data := &FormulaData{
+		ClassName:    className,
 		PackageName:  packageName,
 		Version:      version,
 		SHA256:       sha256,
@@ -145,22 +443,58 @@ func NewFormulaData(packageName, version, sha256, url, description, homepage, li
 		Dependencies: buildDeps,
 		InstallBlock: installBlock,
 		TestBlock:    testBlock,
-	}, nil
+	}
+
+	// Only Go and Rust install blocks render InstallOptions.Completions
+	if completionSubcommand != "" && !strings.Contains(installBlock, "generate_completions_from_executable") {
+		if err := data.AddCompletions(binaryName, completionSubcommand); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// SourcesToFetch returns the repository files whose contents NewFormulaData
+// uses for the detected build system: the Makefile to check for an install
+// target, or Go files to check for cgo
+func SourcesToFetch(bs buildsystem.BuildSystem, repoFiles []string) []string {
+	switch bs.Name() {
+	case "Makefile":
+		return []string{buildsystem.FindMakefile(repoFiles)}
+	case "Go":
+		return buildsystem.GoSourcesToScan(repoFiles)
+	}
+	return nil
+}
+
+// binaryInstallBlock installs the file at path in the extracted archive into
+// bin as name
+func binaryInstallBlock(path, name string) string {
+	target := fmt.Sprintf(`"%s"`, path)
+	if filepath.Base(path) != name {
+		target += fmt.Sprintf(` => "%s"`, name)
+	}
+	return fmt.Sprintf(`def install
+    bin.install %s
+  end`, target)
 }
 
 // NewFormulaDataSimple creates FormulaData for simple binary-only packages
 // (no build system, just extract and install)
-func NewFormulaDataSimple(packageName, version, sha256, url, description, homepage, license, binaryName string) *FormulaData {
-	installBlock := fmt.Sprintf(`def install
-    bin.install "%s"
-  end`, binaryName)
+func NewFormulaDataSimple(packageName, version, sha256, url, description, homepage, license, binaryName string) (*FormulaData, error) {
+	className := PackageNameToClassName(packageName)
+	if err := ValidateClassName(className); err != nil {
+		return nil, fmt.Errorf("package name %q does not produce a valid class name: %w", packageName, err)
+	}
+
+	installBlock := binaryInstallBlock(binaryName, binaryName)
 
 	testBlock := fmt.Sprintf(`test do
     system "#{bin}/%s", "--version"
   end`, binaryName)
 
 	return &FormulaData{
-		ClassName:    PackageNameToClassName(packageName),
+		ClassName:    className,
 		PackageName:  packageName,
 		Version:      version,
 		SHA256:       sha256,
@@ -172,5 +506,152 @@ func NewFormulaDataSimple(packageName, version, sha256, url, description, homepa
 		Dependencies: []string{},
 		InstallBlock: installBlock,
 		TestBlock:    testBlock,
+	}, nil
+}
+
+// NewFormulaDataGoInstall creates FormulaData that builds a Go tool with
+// go install <modulePath>@<version> instead of building the source tarball,
+// where version is tag without a submodule prefix (see GoInstallVersion)
+func NewFormulaDataGoInstall(packageName, version, sha256, url, description, homepage, license, modulePath, tag, binaryName string) (*FormulaData, error) {
+	data, err := NewFormulaDataSimple(packageName, version, sha256, url, description, homepage, license, binaryName)
+	if err != nil {
+		return nil, err
+	}
+
+	data.BuildSystem = "Go"
+	data.Dependencies = []string{"go"}
+	data.InstallBlock = fmt.Sprintf(`def install
+    ENV["GOPATH"] = buildpath/"gopath"
+    system "go", "install", "%s@%s"
+    bin.install buildpath/"gopath/bin/%s"
+  end`, modulePath, GoInstallVersion(tag), binaryName)
+
+	return data, nil
+}
+
+// GoInstallVersion returns the version go install expects for a release
+// tag: tags of a module in a subdirectory carry its path as a prefix, e.g.
+// "gopls/v0.16.0", but go install wants "gopls@v0.16.0"
+func GoInstallVersion(tag string) string {
+	return tag[strings.LastIndex(tag, "/")+1:]
+}
+
+// majorVersionRe matches a module path's major version suffix, e.g. "v2"
+var majorVersionRe = regexp.MustCompile(`^v[0-9]+$`)
+
+// GoInstallBinaryName returns the binary go install builds for a module
+// path: its last element, skipping a major version suffix such as /v2
+func GoInstallBinaryName(modulePath string) string {
+	name := path.Base(modulePath)
+	if majorVersionRe.MatchString(name) {
+		name = path.Base(path.Dir(modulePath))
 	}
+	return name
+}
+
+// LibexecInstallBlock returns an install block that keeps the extracted
+// tree together in libexec and symlinks the main binary into bin
+// Used for apps with bundled runtimes that break when split up
+func LibexecInstallBlock(binaryPath, binaryName string) string {
+	symlink := fmt.Sprintf(`libexec/"%s"`, binaryPath)
+	if filepath.Base(binaryPath) != binaryName {
+		symlink += fmt.Sprintf(` => "%s"`, binaryName)
+	}
+
+	return fmt.Sprintf(`def install
+    libexec.install Dir["*"]
+    bin.install_symlink %s
+  end`, symlink)
+}
+
+var (
+	// testBinaryRe matches a binary run from the test block, e.g. "#{bin}/rg"
+	testBinaryRe = regexp.MustCompile(`#\{s?bin\}/([A-Za-z0-9._+-]+)`)
+
+	// installedBinaryRe matches bin.install and bin.install_symlink of a quoted
+	// path, with an optional "=> name" rename
+	installedBinaryRe = regexp.MustCompile(`bin\.install(?:_symlink)?\s+[^"\n]*"([^"]+)"(?:\s*=>\s*"([^"]+)")?`)
+
+	// globInstallRe matches installs of a glob, e.g. Dir["#{libexec}/bin/*"],
+	// whose binary names are only known at install time
+	globInstallRe = regexp.MustCompile(`bin\.install(?:_symlink)?\s+Dir\[`)
+
+	// goOutputRe matches the binary named by std_go_args(output: bin/"name")
+	goOutputRe = regexp.MustCompile(`output:\s*s?bin/"([^"]+)"`)
+)
+
+// CheckTestBinary verifies the binary run by the test block is one the
+// install block actually installs. It returns nil when either side cannot
+// be determined (e.g. cargo or cmake installs, or globs).
+func CheckTestBinary(data *FormulaData) error {
+	matches := testBinaryRe.FindStringSubmatch(data.TestBlock)
+	if len(matches) < 2 {
+		return nil
+	}
+	tested := matches[1]
+
+	installed := InstalledBinaries(data.InstallBlock, data.PackageName)
+	if len(installed) == 0 {
+		return nil
+	}
+
+	for _, name := range installed {
+		if name == tested {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("test block runs %q but install block installs %s", tested, strings.Join(installed, ", "))
+}
+
+// InstalledBinaries returns the names an install block places in bin or sbin,
+// or nil when they cannot be known (glob installs). std_go_args builds a
+// binary named after the formula (packageName) unless given an output.
+func InstalledBinaries(installBlock, packageName string) []string {
+	if globInstallRe.MatchString(installBlock) {
+		return nil
+	}
+
+	var names []string
+	for _, m := range installedBinaryRe.FindAllStringSubmatch(installBlock, -1) {
+		if m[2] != "" {
+			names = append(names, m[2])
+		} else {
+			names = append(names, filepath.Base(m[1]))
+		}
+	}
+
+	for _, line := range strings.Split(installBlock, "\n") {
+		if !strings.Contains(line, "std_go_args") {
+			continue
+		}
+		if m := goOutputRe.FindStringSubmatch(line); m != nil {
+			names = append(names, m[1])
+		} else {
+			names = append(names, packageName)
+		}
+	}
+
+	return names
+}
+
+// ExtractFormulaBinary returns the binary name exercised by a formula file.
+// It prefers the binary referenced from the test block ("#{bin}/<name>" or
+// "#{sbin}/<name>"), then falls back to the name the first bin.install
+// entry installs as.
+func ExtractFormulaBinary(content string) (string, error) {
+	if idx := strings.Index(content, "test do"); idx != -1 {
+		if matches := testBinaryRe.FindStringSubmatch(content[idx:]); len(matches) > 1 {
+			return matches[1], nil
+		}
+	}
+
+	if matches := installedBinaryRe.FindStringSubmatch(content); matches != nil {
+		if matches[2] != "" {
+			return matches[2], nil
+		}
+		return filepath.Base(matches[1]), nil
+	}
+
+	return "", fmt.Errorf("could not find binary name in formula")
 }
@@ -39,7 +39,8 @@ func BenchmarkNewFormulaData(b *testing.B) {
 			"https://github.com/BurntSushi/ripgrep",
 			"Unlicense",
 			repoFiles,
-			"rg")
+			nil,
+			"rg", "")
 		if err != nil {
 			b.Fatal(err)
 		}
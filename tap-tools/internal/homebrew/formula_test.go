@@ -1,8 +1,11 @@
 package homebrew
 
 import (
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/castrojo/tap-tools/internal/buildsystem"
 )
 
 func TestPackageNameToClassName(t *testing.T) {
@@ -46,6 +49,21 @@ func TestPackageNameToClassName(t *testing.T) {
 			input:    "MyApp",
 			expected: "MyApp",
 		},
+		{
+			name:     "Leading digit",
+			input:    "2fa",
+			expected: "X2fa",
+		},
+		{
+			name:     "Leading digit with hyphen",
+			input:    "3d-tool",
+			expected: "X3dTool",
+		},
+		{
+			name:     "Only separators",
+			input:    "-_-",
+			expected: "",
+		},
 	}
 
 	for _, tt := range tests {
@@ -58,6 +76,49 @@ func TestPackageNameToClassName(t *testing.T) {
 	}
 }
 
+func TestValidateClassName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"Simple", "Ripgrep", false},
+		{"Mixed case", "GoTask", false},
+		{"With digits", "Tool2fa", false},
+		{"Empty", "", true},
+		{"Lowercase start", "ripgrep", true},
+		{"Digit start", "2fa", true},
+		{"Hyphen", "Go-Task", true},
+		{"Underscore", "Go_Task", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateClassName(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateClassName(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateFormulaClassNameOverride(t *testing.T) {
+	data, err := NewFormulaDataSimple("yq", "4.40.5", "abc123", "https://example.com/yq.tar.gz",
+		"YAML processor", "https://example.com", "MIT", "yq")
+	if err != nil {
+		t.Fatalf("Failed to create formula data: %v", err)
+	}
+	data.ClassName = "YQ"
+
+	result, err := GenerateFormula(data)
+	if err != nil {
+		t.Fatalf("Failed to generate formula: %v", err)
+	}
+	if !strings.Contains(result, "class YQ < Formula") {
+		t.Errorf("Formula should use overridden class name. Got:\n%s", result)
+	}
+}
+
 func TestGenerateFormula(t *testing.T) {
 	t.Run("Simple formula", func(t *testing.T) {
 		data := &FormulaData{
@@ -165,6 +226,369 @@ func TestGenerateFormula(t *testing.T) {
 	})
 }
 
+func TestGenerateFormulaCustomLicense(t *testing.T) {
+	data, err := NewFormulaDataSimple("tool", "1.0.0", "abc123", "https://example.com/tool.tar.gz",
+		"Tool with a custom license", "https://example.com", LicenseCannotRepresent, "tool")
+	if err != nil {
+		t.Fatalf("Failed to create formula data: %v", err)
+	}
+
+	result, err := GenerateFormula(data)
+	if err != nil {
+		t.Fatalf("Failed to generate formula: %v", err)
+	}
+	if !strings.Contains(result, "license :cannot_represent") {
+		t.Errorf("Formula should contain bare license symbol. Got:\n%s", result)
+	}
+}
+
+func TestGenerateFormulaMinGlibcCaveat(t *testing.T) {
+	data, err := NewFormulaDataSimple("tool", "1.0.0", "abc123", "https://example.com/tool.tar.gz",
+		"Prebuilt tool", "https://example.com", "MIT", "tool")
+	if err != nil {
+		t.Fatalf("Failed to create formula data: %v", err)
+	}
+
+	result, err := GenerateFormula(data)
+	if err != nil {
+		t.Fatalf("Failed to generate formula: %v", err)
+	}
+	if strings.Contains(result, "def caveats") {
+		t.Errorf("Formula without MinGlibc should not have caveats. Got:\n%s", result)
+	}
+
+	data.MinGlibc = "2.34"
+	result, err = GenerateFormula(data)
+	if err != nil {
+		t.Fatalf("Failed to generate formula: %v", err)
+	}
+	if !strings.Contains(result, "requires glibc 2.34 or newer") {
+		t.Errorf("Formula should contain glibc caveat. Got:\n%s", result)
+	}
+	if strings.Index(result, "def caveats") > strings.Index(result, "test do") {
+		t.Errorf("Caveats should come before the test block. Got:\n%s", result)
+	}
+}
+
+func TestGenerateFormulaCustomCaveats(t *testing.T) {
+	data, err := NewFormulaDataSimple("tool", "1.0.0", "abc123", "https://example.com/tool.tar.gz",
+		"Prebuilt tool", "https://example.com", "MIT", "tool")
+	if err != nil {
+		t.Fatalf("Failed to create formula data: %v", err)
+	}
+	data.AddCaveat("Run `tool --setup` first.")
+
+	result, err := GenerateFormula(data)
+	if err != nil {
+		t.Fatalf("Failed to generate formula: %v", err)
+	}
+	want := `  def caveats
+    <<~EOS
+      Run ` + "`tool --setup`" + ` first.
+    EOS
+  end`
+	if !strings.Contains(result, want) {
+		t.Errorf("Formula should contain custom caveat. Got:\n%s", result)
+	}
+
+	// Custom text follows the generated glibc caveat
+	data.MinGlibc = "2.34"
+	data.AddCaveat("Config lives in ~/.config/tool\n\nSee #{docs}")
+	result, err = GenerateFormula(data)
+	if err != nil {
+		t.Fatalf("Failed to generate formula: %v", err)
+	}
+	want = `      Check your system version with: ldd --version
+      Run ` + "`tool --setup`" + ` first.
+
+      Config lives in ~/.config/tool
+
+      See \#{docs}
+    EOS`
+	if !strings.Contains(result, want) {
+		t.Errorf("Formula should append custom caveats after the glibc caveat. Got:\n%s", result)
+	}
+	if strings.Count(result, "def caveats") != 1 {
+		t.Errorf("Formula should have a single caveats block. Got:\n%s", result)
+	}
+}
+
+func TestAppendCaveat(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []string
+		wantErr bool
+	}{
+		{"Plain text", "Run tool --setup", []string{"Run tool --setup"}, false},
+		{"Interpolation", `Uses #{HOME}, #@var and #$PATH`, []string{`Uses \#{HOME}, \#@var and \#$PATH`}, false},
+		{"Backslash and quotes", `C:\dir "quoted"`, []string{`C:\\dir "quoted"`}, false},
+		{"EOS inside a line", "EOS marks the end", []string{"EOS marks the end"}, false},
+		{"Heredoc terminator", "Done\n  EOS", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := appendCaveat(nil, tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("appendCaveat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("appendCaveat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateFormulaArchAssets(t *testing.T) {
+	intel := &ArchAsset{URL: "https://example.com/tool-x86_64.tar.gz", SHA256: "aaa111"}
+	arm := &ArchAsset{URL: "https://example.com/tool-aarch64.tar.gz", SHA256: "bbb222"}
+
+	tests := []struct {
+		name         string
+		intel, arm   *ArchAsset
+		wantContains []string
+		wantAbsent   []string
+	}{
+		{
+			name:  "Both architectures",
+			intel: intel,
+			arm:   arm,
+			wantContains: []string{
+				`version "1.0.0"`,
+				"on_arm do\n    url \"https://example.com/tool-aarch64.tar.gz\"\n    sha256 \"bbb222\"\n  end",
+				"on_intel do\n    url \"https://example.com/tool-x86_64.tar.gz\"\n    sha256 \"aaa111\"\n  end",
+			},
+			wantAbsent: []string{"  url \"https://example.com/tool.tar.gz\"", "depends_on arch:"},
+		},
+		{
+			name:         "Only x86_64",
+			intel:        intel,
+			wantContains: []string{`url "https://example.com/tool-x86_64.tar.gz"`, `sha256 "aaa111"`, "depends_on arch: :x86_64"},
+			wantAbsent:   []string{"on_intel", "on_arm"},
+		},
+		{
+			name:         "Only arm64",
+			arm:          arm,
+			wantContains: []string{`url "https://example.com/tool-aarch64.tar.gz"`, `sha256 "bbb222"`, "depends_on arch: :arm64"},
+			wantAbsent:   []string{"on_intel", "on_arm"},
+		},
+		{
+			name:         "No architectures keeps the single url",
+			wantContains: []string{`url "https://example.com/tool.tar.gz"`, `sha256 "abc123"`},
+			wantAbsent:   []string{"on_intel", "on_arm", "depends_on arch:"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewFormulaDataSimple("tool", "1.0.0", "abc123", "https://example.com/tool.tar.gz",
+				"A tool", "https://example.com", "MIT", "tool")
+			if err != nil {
+				t.Fatalf("NewFormulaDataSimple() error = %v", err)
+			}
+			data.SetArchAssets(tt.intel, tt.arm)
+
+			formula, err := GenerateFormula(data)
+			if err != nil {
+				t.Fatalf("GenerateFormula() error = %v", err)
+			}
+			for _, want := range tt.wantContains {
+				if !strings.Contains(formula, want) {
+					t.Errorf("Formula should contain %q:\n%s", want, formula)
+				}
+			}
+			for _, absent := range tt.wantAbsent {
+				if strings.Contains(formula, absent) {
+					t.Errorf("Formula should not contain %q:\n%s", absent, formula)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateFormulaResources(t *testing.T) {
+	data, err := NewFormulaDataSimple("tool", "1.0.0", "abc123", "https://example.com/tool-core-linux-amd64.tar.gz",
+		"A split tool", "https://example.com", "MIT", "tool")
+	if err != nil {
+		t.Fatalf("Failed to create formula data: %v", err)
+	}
+	data.AddResource("tool-plugins-linux-amd64", "https://example.com/tool-plugins-linux-amd64.tar.gz", "def456")
+
+	formula, err := GenerateFormula(data)
+	if err != nil {
+		t.Fatalf("GenerateFormula() error = %v", err)
+	}
+
+	want := `  resource "tool-plugins-linux-amd64" do
+    url "https://example.com/tool-plugins-linux-amd64.tar.gz"
+    sha256 "def456"
+  end
+
+  def install
+    bin.install "tool"
+    resource("tool-plugins-linux-amd64").stage(libexec/"tool-plugins-linux-amd64")
+  end`
+	if !strings.Contains(formula, want) {
+		t.Errorf("Formula missing resource and staging:\n%s", formula)
+	}
+}
+
+func TestGenerateFormulaHead(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		branch string
+		want   string
+	}{
+		{
+			name:   "default branch",
+			url:    "https://github.com/owner/tool.git",
+			branch: "main",
+			want:   "  license \"MIT\"\n  head \"https://github.com/owner/tool.git\", branch: \"main\"\n",
+		},
+		{
+			name:   "other branch",
+			url:    "https://gitlab.com/group/tool.git",
+			branch: "trunk",
+			want:   `  head "https://gitlab.com/group/tool.git", branch: "trunk"`,
+		},
+		{
+			name: "no branch",
+			url:  "https://github.com/owner/tool.git",
+			want: "  head \"https://github.com/owner/tool.git\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewFormulaDataSimple("tool", "1.0.0", "abc123", "https://example.com/tool.tar.gz",
+				"A tool", "https://example.com", "MIT", "tool")
+			if err != nil {
+				t.Fatalf("NewFormulaDataSimple() error = %v", err)
+			}
+			data.HeadURL = tt.url
+			data.HeadBranch = tt.branch
+
+			formula, err := GenerateFormula(data)
+			if err != nil {
+				t.Fatalf("GenerateFormula() error = %v", err)
+			}
+			if !strings.Contains(formula, tt.want) {
+				t.Errorf("Formula missing %q:\n%s", tt.want, formula)
+			}
+		})
+	}
+}
+
+func TestGenerateFormulaConflictsWith(t *testing.T) {
+	tests := []struct {
+		name      string
+		conflicts []string
+		because   string
+		want      string
+	}{
+		{
+			name:      "with reason",
+			conflicts: []string{"homebrew/core/tool"},
+			because:   "homebrew-core also has a tool formula",
+			want:      `  conflicts_with "homebrew/core/tool", because: "homebrew-core also has a tool formula"`,
+		},
+		{
+			name:      "several without reason",
+			conflicts: []string{"tool-a", "tool-b"},
+			want:      `  conflicts_with "tool-a", "tool-b"` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewFormulaDataSimple("tool", "1.0.0", "abc123", "https://example.com/tool.tar.gz",
+				"A tool", "https://example.com", "MIT", "tool")
+			if err != nil {
+				t.Fatalf("NewFormulaDataSimple() error = %v", err)
+			}
+			data.ConflictsWith = tt.conflicts
+			data.ConflictsBecause = tt.because
+
+			formula, err := GenerateFormula(data)
+			if err != nil {
+				t.Fatalf("GenerateFormula() error = %v", err)
+			}
+			if !strings.Contains(formula, tt.want) {
+				t.Errorf("Formula missing %q:\n%s", tt.want, formula)
+			}
+		})
+	}
+
+	data, err := NewFormulaDataSimple("tool", "1.0.0", "abc123", "https://example.com/tool.tar.gz",
+		"A tool", "https://example.com", "MIT", "tool")
+	if err != nil {
+		t.Fatalf("NewFormulaDataSimple() error = %v", err)
+	}
+	formula, err := GenerateFormula(data)
+	if err != nil {
+		t.Fatalf("GenerateFormula() error = %v", err)
+	}
+	if strings.Contains(formula, "conflicts_with") {
+		t.Errorf("Formula without conflicts should not have conflicts_with:\n%s", formula)
+	}
+}
+
+func TestGenerateFormulaMagicComments(t *testing.T) {
+	data, err := NewFormulaDataSimple("tool", "1.0.0", "abc123", "https://example.com/tool.tar.gz",
+		"A tool", "https://example.com", "MIT", "tool")
+	if err != nil {
+		t.Fatalf("NewFormulaDataSimple() error = %v", err)
+	}
+
+	formula, err := GenerateFormula(data)
+	if err != nil {
+		t.Fatalf("GenerateFormula() error = %v", err)
+	}
+	if !strings.HasPrefix(formula, "# typed: strict\n# frozen_string_literal: true\n\n# tool") {
+		t.Errorf("Formula should start with magic comments by default:\n%s", formula)
+	}
+
+	data.NoMagicComments = true
+	formula, err = GenerateFormula(data)
+	if err != nil {
+		t.Fatalf("GenerateFormula() error = %v", err)
+	}
+	if strings.Contains(formula, "# typed:") || strings.Contains(formula, "frozen_string_literal") {
+		t.Errorf("Formula should omit magic comments:\n%s", formula)
+	}
+	if !strings.HasPrefix(formula, "# tool\nclass Tool < Formula") {
+		t.Errorf("Formula should start with the description comment:\n%s", formula)
+	}
+}
+
+func TestGenerateFormulaNotes(t *testing.T) {
+	data, err := NewFormulaDataSimple("tool", "1.0.0", "abc123", "https://example.com/tool.tar.gz",
+		"A tool", "https://example.com", "MIT", "tool")
+	if err != nil {
+		t.Fatalf("NewFormulaDataSimple() error = %v", err)
+	}
+	data.SourceURL = "https://github.com/example/tool"
+	data.Notes = "Built from source: the prebuilt binary needs glibc 2.38+\n"
+
+	formula, err := GenerateFormula(data)
+	if err != nil {
+		t.Fatalf("GenerateFormula() error = %v", err)
+	}
+
+	// Notes follow the header and precede the magic comments
+	want := "# Validation: Auto-validated with tap-validate --fix\n\n" +
+		"# Maintainer notes:\n#   Built from source: the prebuilt binary needs glibc 2.38+\n\n" +
+		"# typed: strict\n"
+	if !strings.Contains(formula, want) {
+		t.Errorf("Formula should render notes after the header:\n%s", formula)
+	}
+	if !strings.HasPrefix(formula, "# Generated by tap-formula") {
+		t.Errorf("Formula should still start with the header:\n%s", formula)
+	}
+}
+
 func TestNewFormulaData(t *testing.T) {
 	t.Run("Go project", func(t *testing.T) {
 		repoFiles := []string{
@@ -183,7 +607,9 @@ func TestNewFormulaData(t *testing.T) {
 			"https://example.com",
 			"MIT",
 			repoFiles,
+			nil,
 			"mytool",
+			"",
 		)
 
 		if err != nil {
@@ -227,7 +653,9 @@ func TestNewFormulaData(t *testing.T) {
 			"https://example.com",
 			"Apache-2.0",
 			repoFiles,
+			nil,
 			"rust-app",
+			"",
 		)
 
 		if err != nil {
@@ -263,7 +691,9 @@ func TestNewFormulaData(t *testing.T) {
 			"https://example.com",
 			"GPL-3.0",
 			repoFiles,
+			nil,
 			"cmake-tool",
+			"",
 		)
 
 		if err != nil {
@@ -294,7 +724,9 @@ func TestNewFormulaData(t *testing.T) {
 			"https://example.com",
 			"MIT",
 			repoFiles,
+			nil,
 			"unknown",
+			"",
 		)
 
 		if err == nil {
@@ -307,9 +739,174 @@ func TestNewFormulaData(t *testing.T) {
 	})
 }
 
+func TestGenerateFormulaToolchainOverride(t *testing.T) {
+	data, err := NewFormulaData("mytool", "1.0.0", "abc123", "https://example.com/mytool-1.0.0.tar.gz",
+		"My tool", "https://example.com", "MIT", []string{"main.go", "go.mod"}, nil, "mytool", "")
+	if err != nil {
+		t.Fatalf("NewFormulaData() error = %v", err)
+	}
+
+	data.Dependencies, err = buildsystem.ReplaceToolchain(data.Dependencies, "go@1.21")
+	if err != nil {
+		t.Fatalf("ReplaceToolchain() error = %v", err)
+	}
+
+	formula, err := GenerateFormula(data)
+	if err != nil {
+		t.Fatalf("GenerateFormula() error = %v", err)
+	}
+
+	if !strings.Contains(formula, `depends_on "go@1.21"`) {
+		t.Errorf("Formula missing pinned toolchain:\n%s", formula)
+	}
+	if strings.Contains(formula, `depends_on "go"`) {
+		t.Errorf("Formula still depends on the default toolchain:\n%s", formula)
+	}
+}
+
+func TestNewFormulaDataGoMonorepo(t *testing.T) {
+	repoFiles := []string{"go.mod", "go.sum", "cmd/foo/main.go", "cmd/bar/main.go", "cmd/lib/lib.go", "cmd/README.md", "internal/lib/lib.go"}
+	data, err := NewFormulaData("foo", "1.0.0", "abc123", "https://example.com/foo-1.0.0.tar.gz",
+		"A tool", "https://example.com", "MIT", repoFiles, nil, "foo", "")
+	if err != nil {
+		t.Fatalf("NewFormulaData() error = %v", err)
+	}
+
+	for _, want := range []string{
+		`system "go", "build", *std_go_args(output: bin/"bar"), "./cmd/bar"`,
+		`system "go", "build", *std_go_args(output: bin/"foo"), "./cmd/foo"`,
+	} {
+		if !strings.Contains(data.InstallBlock, want) {
+			t.Errorf("Install block should build every command, missing %q:\n%s", want, data.InstallBlock)
+		}
+	}
+	if strings.Contains(data.InstallBlock, "cmd/lib") || strings.Contains(data.InstallBlock, "Dir[") {
+		t.Errorf("Install block should only build the detected commands:\n%s", data.InstallBlock)
+	}
+}
+
+func TestNewFormulaDataCompletionSubcommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		repoFiles []string
+		sources   map[string]string
+	}{
+		{"Wins over completion files", []string{"Cargo.toml", "Cargo.lock", "src/main.rs", "completions/tool.bash"}, nil},
+		{"Build system without completions", []string{"Makefile", "main.c"}, map[string]string{"Makefile": "tool: main.c\n\tcc -o tool main.c\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewFormulaData("tool", "1.0.0", "abc123", "https://example.com/tool-1.0.0.tar.gz",
+				"A tool", "https://example.com", "MIT", tt.repoFiles, tt.sources, "tool", "completion")
+			if err != nil {
+				t.Fatalf("NewFormulaData() error = %v", err)
+			}
+			if !strings.Contains(data.InstallBlock, `generate_completions_from_executable(bin/"tool", "completion")`) {
+				t.Errorf("Install block should generate completions:\n%s", data.InstallBlock)
+			}
+			if strings.Contains(data.InstallBlock, "_completion.install") {
+				t.Errorf("Install block should not install completion files:\n%s", data.InstallBlock)
+			}
+		})
+	}
+}
+
+func TestNewFormulaDataMakefileSources(t *testing.T) {
+	repoFiles := []string{"Makefile", "main.c"}
+	bs := buildsystem.Detect(repoFiles)
+	if got := SourcesToFetch(bs, repoFiles); !reflect.DeepEqual(got, []string{"Makefile"}) {
+		t.Errorf("SourcesToFetch() = %v, want [Makefile]", got)
+	}
+
+	sources := map[string]string{"Makefile": "tool: main.c\n\tcc -o tool main.c\n"}
+	data, err := NewFormulaData("tool", "1.0.0", "abc123", "https://example.com/tool-1.0.0.tar.gz",
+		"A tool", "https://example.com", "MIT", repoFiles, sources, "tool", "")
+	if err != nil {
+		t.Fatalf("NewFormulaData() error = %v", err)
+	}
+	if !strings.Contains(data.InstallBlock, `bin.install "tool"`) || strings.Contains(data.InstallBlock, `"install"`) {
+		t.Errorf("Install block should fall back to bin.install without an install target:\n%s", data.InstallBlock)
+	}
+}
+
+func TestNewFormulaDataCGO(t *testing.T) {
+	repoFiles := []string{"go.mod", "main.go", "internal/db/sqlite.go"}
+	tests := []struct {
+		name    string
+		sources map[string]string
+		want    []string
+	}{
+		{"Pure Go", map[string]string{"main.go": "package main\n\nfunc main() {}\n"}, []string{"go"}},
+		{"Cgo", map[string]string{"internal/db/sqlite.go": "package db\n\n// #cgo LDFLAGS: -lsqlite3\nimport \"C\"\n"}, []string{"go", "pkg-config"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewFormulaData("tool", "1.0.0", "abc123", "https://example.com/tool-1.0.0.tar.gz",
+				"A tool", "https://example.com", "MIT", repoFiles, tt.sources, "tool", "")
+			if err != nil {
+				t.Fatalf("NewFormulaData() error = %v", err)
+			}
+			if !reflect.DeepEqual(data.Dependencies, tt.want) {
+				t.Errorf("Dependencies = %v, want %v", data.Dependencies, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFormulaDataManPages(t *testing.T) {
+	tests := []struct {
+		name      string
+		repoFiles []string
+		want      []string
+		absent    []string
+	}{
+		{
+			name:      "Section 1 page",
+			repoFiles: []string{"go.mod", "main.go", "man/tool.1"},
+			want:      []string{`man1.install "man/tool.1"`},
+		},
+		{
+			name:      "Several sections in section directories",
+			repoFiles: []string{"Makefile", "doc/man/man1/tool.1", "doc/man/man5/tool.conf.5"},
+			want:      []string{`man1.install "doc/man/man1/tool.1"`, `man5.install "doc/man/man5/tool.conf.5"`},
+		},
+		{
+			name:      "Not man pages",
+			repoFiles: []string{"go.mod", "main.go", "man/README.md", "docs/tool.1", "man/tool.9", "vendor/x/man/x.1"},
+			absent:    []string{"man"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewFormulaData("tool", "1.0.0", "abc123", "https://example.com/tool-1.0.0.tar.gz",
+				"A tool", "https://example.com", "MIT", tt.repoFiles, nil, "tool", "")
+			if err != nil {
+				t.Fatalf("NewFormulaData() error = %v", err)
+			}
+
+			for _, line := range tt.want {
+				if !strings.Contains(data.InstallBlock, "\n    "+line+"\n") {
+					t.Errorf("Install block missing %q:\n%s", line, data.InstallBlock)
+				}
+			}
+			for _, text := range tt.absent {
+				if strings.Contains(data.InstallBlock, text) {
+					t.Errorf("Install block should not contain %q:\n%s", text, data.InstallBlock)
+				}
+			}
+			if !strings.HasSuffix(data.InstallBlock, "\n  end") {
+				t.Errorf("Install block should end after the man pages:\n%s", data.InstallBlock)
+			}
+		})
+	}
+}
+
 func TestNewFormulaDataSimple(t *testing.T) {
 	t.Run("Simple binary formula", func(t *testing.T) {
-		data := NewFormulaDataSimple(
+		data, err := NewFormulaDataSimple(
 			"simple-tool",
 			"3.0.0",
 			"hash123",
@@ -319,6 +916,9 @@ func TestNewFormulaDataSimple(t *testing.T) {
 			"BSD-3-Clause",
 			"simple-tool",
 		)
+		if err != nil {
+			t.Fatalf("Failed to create formula data: %v", err)
+		}
 
 		if data.ClassName != "SimpleTool" {
 			t.Errorf("Expected class name 'SimpleTool', got %s", data.ClassName)
@@ -346,7 +946,7 @@ func TestNewFormulaDataSimple(t *testing.T) {
 	})
 
 	t.Run("Binary with different name", func(t *testing.T) {
-		data := NewFormulaDataSimple(
+		data, err := NewFormulaDataSimple(
 			"my-package",
 			"1.0.0",
 			"abc123",
@@ -356,6 +956,9 @@ func TestNewFormulaDataSimple(t *testing.T) {
 			"MIT",
 			"actual-binary-name",
 		)
+		if err != nil {
+			t.Fatalf("Failed to create formula data: %v", err)
+		}
 
 		if !strings.Contains(data.InstallBlock, "actual-binary-name") {
 			t.Error("Install block should use the provided binary name")
@@ -367,6 +970,40 @@ func TestNewFormulaDataSimple(t *testing.T) {
 	})
 }
 
+func TestNewFormulaDataClassNameValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		packageName string
+		wantClass   string
+		wantErr     bool
+	}{
+		{"Leading digit", "2fa", "X2fa", false},
+		{"Leading digit with hyphen", "3d-tool", "X3dTool", false},
+		{"All symbols", "@#$", "", true},
+		{"Empty class name", "--", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := NewFormulaDataSimple(tt.packageName, "1.0.0", "abc123",
+				"https://example.com/tool.tar.gz", "Tool", "https://example.com", "MIT", "tool")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewFormulaDataSimple() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && data.ClassName != tt.wantClass {
+				t.Errorf("Expected class name %s, got %s", tt.wantClass, data.ClassName)
+			}
+
+			_, err = NewFormulaData(tt.packageName, "1.0.0", "abc123",
+				"https://example.com/tool.tar.gz", "Tool", "https://example.com", "MIT",
+				[]string{"go.mod"}, nil, "tool", "")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewFormulaData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestGenerateFormulaIntegration(t *testing.T) {
 	t.Run("Full Go project formula", func(t *testing.T) {
 		repoFiles := []string{"main.go", "go.mod", "README.md"}
@@ -380,7 +1017,9 @@ func TestGenerateFormulaIntegration(t *testing.T) {
 			"https://github.com/BurntSushi/ripgrep",
 			"MIT",
 			repoFiles,
+			nil,
 			"rg",
+			"",
 		)
 
 		if err != nil {
@@ -423,3 +1062,381 @@ func TestGenerateFormulaIntegration(t *testing.T) {
 		}
 	})
 }
+
+func TestExtractFormulaBinary(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name: "Binary from test block",
+			content: `class Ripgrep < Formula
+  def install
+    bin.install "rg"
+  end
+
+  test do
+    system "#{bin}/rg", "--version"
+  end
+end`,
+			expected: "rg",
+		},
+		{
+			name: "Test block takes precedence over install",
+			content: `class Tool < Formula
+  def install
+    bin.install "build/tool-linux-amd64"
+  end
+
+  test do
+    assert_match version.to_s, shell_output("#{bin}/tool --version")
+  end
+end`,
+			expected: "tool",
+		},
+		{
+			name: "Fallback to bin.install",
+			content: `class Tool < Formula
+  def install
+    bin.install "dist/mytool"
+  end
+end`,
+			expected: "mytool",
+		},
+		{
+			name: "Fallback to a renamed bin.install",
+			content: `class Tool < Formula
+  def install
+    bin.install "tool-linux-amd64" => "tool"
+  end
+end`,
+			expected: "tool",
+		},
+		{
+			name:    "No binary",
+			content: "class Lib < Formula\nend",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExtractFormulaBinary(tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractFormulaBinary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestNewFormulaDataGoInstall(t *testing.T) {
+	data, err := NewFormulaDataGoInstall("gopls", "0.16.0", "abc123", "https://example.com/tools-0.16.0.tar.gz",
+		"Go language server", "https://example.com", "BSD-3-Clause",
+		"golang.org/x/tools/gopls", "gopls/v0.16.0", "gopls")
+	if err != nil {
+		t.Fatalf("NewFormulaDataGoInstall() error = %v", err)
+	}
+
+	if data.BuildSystem != "Go" {
+		t.Errorf("BuildSystem = %q, want Go", data.BuildSystem)
+	}
+	if len(data.Dependencies) != 1 || data.Dependencies[0] != "go" {
+		t.Errorf("Dependencies = %v, want [go]", data.Dependencies)
+	}
+	for _, want := range []string{
+		`ENV["GOPATH"] = buildpath/"gopath"`,
+		`system "go", "install", "golang.org/x/tools/gopls@v0.16.0"`,
+		`bin.install buildpath/"gopath/bin/gopls"`,
+	} {
+		if !strings.Contains(data.InstallBlock, want) {
+			t.Errorf("Install block should contain %q, got:\n%s", want, data.InstallBlock)
+		}
+	}
+	if err := CheckTestBinary(data); err != nil {
+		t.Errorf("CheckTestBinary() error = %v", err)
+	}
+}
+
+func TestGoInstallVersion(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"v1.2.3", "v1.2.3"},
+		{"gopls/v0.16.0", "v0.16.0"},
+		{"tools/cmd/tool/v2.0.0", "v2.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := GoInstallVersion(tt.tag); got != tt.want {
+				t.Errorf("GoInstallVersion(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoInstallBinaryName(t *testing.T) {
+	tests := []struct {
+		modulePath string
+		want       string
+	}{
+		{"github.com/junegunn/fzf", "fzf"},
+		{"golang.org/x/tools/gopls", "gopls"},
+		{"github.com/user/tool/cmd/tool-cli", "tool-cli"},
+		{"github.com/user/tool/v2", "tool"},
+		{"github.com/user/tool/v2/cmd/tool", "tool"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.modulePath, func(t *testing.T) {
+			if got := GoInstallBinaryName(tt.modulePath); got != tt.want {
+				t.Errorf("GoInstallBinaryName(%q) = %q, want %q", tt.modulePath, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLibexecInstallBlock(t *testing.T) {
+	tests := []struct {
+		name       string
+		binaryPath string
+		binaryName string
+		want       string
+	}{
+		{
+			name:       "Binary at root",
+			binaryPath: "obsidian",
+			binaryName: "obsidian",
+			want: `def install
+    libexec.install Dir["*"]
+    bin.install_symlink libexec/"obsidian"
+  end`,
+		},
+		{
+			name:       "Renamed binary",
+			binaryPath: "Signal",
+			binaryName: "signal",
+			want: `def install
+    libexec.install Dir["*"]
+    bin.install_symlink libexec/"Signal" => "signal"
+  end`,
+		},
+		{
+			name:       "Nested binary",
+			binaryPath: "app/tool",
+			binaryName: "tool",
+			want: `def install
+    libexec.install Dir["*"]
+    bin.install_symlink libexec/"app/tool"
+  end`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LibexecInstallBlock(tt.binaryPath, tt.binaryName); got != tt.want {
+				t.Errorf("LibexecInstallBlock() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstallToSbin(t *testing.T) {
+	t.Run("Binary formula", func(t *testing.T) {
+		data, err := NewFormulaDataSimple("mydaemon", "1.0.0", "abc123", "https://example.com/mydaemon-1.0.0.tar.gz",
+			"A daemon", "https://example.com", "MIT", "mydaemond")
+		if err != nil {
+			t.Fatalf("Failed to create formula data: %v", err)
+		}
+		if err := data.InstallToSbin(); err != nil {
+			t.Fatalf("InstallToSbin() error = %v", err)
+		}
+
+		formula, err := GenerateFormula(data)
+		if err != nil {
+			t.Fatalf("GenerateFormula() error = %v", err)
+		}
+		for _, want := range []string{`sbin.install "mydaemond"`, `system "#{sbin}/mydaemond", "--version"`} {
+			if !strings.Contains(formula, want) {
+				t.Errorf("Formula missing %q:\n%s", want, formula)
+			}
+		}
+		if strings.Contains(formula, "#{bin}/") || strings.Contains(formula, " bin.install") {
+			t.Errorf("Formula still installs into bin:\n%s", formula)
+		}
+		if err := CheckTestBinary(data); err != nil {
+			t.Errorf("CheckTestBinary() error = %v", err)
+		}
+	})
+
+	t.Run("Libexec symlink", func(t *testing.T) {
+		data := &FormulaData{InstallBlock: LibexecInstallBlock("app/bin/tool", "tool"), TestBlock: "test do\n    system \"#{bin}/tool\"\n  end"}
+		if err := data.InstallToSbin(); err != nil {
+			t.Fatalf("InstallToSbin() error = %v", err)
+		}
+		if !strings.Contains(data.InstallBlock, `sbin.install_symlink libexec/"app/bin/tool"`) {
+			t.Errorf("InstallBlock = %s, want an sbin symlink", data.InstallBlock)
+		}
+	})
+
+	t.Run("Source build", func(t *testing.T) {
+		data := &FormulaData{InstallBlock: "def install\n    system \"go\", \"build\", *std_go_args\n  end"}
+		if err := data.InstallToSbin(); err == nil {
+			t.Error("InstallToSbin() expected error for std_go_args")
+		}
+	})
+}
+
+func TestAddCompletions(t *testing.T) {
+	tests := []struct {
+		name       string
+		install    string
+		subcommand string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "Single subcommand",
+			install:    "def install\n    bin.install \"tool\"\n  end",
+			subcommand: "completion",
+			want:       "def install\n    bin.install \"tool\"\n    generate_completions_from_executable(bin/\"tool\", \"completion\")\n  end",
+		},
+		{
+			name:       "Subcommand with arguments",
+			install:    "def install\n    system \"go\", \"build\", *std_go_args\n  end",
+			subcommand: "gen completions",
+			want:       "def install\n    system \"go\", \"build\", *std_go_args\n    generate_completions_from_executable(bin/\"tool\", \"gen\", \"completions\")\n  end",
+		},
+		{
+			name:       "Installed into sbin",
+			install:    "def install\n    sbin.install \"tool\"\n  end",
+			subcommand: "completion",
+			want:       "def install\n    sbin.install \"tool\"\n    generate_completions_from_executable(sbin/\"tool\", \"completion\")\n  end",
+		},
+		{
+			name:       "Empty subcommand",
+			install:    "def install\n    bin.install \"tool\"\n  end",
+			subcommand: "  ",
+			wantErr:    true,
+		},
+		{
+			name:       "Completion files already installed",
+			install:    "def install\n    system \"cargo\", \"install\", *std_cargo_args\n    bash_completion.install \"completions/tool.bash\" => \"tool\"\n  end",
+			subcommand: "completion",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &FormulaData{InstallBlock: tt.install}
+			err := data.AddCompletions("tool", tt.subcommand)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AddCompletions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && data.InstallBlock != tt.want {
+				t.Errorf("InstallBlock = %q, want %q", data.InstallBlock, tt.want)
+			}
+		})
+	}
+
+	// The call renders inside the generated install method
+	data, err := NewFormulaDataSimple("tool", "1.0.0", "abc123", "https://example.com/tool-1.0.0.tar.gz",
+		"A tool", "https://example.com", "MIT", "tool")
+	if err != nil {
+		t.Fatalf("NewFormulaDataSimple() error = %v", err)
+	}
+	if err := data.AddCompletions("tool", "completion"); err != nil {
+		t.Fatalf("AddCompletions() error = %v", err)
+	}
+	formula, err := GenerateFormula(data)
+	if err != nil {
+		t.Fatalf("GenerateFormula() error = %v", err)
+	}
+	if !strings.Contains(formula, "    bin.install \"tool\"\n    generate_completions_from_executable(bin/\"tool\", \"completion\")\n  end\n") {
+		t.Errorf("Formula should generate completions in the install method:\n%s", formula)
+	}
+}
+
+func TestCheckTestBinary(t *testing.T) {
+	tests := []struct {
+		name         string
+		installBlock string
+		testBlock    string
+		wantErr      bool
+	}{
+		{
+			name:         "Matching bin.install",
+			installBlock: "def install\n    bin.install \"rg\"\n  end",
+			testBlock:    "test do\n    system \"#{bin}/rg\", \"--version\"\n  end",
+		},
+		{
+			name:         "Mismatching bin.install",
+			installBlock: "def install\n    bin.install \"rg\"\n  end",
+			testBlock:    "test do\n    system \"#{bin}/ripgrep\", \"--version\"\n  end",
+			wantErr:      true,
+		},
+		{
+			name:         "Renamed on install",
+			installBlock: "def install\n    bin.install \"tool-linux-amd64\" => \"tool\"\n  end",
+			testBlock:    "test do\n    system \"#{bin}/tool\", \"--version\"\n  end",
+		},
+		{
+			name:         "Libexec symlink",
+			installBlock: "def install\n    libexec.install Dir[\"*\"]\n    bin.install_symlink libexec/\"Signal\" => \"signal\"\n  end",
+			testBlock:    "test do\n    system \"#{bin}/signal\", \"--version\"\n  end",
+		},
+		{
+			name:         "Matching sbin.install",
+			installBlock: "def install\n    sbin.install \"daemon\"\n  end",
+			testBlock:    "test do\n    system \"#{sbin}/daemon\", \"--version\"\n  end",
+		},
+		{
+			name:         "Go build uses formula name",
+			installBlock: "def install\n    system \"go\", \"build\", *std_go_args\n  end",
+			testBlock:    "test do\n    system \"#{bin}/other\", \"--version\"\n  end",
+			wantErr:      true,
+		},
+		{
+			name:         "Go monorepo output",
+			installBlock: (&buildsystem.GoBuildSystem{}).GenerateInstallBlock(buildsystem.InstallOptions{Commands: []string{"cmd/tool", "cmd/tool-server"}}),
+			testBlock:    "test do\n    system \"#{bin}/tool-server\", \"--version\"\n  end",
+		},
+		{
+			name:         "Go monorepo output mismatch",
+			installBlock: (&buildsystem.GoBuildSystem{}).GenerateInstallBlock(buildsystem.InstallOptions{Commands: []string{"cmd/tool"}}),
+			testBlock:    "test do\n    system \"#{bin}/mytool\", \"--version\"\n  end",
+			wantErr:      true,
+		},
+		{
+			name:         "Node glob symlink cannot be checked",
+			installBlock: (&buildsystem.NodeBuildSystem{}).GenerateInstallBlock(buildsystem.InstallOptions{}),
+			testBlock:    "test do\n    system \"#{bin}/anything\", \"--version\"\n  end",
+		},
+		{
+			name:         "Cargo install cannot be checked",
+			installBlock: "def install\n    system \"cargo\", \"install\", *std_cargo_args\n  end",
+			testBlock:    "test do\n    system \"#{bin}/anything\", \"--version\"\n  end",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := &FormulaData{
+				PackageName:  "mytool",
+				InstallBlock: tt.installBlock,
+				TestBlock:    tt.testBlock,
+			}
+			err := CheckTestBinary(data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckTestBinary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
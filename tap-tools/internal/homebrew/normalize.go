@@ -0,0 +1,54 @@
+package homebrew
+
+import (
+	"strings"
+)
+
+// StripMacOSStanzas removes macOS-specific stanzas from formula content
+// This tap is Linux-only, so uses_from_macos, on_macos blocks, and
+// depends_on :macos are dead weight when adapting formulas from homebrew-core
+func StripMacOSStanzas(content string) string {
+	lines := strings.Split(content, "\n")
+	out := make([]string, 0, len(lines))
+
+	// Indentation of the on_macos block being skipped, or -1 when not in one
+	skipIndent := -1
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+
+		if skipIndent >= 0 {
+			if trimmed == "end" && indent == skipIndent {
+				skipIndent = -1
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "on_macos do"):
+			skipIndent = indent
+			continue
+		case strings.HasPrefix(trimmed, "uses_from_macos "),
+			strings.HasPrefix(trimmed, "depends_on :macos"),
+			strings.HasPrefix(trimmed, "depends_on macos:"):
+			continue
+		}
+
+		// Tidy blank lines left behind by removed stanzas: no doubled blank
+		// lines, none right after a block opens, none right before it closes
+		if len(out) > 0 {
+			prev := strings.TrimSpace(out[len(out)-1])
+			if trimmed == "" && (prev == "" || strings.HasSuffix(prev, " do") || strings.HasPrefix(prev, "class ")) {
+				continue
+			}
+			if trimmed == "end" && prev == "" {
+				out = out[:len(out)-1]
+			}
+		}
+
+		out = append(out, line)
+	}
+
+	return strings.Join(out, "\n")
+}
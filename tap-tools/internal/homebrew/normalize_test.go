@@ -0,0 +1,94 @@
+package homebrew
+
+import (
+	"testing"
+)
+
+func TestStripMacOSStanzas(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name: "uses_from_macos",
+			content: `class Tool < Formula
+  depends_on "pkg-config" => :build
+  uses_from_macos "zlib"
+  uses_from_macos "curl", since: :catalina
+end`,
+			want: `class Tool < Formula
+  depends_on "pkg-config" => :build
+end`,
+		},
+		{
+			name: "on_macos block with nested block",
+			content: `class Tool < Formula
+  on_macos do
+    depends_on "gettext"
+    if Hardware::CPU.arm?
+      url "https://example.com/arm.tar.gz"
+    end
+  end
+
+  on_linux do
+    depends_on "glibc"
+  end
+end`,
+			want: `class Tool < Formula
+  on_linux do
+    depends_on "glibc"
+  end
+end`,
+		},
+		{
+			name: "Trailing on_macos block",
+			content: `class Tool < Formula
+  depends_on "go" => :build
+
+  on_macos do
+    depends_on "gettext"
+  end
+end`,
+			want: `class Tool < Formula
+  depends_on "go" => :build
+end`,
+		},
+		{
+			name: "depends_on macos",
+			content: `class Tool < Formula
+  depends_on :macos
+  depends_on macos: :monterey
+  depends_on "openssl@3"
+end`,
+			want: `class Tool < Formula
+  depends_on "openssl@3"
+end`,
+		},
+		{
+			name: "Linux formula unchanged",
+			content: `class Tool < Formula
+  depends_on "go" => :build
+
+  def install
+    system "go", "build", *std_go_args
+  end
+end`,
+			want: `class Tool < Formula
+  depends_on "go" => :build
+
+  def install
+    system "go", "build", *std_go_args
+  end
+end`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripMacOSStanzas(tt.content); got != tt.want {
+				t.Errorf("StripMacOSStanzas() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
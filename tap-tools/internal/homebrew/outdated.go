@@ -0,0 +1,128 @@
+package homebrew
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/castrojo/tap-tools/internal/github"
+	"github.com/castrojo/tap-tools/internal/version"
+)
+
+// LatestVersionFunc returns the latest upstream version of a repository
+type LatestVersionFunc func(host, owner, repo string) (string, error)
+
+// PackageStatus is the result of checking one formula or cask for a newer
+// upstream version
+type PackageStatus struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"` // "formula" or "cask"
+	Path     string `json:"path"`
+	Repo     string `json:"repo,omitempty"`
+	Current  string `json:"current,omitempty"`
+	Latest   string `json:"latest,omitempty"`
+	Outdated bool   `json:"outdated"`
+	Error    string `json:"error,omitempty"`
+}
+
+// IsOutdated reports whether latest is a newer version than current; a
+// "v" prefix on either side is ignored
+func IsOutdated(current, latest string) bool {
+	return version.Compare(latest, current) > 0
+}
+
+// CheckOutdated reads the version and repository of each formula and cask
+// in files and asks latest for the upstream version, using at most workers
+// concurrent lookups so large taps stay under API rate limits. Results keep
+// the order of files; a package that cannot be checked has Error set.
+func CheckOutdated(files []string, workers int, latest LatestVersionFunc) []*PackageStatus {
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make([]*PackageStatus, len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = checkPackage(files[i], latest)
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// checkPackage compares one formula or cask with its latest upstream version
+func checkPackage(path string, latest LatestVersionFunc) *PackageStatus {
+	status := &PackageStatus{
+		Name: strings.TrimSuffix(filepath.Base(path), ".rb"),
+		Kind: "formula",
+		Path: path,
+	}
+	if filepath.Base(filepath.Dir(path)) == "Casks" {
+		status.Kind = "cask"
+	}
+
+	repoURL, err := packageSource(path, status)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	host, owner, repo, err := github.ParseRepoURL(repoURL)
+	if err != nil {
+		status.Error = fmt.Sprintf("cannot find the repository: %v", err)
+		return status
+	}
+	status.Repo = owner + "/" + repo
+
+	upstream, err := latest(host, owner, repo)
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.Latest = upstream
+	status.Outdated = IsOutdated(status.Current, upstream)
+	return status
+}
+
+// packageSource sets the current version of status from the package file and
+// returns its repository URL, from the generation header or the download URL
+func packageSource(path string, status *PackageStatus) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if status.Kind == "cask" {
+		src, err := ParseCaskSource(string(content))
+		if err != nil {
+			return "", err
+		}
+		status.Current = src.Version
+		if src.RepoURL != "" {
+			return src.RepoURL, nil
+		}
+		return ExpandVersion(src.URL, src.Version), nil
+	}
+
+	src, err := ParseFormulaSource(string(content))
+	if err != nil {
+		return "", err
+	}
+	status.Current = src.Version
+	if src.RepoURL != "" || len(src.URLs) == 0 {
+		return src.RepoURL, nil
+	}
+	return src.URLs[0], nil
+}
@@ -0,0 +1,104 @@
+package homebrew
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIsOutdated(t *testing.T) {
+	tests := []struct {
+		current string
+		latest  string
+		want    bool
+	}{
+		{"1.2.0", "1.3.0", true},
+		{"1.9.0", "1.10.0", true},
+		{"1.10.0", "1.9.0", false},
+		{"1.2.0", "1.2.0", false},
+		{"v1.2.0", "1.2.0", false},
+		{"1.2.0", "v1.2.1", true},
+		{"1.2", "1.2.0", false},
+		{"1.2", "1.2.1", true},
+		{"2.0.0", "1.99.99", false},
+		{"4169", "4200", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current+"->"+tt.latest, func(t *testing.T) {
+			if got := IsOutdated(tt.current, tt.latest); got != tt.want {
+				t.Errorf("IsOutdated(%q, %q) = %v, want %v", tt.current, tt.latest, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckOutdated(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"Formula", "Casks"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := []string{
+		filepath.Join(root, "Formula", "tool.rb"),
+		filepath.Join(root, "Casks", "tool-linux.rb"),
+		filepath.Join(root, "Formula", "current.rb"),
+		filepath.Join(root, "Formula", "broken.rb"),
+	}
+	contents := []string{
+		bumpSampleFormula,
+		bumpSampleCask,
+		`class Current < Formula
+  url "https://github.com/example/current/releases/download/v2.0.0/current-linux.tar.gz"
+end
+`,
+		"class Broken < Formula\nend\n",
+	}
+	for i, file := range files {
+		if err := os.WriteFile(file, []byte(contents[i]), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var calls atomic.Int32
+	latest := func(host, owner, repo string) (string, error) {
+		calls.Add(1)
+		switch repo {
+		case "tool":
+			return "v1.3.0", nil
+		case "current":
+			return "v2.0.0", nil
+		}
+		return "", fmt.Errorf("unexpected repository %s/%s", owner, repo)
+	}
+
+	results := CheckOutdated(files, 2, latest)
+	if len(results) != len(files) {
+		t.Fatalf("CheckOutdated() returned %d results, want %d", len(results), len(files))
+	}
+
+	want := []struct {
+		kind     string
+		current  string
+		outdated bool
+		failed   bool
+	}{
+		{"formula", "1.2.0", true, false},
+		{"cask", "v1.2.0", true, false},
+		{"formula", "2.0.0", false, false},
+		{"formula", "", false, true},
+	}
+	for i, w := range want {
+		got := results[i]
+		if got.Path != files[i] || got.Kind != w.kind || got.Current != w.current ||
+			got.Outdated != w.outdated || (got.Error != "") != w.failed {
+			t.Errorf("CheckOutdated()[%d] = %+v, want %+v", i, got, w)
+		}
+	}
+	if calls.Load() != 3 {
+		t.Errorf("latest called %d times, want 3 (not for unparseable files)", calls.Load())
+	}
+}
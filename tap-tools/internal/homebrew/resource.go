@@ -0,0 +1,50 @@
+package homebrew
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InsertResource adds a resource stanza to formula content
+// The stanza goes before the install method, where Homebrew's style expects it
+func InsertResource(content, name, url, sha256 string) (string, error) {
+	if strings.Contains(content, fmt.Sprintf("resource %q do", name)) {
+		return "", fmt.Errorf("formula already has a resource named %q", name)
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "def install" {
+			continue
+		}
+
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		stanza := []string{
+			fmt.Sprintf("%sresource %q do", indent, name),
+			fmt.Sprintf("%s  url %q", indent, url),
+			fmt.Sprintf("%s  sha256 %q", indent, sha256),
+			indent + "end",
+			"",
+		}
+
+		out := make([]string, 0, len(lines)+len(stanza))
+		out = append(out, lines[:i]...)
+		out = append(out, stanza...)
+		out = append(out, lines[i:]...)
+		return strings.Join(out, "\n"), nil
+	}
+
+	return "", fmt.Errorf("formula has no install method to insert the resource before")
+}
+
+// ResourceNameFromURL derives a resource name from a download URL
+// by taking the filename and dropping archive extensions
+func ResourceNameFromURL(url string) string {
+	name := url[strings.LastIndex(url, "/")+1:]
+	for _, ext := range []string{".tar.gz", ".tar.xz", ".tar.bz2", ".tar.zst", ".tgz", ".zip", ".tar"} {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext)
+		}
+	}
+	return name
+}
@@ -0,0 +1,72 @@
+package homebrew
+
+import (
+	"testing"
+)
+
+func TestInsertResource(t *testing.T) {
+	formula := `class Tool < Formula
+  desc "Example tool"
+  url "https://example.com/tool-1.0.0.tar.gz"
+  sha256 "abc123"
+
+  depends_on "python@3.12"
+
+  def install
+    bin.install "tool"
+  end
+end`
+
+	want := `class Tool < Formula
+  desc "Example tool"
+  url "https://example.com/tool-1.0.0.tar.gz"
+  sha256 "abc123"
+
+  depends_on "python@3.12"
+
+  resource "plugin" do
+    url "https://example.com/plugin-2.0.tar.gz"
+    sha256 "def456"
+  end
+
+  def install
+    bin.install "tool"
+  end
+end`
+
+	got, err := InsertResource(formula, "plugin", "https://example.com/plugin-2.0.tar.gz", "def456")
+	if err != nil {
+		t.Fatalf("InsertResource() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("InsertResource() =\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := InsertResource(got, "plugin", "https://example.com/plugin-2.0.tar.gz", "def456"); err == nil {
+		t.Error("InsertResource() should reject a duplicate resource name")
+	}
+
+	if _, err := InsertResource("class Tool < Formula\nend", "plugin", "https://example.com/p.tar.gz", "def456"); err == nil {
+		t.Error("InsertResource() should fail without an install method")
+	}
+}
+
+func TestResourceNameFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://example.com/plugin-2.0.tar.gz", "plugin-2.0"},
+		{"https://files.pythonhosted.org/packages/requests-2.31.0.tar.gz", "requests-2.31.0"},
+		{"https://example.com/data.zip", "data"},
+		{"https://example.com/tool", "tool"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := ResourceNameFromURL(tt.url); got != tt.want {
+				t.Errorf("ResourceNameFromURL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
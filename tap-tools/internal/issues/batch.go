@@ -0,0 +1,80 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// ListIssuesByLabel returns the numbers of open issues carrying a label
+// Pull requests are skipped since the issues API also returns them
+func (c *Client) ListIssuesByLabel(owner, repo, label string) ([]int, error) {
+	ctx := context.Background()
+
+	opts := &github.IssueListByRepoOptions{
+		State:       "open",
+		Labels:      []string{label},
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var numbers []int
+	for {
+		issues, resp, err := c.gh.Issues.ListByRepo(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+
+		for _, issue := range issues {
+			if issue.IsPullRequest() {
+				continue
+			}
+			numbers = append(numbers, issue.GetNumber())
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return numbers, nil
+}
+
+// BatchResult records the outcome of processing one issue in a batch
+type BatchResult struct {
+	Number int
+	Err    error
+}
+
+// FailedCount returns how many issues in a batch failed
+func FailedCount(results []BatchResult) int {
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// SummarizeBatch renders a Markdown status report for a batch run
+// suitable for printing or posting as an issue comment
+func SummarizeBatch(label string, results []BatchResult) string {
+	var b strings.Builder
+
+	failed := FailedCount(results)
+	fmt.Fprintf(&b, "## Batch processing: `%s`\n\n", label)
+	fmt.Fprintf(&b, "Processed %d issue(s): %d succeeded, %d failed\n\n", len(results), len(results)-failed, failed)
+
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(&b, "- ❌ #%d: %v\n", r.Number, r.Err)
+		} else {
+			fmt.Fprintf(&b, "- ✅ #%d\n", r.Number)
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
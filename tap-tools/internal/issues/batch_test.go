@@ -0,0 +1,90 @@
+package issues
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v60/github"
+)
+
+// newTestClient returns a Client that talks to a local test server
+func newTestClient(t *testing.T, mux *http.ServeMux) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	gh := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Failed to parse test server URL: %v", err)
+	}
+	gh.BaseURL = baseURL
+
+	return &Client{gh: gh}
+}
+
+func TestListIssuesByLabel(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/user/tap/issues", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("labels"); got != "package-request" {
+			t.Errorf("labels = %q, want package-request", got)
+		}
+		if got := r.URL.Query().Get("state"); got != "open" {
+			t.Errorf("state = %q, want open", got)
+		}
+		w.Write([]byte(`[
+			{"number": 12, "title": "Add ripgrep"},
+			{"number": 11, "title": "Add fd", "pull_request": {"url": "https://api.github.com/repos/user/tap/pulls/11"}},
+			{"number": 9, "title": "Add bat"}
+		]`))
+	})
+
+	client := newTestClient(t, mux)
+
+	numbers, err := client.ListIssuesByLabel("user", "tap", "package-request")
+	if err != nil {
+		t.Fatalf("ListIssuesByLabel() error = %v", err)
+	}
+
+	want := []int{12, 9}
+	if len(numbers) != len(want) {
+		t.Fatalf("ListIssuesByLabel() = %v, want %v", numbers, want)
+	}
+	for i := range want {
+		if numbers[i] != want[i] {
+			t.Errorf("ListIssuesByLabel()[%d] = %d, want %d", i, numbers[i], want[i])
+		}
+	}
+}
+
+func TestSummarizeBatch(t *testing.T) {
+	results := []BatchResult{
+		{Number: 12},
+		{Number: 10, Err: errors.New("could not find repository URL in issue body")},
+		{Number: 9},
+	}
+
+	if got := FailedCount(results); got != 1 {
+		t.Errorf("FailedCount() = %d, want 1", got)
+	}
+
+	summary := SummarizeBatch("package-request", results)
+
+	wantLines := []string{
+		"## Batch processing: `package-request`",
+		"Processed 3 issue(s): 2 succeeded, 1 failed",
+		"- ✅ #12",
+		"- ❌ #10: could not find repository URL in issue body",
+		"- ✅ #9",
+	}
+	for _, line := range wantLines {
+		if !strings.Contains(summary, line) {
+			t.Errorf("SummarizeBatch() missing %q in:\n%s", line, summary)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/castrojo/tap-tools/internal/checksum"
+	tapgithub "github.com/castrojo/tap-tools/internal/github"
 	"github.com/google/go-github/v60/github"
 	"golang.org/x/oauth2"
 )
@@ -40,16 +42,17 @@ type Client struct {
 }
 
 // NewClient creates a new issues client
-// Uses GITHUB_TOKEN environment variable if available
+// Uses GITHUB_TOKEN environment variable if available, and the response
+// cache when enabled with github.ConfigureCache
 func NewClient() *Client {
 	var client *github.Client
 
 	if token := getGitHubToken(); token != "" {
 		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-		tc := oauth2.NewClient(context.Background(), ts)
-		client = github.NewClient(tc)
+		tc := oauth2.NewClient(context.WithValue(context.Background(), oauth2.HTTPClient, checksum.HTTPClient()), ts)
+		client = github.NewClient(tapgithub.CachedHTTPClient(tc))
 	} else {
-		client = github.NewClient(nil)
+		client = github.NewClient(tapgithub.CachedHTTPClient(nil))
 	}
 
 	return &Client{gh: client}
@@ -189,40 +192,59 @@ func detectPackageType(body, title string) PackageType {
 	combined := strings.ToLower(body + " " + title)
 
 	// Check for explicit type hints
-	if strings.Contains(combined, "type: cask") || strings.Contains(combined, "type: gui") {
-		return PackageTypeCask
-	}
-	if strings.Contains(combined, "type: formula") || strings.Contains(combined, "type: cli") {
-		return PackageTypeFormula
+	if explicit := explicitPackageType(combined); explicit != PackageTypeUnknown {
+		return explicit
 	}
 
 	// Check for GUI/application indicators
-	guiKeywords := []string{
-		"gui", "desktop", "application", " app",
-		"electron", "tauri", "qt", "gtk",
-		"visual", "editor", "ide",
-	}
-	for _, keyword := range guiKeywords {
-		if strings.Contains(combined, keyword) {
-			return PackageTypeCask
-		}
+	if containsAny(combined, issueGUIKeywords) {
+		return PackageTypeCask
 	}
 
 	// Check for CLI indicators
-	cliKeywords := []string{
-		"cli", "command-line", "terminal", "shell",
-		"tool", "utility", "binary",
-	}
-	for _, keyword := range cliKeywords {
-		if strings.Contains(combined, keyword) {
-			return PackageTypeFormula
-		}
+	if containsAny(combined, issueCLIKeywords) {
+		return PackageTypeFormula
 	}
 
 	// Default to formula (most packages are CLI tools)
 	return PackageTypeFormula
 }
 
+// issueGUIKeywords suggest a cask when found in an issue title or body
+var issueGUIKeywords = []string{
+	"gui", "desktop", "application", " app",
+	"electron", "tauri", "qt", "gtk",
+	"visual", "editor", "ide",
+}
+
+// issueCLIKeywords suggest a formula when found in an issue title or body
+var issueCLIKeywords = []string{
+	"cli", "command-line", "terminal", "shell",
+	"tool", "utility", "binary",
+}
+
+// explicitPackageType returns the type named by a "Type:" hint in lowercased
+// issue text, or PackageTypeUnknown if there is none
+func explicitPackageType(combined string) PackageType {
+	if strings.Contains(combined, "type: cask") || strings.Contains(combined, "type: gui") {
+		return PackageTypeCask
+	}
+	if strings.Contains(combined, "type: formula") || strings.Contains(combined, "type: cli") {
+		return PackageTypeFormula
+	}
+	return PackageTypeUnknown
+}
+
+// containsAny reports whether s contains any of the keywords
+func containsAny(s string, keywords []string) bool {
+	for _, keyword := range keywords {
+		if strings.Contains(s, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
 // DetectPackageTypeFromRepo uses GitHub API to detect package type from repository
 func (c *Client) DetectPackageTypeFromRepo(owner, repo string) (PackageType, error) {
 	ctx := context.Background()
@@ -0,0 +1,144 @@
+package issues
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FieldStatus describes how well a field could be parsed from an issue
+type FieldStatus string
+
+const (
+	FieldFound     FieldStatus = "found"
+	FieldMissing   FieldStatus = "missing"
+	FieldAmbiguous FieldStatus = "ambiguous"
+	FieldInvalid   FieldStatus = "invalid"
+)
+
+// FieldDiagnostic reports the parse result for one package request field
+type FieldDiagnostic struct {
+	Field      string      // Field name, e.g. "Repository URL"
+	Value      string      // Parsed value, if any
+	Status     FieldStatus // Parse result
+	Required   bool        // Whether processing fails without this field
+	Suggestion string      // How the issue author can fix it
+}
+
+// LintReport holds field diagnostics for a package request issue
+type LintReport struct {
+	Number int
+	Title  string
+	Fields []FieldDiagnostic
+}
+
+// OK reports whether every required field was parsed
+func (r *LintReport) OK() bool {
+	for _, f := range r.Fields {
+		if f.Required && (f.Status == FieldMissing || f.Status == FieldInvalid) {
+			return false
+		}
+	}
+	return true
+}
+
+// LintIssue fetches an issue and reports which package request fields can be parsed
+func (c *Client) LintIssue(owner, repo string, number int) (*LintReport, error) {
+	issue, _, err := c.gh.Issues.Get(context.Background(), owner, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch issue: %w", err)
+	}
+
+	report := lintIssue(issue.GetTitle(), issue.GetBody())
+	report.Number = number
+	return report, nil
+}
+
+// githubRepoPattern matches owner/repo references in GitHub URLs
+var githubRepoPattern = regexp.MustCompile(`(?i)github\.com/([\w.-]+)/([\w.-]+)`)
+
+// otherURLPattern matches any URL, used to spot links to non-GitHub hosts
+var otherURLPattern = regexp.MustCompile(`https?://[^\s)\]]+`)
+
+// lintIssue diagnoses each field the same way parseIssue extracts it
+func lintIssue(title, body string) *LintReport {
+	report := &LintReport{Title: title}
+
+	// Repository URL
+	repoURL := extractRepositoryURL(body)
+	repoField := FieldDiagnostic{Field: "Repository URL", Value: repoURL, Required: true}
+	switch {
+	case repoURL == "" && otherURLPattern.MatchString(body):
+		repoField.Status = FieldInvalid
+		repoField.Value = otherURLPattern.FindString(body)
+		repoField.Suggestion = "Only GitHub repositories are supported; link the project's GitHub repository"
+	case repoURL == "":
+		repoField.Status = FieldMissing
+		repoField.Suggestion = "Add a '### Repository URL' section containing https://github.com/owner/repo"
+	case !strings.Contains(repoURL, "github.com"):
+		repoField.Status = FieldInvalid
+		repoField.Suggestion = "Only GitHub repositories are supported; link the project's GitHub repository"
+	case countRepositories(body) > 1:
+		repoField.Status = FieldAmbiguous
+		repoField.Suggestion = "Several GitHub repositories are linked; put the one to package first under '### Repository URL'"
+	default:
+		repoField.Status = FieldFound
+	}
+	report.Fields = append(report.Fields, repoField)
+
+	// Package name
+	nameField := FieldDiagnostic{Field: "Package name", Required: true}
+	if repoURL != "" {
+		nameField.Value = extractPackageNameFromURL(repoURL)
+	}
+	if nameField.Value == "" {
+		nameField.Status = FieldMissing
+		nameField.Suggestion = "The name is derived from the repository URL; fix the URL first"
+	} else {
+		nameField.Status = FieldFound
+	}
+	report.Fields = append(report.Fields, nameField)
+
+	// Package type
+	combined := strings.ToLower(body + " " + title)
+	typeField := FieldDiagnostic{Field: "Package type", Value: string(detectPackageType(body, title))}
+	isGUI := containsAny(combined, issueGUIKeywords)
+	isCLI := containsAny(combined, issueCLIKeywords)
+	switch {
+	case explicitPackageType(combined) != PackageTypeUnknown:
+		typeField.Status = FieldFound
+	case isGUI && isCLI:
+		typeField.Status = FieldAmbiguous
+		typeField.Suggestion = "Both GUI and CLI keywords found; add 'Type: cask' or 'Type: formula'"
+	case !isGUI && !isCLI:
+		typeField.Status = FieldAmbiguous
+		typeField.Suggestion = "No type indicators found, defaulting to formula; add 'Type: cask' for GUI apps"
+	default:
+		typeField.Status = FieldFound
+	}
+	report.Fields = append(report.Fields, typeField)
+
+	// Description (optional)
+	description := extractDescription(body)
+	descField := FieldDiagnostic{Field: "Description", Value: description}
+	if description == "" {
+		descField.Status = FieldMissing
+		descField.Suggestion = "Optional: add a '### Description' section; the repository description is used otherwise"
+	} else {
+		descField.Status = FieldFound
+	}
+	report.Fields = append(report.Fields, descField)
+
+	return report
+}
+
+// countRepositories counts distinct GitHub repositories linked in text
+func countRepositories(body string) int {
+	seen := make(map[string]bool)
+	for _, m := range githubRepoPattern.FindAllStringSubmatch(body, -1) {
+		repo := strings.TrimSuffix(m[2], ".git")
+		seen[strings.ToLower(m[1]+"/"+repo)] = true
+	}
+	return len(seen)
+}
@@ -0,0 +1,112 @@
+package issues
+
+import (
+	"testing"
+)
+
+func TestLintIssue(t *testing.T) {
+	tests := []struct {
+		name       string
+		title      string
+		body       string
+		wantOK     bool
+		wantStatus map[string]FieldStatus
+	}{
+		{
+			name:  "Well-formed issue form",
+			title: "Package request: ripgrep",
+			body: `### Repository URL
+
+https://github.com/BurntSushi/ripgrep
+
+### Description
+
+Fast line-oriented search tool
+
+Type: formula`,
+			wantOK: true,
+			wantStatus: map[string]FieldStatus{
+				"Repository URL": FieldFound,
+				"Package name":   FieldFound,
+				"Package type":   FieldFound,
+				"Description":    FieldFound,
+			},
+		},
+		{
+			name:   "Missing repository URL",
+			title:  "Please add my tool",
+			body:   "It's a great CLI tool, you can find it on my website.",
+			wantOK: false,
+			wantStatus: map[string]FieldStatus{
+				"Repository URL": FieldMissing,
+				"Package name":   FieldMissing,
+				"Package type":   FieldFound,
+				"Description":    FieldMissing,
+			},
+		},
+		{
+			name:   "Non-GitHub repository",
+			title:  "Add tool",
+			body:   "### Repository URL\n\nhttps://gitlab.com/user/tool",
+			wantOK: false,
+			wantStatus: map[string]FieldStatus{
+				"Repository URL": FieldInvalid,
+			},
+		},
+		{
+			name:   "Several repositories linked",
+			title:  "Add app",
+			body:   "https://github.com/user/app is a fork of https://github.com/other/app",
+			wantOK: true,
+			wantStatus: map[string]FieldStatus{
+				"Repository URL": FieldAmbiguous,
+			},
+		},
+		{
+			name:   "Conflicting type keywords",
+			title:  "Add editor",
+			body:   "https://github.com/user/editor\n\nA GUI editor with a CLI",
+			wantOK: true,
+			wantStatus: map[string]FieldStatus{
+				"Package type": FieldAmbiguous,
+			},
+		},
+		{
+			name:   "No type indicators",
+			title:  "Package request",
+			body:   "https://github.com/user/thing",
+			wantOK: true,
+			wantStatus: map[string]FieldStatus{
+				"Package type": FieldAmbiguous,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := lintIssue(tt.title, tt.body)
+
+			if report.OK() != tt.wantOK {
+				t.Errorf("OK() = %v, want %v", report.OK(), tt.wantOK)
+			}
+
+			got := make(map[string]FieldDiagnostic)
+			for _, f := range report.Fields {
+				got[f.Field] = f
+			}
+			for field, want := range tt.wantStatus {
+				f, ok := got[field]
+				if !ok {
+					t.Errorf("Missing diagnostic for %s", field)
+					continue
+				}
+				if f.Status != want {
+					t.Errorf("%s status = %s, want %s", field, f.Status, want)
+				}
+				if f.Status != FieldFound && f.Suggestion == "" {
+					t.Errorf("%s should include a suggestion", field)
+				}
+			}
+		})
+	}
+}
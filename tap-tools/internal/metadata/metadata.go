@@ -0,0 +1,71 @@
+// Package metadata loads hand-curated package metadata from a tap's
+// metadata.yaml, so maintainers can fix fields the GitHub API gets wrong.
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Override holds curated fields for one package
+// Empty fields keep the value fetched from the API
+type Override struct {
+	Desc     string `yaml:"desc"`
+	Homepage string `yaml:"homepage"`
+	License  string `yaml:"license"`
+}
+
+// File maps package names to their overrides
+type File map[string]Override
+
+// Load reads a metadata file keyed by package name
+// A missing file is not an error and yields no overrides
+func Load(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return File{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata file: %w", err)
+	}
+
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if f == nil {
+		f = File{}
+	}
+
+	return f, nil
+}
+
+// Apply replaces the API values for a package with any curated overrides
+// and returns the names of the fields that were overridden
+func (f File) Apply(name string, desc, homepage, license *string) []string {
+	o, ok := f[name]
+	if !ok {
+		return nil
+	}
+
+	var applied []string
+	for _, field := range []struct {
+		name     string
+		value    string
+		apiValue *string
+	}{
+		{"desc", o.Desc, desc},
+		{"homepage", o.Homepage, homepage},
+		{"license", o.License, license},
+	} {
+		if field.value != "" {
+			*field.apiValue = field.value
+			applied = append(applied, field.name)
+		}
+	}
+
+	return applied
+}
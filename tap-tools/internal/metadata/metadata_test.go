@@ -0,0 +1,126 @@
+package metadata
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testMetadata = `lazygit:
+  desc: Simple terminal UI for git commands
+ripgrep:
+  desc: Search tool like grep and The Silver Searcher
+  homepage: https://github.com/BurntSushi/ripgrep
+  license: Unlicense
+`
+
+func writeMetadata(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "metadata.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write metadata: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Valid file", func(t *testing.T) {
+		f, err := Load(writeMetadata(t, testMetadata))
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if len(f) != 2 {
+			t.Errorf("Load() returned %d packages, want 2", len(f))
+		}
+		if f["ripgrep"].License != "Unlicense" {
+			t.Errorf("ripgrep license = %q, want Unlicense", f["ripgrep"].License)
+		}
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		f, err := Load(filepath.Join(t.TempDir(), "metadata.yaml"))
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if len(f) != 0 {
+			t.Errorf("Load() = %v, want no overrides", f)
+		}
+	})
+
+	t.Run("Empty file", func(t *testing.T) {
+		f, err := Load(writeMetadata(t, ""))
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if f == nil || len(f) != 0 {
+			t.Errorf("Load() = %v, want empty overrides", f)
+		}
+	})
+
+	t.Run("Invalid YAML", func(t *testing.T) {
+		if _, err := Load(writeMetadata(t, "lazygit: [unclosed")); err == nil {
+			t.Error("Load() expected error for invalid YAML")
+		}
+	})
+}
+
+func TestApply(t *testing.T) {
+	f, err := Load(writeMetadata(t, testMetadata))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		pkg          string
+		wantDesc     string
+		wantHomepage string
+		wantLicense  string
+		wantApplied  []string
+	}{
+		{
+			name:         "All fields override API values",
+			pkg:          "ripgrep",
+			wantDesc:     "Search tool like grep and The Silver Searcher",
+			wantHomepage: "https://github.com/BurntSushi/ripgrep",
+			wantLicense:  "Unlicense",
+			wantApplied:  []string{"desc", "homepage", "license"},
+		},
+		{
+			name:         "Partial override keeps other API values",
+			pkg:          "lazygit",
+			wantDesc:     "Simple terminal UI for git commands",
+			wantHomepage: "https://api.example.com",
+			wantLicense:  "MIT",
+			wantApplied:  []string{"desc"},
+		},
+		{
+			name:         "Unknown package keeps API values",
+			pkg:          "fzf",
+			wantDesc:     "API description",
+			wantHomepage: "https://api.example.com",
+			wantLicense:  "MIT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc, homepage, license := "API description", "https://api.example.com", "MIT"
+
+			applied := f.Apply(tt.pkg, &desc, &homepage, &license)
+			if !reflect.DeepEqual(applied, tt.wantApplied) {
+				t.Errorf("Apply() = %v, want %v", applied, tt.wantApplied)
+			}
+			if desc != tt.wantDesc {
+				t.Errorf("desc = %q, want %q", desc, tt.wantDesc)
+			}
+			if homepage != tt.wantHomepage {
+				t.Errorf("homepage = %q, want %q", homepage, tt.wantHomepage)
+			}
+			if license != tt.wantLicense {
+				t.Errorf("license = %q, want %q", license, tt.wantLicense)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package platform
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Decision records why a release asset was chosen
+// It is emitted under --verbose or written to --log-json for auditing
+type Decision struct {
+	Repository  string      `json:"repository"`
+	Version     string      `json:"version"`
+	BuildSystem string      `json:"build_system,omitempty"`
+	Candidates  []Candidate `json:"candidates"`
+	Selected    string      `json:"selected,omitempty"`
+	Reason      string      `json:"reason,omitempty"`
+}
+
+// Candidate is a single asset considered during selection
+type Candidate struct {
+	Name     string       `json:"name"`
+	Platform Platform     `json:"platform"`
+	Arch     Architecture `json:"arch"`
+	Format   Format       `json:"format"`
+	Priority int          `json:"priority"`
+	Size     int64        `json:"size"`
+	Rejected string       `json:"rejected,omitempty"` // Why the asset was filtered out
+}
+
+// NewDecision creates a Decision for a repository release
+func NewDecision(repository, version string) *Decision {
+	return &Decision{
+		Repository: repository,
+		Version:    version,
+		Candidates: []Candidate{},
+	}
+}
+
+// AddCandidates records assets with the reason each one was filtered, if any
+func (d *Decision) AddCandidates(assets []*Asset) {
+	for _, asset := range assets {
+		d.Candidates = append(d.Candidates, Candidate{
+			Name:     asset.Name,
+			Platform: asset.Platform,
+			Arch:     asset.Arch,
+			Format:   asset.Format,
+			Priority: asset.Priority,
+			Size:     asset.Size,
+			Rejected: ExplainFilter(asset),
+		})
+	}
+}
+
+// Reject marks a previously added candidate as rejected
+func (d *Decision) Reject(name, reason string) {
+	for i := range d.Candidates {
+		if d.Candidates[i].Name == name && d.Candidates[i].Rejected == "" {
+			d.Candidates[i].Rejected = reason
+		}
+	}
+}
+
+// Select records the winning asset and why it won
+func (d *Decision) Select(asset *Asset) {
+	d.Selected = asset.Name
+	d.Reason = fmt.Sprintf("lowest priority %d (%s), arch %s", asset.Priority, asset.Format, asset.Arch)
+}
+
+// WriteText writes a human-readable summary of the decision
+func (d *Decision) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Selection for %s %s:\n", d.Repository, d.Version)
+	for _, c := range d.Candidates {
+		status := "candidate"
+		if c.Name == d.Selected {
+			status = "selected"
+		} else if c.Rejected != "" {
+			status = "rejected: " + c.Rejected
+		}
+		fmt.Fprintf(w, "  - %s [%s, %s, priority %d] %s\n", c.Name, c.Format, c.Arch, c.Priority, status)
+	}
+	if d.Selected != "" {
+		fmt.Fprintf(w, "  Selected %s: %s\n", d.Selected, d.Reason)
+	}
+	if d.BuildSystem != "" {
+		fmt.Fprintf(w, "  Build system: %s\n", d.BuildSystem)
+	}
+}
+
+// WriteJSON writes the decision as indented JSON to path
+func (d *Decision) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode decision: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write decision log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package platform
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecision(t *testing.T) {
+	names := []string{
+		"app-linux-x64.tar.gz",
+		"app-linux-arm64.tar.gz",
+		"app_amd64.deb",
+		"app-darwin-arm64.zip",
+		"checksums.txt",
+		"app-source.tar.gz",
+	}
+
+	var assets []*Asset
+	for _, name := range names {
+		assets = append(assets, DetectPlatform(name))
+	}
+
+	decision := NewDecision("owner/app", "1.0.0")
+	decision.AddCandidates(assets)
+
+	best, err := SelectBestAsset(FilterLinuxAssets(assets))
+	if err != nil {
+		t.Fatalf("SelectBestAsset() error = %v", err)
+	}
+	decision.Select(best)
+
+	if len(decision.Candidates) != len(names) {
+		t.Fatalf("Decision has %d candidates, want %d", len(decision.Candidates), len(names))
+	}
+	if decision.Selected != "app-linux-x64.tar.gz" {
+		t.Errorf("Decision.Selected = %s, want app-linux-x64.tar.gz", decision.Selected)
+	}
+	if decision.Reason == "" {
+		t.Error("Decision.Reason should explain the pick")
+	}
+
+	wantRejected := map[string]string{
+		"app-linux-x64.tar.gz":   "",
+		"app-linux-arm64.tar.gz": "",
+		"app_amd64.deb":          "",
+		"app-darwin-arm64.zip":   "not a Linux asset",
+		"checksums.txt":          "checksum file",
+		"app-source.tar.gz":      "source archive",
+	}
+	for _, c := range decision.Candidates {
+		if c.Rejected != wantRejected[c.Name] {
+			t.Errorf("Candidate %s rejected = %q, want %q", c.Name, c.Rejected, wantRejected[c.Name])
+		}
+	}
+
+	// Rejections added later (e.g. size limits) are recorded too
+	decision.Reject("app_amd64.deb", "exceeds --max-asset-size")
+	for _, c := range decision.Candidates {
+		if c.Name == "app_amd64.deb" && c.Rejected != "exceeds --max-asset-size" {
+			t.Errorf("Reject() did not record reason, got %q", c.Rejected)
+		}
+	}
+
+	var text strings.Builder
+	decision.WriteText(&text)
+	if !strings.Contains(text.String(), "app-linux-x64.tar.gz [tar.gz, x86_64, priority 1] selected") {
+		t.Errorf("WriteText() missing selected candidate:\n%s", text.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "decision.json")
+	if err := decision.WriteJSON(path); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read decision log: %v", err)
+	}
+	var decoded Decision
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Decision log is not valid JSON: %v", err)
+	}
+	if decoded.Selected != decision.Selected || len(decoded.Candidates) != len(names) {
+		t.Errorf("Decoded decision does not match original")
+	}
+}
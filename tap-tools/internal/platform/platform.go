@@ -1,8 +1,14 @@
 package platform
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"net/url"
+	"path"
 	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
 )
 
@@ -25,6 +31,15 @@ const (
 	ArchUnknown Architecture = "unknown"
 )
 
+// Libc represents the C library a Linux binary is linked against
+type Libc string
+
+const (
+	LibcGNU     Libc = "gnu"
+	LibcMusl    Libc = "musl"
+	LibcUnknown Libc = "unknown"
+)
+
 // Format represents package format
 type Format string
 
@@ -32,16 +47,19 @@ const (
 	FormatTarGz    Format = "tar.gz"
 	FormatTarXz    Format = "tar.xz"
 	FormatTarBz2   Format = "tar.bz2"
+	FormatTarZst   Format = "tar.zst"
 	FormatTgz      Format = "tgz"
 	FormatDeb      Format = "deb"
 	FormatRpm      Format = "rpm"
 	FormatAppImage Format = "appimage"
+	FormatZip      Format = "zip"
+	FormatFlatpak  Format = "flatpak"
 	FormatUnknown  Format = "unknown"
 )
 
 // Priority levels for package formats (lower is better)
 const (
-	PriorityTarball = 1 // .tar.gz, .tar.xz, .tgz
+	PriorityTarball = 1 // .tar.gz, .tar.xz, .tar.zst, .tgz
 	PriorityDeb     = 2 // .deb
 	PriorityOther   = 3 // Everything else
 )
@@ -54,10 +72,12 @@ type Asset struct {
 	Size        int64
 	Platform    Platform
 	Arch        Architecture
+	Libc        Libc
 	Format      Format
 	Priority    int
 	IsSource    bool
 	IsChecksum  bool
+	IsInstaller bool
 }
 
 // DetectPlatform analyzes a filename and returns asset metadata
@@ -68,6 +88,7 @@ func DetectPlatform(filename string) *Asset {
 		Name:     filename,
 		Platform: detectPlatformFromFilename(lower),
 		Arch:     detectArchFromFilename(lower),
+		Libc:     detectLibcFromFilename(lower),
 		Format:   detectFormatFromFilename(lower),
 	}
 
@@ -77,6 +98,9 @@ func DetectPlatform(filename string) *Asset {
 	// Check if it's a checksum file
 	asset.IsChecksum = isChecksumFile(lower)
 
+	// Check if it's an installer script rather than a package
+	asset.IsInstaller = isInstallerScript(lower, asset.Format)
+
 	// Assign priority based on format
 	asset.Priority = getPriority(asset.Format)
 
@@ -86,8 +110,9 @@ func DetectPlatform(filename string) *Asset {
 // detectPlatformFromFilename detects the platform from filename
 // For Linux-only tap, we only detect Linux formats
 func detectPlatformFromFilename(filename string) Platform {
-	// Check format first - .deb and .rpm are Linux-specific
-	if strings.HasSuffix(filename, ".deb") || strings.HasSuffix(filename, ".rpm") {
+	// Check format first - .deb, .rpm, .appimage and .flatpak are Linux-specific
+	if strings.HasSuffix(filename, ".deb") || strings.HasSuffix(filename, ".rpm") ||
+		strings.HasSuffix(strings.ToLower(filename), ".appimage") || strings.HasSuffix(strings.ToLower(filename), ".flatpak") {
 		return PlatformLinux
 	}
 
@@ -151,6 +176,18 @@ func detectArchFromFilename(filename string) Architecture {
 	return ArchUnknown
 }
 
+// detectLibcFromFilename detects the C library from filename, e.g.
+// x86_64-unknown-linux-musl or aarch64-unknown-linux-gnu
+func detectLibcFromFilename(filename string) Libc {
+	switch {
+	case strings.Contains(filename, "musl"):
+		return LibcMusl
+	case strings.Contains(filename, "glibc"), strings.Contains(filename, "-gnu"), strings.Contains(filename, "_gnu"):
+		return LibcGNU
+	}
+	return LibcUnknown
+}
+
 // detectFormatFromFilename detects the package format from filename
 func detectFormatFromFilename(filename string) Format {
 	switch {
@@ -160,6 +197,8 @@ func detectFormatFromFilename(filename string) Format {
 		return FormatTarXz
 	case strings.HasSuffix(filename, ".tar.bz2"):
 		return FormatTarBz2
+	case strings.HasSuffix(filename, ".tar.zst"), strings.HasSuffix(filename, ".tzst"):
+		return FormatTarZst
 	case strings.HasSuffix(filename, ".tgz"):
 		return FormatTgz
 	case strings.HasSuffix(filename, ".deb"):
@@ -168,6 +207,10 @@ func detectFormatFromFilename(filename string) Format {
 		return FormatRpm
 	case strings.HasSuffix(strings.ToLower(filename), ".appimage"):
 		return FormatAppImage
+	case strings.HasSuffix(strings.ToLower(filename), ".flatpak"):
+		return FormatFlatpak
+	case strings.HasSuffix(filename, ".zip"):
+		return FormatZip
 	default:
 		return FormatUnknown
 	}
@@ -200,10 +243,30 @@ func isChecksumFile(filename string) bool {
 	return false
 }
 
+// isInstallerScript checks if the filename is an installer or setup script
+// (install.sh, *-installer.run, setup.bin) rather than something that can
+// be packaged. Binaries merely named after setup, like setup-envtest, are
+// not installers, and neither is a standalone .bin binary.
+func isInstallerScript(filename string, format Format) bool {
+	if format != FormatUnknown {
+		return false
+	}
+	ext := path.Ext(filename)
+	switch ext {
+	case ".sh", ".run":
+		return true
+	}
+	switch strings.TrimSuffix(filename, ext) {
+	case "install", "installer", "setup":
+		return true
+	}
+	return false
+}
+
 // getPriority returns the priority for a given format
 func getPriority(format Format) int {
 	switch format {
-	case FormatTarGz, FormatTarXz, FormatTarBz2, FormatTgz:
+	case FormatTarGz, FormatTarXz, FormatTarBz2, FormatTarZst, FormatTgz:
 		return PriorityTarball
 	case FormatDeb:
 		return PriorityDeb
@@ -213,33 +276,52 @@ func getPriority(format Format) int {
 }
 
 // FilterLinuxAssets filters assets to only include Linux packages
-// Excludes: source archives, checksums, non-Linux platforms
+// Excludes: source archives, checksums, installer scripts, non-Linux platforms
 func FilterLinuxAssets(assets []*Asset) []*Asset {
 	var filtered []*Asset
 
 	for _, asset := range assets {
-		// Skip source archives and checksums
-		if asset.IsSource || asset.IsChecksum {
-			continue
+		if ExplainFilter(asset) == "" {
+			filtered = append(filtered, asset)
 		}
+	}
 
-		// Skip explicitly non-Linux platforms
-		if asset.Platform == PlatformUnknown && !isLikelyLinux(asset) {
-			continue
-		}
+	return filtered
+}
 
-		// Skip unknown formats (unless it's explicitly Linux)
-		if asset.Format == FormatUnknown && asset.Platform != PlatformLinux {
-			continue
-		}
+// ExplainFilter returns the reason FilterLinuxAssets would exclude an asset,
+// or "" if the asset is kept
+func ExplainFilter(asset *Asset) string {
+	// Skip source archives and checksums
+	if asset.IsSource {
+		return "source archive"
+	}
+	if asset.IsChecksum {
+		return "checksum file"
+	}
+	if asset.IsInstaller {
+		return "installer script, not a package"
+	}
+	if asset.Format == FormatFlatpak {
+		return "Flatpak bundle, not supported yet"
+	}
 
-		// Include Linux or likely Linux packages
-		if asset.Platform == PlatformLinux {
-			filtered = append(filtered, asset)
-		}
+	// Skip explicitly non-Linux platforms
+	if asset.Platform == PlatformUnknown && !isLikelyLinux(asset) {
+		return "not a Linux asset"
 	}
 
-	return filtered
+	// Skip unknown formats (unless it's explicitly Linux)
+	if asset.Format == FormatUnknown && asset.Platform != PlatformLinux {
+		return "unknown format"
+	}
+
+	// Include Linux or likely Linux packages
+	if asset.Platform != PlatformLinux {
+		return "no Linux marker in filename"
+	}
+
+	return ""
 }
 
 // isLikelyLinux checks if an asset is likely for Linux based on format
@@ -248,12 +330,160 @@ func isLikelyLinux(asset *Asset) bool {
 	return asset.Format == FormatTarGz ||
 		asset.Format == FormatTarXz ||
 		asset.Format == FormatTarBz2 ||
+		asset.Format == FormatTarZst ||
 		asset.Format == FormatTgz
 }
 
+// ErrNoAssets is returned for source-only releases with no uploaded assets
+var ErrNoAssets = errors.New("this release has no uploaded assets")
+
+// CheckReleaseAssets returns an error wrapping ErrNoAssets, with guidance
+// for the package type being generated, when a release has no assets at all.
+// This is distinct from a release whose assets are all for other platforms.
+func CheckReleaseAssets(assets []*Asset, isCask bool) error {
+	if len(assets) > 0 {
+		return nil
+	}
+	if isCask {
+		return fmt.Errorf("%w; casks require a prebuilt asset, consider a formula (tap-formula --from-source)", ErrNoAssets)
+	}
+	return fmt.Errorf("%w; the formula will build from the source tarball", ErrNoAssets)
+}
+
+// ErrFlatpakBundle is returned when a Flatpak bundle is the only Linux
+// package on offer. A bundle is a single OSTree file installed with
+// flatpak install --bundle, so it cannot be unpacked like a tarball.
+var ErrFlatpakBundle = errors.New("Flatpak bundles are not supported yet")
+
+// CheckFlatpak returns an error wrapping ErrFlatpakBundle, naming the bundle,
+// if any of assets is a Flatpak bundle
+func CheckFlatpak(assets []*Asset) error {
+	for _, asset := range assets {
+		if asset.Format == FormatFlatpak {
+			return fmt.Errorf("%w: %s (install it with flatpak install --bundle)", ErrFlatpakBundle, asset.Name)
+		}
+	}
+	return nil
+}
+
+// ErrNoLinuxAssets is returned by LinuxCandidates when no asset in a
+// release is a Linux package
+var ErrNoLinuxAssets = errors.New("no Linux assets found in release")
+
+// LinuxCandidates narrows a release's assets to the Linux packages one can
+// be selected from. Source-only and Flatpak-only releases are errors. Assets
+// over maxSize are returned as skipped and rejected in decision, so
+// candidates may be empty when every Linux asset is too large.
+func LinuxCandidates(assets []*Asset, maxSize int64, decision *Decision) (candidates, skipped []*Asset, err error) {
+	// Source-only releases need different advice than non-Linux ones
+	if err := CheckReleaseAssets(assets, true); err != nil {
+		return nil, nil, err
+	}
+
+	linux := FilterLinuxAssets(assets)
+	if len(linux) == 0 {
+		if err := CheckFlatpak(assets); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, ErrNoLinuxAssets
+	}
+
+	// Skip oversized assets (e.g. VM images) before selection
+	candidates, skipped = FilterBySize(linux, maxSize)
+	for _, asset := range skipped {
+		decision.Reject(asset.Name, "exceeds --max-asset-size")
+	}
+	return candidates, skipped, nil
+}
+
+// AssetFromURL builds asset metadata for a download URL given directly by
+// the user, bypassing release asset filtering and selection
+func AssetFromURL(rawURL string) (*Asset, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse asset URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("asset URL must be http or https: %s", rawURL)
+	}
+
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		return nil, fmt.Errorf("asset URL has no filename: %s", rawURL)
+	}
+
+	asset := DetectPlatform(name)
+	asset.URL = rawURL
+	asset.DownloadURL = rawURL
+	return asset, nil
+}
+
+// AssetChoice is what the user asked of SelectAsset on the command line
+type AssetChoice struct {
+	URL       string // --asset-url: download this instead of a release asset
+	Pick      string // --select-asset: index or file name of a candidate
+	MaxSize   int64  // --max-asset-size in bytes, 0 disables
+	SplitHint string // advice for the rest of an asset split across archives
+}
+
+// Selection is the asset SelectAsset chose, with what the caller reports
+type Selection struct {
+	Asset      *Asset
+	Candidates []*Asset // Linux assets it was chosen from, none for a URL
+	Skipped    []*Asset // Linux assets over MaxSize
+	Reason     string   // how it was chosen, e.g. "Priority 1" or "--asset-url"
+	Warning    string   // split archive warning, if any
+}
+
+// SelectAsset picks the release asset to package: the --asset-url download,
+// the --select-asset candidate, or the best Linux asset, recording the
+// choice in decision
+func SelectAsset(assets []*Asset, choice AssetChoice, decision *Decision) (*Selection, error) {
+	if choice.URL != "" {
+		asset, err := AssetFromURL(choice.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --asset-url: %w", err)
+		}
+		if err := CheckFlatpak([]*Asset{asset}); err != nil {
+			return nil, err
+		}
+		decision.Selected = asset.Name
+		decision.Reason = "asset URL given with --asset-url"
+		return &Selection{Asset: asset, Reason: "--asset-url"}, nil
+	}
+
+	candidates, skipped, err := LinuxCandidates(assets, choice.MaxSize, decision)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("all Linux assets exceed --max-asset-size")
+	}
+	selection := &Selection{Candidates: candidates, Skipped: skipped}
+
+	if choice.Pick != "" {
+		if selection.Asset, err = SelectAssetByChoice(candidates, choice.Pick); err != nil {
+			return nil, fmt.Errorf("invalid --select-asset: %w", err)
+		}
+		decision.Selected = selection.Asset.Name
+		decision.Reason = "chosen with --select-asset"
+		selection.Reason = "--select-asset"
+	} else {
+		if selection.Asset, err = SelectBestAsset(candidates); err != nil {
+			return nil, fmt.Errorf("failed to select asset: %w", err)
+		}
+		decision.Select(selection.Asset)
+		selection.Reason = fmt.Sprintf("Priority %d", selection.Asset.Priority)
+	}
+
+	selection.Warning = SplitAssetsWarning(SplitAssets(candidates, selection.Asset), selection.Asset, choice.SplitHint)
+	return selection, nil
+}
+
 // SelectBestAsset selects the best asset from a list based on priority
 // Priority order: tarball > deb > other
-// If multiple assets have the same priority, prefer x86_64/amd64
+// If multiple assets have the same priority, prefer x86_64/amd64, then
+// glibc builds over musl ones, which may not run on a glibc host
 func SelectBestAsset(assets []*Asset) (*Asset, error) {
 	if len(assets) == 0 {
 		return nil, fmt.Errorf("no assets to select from")
@@ -281,14 +511,218 @@ func SelectBestAsset(assets []*Asset) (*Asset, error) {
 	}
 
 	// Prefer x86_64/amd64 architecture
+	var x86 []*Asset
 	for _, asset := range candidates {
 		if asset.Arch == ArchX86_64 || asset.Arch == ArchAMD64 {
+			x86 = append(x86, asset)
+		}
+	}
+	if len(x86) > 0 {
+		candidates = x86
+	}
+
+	// Prefer glibc, keeping musl as a fallback
+	best := candidates[0]
+	for _, asset := range candidates[1:] {
+		if libcRank(asset.Libc) < libcRank(best.Libc) {
+			best = asset
+		}
+	}
+	return best, nil
+}
+
+// SelectArchAssets picks the best asset for each architecture a Linux
+// formula can target, x86_64 and arm64. Architectures without an asset are
+// left out of the result.
+func SelectArchAssets(assets []*Asset) map[Architecture]*Asset {
+	byArch := make(map[Architecture][]*Asset)
+	for _, asset := range assets {
+		switch asset.Arch {
+		case ArchX86_64, ArchAMD64:
+			byArch[ArchX86_64] = append(byArch[ArchX86_64], asset)
+		case ArchARM64:
+			byArch[ArchARM64] = append(byArch[ArchARM64], asset)
+		}
+	}
+
+	selected := make(map[Architecture]*Asset)
+	for arch, candidates := range byArch {
+		if best, err := SelectBestAsset(candidates); err == nil {
+			selected[arch] = best
+		}
+	}
+	return selected
+}
+
+// MaxMergedAssets limits how many extra archives --merge-assets will add
+const MaxMergedAssets = 4
+
+// SplitAssets returns the other Linux tarballs built for the selected
+// asset's architecture and libc, for releases that split a tool across
+// several archives (e.g. core and plugins). The selected archive in another
+// compression format is a duplicate, not a split, and is left out.
+func SplitAssets(assets []*Asset, selected *Asset) []*Asset {
+	if selected == nil || selected.Priority != PriorityTarball {
+		return nil
+	}
+
+	var split []*Asset
+	for _, asset := range assets {
+		if asset == selected || asset.Priority != PriorityTarball || asset.IsSource || asset.IsChecksum {
+			continue
+		}
+		if canonicalArch(asset.Arch) != canonicalArch(selected.Arch) || asset.Libc != selected.Libc {
+			continue
+		}
+		if archiveStem(asset.Name) == archiveStem(selected.Name) {
+			continue
+		}
+		split = append(split, asset)
+	}
+	return split
+}
+
+// SplitAssetsWarning describes the archives from SplitAssets that are left
+// out of the package, ending with hint on how to include them. It returns
+// "" when nothing was split off.
+func SplitAssetsWarning(split []*Asset, selected *Asset, hint string) string {
+	if len(split) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Release has %d other %s Linux archive(s) that are not included: %s (%s)",
+		len(split), selected.Arch, AssetNames(split), hint)
+}
+
+// AssetNames joins asset file names for messages
+func AssetNames(assets []*Asset) string {
+	names := make([]string, len(assets))
+	for i, asset := range assets {
+		names[i] = asset.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// canonicalArch treats amd64 and x86_64 as the same architecture
+func canonicalArch(arch Architecture) Architecture {
+	if arch == ArchAMD64 {
+		return ArchX86_64
+	}
+	return arch
+}
+
+// RunsOnHost reports whether a Linux binary for arch can run on this
+// machine. An unknown architecture is assumed to match.
+func RunsOnHost(arch Architecture) bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	if arch == ArchUnknown || arch == "" {
+		return true
+	}
+	host := map[string]Architecture{"amd64": ArchX86_64, "arm64": ArchARM64, "arm": ArchARM}[runtime.GOARCH]
+	return canonicalArch(arch) == host
+}
+
+// archiveStem returns a tarball's name without its extension
+func archiveStem(filename string) string {
+	for _, ext := range []string{".tar.gz", ".tar.xz", ".tar.bz2", ".tar.zst", ".tzst", ".tgz"} {
+		if stem, ok := strings.CutSuffix(filename, ext); ok {
+			return stem
+		}
+	}
+	return filename
+}
+
+// libcRank orders C libraries for selection (lower is better)
+// Unlabeled builds are usually glibc, so they rank ahead of musl
+func libcRank(libc Libc) int {
+	switch libc {
+	case LibcGNU:
+		return 0
+	case LibcMusl:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// SelectAssetByChoice picks an asset by its zero-based index in assets or by
+// exact file name, for a deterministic override of SelectBestAsset
+func SelectAssetByChoice(assets []*Asset, choice string) (*Asset, error) {
+	if len(assets) == 0 {
+		return nil, fmt.Errorf("no assets to select from")
+	}
+
+	if index, err := strconv.Atoi(choice); err == nil {
+		if index < 0 || index >= len(assets) {
+			return nil, fmt.Errorf("asset index %d out of range (0-%d)", index, len(assets)-1)
+		}
+		return assets[index], nil
+	}
+
+	for _, asset := range assets {
+		if asset.Name == choice {
 			return asset, nil
 		}
 	}
+	return nil, fmt.Errorf("no Linux asset named %q", choice)
+}
+
+// DefaultMaxAssetSize is the default --max-asset-size, in ParseSize form
+const DefaultMaxAssetSize = "1G"
+
+// FilterBySize removes assets larger than maxSize bytes
+// Assets with an unknown size (0) are kept. A maxSize of 0 disables the check.
+// Returns the kept assets and the skipped ones so callers can report them.
+func FilterBySize(assets []*Asset, maxSize int64) (kept, skipped []*Asset) {
+	for _, asset := range assets {
+		if maxSize > 0 && asset.Size > maxSize {
+			skipped = append(skipped, asset)
+			continue
+		}
+		kept = append(kept, asset)
+	}
+	return kept, skipped
+}
+
+// ParseSize parses a human-readable size like "500M", "1G", or "1048576"
+// Suffixes K, M, G, and T are binary multiples (1K = 1024 bytes).
+// An optional trailing "B" or "iB" is accepted ("500MB", "1GiB").
+// "0" means no limit; NaN, infinities, negative sizes, and sizes that round
+// to zero bytes or overflow are rejected.
+func ParseSize(s string) (int64, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	str = strings.TrimSuffix(str, "IB")
+	str = strings.TrimSuffix(str, "B")
+
+	multiplier := int64(1)
+	if str != "" {
+		switch str[len(str)-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			str = str[:len(str)-1]
+		}
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) || value < 0 {
+		return 0, fmt.Errorf("invalid size: %q (expected e.g. 500M, 1G)", s)
+	}
+
+	size := value * float64(multiplier)
+	if size >= math.MaxInt64 || (value > 0 && size < 1) {
+		return 0, fmt.Errorf("invalid size: %q (expected e.g. 500M, 1G)", s)
+	}
 
-	// Return the first candidate
-	return candidates[0], nil
+	return int64(size), nil
 }
 
 // NormalizePackageName normalizes a repository name to a package name
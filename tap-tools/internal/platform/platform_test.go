@@ -1,6 +1,10 @@
 package platform
 
 import (
+	"errors"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -14,6 +18,9 @@ func TestDetectPlatformFromFilename(t *testing.T) {
 		{"tool_ubuntu_amd64.deb", PlatformLinux},
 		{"program-debian.tar.xz", PlatformLinux},
 		{"binary-fedora-x86_64.rpm", PlatformLinux},
+		{"org.example.App.flatpak", PlatformLinux},
+		{"org.example.App.FLATPAK", PlatformLinux},
+		{"app-1.0.0-x86_64.appimage", PlatformLinux},
 		// Non-Linux (should be rejected/unknown)
 		{"app-macos-arm64.tar.gz", PlatformUnknown},
 		{"tool-darwin-x64.tar.gz", PlatformUnknown},
@@ -68,6 +75,10 @@ func TestDetectFormatFromFilename(t *testing.T) {
 		{"binary.deb", FormatDeb},
 		{"package.rpm", FormatRpm},
 		{"app.AppImage", FormatAppImage},
+		{"app-linux.zip", FormatZip},
+		{"org.example.app.flatpak", FormatFlatpak},
+		{"org.example.App.Flatpak", FormatFlatpak},
+		{"org.example.app.flatpakref", FormatUnknown},
 		{"unknown", FormatUnknown},
 	}
 
@@ -100,6 +111,17 @@ func TestDetectPlatform(t *testing.T) {
 				IsChecksum: false,
 			},
 		},
+		{
+			name:     "Linux zstd tarball",
+			filename: "tool-1.0.0-x86_64-linux.tar.zst",
+			want: &Asset{
+				Name:     "tool-1.0.0-x86_64-linux.tar.zst",
+				Platform: PlatformLinux,
+				Arch:     ArchX86_64,
+				Format:   FormatTarZst,
+				Priority: PriorityTarball,
+			},
+		},
 		{
 			name:     "Linux deb amd64",
 			filename: "app_1.0.0_amd64.deb",
@@ -182,6 +204,99 @@ func TestDetectPlatform(t *testing.T) {
 	}
 }
 
+func TestInstallerAssets(t *testing.T) {
+	tests := []struct {
+		filename      string
+		wantInstaller bool
+	}{
+		{"install.sh", true},
+		{"tool-linux-x86_64-installer.run", true},
+		{"setup.bin", true},
+		{"tool-linux-amd64.bin", false},
+		{"get-tool.sh", true},
+		{"tool-linux-x86_64.tar.gz", false},
+		{"tool-installer-linux.tar.gz", false},
+		{"tool_amd64.deb", false},
+		{"tool-linux-amd64", false},
+		{"setup", true},
+		{"setup-envtest-linux-amd64", false},
+		{"kubebuilder-installer-helper-linux-amd64", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			asset := DetectPlatform(tt.filename)
+			if asset.IsInstaller != tt.wantInstaller {
+				t.Errorf("DetectPlatform(%q).IsInstaller = %v, want %v", tt.filename, asset.IsInstaller, tt.wantInstaller)
+			}
+
+			reason := ExplainFilter(asset)
+			if tt.wantInstaller && reason != "installer script, not a package" {
+				t.Errorf("ExplainFilter(%q) = %q, want installer reason", tt.filename, reason)
+			}
+		})
+	}
+
+	assets := []*Asset{
+		DetectPlatform("install.sh"),
+		DetectPlatform("tool-linux-x86_64-installer.run"),
+		DetectPlatform("tool-linux-x86_64.tar.gz"),
+	}
+	filtered := FilterLinuxAssets(assets)
+	if len(filtered) != 1 || filtered[0].Name != "tool-linux-x86_64.tar.gz" {
+		t.Errorf("FilterLinuxAssets() kept %v, want only the tarball", filtered)
+	}
+
+	// A release shipping only a standalone .bin binary still has a candidate
+	filtered = FilterLinuxAssets([]*Asset{DetectPlatform("tool-linux-amd64.bin")})
+	if len(filtered) != 1 {
+		t.Errorf("FilterLinuxAssets() kept %v, want the .bin binary", filtered)
+	}
+}
+
+func TestCheckFlatpak(t *testing.T) {
+	tests := []struct {
+		name    string
+		assets  []string
+		wantErr bool
+	}{
+		{"Only a Flatpak bundle", []string{"App-x86_64.flatpak", "App-macos.dmg"}, true},
+		{"No Flatpak bundle", []string{"app-linux-x64.tar.gz"}, false},
+		{"No assets", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var assets []*Asset
+			for _, name := range tt.assets {
+				assets = append(assets, DetectPlatform(name))
+			}
+
+			// Bundles are never selected, so they cannot reach archive listing
+			for _, asset := range FilterLinuxAssets(assets) {
+				if asset.Format == FormatFlatpak {
+					t.Errorf("FilterLinuxAssets() kept %s", asset.Name)
+				}
+			}
+
+			err := CheckFlatpak(assets)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckFlatpak() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			if !errors.Is(err, ErrFlatpakBundle) || !strings.Contains(err.Error(), "flatpak install --bundle") {
+				t.Errorf("CheckFlatpak() error = %q, want ErrFlatpakBundle with install advice", err)
+			}
+		})
+	}
+
+	if reason := ExplainFilter(DetectPlatform("App-x86_64.flatpak")); reason != "Flatpak bundle, not supported yet" {
+		t.Errorf("ExplainFilter() = %q, want the Flatpak reason", reason)
+	}
+}
+
 func TestFilterLinuxAssets(t *testing.T) {
 	assets := []*Asset{
 		{Name: "app-linux-x64.tar.gz", Platform: PlatformLinux, Format: FormatTarGz, IsSource: false, IsChecksum: false},
@@ -212,6 +327,27 @@ func TestFilterLinuxAssets(t *testing.T) {
 	}
 }
 
+func TestDetectLibc(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     Libc
+	}{
+		{"ripgrep-14.0.0-x86_64-unknown-linux-musl.tar.gz", LibcMusl},
+		{"ripgrep-14.0.0-x86_64-unknown-linux-gnu.tar.gz", LibcGNU},
+		{"ripgrep-14.0.0-arm-unknown-linux-gnueabihf.tar.gz", LibcGNU},
+		{"tool_linux_amd64_glibc.tar.gz", LibcGNU},
+		{"tool-linux-amd64.tar.gz", LibcUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			if got := DetectPlatform(tt.filename).Libc; got != tt.want {
+				t.Errorf("DetectPlatform(%q).Libc = %v, want %v", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestSelectBestAsset(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -243,6 +379,33 @@ func TestSelectBestAsset(t *testing.T) {
 			want:    "app-linux-x64.tar.gz",
 			wantErr: false,
 		},
+		{
+			name: "Prefer gnu over musl",
+			assets: []*Asset{
+				DetectPlatform("ripgrep-14.0.0-x86_64-unknown-linux-musl.tar.gz"),
+				DetectPlatform("ripgrep-14.0.0-x86_64-unknown-linux-gnu.tar.gz"),
+			},
+			want:    "ripgrep-14.0.0-x86_64-unknown-linux-gnu.tar.gz",
+			wantErr: false,
+		},
+		{
+			name: "Prefer x86_64 musl over arm64 gnu",
+			assets: []*Asset{
+				DetectPlatform("ripgrep-14.0.0-aarch64-unknown-linux-gnu.tar.gz"),
+				DetectPlatform("ripgrep-14.0.0-x86_64-unknown-linux-musl.tar.gz"),
+			},
+			want:    "ripgrep-14.0.0-x86_64-unknown-linux-musl.tar.gz",
+			wantErr: false,
+		},
+		{
+			name: "Musl is kept as a fallback",
+			assets: []*Asset{
+				DetectPlatform("ripgrep-14.0.0-x86_64-unknown-linux-musl.tar.gz"),
+				DetectPlatform("ripgrep_14.0.0-1_amd64.deb"),
+			},
+			want:    "ripgrep-14.0.0-x86_64-unknown-linux-musl.tar.gz",
+			wantErr: false,
+		},
 		{
 			name: "Single asset",
 			assets: []*Asset{
@@ -267,6 +430,467 @@ func TestSelectBestAsset(t *testing.T) {
 	}
 }
 
+func TestSelectAssetByChoice(t *testing.T) {
+	assets := []*Asset{
+		{Name: "tool-linux-x86_64.tar.gz", Priority: PriorityTarball, Arch: ArchX86_64},
+		{Name: "tool-linux-musl.tar.gz", Priority: PriorityTarball, Arch: ArchX86_64},
+		{Name: "tool_amd64.deb", Priority: PriorityDeb, Arch: ArchX86_64},
+	}
+
+	tests := []struct {
+		name    string
+		assets  []*Asset
+		choice  string
+		want    string
+		wantErr string
+	}{
+		{"First index", assets, "0", "tool-linux-x86_64.tar.gz", ""},
+		{"Last index", assets, "2", "tool_amd64.deb", ""},
+		{"By name", assets, "tool-linux-musl.tar.gz", "tool-linux-musl.tar.gz", ""},
+		{"Index out of range", assets, "3", "", "out of range"},
+		{"Negative index", assets, "-1", "", "out of range"},
+		{"Name not found", assets, "tool-darwin.tar.gz", "", "no Linux asset named"},
+		{"Empty list", nil, "0", "", "no assets"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectAssetByChoice(tt.assets, tt.choice)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("SelectAssetByChoice() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SelectAssetByChoice() error = %v", err)
+			}
+			if got.Name != tt.want {
+				t.Errorf("SelectAssetByChoice() = %v, want %v", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterLinuxAssetsKeepsZstd(t *testing.T) {
+	assets := []*Asset{DetectPlatform("tool-1.0.0-linux-x86_64.tar.zst"), DetectPlatform("tool-1.0.0-darwin-x86_64.tar.zst")}
+
+	got := FilterLinuxAssets(assets)
+	if len(got) != 1 || got[0].Name != "tool-1.0.0-linux-x86_64.tar.zst" {
+		t.Errorf("FilterLinuxAssets() = %v, want [tool-1.0.0-linux-x86_64.tar.zst]", got)
+	}
+}
+
+func TestSelectArchAssets(t *testing.T) {
+	var assets []*Asset
+	for _, name := range []string{
+		"tool-1.0-x86_64-unknown-linux-musl.tar.gz",
+		"tool-1.0-x86_64-unknown-linux-gnu.tar.gz",
+		"tool-1.0-aarch64-unknown-linux-gnu.tar.gz",
+		"tool_1.0_arm64.deb",
+		"tool-1.0-armv7-unknown-linux-gnueabihf.tar.gz",
+	} {
+		assets = append(assets, DetectPlatform(name))
+	}
+
+	got := SelectArchAssets(assets)
+	want := map[Architecture]string{
+		ArchX86_64: "tool-1.0-x86_64-unknown-linux-gnu.tar.gz",
+		ArchARM64:  "tool-1.0-aarch64-unknown-linux-gnu.tar.gz",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("SelectArchAssets() returned %d architectures, want %d", len(got), len(want))
+	}
+	for arch, name := range want {
+		if got[arch] == nil || got[arch].Name != name {
+			t.Errorf("SelectArchAssets()[%s] = %v, want %s", arch, got[arch], name)
+		}
+	}
+
+	t.Run("Single architecture", func(t *testing.T) {
+		got := SelectArchAssets(assets[:2])
+		if len(got) != 1 || got[ArchX86_64] == nil {
+			t.Errorf("SelectArchAssets() = %v, want only x86_64", got)
+		}
+	})
+}
+
+func TestSplitAssets(t *testing.T) {
+	tests := []struct {
+		name     string
+		assets   []string
+		selected string
+		want     []string
+	}{
+		{
+			name:     "Core and plugins",
+			assets:   []string{"tool-core-linux-amd64.tar.gz", "tool-plugins-linux-amd64.tar.gz", "tool-core-linux-arm64.tar.gz", "tool-plugins-linux-arm64.tar.gz"},
+			selected: "tool-core-linux-amd64.tar.gz",
+			want:     []string{"tool-plugins-linux-amd64.tar.gz"},
+		},
+		{
+			name:     "x86_64 and amd64 are the same architecture",
+			assets:   []string{"tool-linux-x86_64.tar.gz", "tool-extras-linux-amd64.tar.xz"},
+			selected: "tool-linux-x86_64.tar.gz",
+			want:     []string{"tool-extras-linux-amd64.tar.xz"},
+		},
+		{
+			name:     "Other compression is a duplicate",
+			assets:   []string{"tool-linux-amd64.tar.gz", "tool-linux-amd64.tar.xz", "tool-linux-amd64.tar.zst"},
+			selected: "tool-linux-amd64.tar.gz",
+		},
+		{
+			name:     "musl build is an alternative",
+			assets:   []string{"tool-x86_64-unknown-linux-gnu.tar.gz", "tool-x86_64-unknown-linux-musl.tar.gz"},
+			selected: "tool-x86_64-unknown-linux-gnu.tar.gz",
+		},
+		{
+			name:     "Packages are not tarballs",
+			assets:   []string{"tool-linux-amd64.tar.gz", "tool_amd64.deb", "tool-linux-amd64.AppImage"},
+			selected: "tool-linux-amd64.tar.gz",
+		},
+		{
+			name:     "Selected asset is not a tarball",
+			assets:   []string{"tool_amd64.deb", "tool-plugins_amd64.deb"},
+			selected: "tool_amd64.deb",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var assets []*Asset
+			var selected *Asset
+			for _, name := range tt.assets {
+				asset := DetectPlatform(name)
+				if name == tt.selected {
+					selected = asset
+				}
+				assets = append(assets, asset)
+			}
+
+			var got []string
+			for _, asset := range SplitAssets(assets, selected) {
+				got = append(got, asset.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitAssets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitAssetsWarning(t *testing.T) {
+	selected := DetectPlatform("tool-core-linux-amd64.tar.gz")
+	split := []*Asset{DetectPlatform("tool-plugins-linux-amd64.tar.gz"), DetectPlatform("tool-docs-linux-amd64.tar.gz")}
+
+	want := "Release has 2 other x86_64 Linux archive(s) that are not included: tool-plugins-linux-amd64.tar.gz, tool-docs-linux-amd64.tar.gz (add them with --merge-assets)"
+	if got := SplitAssetsWarning(split, selected, "add them with --merge-assets"); got != want {
+		t.Errorf("SplitAssetsWarning() = %q, want %q", got, want)
+	}
+	if got := SplitAssetsWarning(nil, selected, "add them with --merge-assets"); got != "" {
+		t.Errorf("SplitAssetsWarning() = %q, want no warning", got)
+	}
+}
+
+func TestSelectAppImage(t *testing.T) {
+	var assets []*Asset
+	for _, name := range []string{
+		"App-1.0.0-x86_64.AppImage",
+		"App-1.0.0-arm64.AppImage",
+		"App-1.0.0.dmg",
+		"App-Setup-1.0.0.exe",
+		"App-1.0.0-x86_64.AppImage.zsync",
+	} {
+		assets = append(assets, DetectPlatform(name))
+	}
+
+	linux := FilterLinuxAssets(assets)
+	if len(linux) != 2 {
+		t.Fatalf("FilterLinuxAssets() kept %d assets, want both AppImages", len(linux))
+	}
+	best, err := SelectBestAsset(linux)
+	if err != nil {
+		t.Fatalf("SelectBestAsset() error = %v", err)
+	}
+	if best.Name != "App-1.0.0-x86_64.AppImage" || best.Format != FormatAppImage {
+		t.Errorf("SelectBestAsset() = %s (%s), want the x86_64 AppImage", best.Name, best.Format)
+	}
+}
+
+func TestCheckReleaseAssets(t *testing.T) {
+	tests := []struct {
+		name     string
+		assets   []*Asset
+		isCask   bool
+		wantErr  bool
+		wantHint string
+	}{
+		{"Cask with no assets", nil, true, true, "casks require a prebuilt asset"},
+		{"Formula with no assets", []*Asset{}, false, true, "build from the source tarball"},
+		{"Cask with only non-Linux assets", []*Asset{DetectPlatform("tool-darwin-arm64.tar.gz")}, true, false, ""},
+		{"Formula with assets", []*Asset{DetectPlatform("tool-linux-amd64.tar.gz")}, false, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckReleaseAssets(tt.assets, tt.isCask)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckReleaseAssets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				return
+			}
+			if !errors.Is(err, ErrNoAssets) {
+				t.Errorf("CheckReleaseAssets() error = %v, want ErrNoAssets", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantHint) {
+				t.Errorf("CheckReleaseAssets() error = %q, want hint %q", err, tt.wantHint)
+			}
+		})
+	}
+}
+
+func TestLinuxCandidates(t *testing.T) {
+	detect := func(names ...string) []*Asset {
+		var assets []*Asset
+		for _, name := range names {
+			asset := DetectPlatform(name)
+			asset.Size = 10 << 20
+			if strings.Contains(name, "huge") {
+				asset.Size = 2 << 30
+			}
+			assets = append(assets, asset)
+		}
+		return assets
+	}
+	names := func(assets []*Asset) []string {
+		var names []string
+		for _, asset := range assets {
+			names = append(names, asset.Name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name        string
+		assets      []*Asset
+		wantErr     error
+		wantKept    []string
+		wantSkipped []string
+	}{
+		{"No assets", nil, ErrNoAssets, nil, nil},
+		{"Only other platforms", detect("tool-darwin-arm64.tar.gz", "tool-windows-amd64.zip"), ErrNoLinuxAssets, nil, nil},
+		{"Only a Flatpak bundle", detect("tool-darwin-arm64.tar.gz", "tool.flatpak"), ErrFlatpakBundle, nil, nil},
+		{"Linux assets", detect("tool-linux-amd64.tar.gz", "tool-darwin-arm64.tar.gz", "checksums.txt"), nil, []string{"tool-linux-amd64.tar.gz"}, nil},
+		{"Oversized asset skipped", detect("tool-linux-amd64.tar.gz", "tool-linux-amd64-huge.qcow2.tar.gz"), nil, []string{"tool-linux-amd64.tar.gz"}, []string{"tool-linux-amd64-huge.qcow2.tar.gz"}},
+		{"All oversized", detect("tool-linux-amd64-huge.tar.gz"), nil, nil, []string{"tool-linux-amd64-huge.tar.gz"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := NewDecision("owner/tool", "v1.0.0")
+			decision.AddCandidates(tt.assets)
+
+			kept, skipped, err := LinuxCandidates(tt.assets, 1<<30, decision)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("LinuxCandidates() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := names(kept); !reflect.DeepEqual(got, tt.wantKept) {
+				t.Errorf("LinuxCandidates() kept = %v, want %v", got, tt.wantKept)
+			}
+			if got := names(skipped); !reflect.DeepEqual(got, tt.wantSkipped) {
+				t.Errorf("LinuxCandidates() skipped = %v, want %v", got, tt.wantSkipped)
+			}
+			for _, c := range decision.Candidates {
+				for _, name := range tt.wantSkipped {
+					if c.Name == name && c.Rejected != "exceeds --max-asset-size" {
+						t.Errorf("Decision rejected %s as %q, want exceeds --max-asset-size", c.Name, c.Rejected)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSelectAsset(t *testing.T) {
+	assets := []*Asset{
+		DetectPlatform("tool-linux-amd64.deb"),
+		DetectPlatform("tool-linux-amd64.tar.gz"),
+		DetectPlatform("tool-darwin-arm64.tar.gz"),
+	}
+
+	tests := []struct {
+		name       string
+		choice     AssetChoice
+		wantAsset  string
+		wantReason string
+		wantErr    bool
+	}{
+		{"Best asset", AssetChoice{}, "tool-linux-amd64.tar.gz", "Priority 1", false},
+		{"Picked by name", AssetChoice{Pick: "tool-linux-amd64.deb"}, "tool-linux-amd64.deb", "--select-asset", false},
+		{"Unknown pick", AssetChoice{Pick: "tool.rpm"}, "", "", true},
+		{"Asset URL", AssetChoice{URL: "https://example.com/tool-linux-arm64.tar.gz"}, "tool-linux-arm64.tar.gz", "--asset-url", false},
+		{"All oversized", AssetChoice{MaxSize: 1}, "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, asset := range assets {
+				asset.Size = 10 << 20
+			}
+			decision := NewDecision("owner/tool", "v1.0.0")
+			decision.AddCandidates(assets)
+
+			selection, err := SelectAsset(assets, tt.choice, decision)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SelectAsset() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if selection.Asset.Name != tt.wantAsset || selection.Reason != tt.wantReason {
+				t.Errorf("SelectAsset() = %s (%s), want %s (%s)", selection.Asset.Name, selection.Reason, tt.wantAsset, tt.wantReason)
+			}
+			if decision.Selected != tt.wantAsset {
+				t.Errorf("Decision selected %s, want %s", decision.Selected, tt.wantAsset)
+			}
+		})
+	}
+}
+
+func TestAssetFromURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		wantName   string
+		wantFormat Format
+		wantArch   Architecture
+		wantErr    bool
+	}{
+		{
+			name:       "Release asset",
+			url:        "https://github.com/o/tool/releases/download/v1.0.0/tool-1.0.0-linux-amd64.tar.gz",
+			wantName:   "tool-1.0.0-linux-amd64.tar.gz",
+			wantFormat: FormatTarGz,
+			wantArch:   ArchX86_64,
+		},
+		{
+			name:       "Asset a Linux filter would reject",
+			url:        "https://downloads.example.com/tool/tool-1.0.0.tar.xz?download=1",
+			wantName:   "tool-1.0.0.tar.xz",
+			wantFormat: FormatTarXz,
+			wantArch:   ArchUnknown,
+		},
+		{
+			name:       "Escaped filename",
+			url:        "https://example.com/files/tool%201.0.deb",
+			wantName:   "tool 1.0.deb",
+			wantFormat: FormatDeb,
+			wantArch:   ArchUnknown,
+		},
+		{name: "Not HTTP", url: "ftp://example.com/tool.tar.gz", wantErr: true},
+		{name: "No filename", url: "https://example.com/", wantErr: true},
+		{name: "Relative path", url: "tool.tar.gz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asset, err := AssetFromURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AssetFromURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if asset.Name != tt.wantName {
+				t.Errorf("Name = %q, want %q", asset.Name, tt.wantName)
+			}
+			if asset.DownloadURL != tt.url {
+				t.Errorf("DownloadURL = %q, want %q", asset.DownloadURL, tt.url)
+			}
+			if asset.Format != tt.wantFormat {
+				t.Errorf("Format = %q, want %q", asset.Format, tt.wantFormat)
+			}
+			if asset.Arch != tt.wantArch {
+				t.Errorf("Arch = %q, want %q", asset.Arch, tt.wantArch)
+			}
+		})
+	}
+}
+
+func TestFilterBySize(t *testing.T) {
+	assets := []*Asset{
+		{Name: "app-linux-x64.tar.gz", Priority: PriorityTarball, Arch: ArchX86_64, Size: 4 << 30},
+		{Name: "app_amd64.deb", Priority: PriorityDeb, Arch: ArchX86_64, Size: 50 << 20},
+		{Name: "app-unknown-size.AppImage", Priority: PriorityOther, Arch: ArchX86_64},
+	}
+
+	limit, err := ParseSize(DefaultMaxAssetSize)
+	if err != nil {
+		t.Fatalf("ParseSize(DefaultMaxAssetSize) error = %v", err)
+	}
+	kept, skipped := FilterBySize(assets, limit)
+	if len(kept) != 2 {
+		t.Fatalf("FilterBySize() kept %d assets, want 2", len(kept))
+	}
+	if len(skipped) != 1 || skipped[0].Name != "app-linux-x64.tar.gz" {
+		t.Errorf("FilterBySize() should skip the oversized tarball, got %v", skipped)
+	}
+
+	// The oversized tarball would win on priority, so make sure it is not selected
+	best, err := SelectBestAsset(kept)
+	if err != nil {
+		t.Fatalf("SelectBestAsset() error = %v", err)
+	}
+	if best.Name != "app_amd64.deb" {
+		t.Errorf("SelectBestAsset() = %v, want app_amd64.deb", best.Name)
+	}
+
+	// A limit of 0 disables the check
+	kept, skipped = FilterBySize(assets, 0)
+	if len(kept) != 3 || len(skipped) != 0 {
+		t.Errorf("FilterBySize() with no limit should keep all assets")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"1048576", 1 << 20, false},
+		{"500M", 500 << 20, false},
+		{"500MB", 500 << 20, false},
+		{"1G", 1 << 30, false},
+		{"1GiB", 1 << 30, false},
+		{"1.5G", 3 << 29, false},
+		{"64k", 64 << 10, false},
+		{"", 0, true},
+		{"lots", 0, true},
+		{"0", 0, false},
+		{"-1G", 0, true},
+		{"NaN", 0, true},
+		{"Inf", 0, true},
+		{"-Inf", 0, true},
+		{"1e30T", 0, true},
+		{"0.1", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseSize(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestNormalizePackageName(t *testing.T) {
 	tests := []struct {
 		input string
@@ -310,3 +934,28 @@ func TestEnsureLinuxSuffix(t *testing.T) {
 		})
 	}
 }
+
+func TestRunsOnHost(t *testing.T) {
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		t.Skip("expectations are for a linux/amd64 host")
+	}
+
+	tests := []struct {
+		arch Architecture
+		want bool
+	}{
+		{ArchX86_64, true},
+		{ArchAMD64, true},
+		{ArchUnknown, true},
+		{ArchARM64, false},
+		{ArchARM, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.arch), func(t *testing.T) {
+			if got := RunsOnHost(tt.arch); got != tt.want {
+				t.Errorf("RunsOnHost(%s) = %v, want %v", tt.arch, got, tt.want)
+			}
+		})
+	}
+}
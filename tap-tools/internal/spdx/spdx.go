@@ -0,0 +1,99 @@
+// Package spdx guesses a license's SPDX identifier from the text of a
+// license file, for repositories whose license GitHub cannot classify.
+package spdx
+
+import "strings"
+
+// LicenseFiles are the conventional license file names, in lookup order
+var LicenseFiles = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "COPYING"}
+
+// titleLength bounds how far into the text a license title is looked for,
+// enough to skip a short copyright line above it
+const titleLength = 200
+
+// matcher identifies a license by its title near the start of the text, or
+// by phrases that all appear in its text
+type matcher struct {
+	id       string
+	title    string
+	phrases  []string
+	excludes []string // Phrases that rule the license out
+}
+
+// matchers are checked in order. Licenses that name each other in their
+// body (the GPL points to the AGPL and LGPL, the MPL lists all three as
+// secondary licenses) are matched on their title line instead.
+var matchers = []matcher{
+	{id: "AGPL-3.0-only", title: "gnu affero general public license version 3"},
+	{id: "LGPL-3.0-only", title: "gnu lesser general public license version 3"},
+	{id: "LGPL-2.1-only", title: "gnu lesser general public license version 2.1"},
+	{id: "GPL-3.0-only", title: "gnu general public license version 3"},
+	{id: "GPL-2.0-only", title: "gnu general public license version 2"},
+	{id: "Apache-2.0", title: "apache license version 2.0"},
+	{id: "MPL-2.0", title: "mozilla public license version 2.0"},
+	{id: "MIT", phrases: []string{"permission is hereby granted, free of charge", "the above copyright notice and this permission notice shall be included"}},
+	{id: "ISC", phrases: []string{"permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted"}},
+	{id: "BSD-3-Clause", phrases: []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{id: "BSD-2-Clause", phrases: []string{"redistribution and use in source and binary forms"}, excludes: []string{"neither the name", "advertising materials"}},
+	{id: "Unlicense", phrases: []string{"this is free and unencumbered software released into the public domain"}},
+}
+
+// Guess returns the SPDX identifier of the license text, or "" if it does
+// not look like a well-known license. Matching ignores case and line
+// wrapping; it is a heuristic, so callers should flag the result as guessed.
+func Guess(text string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(text), " "))
+
+	head := normalized[:min(len(normalized), titleLength)]
+
+	for _, m := range matchers {
+		if m.title != "" {
+			if strings.Contains(head, m.title) {
+				return m.id
+			}
+			continue
+		}
+		if containsAll(normalized, m.phrases) && !containsAny(normalized, m.excludes) {
+			return m.id
+		}
+	}
+	return ""
+}
+
+// ContentGetter fetches a file from a repository, as github.Forge does
+type ContentGetter interface {
+	GetFileContent(owner, repo, path string) (string, error)
+}
+
+// GuessRepo matches the first license file found among paths against
+// well-known license texts, returning the SPDX ID and the file it came from
+func GuessRepo(client ContentGetter, owner, repo string, paths ...string) (string, string) {
+	for _, path := range paths {
+		text, err := client.GetFileContent(owner, repo, path)
+		if err != nil {
+			continue
+		}
+		return Guess(text), path
+	}
+	return "", ""
+}
+
+// containsAll reports whether s contains every phrase
+func containsAll(s string, phrases []string) bool {
+	for _, p := range phrases {
+		if !strings.Contains(s, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsAny reports whether s contains at least one phrase
+func containsAny(s string, phrases []string) bool {
+	for _, p := range phrases {
+		if strings.Contains(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package spdx
+
+import (
+	"errors"
+	"testing"
+)
+
+const mitText = `MIT License
+
+Copyright (c) 2024 Example Author
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND.`
+
+const apacheText = `
+                                 Apache License
+                           Version 2.0, January 2004
+                        http://www.apache.org/licenses/
+
+   TERMS AND CONDITIONS FOR USE, REPRODUCTION, AND DISTRIBUTION
+
+   1. Definitions.
+
+      "License" shall mean the terms and conditions for use, reproduction,
+      and distribution as defined by Sections 1 through 9 of this document.`
+
+const gpl3Text = `                    GNU GENERAL PUBLIC LICENSE
+                       Version 3, 29 June 2007
+
+ Copyright (C) 2007 Free Software Foundation, Inc. <https://fsf.org/>
+ Everyone is permitted to copy and distribute verbatim copies
+ of this license document, but changing it is not allowed.
+
+                            Preamble
+
+  The GNU General Public License is a free, copyleft license for
+software and other kinds of works.`
+
+// gpl3FullText quotes the sections of the GPL that name the AGPL and LGPL
+const gpl3FullText = gpl3Text + `
+
+  13. Use with the GNU Affero General Public License.
+
+  Notwithstanding any other provision of this License, you have
+permission to link or combine any covered work with a work licensed
+under version 3 of the GNU Affero General Public License into a single
+combined work, and to convey the resulting work.
+
+But first, please read
+<https://www.gnu.org/licenses/why-not-lgpl.html>. If this is what you want
+to do, use the GNU Lesser General Public License instead of this License.`
+
+const mpl2Text = `Mozilla Public License Version 2.0
+==================================
+
+1. Definitions
+--------------
+
+1.12. "Secondary License"
+    means either the GNU General Public License, Version 2.0, the GNU
+    Lesser General Public License, Version 2.1, the GNU Affero General
+    Public License, Version 3.0, or any later versions of those
+    licenses.`
+
+const gpl2Text = `                    GNU GENERAL PUBLIC LICENSE
+                       Version 2, June 1991
+
+ Copyright (C) 1989, 1991 Free Software Foundation, Inc.,
+ 51 Franklin Street, Fifth Floor, Boston, MA 02110-1301 USA`
+
+const lgpl3Text = `                   GNU LESSER GENERAL PUBLIC LICENSE
+                       Version 3, 29 June 2007
+
+  This version of the GNU Lesser General Public License incorporates
+the terms and conditions of version 3 of the GNU General Public
+License, supplemented by the additional permissions listed below.`
+
+const bsd3Text = `Copyright (c) 2020, Example Author
+All rights reserved.
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.
+
+3. Neither the name of the copyright holder nor the names of its
+   contributors may be used to endorse or promote products derived from
+   this software without specific prior written permission.`
+
+const bsd2Text = `Copyright (c) 2020, Example Author
+
+Redistribution and use in source and binary forms, with or without
+modification, are permitted provided that the following conditions are met:
+
+1. Redistributions of source code must retain the above copyright notice, this
+   list of conditions and the following disclaimer.
+
+2. Redistributions in binary form must reproduce the above copyright notice,
+   this list of conditions and the following disclaimer in the documentation
+   and/or other materials provided with the distribution.`
+
+const bsd4Text = bsd2Text + `
+
+3. All advertising materials mentioning features or use of this software
+   must display the following acknowledgement.`
+
+func TestGuess(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"MIT", mitText, "MIT"},
+		{"Apache 2.0", apacheText, "Apache-2.0"},
+		{"GPL 3.0", gpl3Text, "GPL-3.0-only"},
+		{"GPL 3.0 full text names the AGPL and LGPL", gpl3FullText, "GPL-3.0-only"},
+		{"GPL 3.0 below a copyright line", "Copyright (C) 2024 Example Author\n\n" + gpl3Text, "GPL-3.0-only"},
+		{"GPL 2.0", gpl2Text, "GPL-2.0-only"},
+		{"MPL 2.0 lists secondary licenses", mpl2Text, "MPL-2.0"},
+		{"LGPL 3.0 mentions the GPL", lgpl3Text, "LGPL-3.0-only"},
+		{"BSD 3-clause", bsd3Text, "BSD-3-Clause"},
+		{"BSD 2-clause", bsd2Text, "BSD-2-Clause"},
+		{"BSD 4-clause is not guessed", bsd4Text, ""},
+		{"Rewrapped and uppercased MIT", "PERMISSION IS HEREBY GRANTED,\nFREE OF CHARGE, to any person. THE ABOVE COPYRIGHT NOTICE AND THIS\nPERMISSION NOTICE SHALL BE INCLUDED in all copies.", "MIT"},
+		{"Custom license", "Copyright (c) 2024 Example Corp. All rights reserved. Internal use only.", ""},
+		{"Empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Guess(tt.text); got != tt.want {
+				t.Errorf("Guess() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeRepo serves file contents by path
+type fakeRepo map[string]string
+
+func (r fakeRepo) GetFileContent(owner, repo, path string) (string, error) {
+	text, ok := r[path]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return text, nil
+}
+
+func TestGuessRepo(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    fakeRepo
+		wantID   string
+		wantPath string
+	}{
+		{"First license file found", fakeRepo{"COPYING": gpl2Text, "LICENSE.md": mitText}, "MIT", "LICENSE.md"},
+		{"Unrecognized text keeps the path", fakeRepo{"LICENSE": "All rights reserved."}, "", "LICENSE"},
+		{"No license file", fakeRepo{}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, path := GuessRepo(tt.files, "owner", "repo", LicenseFiles...)
+			if id != tt.wantID || path != tt.wantPath {
+				t.Errorf("GuessRepo() = %q, %q, want %q, %q", id, path, tt.wantID, tt.wantPath)
+			}
+		})
+	}
+}
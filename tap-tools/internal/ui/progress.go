@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// progressWidth is the width of the bar in cells
+const progressWidth = 30
+
+// isTerminal reports whether w is an interactive terminal
+var isTerminal = func(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
+
+// Progress draws a download progress bar, redrawn in place on one line
+type Progress struct {
+	w       io.Writer
+	label   string
+	percent int // Last drawn percentage, -1 before the first draw
+}
+
+// NewProgress returns a progress bar for a download, or nil when progress
+// output is quiet or not a terminal. A nil *Progress draws nothing, so
+// callers can use it unconditionally.
+func NewProgress(label string) *Progress {
+	w := progress()
+	if w == nil || !isTerminal(w) {
+		return nil
+	}
+	return &Progress{w: w, label: label, percent: -1}
+}
+
+// Update redraws the bar with the bytes downloaded so far; it matches
+// checksum.ProgressFunc. Nothing is drawn when the total is unknown.
+func (p *Progress) Update(downloaded, total int64) {
+	if p == nil || total <= 0 {
+		return
+	}
+
+	// Only redraw when the bar visibly changes
+	percent := int(min(downloaded*100/total, 100))
+	if percent == p.percent {
+		return
+	}
+	p.percent = percent
+
+	filled := percent * progressWidth / 100
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", progressWidth-filled)
+	fmt.Fprintf(p.w, "\r%s %s %3d%% (%.1f/%.1f MB)", p.label, infoStyle.Render(bar), percent,
+		float64(downloaded)/(1024*1024), float64(total)/(1024*1024))
+}
+
+// Done clears the bar so the next line starts on a clean line
+func (p *Progress) Done() {
+	if p == nil || p.percent < 0 {
+		return
+	}
+	fmt.Fprint(p.w, "\r\033[K")
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// fakeTerminal makes isTerminal report tty for the rest of the test
+func fakeTerminal(t *testing.T, tty bool) {
+	t.Helper()
+	orig := isTerminal
+	t.Cleanup(func() { isTerminal = orig })
+	isTerminal = func(io.Writer) bool { return tty }
+}
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		tty      bool
+		total    int64
+		wantBar  bool
+		wantNone bool // NewProgress returns nil
+	}{
+		{"Terminal with Content-Length", Options{}, true, 4 << 20, true, false},
+		{"Unknown total", Options{}, true, -1, false, false},
+		{"Not a terminal", Options{}, false, 4 << 20, false, true},
+		{"Quiet", Options{Quiet: true}, true, 4 << 20, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _ := capture(t, tt.opts)
+			fakeTerminal(t, tt.tty)
+
+			bar := NewProgress("Downloading")
+			if (bar == nil) != tt.wantNone {
+				t.Fatalf("NewProgress() = %v, want nil: %v", bar, tt.wantNone)
+			}
+			for _, n := range []int64{0, 1 << 20, 1<<20 + 1, 4 << 20} {
+				bar.Update(n, tt.total)
+			}
+			bar.Done()
+
+			got := out.String()
+			if !tt.wantBar {
+				if got != "" {
+					t.Errorf("progress output = %q, want none", got)
+				}
+				return
+			}
+			// 0%, 25% and 100%; the extra byte does not change the bar
+			if n := strings.Count(got, "\r"); n != 4 {
+				t.Errorf("progress output has %d redraws, want 3 plus the clear: %q", n, got)
+			}
+			if !strings.Contains(got, " 25% (1.0/4.0 MB)") || !strings.Contains(got, "100% (4.0/4.0 MB)") {
+				t.Errorf("progress output = %q, want 25%% and 100%% with sizes", got)
+			}
+			if !strings.HasSuffix(got, "\r\033[K") {
+				t.Errorf("progress output = %q, want the bar cleared", got)
+			}
+		})
+	}
+}
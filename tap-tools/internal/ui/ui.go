@@ -0,0 +1,148 @@
+// Package ui provides terminal output helpers shared by the tap-tools CLIs.
+//
+// Progress output is gated by the --quiet and --json modes: quiet mode
+// suppresses everything except warnings and errors, and JSON mode moves
+// progress output to stderr so stdout stays machine-readable. Commands
+// that print generated files to stdout move progress to stderr the same way.
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Options controls how output is rendered
+type Options struct {
+	NoColor bool // Disable styling (also honors NO_COLOR)
+	Quiet   bool // Only print warnings and errors
+	JSON    bool // Print progress to stderr, leaving stdout for JSON
+	Stderr  bool // Print progress to stderr, leaving stdout for generated files
+}
+
+var (
+	// Styles for pretty output
+	titleStyle     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	successStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	errorStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true)
+	infoStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
+	warnStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	sectionStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("13")).Bold(true)
+	highlightStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Bold(true)
+)
+
+var (
+	opts   Options
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+// ColorEnabled reports whether styled output should be used
+// Color is disabled by the --no-color flag or a non-empty NO_COLOR
+// environment variable (see https://no-color.org)
+func ColorEnabled(noColorFlag bool) bool {
+	if noColorFlag {
+		return false
+	}
+	return os.Getenv("NO_COLOR") == ""
+}
+
+// Configure applies output options for the current process
+// Styles render as plain text when color is not enabled
+func Configure(o Options) {
+	opts = o
+	if !ColorEnabled(o.NoColor) {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// Quiet reports whether quiet mode is enabled
+func Quiet() bool {
+	return opts.Quiet
+}
+
+// progress returns the writer for progress output, or nil in quiet mode
+func progress() io.Writer {
+	if opts.Quiet {
+		return nil
+	}
+	if opts.JSON || opts.Stderr {
+		return stderr
+	}
+	return stdout
+}
+
+// render writes a rendered line to the progress writer
+func render(style lipgloss.Style, msg string) {
+	if w := progress(); w != nil {
+		fmt.Fprintln(w, style.Render(msg))
+	}
+}
+
+// Title prints a step heading
+func Title(msg string) {
+	render(titleStyle, msg)
+}
+
+// Section prints a section divider preceded by a blank line
+func Section(msg string) {
+	if w := progress(); w != nil {
+		fmt.Fprintln(w)
+	}
+	render(sectionStyle, "━━━ "+msg+" ━━━")
+}
+
+// Success prints a completed step
+func Success(msg string) {
+	render(successStyle, "✓ "+msg)
+}
+
+// Info prints a detail or progress line
+func Info(msg string) {
+	render(infoStyle, "  "+msg)
+}
+
+// Highlight prints emphasized text such as a summary heading
+func Highlight(msg string) {
+	render(highlightStyle, msg)
+}
+
+// Warn prints a warning; warnings are shown even in quiet mode
+func Warn(msg string) {
+	w := progress()
+	if w == nil {
+		w = stderr
+	}
+	fmt.Fprintln(w, warnStyle.Render("⚠ "+msg))
+}
+
+// Error prints an error to stderr; errors are always shown
+func Error(msg string) {
+	fmt.Fprintln(stderr, errorStyle.Render("Error: "+msg))
+}
+
+// Printf prints unstyled progress output
+func Printf(format string, args ...any) {
+	if w := progress(); w != nil {
+		fmt.Fprintf(w, format, args...)
+	}
+}
+
+// Println prints an unstyled progress line
+func Println(args ...any) {
+	if w := progress(); w != nil {
+		fmt.Fprintln(w, args...)
+	}
+}
+
+// Writer returns the writer used for progress output
+// Returns io.Discard in quiet mode
+func Writer() io.Writer {
+	if w := progress(); w != nil {
+		return w
+	}
+	return io.Discard
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// capture redirects output to buffers and resets options after the test
+func capture(t *testing.T, o Options) (*bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+
+	origProfile := lipgloss.ColorProfile()
+	origOpts, origStdout, origStderr := opts, stdout, stderr
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(origProfile)
+		opts, stdout, stderr = origOpts, origStdout, origStderr
+	})
+
+	var out, errOut bytes.Buffer
+	stdout, stderr = &out, &errOut
+
+	t.Setenv("NO_COLOR", "1")
+	Configure(o)
+
+	return &out, &errOut
+}
+
+func TestColorEnabled(t *testing.T) {
+	tests := []struct {
+		name        string
+		noColorEnv  string
+		noColorFlag bool
+		want        bool
+	}{
+		{"Default", "", false, true},
+		{"NO_COLOR set", "1", false, false},
+		{"Flag set", "", true, false},
+		{"Both set", "1", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColorEnv)
+			if got := ColorEnabled(tt.noColorFlag); got != tt.want {
+				t.Errorf("ColorEnabled(%v) = %v, want %v", tt.noColorFlag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigureNoColor(t *testing.T) {
+	original := lipgloss.ColorProfile()
+	t.Cleanup(func() { lipgloss.SetColorProfile(original) })
+
+	// Force a color profile so the test does not depend on the terminal
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Setenv("NO_COLOR", "1")
+	Configure(Options{})
+
+	style := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10"))
+	if got := style.Render("✓ Done"); got != "✓ Done" {
+		t.Errorf("Render() with NO_COLOR = %q, want plain text", got)
+	}
+}
+
+func TestDefaultOutput(t *testing.T) {
+	out, errOut := capture(t, Options{})
+
+	Title("🔍 Fetching")
+	Section("Summary")
+	Success("Created")
+	Info("Homepage: https://example.com")
+	Warn("Low rate limit")
+	Error("boom")
+
+	want := "🔍 Fetching\n\n━━━ Summary ━━━\n✓ Created\n  Homepage: https://example.com\n⚠ Low rate limit\n"
+	if out.String() != want {
+		t.Errorf("stdout = %q, want %q", out.String(), want)
+	}
+	if errOut.String() != "Error: boom\n" {
+		t.Errorf("stderr = %q, want %q", errOut.String(), "Error: boom\n")
+	}
+}
+
+func TestQuietOutput(t *testing.T) {
+	out, errOut := capture(t, Options{Quiet: true})
+
+	Title("🔍 Fetching")
+	Section("Summary")
+	Success("Created")
+	Info("Homepage")
+	Printf("plain %d\n", 1)
+	Warn("Low rate limit")
+	Error("boom")
+
+	if out.Len() != 0 {
+		t.Errorf("Quiet mode should not write to stdout, got %q", out.String())
+	}
+	if !strings.Contains(errOut.String(), "⚠ Low rate limit") {
+		t.Error("Quiet mode should still show warnings")
+	}
+	if !strings.Contains(errOut.String(), "Error: boom") {
+		t.Error("Quiet mode should still show errors")
+	}
+	if Writer() == nil {
+		t.Error("Writer() should never be nil")
+	}
+}
+
+func TestStderrOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"JSON", Options{JSON: true}},
+		{"Generated files on stdout", Options{Stderr: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, errOut := capture(t, tt.opts)
+
+			Success("Created")
+			Info("Homepage")
+			Warn("Low rate limit")
+
+			if out.Len() != 0 {
+				t.Errorf("stdout should stay clean, got %q", out.String())
+			}
+			for _, want := range []string{"✓ Created", "  Homepage", "⚠ Low rate limit"} {
+				if !strings.Contains(errOut.String(), want) {
+					t.Errorf("progress %q should go to stderr", want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package validate
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// FormatResult lists the files brew style --fix changed, or in check mode
+// the files brew style reported offenses in
+type FormatResult struct {
+	Changed []string
+	Check   bool // Files were only checked, not fixed
+}
+
+// style runs brew style over paths, with --fix when fix is set, and
+// returns its report; replaced in tests
+var style = func(fix bool, paths []string) (string, error) {
+	var report bytes.Buffer
+	err := runStyle(io.MultiWriter(os.Stdout, &report), fix, paths...)
+	return report.String(), err
+}
+
+// FindRepoRoot returns the top level of the git repository holding the tap
+func FindRepoRoot() (string, error) {
+	output, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// TapFiles returns the formula and cask files of the tap at repoRoot
+func TapFiles(repoRoot string) ([]string, error) {
+	var files []string
+	for _, dir := range []string{"Formula", "Casks"} {
+		matches, err := filepath.Glob(filepath.Join(repoRoot, dir, "*.rb"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to find files in %s: %w", dir, err)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// FormatFiles runs brew style --fix over files in one pass and reports
+// which ones it changed. Check mode runs brew style without --fix, leaving
+// the files untouched, and reports the files with offenses (for CI).
+// Offenses brew cannot fix are returned as an error alongside the result.
+func FormatFiles(files []string, check bool) (*FormatResult, error) {
+	result := &FormatResult{Check: check}
+	if len(files) == 0 {
+		return result, nil
+	}
+
+	if check {
+		report, err := style(false, files)
+		for _, file := range files {
+			if strings.Contains(report, tapPath(file)) {
+				result.Changed = append(result.Changed, file)
+			}
+		}
+		// brew style fails when it finds offenses; only a failure without
+		// any is an error
+		if err != nil && len(result.Changed) == 0 {
+			return nil, fmt.Errorf("brew style failed: %w", err)
+		}
+		return result, nil
+	}
+
+	before := make(map[string][]byte, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		before[file] = data
+	}
+
+	_, styleErr := style(true, files)
+
+	for _, file := range files {
+		after, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if !bytes.Equal(after, before[file]) {
+			result.Changed = append(result.Changed, file)
+		}
+	}
+
+	if styleErr != nil {
+		return result, fmt.Errorf("brew style found offenses it cannot fix: %w", styleErr)
+	}
+	return result, nil
+}
+
+// tapPath returns how brew style names a tap file in its report, e.g.
+// "Casks/tool.rb"
+func tapPath(file string) string {
+	return filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file))
+}
@@ -0,0 +1,170 @@
+package validate
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeStyle replaces brew style with one that reports or, with --fix,
+// strips trailing whitespace, failing with err afterwards when it is set
+func fakeStyle(t *testing.T, err error) *[]string {
+	t.Helper()
+	orig := style
+	t.Cleanup(func() { style = orig })
+
+	var ran []string
+	style = func(fix bool, paths []string) (string, error) {
+		ran = append(ran, paths...)
+		failure := err
+		var report strings.Builder
+		for _, path := range paths {
+			data, _ := os.ReadFile(path)
+			if !strings.Contains(string(data), " \n") {
+				continue
+			}
+			if fix {
+				os.WriteFile(path, []byte(strings.ReplaceAll(string(data), " \n", "\n")), 0644)
+				continue
+			}
+			report.WriteString(tapPath(path) + ":1:14: C: [Correctable] Layout/TrailingWhitespace\n")
+			if failure == nil {
+				failure = errors.New("exit status 1")
+			}
+		}
+		return report.String(), failure
+	}
+	return &ran
+}
+
+// writeTap creates a tap with one clean formula and one cask needing a fix
+func writeTap(t *testing.T) (root, clean, drifted string) {
+	t.Helper()
+	root = t.TempDir()
+	clean = filepath.Join(root, "Formula", "clean.rb")
+	drifted = filepath.Join(root, "Casks", "drifted.rb")
+	for path, content := range map[string]string{
+		clean:   "class Clean < Formula\nend\n",
+		drifted: "cask \"drifted\" do \nend\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root, clean, drifted
+}
+
+func TestFindRepoRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := exec.Command("git", "init", "-q", root).Run(); err != nil {
+		t.Skipf("git is not available: %v", err)
+	}
+	sub := filepath.Join(root, "Formula")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(sub)
+
+	got, err := FindRepoRoot()
+	if err != nil {
+		t.Fatalf("FindRepoRoot() error = %v", err)
+	}
+	want, _ := filepath.EvalSymlinks(root)
+	if got != want {
+		t.Errorf("FindRepoRoot() = %s, want %s", got, want)
+	}
+
+	t.Chdir(t.TempDir())
+	if _, err := FindRepoRoot(); err == nil {
+		t.Error("FindRepoRoot() outside a repository returned no error")
+	}
+}
+
+func TestTapFiles(t *testing.T) {
+	root, clean, drifted := writeTap(t)
+	if err := os.WriteFile(filepath.Join(root, "README.rb"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := TapFiles(root)
+	if err != nil {
+		t.Fatalf("TapFiles() error = %v", err)
+	}
+	if want := []string{clean, drifted}; !reflect.DeepEqual(files, want) {
+		t.Errorf("TapFiles() = %v, want %v", files, want)
+	}
+}
+
+func TestFormatFiles(t *testing.T) {
+	tests := []struct {
+		name        string
+		check       bool
+		wantDrifted string
+	}{
+		{"Fix keeps changes", false, "cask \"drifted\" do\nend\n"},
+		{"Check reverts changes", true, "cask \"drifted\" do \nend\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran := fakeStyle(t, nil)
+			_, clean, drifted := writeTap(t)
+
+			result, err := FormatFiles([]string{clean, drifted}, tt.check)
+			if err != nil {
+				t.Fatalf("FormatFiles() error = %v", err)
+			}
+			if len(*ran) != 2 {
+				t.Errorf("brew style ran on %v, want both files in one pass", *ran)
+			}
+			if !reflect.DeepEqual(result.Changed, []string{drifted}) {
+				t.Errorf("FormatFiles() changed = %v, want only %s", result.Changed, drifted)
+			}
+			if result.Check != tt.check {
+				t.Errorf("FormatFiles() check = %v, want %v", result.Check, tt.check)
+			}
+			if got, _ := os.ReadFile(drifted); string(got) != tt.wantDrifted {
+				t.Errorf("drifted cask = %q, want %q", got, tt.wantDrifted)
+			}
+		})
+	}
+
+	t.Run("Unfixable offenses", func(t *testing.T) {
+		fakeStyle(t, errors.New("exit status 1"))
+		_, clean, drifted := writeTap(t)
+
+		result, err := FormatFiles([]string{clean, drifted}, false)
+		if err == nil || !strings.Contains(err.Error(), "cannot fix") {
+			t.Fatalf("FormatFiles() error = %v, want unfixable offenses", err)
+		}
+		if result == nil || len(result.Changed) != 1 {
+			t.Errorf("FormatFiles() result = %+v, want the fixed file still reported", result)
+		}
+	})
+
+	t.Run("Check with brew failing", func(t *testing.T) {
+		fakeStyle(t, errors.New("brew not found"))
+		_, clean, _ := writeTap(t)
+
+		if _, err := FormatFiles([]string{clean}, true); err == nil {
+			t.Fatal("FormatFiles() error = nil, want the brew failure")
+		}
+	})
+
+	t.Run("No files", func(t *testing.T) {
+		ran := fakeStyle(t, nil)
+		if _, err := FormatFiles(nil, true); err != nil {
+			t.Fatalf("FormatFiles() error = %v", err)
+		}
+		if len(*ran) != 0 {
+			t.Error("FormatFiles() ran brew style with no files")
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package validate
+
+import (
+	"os"
+	"os/exec"
+)
+
+// InstallCommand builds the brew command that installs a local formula file
+// HOMEBREW_NO_INSTALL_FROM_API makes brew use the file rather than the API
+// copy; source formulas additionally need --build-from-source
+func InstallCommand(filePath string, buildFromSource bool) *exec.Cmd {
+	args := []string{"install", "--formula"}
+	if buildFromSource {
+		args = append(args, "--build-from-source")
+	}
+	args = append(args, filePath)
+
+	cmd := exec.Command("brew", args...)
+	cmd.Env = append(os.Environ(), "HOMEBREW_NO_INSTALL_FROM_API=1")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// Install installs a local formula file with brew
+func Install(filePath string, buildFromSource bool) error {
+	return InstallCommand(filePath, buildFromSource).Run()
+}
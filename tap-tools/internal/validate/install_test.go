@@ -0,0 +1,43 @@
+package validate
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestInstallCommand(t *testing.T) {
+	tests := []struct {
+		name            string
+		filePath        string
+		buildFromSource bool
+		wantArgs        []string
+	}{
+		{
+			name:     "Binary formula",
+			filePath: "Formula/ripgrep.rb",
+			wantArgs: []string{"install", "--formula", "Formula/ripgrep.rb"},
+		},
+		{
+			name:            "Source formula",
+			filePath:        "Formula/jq.rb",
+			buildFromSource: true,
+			wantArgs:        []string{"install", "--formula", "--build-from-source", "Formula/jq.rb"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := InstallCommand(tt.filePath, tt.buildFromSource)
+
+			if cmd.Args[0] != "brew" {
+				t.Errorf("InstallCommand() runs %q, want brew", cmd.Args[0])
+			}
+			if !slices.Equal(cmd.Args[1:], tt.wantArgs) {
+				t.Errorf("InstallCommand() args = %v, want %v", cmd.Args[1:], tt.wantArgs)
+			}
+			if !slices.Contains(cmd.Env, "HOMEBREW_NO_INSTALL_FROM_API=1") {
+				t.Error("InstallCommand() should set HOMEBREW_NO_INSTALL_FROM_API=1")
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/castrojo/tap-tools/internal/archive"
+	"github.com/castrojo/tap-tools/internal/platform"
+)
+
+// VersionFlags are tried in order to check that a binary runs; --help and
+// -h cover CLIs without a version flag
+var VersionFlags = []string{"--version", "-v", "-V", "version", "--help", "-h"}
+
+// RunTimeout bounds each execution attempt of RunBinary
+const RunTimeout = 5 * time.Second
+
+// RunBinary runs the binary at path with each of VersionFlags until one
+// exits successfully, returning that flag. Each attempt runs in an empty
+// temporary directory that is also HOME, so the binary cannot touch the
+// caller's files or config.
+//
+// A binary that starts but fails every flag returns "" and no error, as
+// some CLIs insist on specific arguments. An error means the binary could
+// not be executed at all (wrong architecture, corrupt, missing interpreter)
+// or crashed on a signal.
+func RunBinary(path string, timeout time.Duration) (string, error) {
+	dir, err := os.MkdirTemp("", "tap-run-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create sandbox directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, flag := range VersionFlags {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		cmd := exec.CommandContext(ctx, path, flag)
+		cmd.Dir = dir
+		cmd.Env = []string{"HOME=" + dir, "TMPDIR=" + dir, "PATH=" + os.Getenv("PATH"), "LANG=C"}
+		err := cmd.Run()
+		timedOut := ctx.Err() != nil
+		cancel()
+
+		if err == nil {
+			return flag, nil
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			if errors.Is(err, syscall.ENOEXEC) {
+				return "", fmt.Errorf("%s is not executable on this host (wrong architecture or corrupt): %w", filepath.Base(path), err)
+			}
+			return "", fmt.Errorf("failed to execute %s: %w", filepath.Base(path), err)
+		}
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() && !timedOut {
+			return "", fmt.Errorf("%s %s crashed: %s", filepath.Base(path), flag, status.Signal())
+		}
+	}
+
+	return "", nil
+}
+
+// RunBinaryData writes an extracted binary to a temporary executable named
+// name and checks it with RunBinary
+func RunBinaryData(data []byte, name string, timeout time.Duration) (string, error) {
+	dir, err := os.MkdirTemp("", "tap-binary-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, filepath.Base(name))
+	if err := os.WriteFile(path, data, 0755); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return RunBinary(path, timeout)
+}
+
+// VerifyRun checks an extracted binary for --verify-run. ok reports that it
+// answered one of VersionFlags, and message describes the outcome either
+// way, including a binary for another architecture that was not run. An
+// error means the binary cannot run at all.
+func VerifyRun(binary []byte, name string, arch platform.Architecture) (message string, ok bool, err error) {
+	if !platform.RunsOnHost(arch) {
+		return fmt.Sprintf("Skipping --verify-run: a %s binary cannot run on this host", arch), false, nil
+	}
+
+	flag, err := RunBinaryData(binary, name, RunTimeout)
+	if err != nil {
+		return "", false, fmt.Errorf("--verify-run failed: %w", err)
+	}
+	if flag == "" {
+		return fmt.Sprintf("%s runs, but none of %s succeeded; it may need specific arguments",
+			name, strings.Join(VersionFlags, ", ")), false, nil
+	}
+	return fmt.Sprintf("Binary runs: %s %s", name, flag), true, nil
+}
+
+// CheckLinkage describes how a binary is linked. static reports a
+// statically linked binary; otherwise message is a warning, ending with
+// hint, when it needs shared libraries beyond the C runtime that files do
+// not bundle, and "" when there is nothing to report.
+func CheckLinkage(binary []byte, name string, files []string, hint string) (message string, static bool) {
+	if archive.IsStaticELF(binary) {
+		return fmt.Sprintf("%s is statically linked", name), true
+	}
+	needed, err := archive.NeededLibraries(binary)
+	if err != nil || len(needed) == 0 || archive.HasBundledLibraries(files) {
+		return "", false
+	}
+	return fmt.Sprintf("%s is dynamically linked against %s, which the archive does not bundle; %s",
+		name, strings.Join(needed, ", "), hint), false
+}
@@ -0,0 +1,133 @@
+package validate
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/castrojo/tap-tools/internal/platform"
+)
+
+func TestRunBinaryData(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		wantFlag string
+		wantErr  string
+	}{
+		{
+			name:     "Version flag works",
+			script:   "#!/bin/sh\n[ \"$1\" = --version ] && echo tool 1.0.0\n",
+			wantFlag: "--version",
+		},
+		{
+			name:     "Only --help works",
+			script:   "#!/bin/sh\n[ \"$1\" = --help ]\n",
+			wantFlag: "--help",
+		},
+		{
+			name:     "Runs in an empty sandbox HOME",
+			script:   "#!/bin/sh\n[ \"$PWD\" = \"$HOME\" ] && [ -z \"$(ls -A)\" ]\n",
+			wantFlag: "--version",
+		},
+		{
+			name:   "Every flag fails",
+			script: "#!/bin/sh\nexit 2\n",
+		},
+		{
+			name:   "Hangs until the timeout",
+			script: "#!/bin/sh\nexec sleep 10\n",
+		},
+		{
+			name:    "Crashes",
+			script:  "#!/bin/sh\nkill -SEGV $$\n",
+			wantErr: "crashed",
+		},
+		{
+			name:    "Not an executable format",
+			script:  "\x7fELF\x02\x01\x01garbage",
+			wantErr: "not executable on this host",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flag, err := RunBinaryData([]byte(tt.script), "tool", 200*time.Millisecond)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("RunBinaryData() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RunBinaryData() error = %v", err)
+			}
+			if flag != tt.wantFlag {
+				t.Errorf("RunBinaryData() = %q, want %q", flag, tt.wantFlag)
+			}
+		})
+	}
+}
+
+func TestVerifyRun(t *testing.T) {
+	other := platform.ArchARM64
+	if runtime.GOARCH == "arm64" {
+		other = platform.ArchX86_64
+	}
+
+	tests := []struct {
+		name        string
+		script      string
+		arch        platform.Architecture
+		wantMessage string
+		wantOK      bool
+		wantErr     bool
+	}{
+		{"Runs", "#!/bin/sh\nexit 0\n", platform.ArchUnknown, "Binary runs: tool --version", true, false},
+		{"No flag succeeds", "#!/bin/sh\nexit 2\n", platform.ArchUnknown, "tool runs, but none of", false, false},
+		{"Other architecture is skipped", "#!/bin/sh\nexit 0\n", other, "Skipping --verify-run", false, false},
+		{"Cannot execute", "\x7fELF\x02\x01\x01garbage", platform.ArchUnknown, "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, ok, err := VerifyRun([]byte(tt.script), "tool", tt.arch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyRun() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ok != tt.wantOK || !strings.HasPrefix(message, tt.wantMessage) {
+				t.Errorf("VerifyRun() = %q, %v, want %q..., %v", message, ok, tt.wantMessage, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestCheckLinkage(t *testing.T) {
+	tests := []struct {
+		name        string
+		fixture     string
+		files       []string
+		wantMessage string
+		wantStatic  bool
+	}{
+		{"Static", "../archive/testdata/static-x86_64", nil, "tool is statically linked", true},
+		{"Only the C runtime", "../archive/testdata/glibc-2.34-x86_64", nil, "", false},
+		{"Missing library", "../archive/testdata/zlib-x86_64", []string{"tool"}, "tool is dynamically linked against libz.so.1, which the archive does not bundle; use a static build", false},
+		{"Bundled library", "../archive/testdata/zlib-x86_64", []string{"tool", "lib/libz.so.1"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binary, err := os.ReadFile(tt.fixture)
+			if err != nil {
+				t.Fatalf("Failed to read fixture: %v", err)
+			}
+			message, static := CheckLinkage(binary, "tool", tt.files, "use a static build")
+			if message != tt.wantMessage || static != tt.wantStatic {
+				t.Errorf("CheckLinkage() = %q, %v, want %q, %v", message, static, tt.wantMessage, tt.wantStatic)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package validate
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -13,6 +14,7 @@ type ValidateResult struct {
 	StylePassed bool
 	Fixed       bool
 	Errors      []string
+	Warnings    []string
 }
 
 // ValidateFile validates a formula or cask file using brew audit and brew style
@@ -23,13 +25,21 @@ func ValidateFile(filePath string, isCask bool, autoFix bool) (*ValidateResult,
 		StylePassed: true,
 		Fixed:       false,
 		Errors:      []string{},
+		Warnings:    []string{},
+	}
+
+	// Flag hardcoded versions that would break the next bump
+	if isCask {
+		if content, err := os.ReadFile(filePath); err == nil {
+			result.Warnings = append(result.Warnings, CheckVersionInterpolation(string(content), "")...)
+		}
 	}
 
 	// Skip brew audit - it requires the file to be in a tapped repository
 	// The pre-commit hook will run audit after the file is committed to the tap
 
 	// Run brew style (with --fix if autoFix is true)
-	if err := runStyle(filePath, autoFix); err != nil {
+	if err := runStyle(os.Stdout, autoFix, filePath); err != nil {
 		result.StylePassed = false
 		result.Errors = append(result.Errors, fmt.Sprintf("style check failed: %v", err))
 		// Return error only if style check failed
@@ -56,15 +66,16 @@ func runAudit(filePath string, isCask bool) error {
 	return cmd.Run()
 }
 
-func runStyle(filePath string, fix bool) error {
+// runStyle runs brew style over paths, writing its report to stdout
+func runStyle(stdout io.Writer, fix bool, paths ...string) error {
 	args := []string{"style"}
 	if fix {
 		args = append(args, "--fix")
 	}
-	args = append(args, filePath)
+	args = append(args, paths...)
 
 	cmd := exec.Command("brew", args...)
-	cmd.Stdout = os.Stdout
+	cmd.Stdout = stdout
 	cmd.Stderr = os.Stderr
 	return cmd.Run()
 }
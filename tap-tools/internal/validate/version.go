@@ -0,0 +1,54 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// versionStanzaPattern matches a cask's `version "x"` line
+	versionStanzaPattern = regexp.MustCompile(`(?m)^\s*version\s+"([^"]+)"`)
+
+	// interpolatedStanzaPattern matches the first string argument of the
+	// stanzas that should reference the version through #{version}
+	interpolatedStanzaPattern = regexp.MustCompile(`^\s*(url|binary|artifact)\s+"([^"]*)"`)
+)
+
+// CheckVersionInterpolation warns when the literal version appears in a
+// cask's url or in binary/artifact source paths instead of "#{version}",
+// since a hardcoded version breaks those lines on the next `brew bump`.
+// If version is empty, it is read from the content's version stanza.
+func CheckVersionInterpolation(content, version string) []string {
+	if version == "" {
+		if m := versionStanzaPattern.FindStringSubmatch(content); m != nil {
+			version = m[1]
+		}
+	}
+	version = strings.TrimPrefix(version, "v")
+	if version == "" {
+		return nil
+	}
+
+	// Match the version only when it is not part of a longer number,
+	// e.g. "1.2.3" must not match inside "11.2.3" or "1.2.30"
+	literal := regexp.MustCompile(`(?:^|[^0-9.])` + regexp.QuoteMeta(version) + `(?:$|[^0-9.]|\.[^0-9])`)
+
+	var warnings []string
+	for i, line := range strings.Split(content, "\n") {
+		m := interpolatedStanzaPattern.FindStringSubmatch(line)
+		if m == nil || !literal.MatchString(m[2]) {
+			continue
+		}
+
+		stanza := m[1]
+		switch stanza {
+		case "url":
+			warnings = append(warnings, fmt.Sprintf("line %d: url contains literal version %s, use #{version} (tap-cask --template-url)", i+1, version))
+		default:
+			warnings = append(warnings, fmt.Sprintf("line %d: %s path %q contains literal version %s, use #{version}", i+1, stanza, m[2], version))
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,90 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckVersionInterpolation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		version string
+		want    []string // substrings, one per expected warning
+	}{
+		{
+			name: "Literal version in binary path",
+			content: `cask "tool-linux" do
+  version "1.2.3"
+  url "https://github.com/o/tool/releases/download/v#{version}/tool-#{version}.tar.gz"
+  binary "tool-1.2.3/tool", target: "tool"
+end`,
+			want: []string{`line 4: binary path "tool-1.2.3/tool"`},
+		},
+		{
+			name: "Literal version in url and artifact",
+			content: `cask "tool-linux" do
+  version "1.2.3"
+  url "https://github.com/o/tool/releases/download/v1.2.3/tool.tar.gz"
+  binary "tool", target: "tool"
+  artifact "tool-1.2.3/tool.desktop", target: "#{Dir.home}/.local/share/applications/tool.desktop"
+end`,
+			want: []string{"line 3: url contains literal version 1.2.3", `line 5: artifact path "tool-1.2.3/tool.desktop"`},
+		},
+		{
+			name: "Fully interpolated",
+			content: `cask "tool-linux" do
+  version "1.2.3"
+  url "https://github.com/o/tool/releases/download/v#{version}/tool-#{version}.tar.gz"
+  binary "tool-#{version}/tool", target: "tool"
+end`,
+		},
+		{
+			name: "Tag version with v prefix",
+			content: `cask "tool-linux" do
+  version "v2.0.1"
+  url "https://github.com/o/tool/releases/download/v2.0.1/tool.tar.gz"
+end`,
+			want: []string{"line 3: url contains literal version 2.0.1"},
+		},
+		{
+			name: "Longer number is not a match",
+			content: `cask "tool-linux" do
+  version "1.2.3"
+  url "https://example.com/tool-#{version}.tar.gz"
+  binary "tool-11.2.3/tool", target: "tool"
+  artifact "lib-1.2.30/tool.png", target: "/tmp/tool.png"
+end`,
+		},
+		{
+			name: "Explicit version overrides stanza",
+			content: `cask "tool-linux" do
+  version "1.2.3"
+  binary "tool-4.5.6/tool", target: "tool"
+end`,
+			version: "4.5.6",
+			want:    []string{`line 3: binary path "tool-4.5.6/tool"`},
+		},
+		{
+			name: "Version latest",
+			content: `cask "tool-linux" do
+  version :latest
+  url "https://example.com/tool.tar.gz"
+end`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CheckVersionInterpolation(tt.content, tt.version)
+			if len(got) != len(tt.want) {
+				t.Fatalf("CheckVersionInterpolation() = %q, want %d warning(s)", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("warning[%d] = %q, want it to contain %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
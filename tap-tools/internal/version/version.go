@@ -0,0 +1,167 @@
+// Package version provides helpers for comparing upstream release versions.
+package version
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Normalize strips a leading "v" or "V" from a tag name
+// Example: "v1.2.3" -> "1.2.3"
+func Normalize(v string) string {
+	v = strings.TrimSpace(v)
+	if len(v) > 1 && (v[0] == 'v' || v[0] == 'V') && v[1] >= '0' && v[1] <= '9' {
+		return v[1:]
+	}
+	return v
+}
+
+// IsVersion reports whether a tag looks like a version number
+// (starts with a digit after stripping the "v" prefix)
+func IsVersion(v string) bool {
+	v = Normalize(v)
+	return len(v) > 0 && v[0] >= '0' && v[0] <= '9'
+}
+
+// Compare compares two version strings
+// Returns -1 if a < b, 0 if a == b, and 1 if a > b
+// Dotted components are compared numerically when both are numbers,
+// so "1.10.0" is newer than "1.9.0", and plain build numbers such as
+// "4200" compare as integers. A pre-release suffix ("-rc1", "-beta.2")
+// sorts before the release it precedes, following semver precedence;
+// build metadata after "+" is ignored.
+func Compare(a, b string) int {
+	aCore, aPre := split(a)
+	bCore, bPre := split(b)
+
+	if c := compareDotted(aCore, bCore); c != 0 {
+		return c
+	}
+	return comparePrerelease(aPre, bPre)
+}
+
+// split separates the release part of a version from its pre-release
+// suffix, dropping the "v" prefix and any build metadata. Only a suffix
+// starting with a letter counts as a pre-release, so dashed dates like
+// "2024-01-15" stay part of the release.
+func split(v string) (core, pre string) {
+	v = Normalize(v)
+	if i := strings.IndexByte(v, '+'); i != -1 {
+		v = v[:i]
+	}
+	if i := strings.IndexByte(v, '-'); i != -1 && i+1 < len(v) && isLetter(v[i+1]) {
+		return v[:i], v[i+1:]
+	}
+	return v, ""
+}
+
+// compareDotted compares dot-separated components, treating missing
+// components as "0"
+func compareDotted(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		aPart, bPart := "0", "0"
+		if i < len(aParts) {
+			aPart = aParts[i]
+		}
+		if i < len(bParts) {
+			bPart = bParts[i]
+		}
+
+		if c := comparePart(aPart, bPart); c != 0 {
+			return c
+		}
+	}
+
+	return 0
+}
+
+// comparePrerelease orders pre-release suffixes: no suffix is newest,
+// identifiers are compared one dot-separated field at a time, and a
+// shorter list of otherwise equal fields sorts first
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	aIDs := strings.Split(a, ".")
+	bIDs := strings.Split(b, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		if c := compareIdentifier(aIDs[i], bIDs[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(aIDs), len(bIDs))
+}
+
+// compareIdentifier compares one pre-release field. Numbers sort before
+// words, as in semver; a word with a trailing number like "rc10" compares
+// the number numerically, so "rc2" < "rc10".
+func compareIdentifier(a, b string) int {
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInt(aNum, bNum)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+
+	aWord, aSuffix := splitTrailingDigits(a)
+	bWord, bSuffix := splitTrailingDigits(b)
+	if c := strings.Compare(strings.ToLower(aWord), strings.ToLower(bWord)); c != 0 {
+		return c
+	}
+	return comparePart(aSuffix, bSuffix)
+}
+
+// splitTrailingDigits splits "rc10" into "rc" and "10"; the number is "0"
+// when there is none, so "beta" equals "beta0"
+func splitTrailingDigits(s string) (string, string) {
+	i := len(s)
+	for i > 0 && s[i-1] >= '0' && s[i-1] <= '9' {
+		i--
+	}
+	if i == len(s) {
+		return s, "0"
+	}
+	return s[:i], s[i:]
+}
+
+// comparePart compares a single dotted component
+func comparePart(a, b string) int {
+	aNum, aErr := strconv.Atoi(a)
+	bNum, bErr := strconv.Atoi(b)
+
+	if aErr == nil && bErr == nil {
+		return compareInt(aNum, bNum)
+	}
+
+	return strings.Compare(a, b)
+}
+
+// compareInt returns -1, 0 or 1 as a is less than, equal to or greater than b
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// isLetter reports whether c is an ASCII letter
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,66 @@
+package version
+
+import (
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"v1.2.3", "1.2.3"},
+		{"V1.2.3", "1.2.3"},
+		{"1.2.3", "1.2.3"},
+		{"version", "version"},
+		{"4200", "4200"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := Normalize(tt.input); got != tt.expected {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want int
+	}{
+		{"Equal", "1.2.3", "1.2.3", 0},
+		{"Equal with prefix", "v1.2.3", "1.2.3", 0},
+		{"Numeric ordering", "1.10.0", "1.9.0", 1},
+		{"Older patch", "1.2.3", "1.2.4", -1},
+		{"Missing component", "1.2", "1.2.0", 0},
+		{"Build numbers", "4200", "4192", 1},
+		{"Build numbers of different length", "4200", "999", 1},
+		{"Prefix on both", "v2.0.0", "V1.9.9", 1},
+		{"Release after rc", "1.0.0", "1.0.0-rc1", 1},
+		{"Rc before release", "1.0.0-rc1", "1.0.0", -1},
+		{"Rc after older release", "1.0.0-rc1", "0.9.9", 1},
+		{"Alpha before beta", "1.0.0-alpha", "1.0.0-beta", -1},
+		{"Beta before rc", "1.0.0-beta", "1.0.0-rc1", -1},
+		{"Rc numbers", "1.0.0-rc10", "1.0.0-rc2", 1},
+		{"Dotted prerelease numbers", "1.0.0-beta.11", "1.0.0-beta.2", 1},
+		{"Numeric before alphanumeric identifier", "1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"Shorter prerelease first", "1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"Equal prerelease", "v1.0.0-rc.1", "1.0.0-rc.1", 0},
+		{"Prerelease case", "1.0.0-RC1", "1.0.0-rc1", 0},
+		{"Build metadata ignored", "1.0.0+build.5", "1.0.0", 0},
+		{"Dashed date is not a prerelease", "2024-01-15", "2024-01-09", 1},
+		{"Dashed date after shorter", "2024-01-15", "2024", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compare(tt.a, tt.b); got != tt.want {
+				t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}